@@ -1,11 +1,19 @@
 package api
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/isbn"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/jsonpatch"
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -34,7 +42,10 @@ func NewBookHandler(bookStore store.BookStore, logger *log.Logger) *BookHandler
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "The id of the book"
+// @Param        If-None-Match header string false "ETag of the copy the client has"
 // @Success      200 {object} store.Book
+// @Success      304 "Not modified"
+// @Header       200 {string} ETag "Version of the book"
 // @Failure      404 {object} HTTPError "Error: Book not found"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
 // @Router       /books/{id} [get]
@@ -58,20 +69,128 @@ func (bh *BookHandler) HandleGetBookByID(ctx *gin.Context) {
 		return
 	}
 
+	if utils.NotModified(ctx, book.Version) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, book)
+}
+
+// HandleGetBookByISBN godoc
+// @Summary      Get a book by ISBN
+// @Description  Retrieves a catalog book by its ISBN-13 or ISBN-10. Hyphens and spaces are ignored.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        isbn path string true "ISBN-13 or ISBN-10"
+// @Success      200 {object} store.Book
+// @Failure      400 {object} HTTPError "Error: Invalid ISBN"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/isbn/{isbn} [get]
+func (bh *BookHandler) HandleGetBookByISBN(ctx *gin.Context) {
+	isbn13, err := isbn.ToISBN13(ctx.Param("isbn"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid isbn: " + err.Error()})
+		return
+	}
+
+	book, err := bh.bookStore.GetBookByISBN13(isbn13)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookByISBN13 %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if book == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, book)
 }
 
+// ValidationError reports invalid request fields, keyed by field name.
+type ValidationError struct {
+	Error  string            `json:"error" example:"invalid book"`
+	Fields map[string]string `json:"fields"`
+}
+
 type AddBookRequest struct {
 	Title         string           `json:"title" example:"The Hobbit"`
 	Authors       []string         `json:"authors" example:"J.R.R. Tolkien"`
 	Publisher     string           `json:"publisher" example:"George Allen & Unwin"`
 	PublishedDate store.JSONDate   `json:"published_date" example:"1937-09-21"`
-	Description   *string          `json:"description,omitempty" example:"A fantasy novel..."`
-	PageCount     *int             `json:"page_count,omitempty" example:"310"`
+	Description   *string          `json:"description" example:"A fantasy novel..."`
+	PageCount     *int             `json:"page_count" example:"310"`
 	ISBN13        string           `json:"isbn_13" example:"9780261102217"`
-	ISBN10        *string          `json:"isbn_10,omitempty" example:"0261102214"`
+	ISBN10        *string          `json:"isbn_10" example:"0261102214"`
 	Images        store.BookImages `json:"book_images"`
 	Chapters      []store.Chapter  `json:"chapters"`
+	Genres        []store.Genre    `json:"genres"`
+}
+
+// book returns the catalog book the request describes.
+func (req *AddBookRequest) book() store.Book {
+	return store.Book{
+		Title:         req.Title,
+		Authors:       req.Authors,
+		Publisher:     req.Publisher,
+		PublishedDate: req.PublishedDate,
+		Description:   req.Description,
+		PageCount:     req.PageCount,
+		ISBN13:        req.ISBN13,
+		ISBN10:        req.ISBN10,
+		Images:        req.Images,
+		Chapters:      req.Chapters,
+		Genres:        req.Genres,
+	}
+}
+
+// normalizeISBNs validates the request's ISBNs, strips hyphens and spaces, and
+// fills in whichever of the two forms is missing. It returns the problems
+// found, keyed by field name.
+func (req *AddBookRequest) normalizeISBNs() map[string]string {
+	fields := map[string]string{}
+
+	if req.ISBN10 != nil && strings.TrimSpace(*req.ISBN10) == "" {
+		req.ISBN10 = nil
+	}
+	if req.ISBN10 != nil {
+		isbn10 := isbn.Normalize(*req.ISBN10)
+		if err := isbn.ValidateISBN10(isbn10); err != nil {
+			fields["isbn_10"] = err.Error()
+		}
+		req.ISBN10 = &isbn10
+	}
+
+	if strings.TrimSpace(req.ISBN13) == "" {
+		if req.ISBN10 == nil {
+			fields["isbn_13"] = "isbn_13 or isbn_10 is required"
+		} else if isbn13, err := isbn.ToISBN13(*req.ISBN10); err == nil {
+			req.ISBN13 = isbn13
+		}
+	} else {
+		req.ISBN13 = isbn.Normalize(req.ISBN13)
+		if err := isbn.ValidateISBN13(req.ISBN13); err != nil {
+			fields["isbn_13"] = err.Error()
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	isbn10, err := isbn.ToISBN10(req.ISBN13)
+	switch {
+	case req.ISBN10 == nil && err == nil:
+		req.ISBN10 = &isbn10
+	case req.ISBN10 != nil && err != nil:
+		fields["isbn_10"] = err.Error()
+	case req.ISBN10 != nil && *req.ISBN10 != isbn10:
+		fields["isbn_10"] = "isbn_10 does not match isbn_13"
+	}
+	return fields
 }
 
 // HandleAddBook godoc
@@ -79,15 +198,18 @@ type AddBookRequest struct {
 // @Description  Registers a book in the system.
 //
 //	Expects a body with the book information. Returns the created book on success.
+//	Books that look like an existing catalog book (same ISBN, or a similar title by the same author) are rejected with the likely matches; catalog editors can pass force=true to add the book anyway.
 //
 // @Tags         books
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        request body AddBookRequest true "Add book request"
+// @Param        force query bool false "Skip the duplicate check (catalog editors only)"
 // @Success      200 {object} store.Book
+// @Failure      400 {object} ValidationError "Error: Invalid book"
 // @Failure      401 {object} HTTPError "Error: Unauthorized"
-// @Failure      409 {object} HTTPError "Error: Duplicate record"
+// @Failure      409 {object} DuplicateBookError "Error: Duplicate record"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
 // @Router       /books [post]
 func (bh *BookHandler) HandleAddBook(ctx *gin.Context) {
@@ -98,17 +220,19 @@ func (bh *BookHandler) HandleAddBook(ctx *gin.Context) {
 		return
 	}
 
-	book := store.Book{
-		Title:         req.Title,
-		Authors:       req.Authors,
-		Publisher:     req.Publisher,
-		PublishedDate: req.PublishedDate,
-		Description:   req.Description,
-		PageCount:     req.PageCount,
-		ISBN13:        req.ISBN13,
-		ISBN10:        req.ISBN10,
-		Images:        req.Images,
-		Chapters:      req.Chapters,
+	fields := req.normalizeISBNs()
+	if msg := validateChapters(req.Chapters); msg != "" {
+		fields["chapters"] = msg
+	}
+	if len(fields) > 0 {
+		ctx.JSON(http.StatusBadRequest, ValidationError{Error: "invalid book", Fields: fields})
+		return
+	}
+
+	book := req.book()
+
+	if bh.rejectDuplicates(ctx, &book) {
+		return
 	}
 
 	addedBook, err := bh.bookStore.AddBook(&book)
@@ -133,16 +257,24 @@ func (bh *BookHandler) HandleAddBook(ctx *gin.Context) {
 // @Description  Updates a book's information in the system.
 //
 //	Expects a body with the book information. Returns the updated book on success.
+//	Chapters are matched to the existing ones by id, or by number when the id is left out, and updated in place so their comments are kept. Omit `chapters` to leave them unchanged. Chapters left out of the list are deleted, but chapters with comments are only deleted when delete_commented_chapters=true.
+//	Send the book's ETag in If-Match to make sure nobody else has changed it since it was read.
 //
 // @Tags         books
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
 // @Param        request body AddBookRequest true "Add book request"
+// @Param        force query bool false "Skip the duplicate check (catalog editors only)"
+// @Param        delete_commented_chapters query bool false "Allow deleting chapters that have comments"
 // @Success      200 {object} store.Book
+// @Failure      400 {object} ValidationError "Error: Invalid book"
 // @Failure      401 {object} HTTPError "Error: Unauthorized"
 // @Failure      404 {object} HTTPError "Error: User not found"
+// @Failure      409 {object} DuplicateBookError "Error: Duplicate record, or chapters with comments would be deleted"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
 // @Router       /books/{id} [put]
 func (bh *BookHandler) HandleUpdateBookByID(ctx *gin.Context) {
@@ -165,6 +297,11 @@ func (bh *BookHandler) HandleUpdateBookByID(ctx *gin.Context) {
 		return
 	}
 
+	ifMatch, ok := readIfMatch(ctx, existingBook.Version)
+	if !ok {
+		return
+	}
+
 	var req AddBookRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		bh.logger.Printf("ERROR: updateBookByID %v", err)
@@ -172,7 +309,17 @@ func (bh *BookHandler) HandleUpdateBookByID(ctx *gin.Context) {
 		return
 	}
 
+	fields := req.normalizeISBNs()
+	if msg := validateChapters(req.Chapters); msg != "" {
+		fields["chapters"] = msg
+	}
+	if len(fields) > 0 {
+		ctx.JSON(http.StatusBadRequest, ValidationError{Error: "invalid book", Fields: fields})
+		return
+	}
+
 	book := store.Book{
+		ID:            bookID,
 		Title:         req.Title,
 		Authors:       req.Authors,
 		Publisher:     req.Publisher,
@@ -183,25 +330,389 @@ func (bh *BookHandler) HandleUpdateBookByID(ctx *gin.Context) {
 		ISBN10:        req.ISBN10,
 		Images:        req.Images,
 		Chapters:      req.Chapters,
+		Genres:        req.Genres,
+	}
+
+	if bh.rejectDuplicates(ctx, &book) {
+		return
 	}
 
-	err = bh.bookStore.UpdateBook(&book)
+	opts := store.UpdateBookOptions{
+		DeleteCommentedChapters: ctx.Query("delete_commented_chapters") == "true",
+		EditorID:                editorID(ctx),
+		IfMatch:                 ifMatch,
+	}
+	bh.updateBook(ctx, &book, opts)
+}
+
+// updateBook saves the book and responds with its updated data.
+func (bh *BookHandler) updateBook(ctx *gin.Context, book *store.Book, opts store.UpdateBookOptions) {
+	err := bh.bookStore.UpdateBook(book, opts)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "book with this ISBN already exists"})
+			return
+		}
+		var deletionErr *store.ChapterDeletionError
+		if errors.As(err, &deletionErr) {
+			ctx.JSON(http.StatusConflict, ChapterDeletionConflict{
+				Error:      "removing these chapters would delete their comments; pass delete_commented_chapters=true to confirm",
+				ChapterIDs: deletionErr.ChapterIDs,
+			})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		if errors.Is(err, store.ErrChapterNotInBook) || errors.Is(err, store.ErrDuplicateChapter) {
+			ctx.JSON(http.StatusBadRequest, ValidationError{
+				Error:  "invalid book",
+				Fields: map[string]string{"chapters": errors.Unwrap(err).Error()},
+			})
+			return
+		}
 		bh.logger.Printf("ERROR: updateBookByID %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
-	updatedBook, err := bh.bookStore.GetBookByID(bookID)
+	updatedBook, err := bh.bookStore.GetBookByID(book.ID)
 	if err != nil {
 		bh.logger.Printf("ERROR: getBookByID %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	utils.SetETag(ctx, updatedBook.Version)
 	ctx.JSON(http.StatusOK, updatedBook)
 }
 
+// readIfMatch reads the versions listed in the If-Match header. When they do
+// not include the record's current version it responds 412 Precondition
+// Failed and returns false.
+func readIfMatch(ctx *gin.Context, version int) ([]int, bool) {
+	ifMatch := utils.ReadIfMatch(ctx)
+	if ifMatch != nil && !slices.Contains(ifMatch, version) {
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "record has been modified since it was read"})
+		return nil, false
+	}
+	return ifMatch, true
+}
+
+// readBookIfMatch checks the If-Match header against the book's version for
+// handlers that change a part of the book. It writes an error response and
+// returns false when the book does not exist or has been modified.
+func readBookIfMatch(ctx *gin.Context, bookStore store.BookStore, logger *log.Logger, bookID int64) ([]int, bool) {
+	book, err := bookStore.GetBookByID(bookID)
+	if err != nil {
+		logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+	if book == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return nil, false
+	}
+	return readIfMatch(ctx, book.Version)
+}
+
+// setBookETag sets the ETag header to the book's version after a change. It
+// writes an error response and returns false when the book can't be read.
+func setBookETag(ctx *gin.Context, bookStore store.BookStore, logger *log.Logger, bookID int64) bool {
+	book, err := bookStore.GetBookByID(bookID)
+	if err != nil || book == nil {
+		logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return false
+	}
+	utils.SetETag(ctx, book.Version)
+	return true
+}
+
+// Content types accepted by PATCH /books/{id}.
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// HandlePatchBookByID godoc
+// @Summary      Partially update a book
+// @Description  Applies a JSON Merge Patch (RFC 7396, the default) or a JSON Patch (RFC 6902, with Content-Type application/json-patch+json) to a book. The patch targets the same document as the update request; only the fields it changes are written.
+//
+//	Changing one ISBN form re-derives the other. Chapters follow the same rules as a full update, so removing chapters with comments requires delete_commented_chapters=true. A failed JSON Patch test operation returns 409.
+//	Send the book's ETag in If-Match to make sure the patch applies to the version that was read.
+//
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being patched"
+// @Param        request body object true "Merge patch object or JSON Patch operations"
+// @Param        force query bool false "Skip the duplicate check (catalog editors only)"
+// @Param        delete_commented_chapters query bool false "Allow deleting chapters that have comments"
+// @Success      200 {object} store.Book
+// @Failure      400 {object} ValidationError "Error: Invalid patch or book"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      409 {object} DuplicateBookError "Error: Duplicate record, failed test operation, or chapters with comments would be deleted"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      415 {object} HTTPError "Error: Unsupported patch format"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id} [patch]
+func (bh *BookHandler) HandlePatchBookByID(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	var applyPatch func(doc, patch []byte) ([]byte, error)
+	switch ctx.ContentType() {
+	case mergePatchContentType, "application/json", "":
+		applyPatch = jsonpatch.MergePatch
+	case jsonPatchContentType:
+		applyPatch = jsonpatch.Apply
+	default:
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "content type must be " + mergePatchContentType + " or " + jsonPatchContentType,
+		})
+		return
+	}
+
+	existingBook, err := bh.bookStore.GetBookByID(bookID)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if existingBook == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	if _, ok := readIfMatch(ctx, existingBook.Version); !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		bh.logger.Printf("ERROR: patchBookByID %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	current := bookUpdateRequest(existingBook)
+	doc, err := json.Marshal(current)
+	if err != nil {
+		bh.logger.Printf("ERROR: patchBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	patched, err := applyPatch(doc, patch)
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req AddBookRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "patched book is invalid: " + err.Error()})
+		return
+	}
+
+	changed, err := changedRequestFields(current, req)
+	if err != nil {
+		bh.logger.Printf("ERROR: patchBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if len(changed) == 0 {
+		utils.SetETag(ctx, existingBook.Version)
+		ctx.JSON(http.StatusOK, existingBook)
+		return
+	}
+
+	// A new ISBN in one form replaces the stored one in the other form.
+	fields := map[string]string{}
+	isbn13Changed := slices.Contains(changed, store.BookFieldISBN13)
+	isbn10Changed := slices.Contains(changed, store.BookFieldISBN10)
+	if isbn13Changed || isbn10Changed {
+		if !isbn10Changed {
+			req.ISBN10 = nil
+			changed = append(changed, store.BookFieldISBN10)
+		}
+		if !isbn13Changed {
+			req.ISBN13 = ""
+			changed = append(changed, store.BookFieldISBN13)
+		}
+		fields = req.normalizeISBNs()
+	}
+	if slices.Contains(changed, store.BookFieldChapters) {
+		if msg := validateChapters(req.Chapters); msg != "" {
+			fields["chapters"] = msg
+		}
+	}
+	if len(fields) > 0 {
+		ctx.JSON(http.StatusBadRequest, ValidationError{Error: "invalid book", Fields: fields})
+		return
+	}
+
+	book := store.Book{
+		ID:            bookID,
+		Title:         req.Title,
+		Authors:       req.Authors,
+		Publisher:     req.Publisher,
+		PublishedDate: req.PublishedDate,
+		Description:   req.Description,
+		PageCount:     req.PageCount,
+		ISBN13:        req.ISBN13,
+		ISBN10:        req.ISBN10,
+		Images:        req.Images,
+		Chapters:      req.Chapters,
+		Genres:        req.Genres,
+	}
+
+	if slices.ContainsFunc(changed, func(field string) bool {
+		return field == store.BookFieldTitle || field == store.BookFieldAuthors || field == store.BookFieldISBN13
+	}) && bh.rejectDuplicates(ctx, &book) {
+		return
+	}
+
+	opts := store.UpdateBookOptions{
+		DeleteCommentedChapters: ctx.Query("delete_commented_chapters") == "true",
+		EditorID:                editorID(ctx),
+		Fields:                  changed,
+		// The patch was applied to this version, so it must not be written
+		// over a newer one.
+		IfMatch: []int{existingBook.Version},
+	}
+	bh.updateBook(ctx, &book, opts)
+}
+
+// bookUpdateRequest returns the update request that would leave the book as
+// it is. It is the document a patch is applied to.
+func bookUpdateRequest(book *store.Book) AddBookRequest {
+	return AddBookRequest{
+		Title:         book.Title,
+		Authors:       book.Authors,
+		Publisher:     book.Publisher,
+		PublishedDate: book.PublishedDate,
+		Description:   book.Description,
+		PageCount:     book.PageCount,
+		ISBN13:        book.ISBN13,
+		ISBN10:        book.ISBN10,
+		Images:        book.Images,
+		Chapters:      book.Chapters,
+		Genres:        book.Genres,
+	}
+}
+
+// changedRequestFields returns the names of the top-level fields that differ
+// between two update requests.
+func changedRequestFields(before, after AddBookRequest) ([]string, error) {
+	beforeFields, err := requestFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := requestFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for _, field := range store.VersionedBookFields {
+		if !bytes.Equal(beforeFields[field], afterFields[field]) {
+			changed = append(changed, field)
+		}
+	}
+	return changed, nil
+}
+
+func requestFields(req AddBookRequest) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// ChapterDeletionConflict lists the chapters an update would delete along with
+// their comments.
+type ChapterDeletionConflict struct {
+	Error      string  `json:"error"`
+	ChapterIDs []int64 `json:"chapter_ids"`
+}
+
+// DuplicateBookError lists the catalog books a new or updated book appears to
+// duplicate.
+type DuplicateBookError struct {
+	Error      string                      `json:"error" example:"book looks like a duplicate"`
+	Duplicates []*store.DuplicateCandidate `json:"duplicates"`
+}
+
+// rejectDuplicates writes a 409 and returns true when book looks like another
+// catalog book. Catalog editors can skip the check with force=true.
+func (bh *BookHandler) rejectDuplicates(ctx *gin.Context, book *store.Book) bool {
+	return rejectDuplicateBook(ctx, bh.bookStore, bh.logger, book)
+}
+
+// rejectDuplicateBook is rejectDuplicates for handlers that add catalog books
+// outside BookHandler.
+func rejectDuplicateBook(ctx *gin.Context, bookStore store.BookStore, logger *log.Logger, book *store.Book) bool {
+	userValue, _ := ctx.Get("user")
+	if user, _ := userValue.(*store.User); user.Can(store.PermEditCatalog) && ctx.Query("force") == "true" {
+		return false
+	}
+
+	duplicates, err := bookStore.FindDuplicateBooks(book)
+	if err != nil {
+		logger.Printf("ERROR: findDuplicateBooks %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return true
+	}
+
+	if len(duplicates) == 0 {
+		return false
+	}
+
+	ctx.JSON(http.StatusConflict, DuplicateBookError{
+		Error:      "book looks like a duplicate",
+		Duplicates: duplicates,
+	})
+	return true
+}
+
+// HandleDeleteBookByID godoc
+// @Summary      Archive a book
+// @Description  Removes a book from the catalog without deleting it: it no longer shows up in listings or search, but readers who shelved it keep it, with its chapters and comments. Admins can restore it or purge it for good.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being archived"
+// @Success      204 "Book archived"
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book not found or already archived"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id} [delete]
 func (bh *BookHandler) HandleDeleteBookByID(ctx *gin.Context) {
 	bookID, err := utils.ReadIDParam(ctx)
 	if err != nil {
@@ -210,12 +721,15 @@ func (bh *BookHandler) HandleDeleteBookByID(ctx *gin.Context) {
 		return
 	}
 
-	if err := bh.bookStore.DeleteBookByID(bookID); err != nil {
+	if err := bh.bookStore.ArchiveBook(bookID, utils.ReadIfMatch(ctx)); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
 			return
+		} else if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
 		} else {
-			bh.logger.Printf("ERROR: deleteBookByID %v", err)
+			bh.logger.Printf("ERROR: archiveBook %v", err)
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 			return
 		}
@@ -224,6 +738,157 @@ func (bh *BookHandler) HandleDeleteBookByID(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// HandleRestoreBook godoc
+// @Summary      Restore an archived book
+// @Description  Returns an archived book to the catalog.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Success      200 {object} store.Book
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: No archived book with this id"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/restore [post]
+func (bh *BookHandler) HandleRestoreBook(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	if err := bh.bookStore.RestoreBook(bookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "archived book not found"})
+			return
+		}
+		bh.logger.Printf("ERROR: restoreBook %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	book, err := bh.bookStore.GetBookByID(bookID)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, book)
+}
+
+// PurgeConfirmationRequired is returned by a purge that was not confirmed. It
+// reports what the purge would remove.
+type PurgeConfirmationRequired struct {
+	Error  string                 `json:"error"`
+	Report *store.BookPurgeReport `json:"report"`
+}
+
+// HandleGetBookPurgeReport godoc
+// @Summary      Preview purging a book
+// @Description  Reports what permanently deleting a book would remove: readers' shelf entries (moved to another edition of the work when there is one), chapters, comments, tags and edit suggestions.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Success      200 {object} store.BookPurgeReport
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/purge [get]
+func (bh *BookHandler) HandleGetBookPurgeReport(ctx *gin.Context) {
+	report, ok := bh.readPurgeReport(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// HandlePurgeBook godoc
+// @Summary      Permanently delete a book
+// @Description  Deletes an archived book for good, with its chapters, their comments, its tags and edit suggestions. Readers' shelf entries move to another edition of the same work, or are deleted when there is none.
+//
+//	The book must be archived first. Without confirm=true nothing is deleted and a 409 reports what would be removed.
+//
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        confirm query bool true "Confirm the purge"
+// @Success      200 {object} store.BookPurgeReport
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      409 {object} PurgeConfirmationRequired "Error: Not confirmed, or the book is not archived"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/purge [delete]
+func (bh *BookHandler) HandlePurgeBook(ctx *gin.Context) {
+	report, ok := bh.readPurgeReport(ctx)
+	if !ok {
+		return
+	}
+
+	if report.ArchivedAt == nil {
+		ctx.JSON(http.StatusConflict, PurgeConfirmationRequired{Error: store.ErrBookNotArchived.Error(), Report: report})
+		return
+	}
+
+	if ctx.Query("confirm") != "true" {
+		ctx.JSON(http.StatusConflict, PurgeConfirmationRequired{
+			Error:  "purging cannot be undone; pass confirm=true to delete the book and everything listed in the report",
+			Report: report,
+		})
+		return
+	}
+
+	if err := bh.bookStore.PurgeBook(report.BookID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		case errors.Is(err, store.ErrBookNotArchived):
+			ctx.JSON(http.StatusConflict, PurgeConfirmationRequired{Error: err.Error(), Report: report})
+		default:
+			bh.logger.Printf("ERROR: purgeBook %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+func (bh *BookHandler) readPurgeReport(ctx *gin.Context) (*store.BookPurgeReport, bool) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return nil, false
+	}
+
+	report, err := bh.bookStore.GetBookPurgeReport(bookID)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookPurgeReport %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+	if report == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return nil, false
+	}
+
+	return report, true
+}
+
 type PaginatedBooksResponse struct {
 	Books      []*store.Book `json:"books"`
 	Page       int           `json:"page"`
@@ -243,12 +908,41 @@ type PaginatedBooksResponse struct {
 // @Produce      json
 // @Param        page query int false "Page number" default(1)
 // @Param        limit query int false "Items per page" default(20)
+// @Param        q query string false "Search by title or author"
+// @Param        genre query int false "Genre id (includes sub-genres)"
+// @Param        tag query string false "User tag"
 // @Success      200 {object} PaginatedBooksResponse
 // @Failure      400 {object} HTTPError "Error: Invalid or missing id"
 // @Failure      404 {object} HTTPError "Error: Book not found"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
 // @Router       /books [get]
 func (bh *BookHandler) HandleGetAllBooks(ctx *gin.Context) {
+	bh.listBooks(ctx, store.BookFilter{})
+}
+
+// HandleGetArchivedBooks godoc
+// @Summary      List archived books
+// @Description  Lists the books that were removed from the catalog, with the same filters as the catalog listing.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Param        q query string false "Search by title or author"
+// @Param        genre query int false "Genre id (includes sub-genres)"
+// @Param        tag query string false "User tag"
+// @Success      200 {object} PaginatedBooksResponse
+// @Failure      400 {object} HTTPError "Error: Invalid filter or pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/archived [get]
+func (bh *BookHandler) HandleGetArchivedBooks(ctx *gin.Context) {
+	bh.listBooks(ctx, store.BookFilter{Archived: true})
+}
+
+func (bh *BookHandler) listBooks(ctx *gin.Context, filter store.BookFilter) {
 	page, limit, err := utils.ReadPaginationParams(ctx)
 	if err != nil {
 		bh.logger.Printf("ERROR: readPaginationParams %v", err)
@@ -256,7 +950,18 @@ func (bh *BookHandler) HandleGetAllBooks(ctx *gin.Context) {
 		return
 	}
 
-	books, total, err := bh.bookStore.GetAllBooks(page, limit)
+	filter.Query = ctx.Query("q")
+	filter.Tag = ctx.Query("tag")
+	if genreParam := ctx.Query("genre"); genreParam != "" {
+		genreID, err := strconv.ParseInt(genreParam, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid genre id"})
+			return
+		}
+		filter.GenreID = &genreID
+	}
+
+	books, total, err := bh.bookStore.GetAllBooks(page, limit, filter)
 	if err != nil {
 		bh.logger.Printf("ERROR: getAllBooks %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -273,3 +978,49 @@ func (bh *BookHandler) HandleGetAllBooks(ctx *gin.Context) {
 		TotalPages: totalPages,
 	})
 }
+
+type DuplicateReportResponse struct {
+	Duplicates []*store.DuplicatePair `json:"duplicates"`
+	Page       int                    `json:"page"`
+	Limit      int                    `json:"limit"`
+	TotalItems int                    `json:"total_items"`
+	TotalPages int                    `json:"total_pages"`
+}
+
+// HandleGetSuspectedDuplicates godoc
+// @Summary      List suspected duplicate books
+// @Description  Lists pairs of catalog books that share an ISBN-10, or an author and a similar title, and are not grouped as editions of the same work.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} DuplicateReportResponse
+// @Failure      400 {object} HTTPError "Error: Invalid pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/duplicates [get]
+func (bh *BookHandler) HandleGetSuspectedDuplicates(ctx *gin.Context) {
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	pairs, total, err := bh.bookStore.GetSuspectedDuplicates(page, limit)
+	if err != nil {
+		bh.logger.Printf("ERROR: getSuspectedDuplicates %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DuplicateReportResponse{
+		Duplicates: pairs,
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
@@ -34,12 +36,15 @@ func TestBookHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(BookHandlerTestSuite))
 }
 
+var expectedISBN10 = "0451524934"
+
 var expectedBook = &store.Book{
 	ID:        0,
 	Title:     "1984",
 	Authors:   []string{"Test"},
 	Publisher: "test",
-	ISBN13:    "TEST",
+	ISBN13:    "9780451524935",
+	ISBN10:    &expectedISBN10,
 }
 
 func (s *BookHandlerTestSuite) TestHandleAddBook_InvalidRequest() {
@@ -56,6 +61,7 @@ func (s *BookHandlerTestSuite) TestHandleAddBook_InvalidRequest() {
 }
 
 func (s *BookHandlerTestSuite) TestHandleAddBook_ErrorAddingBook() {
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
 	s.mockStore.On("AddBook", expectedBook).Return(expectedBook, fmt.Errorf("test error"))
 
 	body, _ := json.Marshal(expectedBook)
@@ -73,6 +79,7 @@ func (s *BookHandlerTestSuite) TestHandleAddBook_ErrorAddingBook() {
 }
 
 func (s *BookHandlerTestSuite) TestHandleAddBook_Success() {
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
 	s.mockStore.On("AddBook", expectedBook).Return(expectedBook, nil)
 
 	body, _ := json.Marshal(expectedBook)
@@ -241,8 +248,9 @@ func (s *BookHandlerTestSuite) TestHandleUpdateBook_InvalidUpdateRequest() {
 }
 
 func (s *BookHandlerTestSuite) TestHandleUpdateBook_ErrorUpdatingBook() {
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
 	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
-	s.mockStore.On("UpdateBook", mock.Anything).Return(fmt.Errorf("test error"))
+	s.mockStore.On("UpdateBook", mock.Anything, store.UpdateBookOptions{}).Return(fmt.Errorf("test error"))
 
 	body, _ := json.Marshal(expectedBook)
 	req, _ := http.NewRequest(http.MethodGet, "/book/1", bytes.NewBuffer(body))
@@ -285,7 +293,7 @@ func (s *BookHandlerTestSuite) TestHandleDeleteBook_InvalidID() {
 }
 
 func (s *BookHandlerTestSuite) TestHandleDeleteBook_ErrorBookNotFound() {
-	s.mockStore.On("DeleteBookByID", mock.Anything).Return(fmt.Errorf("no rows in result set"))
+	s.mockStore.On("ArchiveBook", mock.Anything, mock.Anything).Return(fmt.Errorf("no rows in result set"))
 
 	req, _ := http.NewRequest(http.MethodDelete, "/books/1", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -302,7 +310,7 @@ func (s *BookHandlerTestSuite) TestHandleDeleteBook_ErrorBookNotFound() {
 }
 
 func (s *BookHandlerTestSuite) TestHandleDeleteBook_Success() {
-	s.mockStore.On("DeleteBookByID", mock.Anything).Return(nil)
+	s.mockStore.On("ArchiveBook", mock.Anything, mock.Anything).Return(nil)
 
 	req, _ := http.NewRequest(http.MethodDelete, "/books/1", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -317,3 +325,565 @@ func (s *BookHandlerTestSuite) TestHandleDeleteBook_Success() {
 	s.Equal(http.StatusOK, w.Code)
 	s.mockStore.AssertExpectations(s.T())
 }
+
+func (s *BookHandlerTestSuite) TestHandleRestoreBook_NotArchived() {
+	s.mockStore.On("RestoreBook", int64(1)).Return(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/1/restore", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleRestoreBook(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleRestoreBook_Success() {
+	s.mockStore.On("RestoreBook", int64(1)).Return(nil)
+	s.mockStore.On("GetBookByID", int64(1)).Return(&store.Book{ID: 1, Title: "1984"}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/1/restore", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleRestoreBook(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"title":"1984"`)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func archivedBookReport() *store.BookPurgeReport {
+	archivedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	return &store.BookPurgeReport{BookID: 1, Title: "1984", ArchivedAt: &archivedAt, ShelvedBy: 3, ShelvesRemoved: 3, Chapters: 24, Comments: 80}
+}
+
+func (s *BookHandlerTestSuite) TestHandlePurgeBook_RequiresConfirmation() {
+	s.mockStore.On("GetBookPurgeReport", int64(1)).Return(archivedBookReport(), nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/books/1/purge", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandlePurgeBook(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	var resp PurgeConfirmationRequired
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal(80, resp.Report.Comments)
+	s.mockStore.AssertNotCalled(s.T(), "PurgeBook", mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandlePurgeBook_NotArchived() {
+	report := archivedBookReport()
+	report.ArchivedAt = nil
+	s.mockStore.On("GetBookPurgeReport", int64(1)).Return(report, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/books/1/purge?confirm=true", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandlePurgeBook(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "must be archived")
+	s.mockStore.AssertNotCalled(s.T(), "PurgeBook", mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandlePurgeBook_Success() {
+	s.mockStore.On("GetBookPurgeReport", int64(1)).Return(archivedBookReport(), nil)
+	s.mockStore.On("PurgeBook", int64(1)).Return(nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/books/1/purge?confirm=true", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandlePurgeBook(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"shelves_removed":3`)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetArchivedBooks() {
+	s.mockStore.On("GetAllBooks", 1, 20, store.BookFilter{Archived: true, Query: "orwell"}).Return([]*store.Book{}, 0, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/archived?q=orwell", nil)
+
+	s.handler.HandleGetArchivedBooks(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetAllBooks_InvalidGenre() {
+	req, _ := http.NewRequest(http.MethodGet, "/books?genre=abc", nil)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+
+	s.handler.HandleGetAllBooks(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetAllBooks_Filters() {
+	genreID := int64(4)
+	filter := store.BookFilter{Query: "tolkien", GenreID: &genreID, Tag: "cozy"}
+	s.mockStore.On("GetAllBooks", 1, 20, filter).Return([]*store.Book{expectedBook}, 1, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/books?q=tolkien&genre=4&tag=cozy", nil)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+
+	s.handler.HandleGetAllBooks(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"total_items":1`)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleAddBook_NormalizesISBNs() {
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("AddBook", mock.MatchedBy(func(b *store.Book) bool {
+		return b.ISBN13 == "9780261102217" && b.ISBN10 != nil && *b.ISBN10 == "0261102214"
+	})).Return(expectedBook, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/books", bytes.NewBufferString(`{"title": "The Hobbit", "isbn_10": "0-261-10221-4"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+
+	s.handler.HandleAddBook(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleAddBook_InvalidISBNs() {
+	req, _ := http.NewRequest(http.MethodPost, "/books", bytes.NewBufferString(`{"title": "The Hobbit", "isbn_13": "9780261102218", "isbn_10": "026110221X"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+
+	s.handler.HandleAddBook(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	var resp ValidationError
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Contains(resp.Fields, "isbn_13")
+	s.Contains(resp.Fields, "isbn_10")
+	s.mockStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandleUpdateBook_MismatchedISBN10() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+
+	req, _ := http.NewRequest(http.MethodPut, "/books/1", bytes.NewBufferString(`{"title": "1984", "isbn_13": "9780451524935", "isbn_10": "0261102214"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleUpdateBookByID(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "isbn_10 does not match isbn_13")
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookByISBN_AcceptsISBN10() {
+	s.mockStore.On("GetBookByISBN13", "9780451524935").Return(expectedBook, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/isbn/0-451-52493-4", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "isbn", Value: "0-451-52493-4"}}
+
+	s.handler.HandleGetBookByISBN(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookByISBN_Invalid() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/isbn/12345", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "isbn", Value: "12345"}}
+
+	s.handler.HandleGetBookByISBN(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookByISBN_NotFound() {
+	s.mockStore.On("GetBookByISBN13", "9780261102217").Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/isbn/9780261102217", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "isbn", Value: "9780261102217"}}
+
+	s.handler.HandleGetBookByISBN(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandleAddBook_RejectsDuplicates() {
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{
+		{Book: &store.Book{ID: 3, Title: "Nineteen Eighty-Four"}, Reason: store.DuplicateReasonTitleAuthor, Similarity: 0.7},
+	}, nil)
+
+	body, _ := json.Marshal(expectedBook)
+	req, _ := http.NewRequest(http.MethodPost, "/books", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+
+	s.handler.HandleAddBook(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	var resp DuplicateBookError
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Duplicates, 1)
+	s.Equal(int64(3), resp.Duplicates[0].Book.ID)
+	s.mockStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandleAddBook_EditorForceSkipsDuplicateCheck() {
+	s.mockStore.On("AddBook", expectedBook).Return(expectedBook, nil)
+
+	body, _ := json.Marshal(expectedBook)
+	req, _ := http.NewRequest(http.MethodPost, "/books?force=true", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Set("user", &store.User{ID: 1, Role: store.RoleEditor})
+
+	s.handler.HandleAddBook(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "FindDuplicateBooks", mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandleUpdateBook_ForceIgnoredForUsers() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+	s.mockStore.On("FindDuplicateBooks", mock.MatchedBy(func(b *store.Book) bool {
+		return b.ID == 1
+	})).Return([]*store.DuplicateCandidate{
+		{Book: &store.Book{ID: 3}, Reason: store.DuplicateReasonISBN, Similarity: 1},
+	}, nil)
+
+	body, _ := json.Marshal(expectedBook)
+	req, _ := http.NewRequest(http.MethodPut, "/books/1?force=true", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	ctx.Set("user", &store.User{ID: 5})
+
+	s.handler.HandleUpdateBookByID(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetSuspectedDuplicates_Success() {
+	s.mockStore.On("GetSuspectedDuplicates", 1, 20).Return([]*store.DuplicatePair{
+		{Book: &store.Book{ID: 1}, Duplicate: &store.Book{ID: 2}, Reason: store.DuplicateReasonTitleAuthor, Similarity: 0.8},
+	}, 21, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/duplicates", nil)
+
+	s.handler.HandleGetSuspectedDuplicates(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp DuplicateReportResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Duplicates, 1)
+	s.Equal(2, resp.TotalPages)
+}
+
+func (s *BookHandlerTestSuite) TestHandleUpdateBook_CommentedChapterDeletion() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("UpdateBook", mock.Anything, store.UpdateBookOptions{}).Return(
+		fmt.Errorf("failed to update book's chapters: %w", &store.ChapterDeletionError{ChapterIDs: []int64{4, 5}}),
+	)
+
+	body, _ := json.Marshal(expectedBook)
+	req, _ := http.NewRequest(http.MethodPut, "/books/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleUpdateBookByID(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	var resp ChapterDeletionConflict
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal([]int64{4, 5}, resp.ChapterIDs)
+}
+
+func (s *BookHandlerTestSuite) TestHandleUpdateBook_ConfirmsCommentedChapterDeletion() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("UpdateBook", mock.Anything, store.UpdateBookOptions{DeleteCommentedChapters: true}).Return(nil)
+
+	body, _ := json.Marshal(expectedBook)
+	req, _ := http.NewRequest(http.MethodPut, "/books/1?delete_commented_chapters=true", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleUpdateBookByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleUpdateBook_ForeignChapter() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("UpdateBook", mock.Anything, store.UpdateBookOptions{}).Return(
+		fmt.Errorf("failed to update book's chapters: %w", fmt.Errorf("%w: %d", store.ErrChapterNotInBook, 99)),
+	)
+
+	body, _ := json.Marshal(expectedBook)
+	req, _ := http.NewRequest(http.MethodPut, "/books/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleUpdateBookByID(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "chapter does not belong to this book: 99")
+}
+
+func patchTestBook() *store.Book {
+	description := "A dystopian novel"
+	return &store.Book{
+		ID:          1,
+		Title:       "1948",
+		Authors:     []string{"George Orwell"},
+		Publisher:   "Secker & Warburg",
+		Description: &description,
+		ISBN13:      "9780451524935",
+		ISBN10:      &expectedISBN10,
+		Chapters:    []store.Chapter{{ID: 3, Number: 1, Title: "Part One"}},
+		Version:     4,
+	}
+}
+
+func (s *BookHandlerTestSuite) newPatchContext(body, contentType string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPatch, "/books/1", bytes.NewBufferString(body))
+	ctx.Request.Header.Set("Content-Type", contentType)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	return ctx, w
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_MergePatchWritesOnlyChangedFields() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("UpdateBook", mock.MatchedBy(func(book *store.Book) bool {
+		return book.Title == "1984" && book.Description == nil &&
+			len(book.Chapters) == 1 && book.Chapters[0].ID == 3
+	}), store.UpdateBookOptions{Fields: []string{store.BookFieldTitle, store.BookFieldDescription}, IfMatch: []int{4}}).Return(nil)
+
+	ctx, w := s.newPatchContext(`{"title":"1984","description":null}`, "application/merge-patch+json")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_JSONPatch() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+	s.mockStore.On("UpdateBook", mock.MatchedBy(func(book *store.Book) bool {
+		return book.Chapters[0].ID == 3 && book.Chapters[0].Title == "Part One: Winston"
+	}), store.UpdateBookOptions{Fields: []string{store.BookFieldChapters}, IfMatch: []int{4}}).Return(nil)
+
+	ctx, w := s.newPatchContext(`[
+		{"op":"test","path":"/chapters/0/id","value":3},
+		{"op":"replace","path":"/chapters/0/title","value":"Part One: Winston"}
+	]`, "application/json-patch+json")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+	s.mockStore.AssertNotCalled(s.T(), "FindDuplicateBooks", mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_FailedTest() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+
+	ctx, w := s.newPatchContext(`[{"op":"test","path":"/title","value":"1984"}]`, "application/json-patch+json")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_UnknownField() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+
+	ctx, w := s.newPatchContext(`{"subtitle":"A novel"}`, "application/merge-patch+json")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "subtitle")
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_WrongType() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+
+	ctx, w := s.newPatchContext(`{"page_count":"many"}`, "application/merge-patch+json")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_ISBN13RederivesISBN10() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("UpdateBook", mock.MatchedBy(func(book *store.Book) bool {
+		return book.ISBN13 == "9780261102217" && book.ISBN10 != nil && *book.ISBN10 == "0261102214"
+	}), store.UpdateBookOptions{Fields: []string{store.BookFieldISBN13, store.BookFieldISBN10}, IfMatch: []int{4}}).Return(nil)
+
+	ctx, w := s.newPatchContext(`{"isbn_13":"978-0-261-10221-7"}`, "application/merge-patch+json")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_NoChanges() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+
+	ctx, w := s.newPatchContext(`{"title":"1948"}`, "application/merge-patch+json")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_UnsupportedContentType() {
+	ctx, w := s.newPatchContext(`title=1984`, "application/x-www-form-urlencoded")
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookByID_SetsETag() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/1", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleGetBookByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(`"4"`, w.Header().Get("ETag"))
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookByID_NotModified() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/1", nil)
+	ctx.Request.Header.Set("If-None-Match", `"3", W/"4"`)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleGetBookByID(ctx)
+	ctx.Writer.WriteHeaderNow()
+
+	s.Equal(http.StatusNotModified, w.Code)
+	s.Empty(w.Body.String())
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_StaleIfMatch() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+
+	ctx, w := s.newPatchContext(`{"title":"1984"}`, "application/merge-patch+json")
+	ctx.Request.Header.Set("If-Match", `"3"`)
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandlePatchBook_ChangedBeforeWrite() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(patchTestBook(), nil)
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("UpdateBook", mock.Anything, mock.Anything).Return(store.ErrVersionMismatch)
+
+	ctx, w := s.newPatchContext(`{"title":"1984"}`, "application/merge-patch+json")
+	ctx.Request.Header.Set("If-Match", `"4"`)
+
+	s.handler.HandlePatchBookByID(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleDeleteBook_IfMatch() {
+	s.mockStore.On("ArchiveBook", int64(1), []int{3, 4}).Return(store.ErrVersionMismatch)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/books/1", nil)
+	ctx.Request.Header.Set("If-Match", `"3", "4", W/"5"`)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleDeleteBookByID(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+)
+
+type PaginatedBookHistoryResponse struct {
+	Items      []*store.BookRevision `json:"items"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalItems int                   `json:"total_items"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+// BookRevisionDiffResponse lists the fields that differ between two revisions
+// of a book.
+type BookRevisionDiffResponse struct {
+	BookID  int64                   `json:"book_id"`
+	From    int                     `json:"from"`
+	To      int                     `json:"to"`
+	Changes []store.BookFieldChange `json:"changes"`
+}
+
+// HandleGetBookHistory godoc
+// @Summary      Get a book's edit history
+// @Description  Lists the recorded revisions of a book, newest first, with who made each change and which fields it changed.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedBookHistoryResponse
+// @Failure      400 {object} HTTPError "Error: Invalid request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/history [get]
+func (bh *BookHandler) HandleGetBookHistory(ctx *gin.Context) {
+	bookID, ok := bh.readExistingBookID(ctx)
+	if !ok {
+		return
+	}
+
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	revisions, total, err := bh.bookStore.GetBookHistory(bookID, page, limit)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookHistory %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PaginatedBookHistoryResponse{
+		Items:      revisions,
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// HandleGetBookRevision godoc
+// @Summary      Get a revision of a book
+// @Description  Returns a recorded revision of a book with a snapshot of its catalog data at that point.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        revision path int true "Revision number"
+// @Success      200 {object} store.BookRevision
+// @Failure      400 {object} HTTPError "Error: Invalid request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Revision not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/history/{revision} [get]
+func (bh *BookHandler) HandleGetBookRevision(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	revision, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil || revision < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	v, ok := bh.readBookRevision(ctx, bookID, revision)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, v)
+}
+
+// HandleGetBookRevisionDiff godoc
+// @Summary      Compare two revisions of a book
+// @Description  Lists the fields that differ between two recorded revisions of a book, with their values in each. Without to, the latest revision is used.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        from query int true "Revision to compare from"
+// @Param        to query int false "Revision to compare to"
+// @Success      200 {object} BookRevisionDiffResponse
+// @Failure      400 {object} HTTPError "Error: Invalid request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book or revision not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/history/diff [get]
+func (bh *BookHandler) HandleGetBookRevisionDiff(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	from, err := strconv.Atoi(ctx.Query("from"))
+	if err != nil || from < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be a revision number"})
+		return
+	}
+
+	to := 0
+	if toParam := ctx.Query("to"); toParam != "" {
+		to, err = strconv.Atoi(toParam)
+		if err != nil || to < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "to must be a revision number"})
+			return
+		}
+	} else {
+		latest, _, err := bh.bookStore.GetBookHistory(bookID, 1, 1)
+		if err != nil {
+			bh.logger.Printf("ERROR: getBookHistory %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if len(latest) == 0 {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book has no recorded history"})
+			return
+		}
+		to = latest[0].Revision
+	}
+
+	fromRevision, ok := bh.readBookRevision(ctx, bookID, from)
+	if !ok {
+		return
+	}
+	toRevision, ok := bh.readBookRevision(ctx, bookID, to)
+	if !ok {
+		return
+	}
+
+	changes, err := store.DiffBookSnapshots(fromRevision.Snapshot, toRevision.Snapshot)
+	if err != nil {
+		bh.logger.Printf("ERROR: diffBookSnapshots %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BookRevisionDiffResponse{
+		BookID:  bookID,
+		From:    from,
+		To:      to,
+		Changes: changes,
+	})
+}
+
+// HandleRevertBook godoc
+// @Summary      Revert a book to a prior revision
+// @Description  Sets the book's catalog data back to a recorded revision. The revert is recorded as a new revision, so it can be undone.
+//
+//	Chapters deleted since then are recreated. Removing chapters readers have commented on requires delete_commented_chapters=true.
+//
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        revision path int true "Revision to revert to"
+// @Param        If-Match header string false "ETag of the book being reverted"
+// @Param        delete_commented_chapters query bool false "Allow deleting chapters that have comments"
+// @Success      200 {object} store.Book
+// @Failure      400 {object} HTTPError "Error: Invalid request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book or revision not found"
+// @Failure      409 {object} ChapterDeletionConflict "Error: Chapters with comments would be deleted, or the ISBN is now used by another book"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/history/{revision}/revert [post]
+func (bh *BookHandler) HandleRevertBook(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	revision, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil || revision < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	opts := store.UpdateBookOptions{
+		DeleteCommentedChapters: ctx.Query("delete_commented_chapters") == "true",
+		EditorID:                editorID(ctx),
+		IfMatch:                 utils.ReadIfMatch(ctx),
+	}
+	if err := bh.bookStore.RevertBook(bookID, revision, opts); err != nil {
+		var pgErr *pgconn.PgError
+		var deletionErr *store.ChapterDeletionError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		case errors.Is(err, store.ErrVersionMismatch):
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			ctx.JSON(http.StatusConflict, gin.H{"error": "another book now has this revision's ISBN"})
+		case errors.As(err, &deletionErr):
+			ctx.JSON(http.StatusConflict, ChapterDeletionConflict{
+				Error:      "reverting would delete chapters that have comments; pass delete_commented_chapters=true to confirm",
+				ChapterIDs: deletionErr.ChapterIDs,
+			})
+		default:
+			bh.logger.Printf("ERROR: revertBook %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	book, err := bh.bookStore.GetBookByID(bookID)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	utils.SetETag(ctx, book.Version)
+	ctx.JSON(http.StatusOK, book)
+}
+
+// readExistingBookID reads the book id from the path and checks that the book
+// exists.
+func (bh *BookHandler) readExistingBookID(ctx *gin.Context) (int64, bool) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		bh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return 0, false
+	}
+
+	book, err := bh.bookStore.GetBookByID(bookID)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return 0, false
+	}
+	if book == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return 0, false
+	}
+
+	return bookID, true
+}
+
+func (bh *BookHandler) readBookRevision(ctx *gin.Context, bookID int64, revision int) (*store.BookRevision, bool) {
+	v, err := bh.bookStore.GetBookRevision(bookID, revision)
+	if err != nil {
+		bh.logger.Printf("ERROR: getBookRevision %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+	if v == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "revision " + strconv.Itoa(revision) + " not found"})
+		return nil, false
+	}
+
+	return v, true
+}
+
+// editorID returns the id of the signed-in user, recorded as the author of a
+// catalog change, or nil when there is none.
+func editorID(ctx *gin.Context) *int64 {
+	userValue, ok := ctx.Get("user")
+	if !ok {
+		return nil
+	}
+	user, ok := userValue.(*store.User)
+	if !ok || user.IsAnonymus() {
+		return nil
+	}
+	return &user.ID
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *BookHandlerTestSuite) newHistoryContext(method, url string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(method, url, nil)
+	ctx.Params = params
+	return ctx, w
+}
+
+func historyRevision(revision int, title string) *store.BookRevision {
+	return &store.BookRevision{
+		BookID:   1,
+		Revision: revision,
+		Action:   store.BookRevisionUpdated,
+		Snapshot: &store.BookSnapshot{Title: title, Authors: []string{"George Orwell"}},
+	}
+}
+
+func (s *BookHandlerTestSuite) TestHandleUpdateBook_RecordsEditor() {
+	editor := &store.User{ID: 7, Role: store.RoleEditor}
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+	s.mockStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockStore.On("UpdateBook", mock.Anything, store.UpdateBookOptions{EditorID: &editor.ID}).Return(nil)
+
+	body, _ := json.Marshal(expectedBook)
+	req, _ := http.NewRequest(http.MethodPut, "/books/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	ctx.Set("user", editor)
+
+	s.handler.HandleUpdateBookByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookHistory() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+	s.mockStore.On("GetBookHistory", int64(1), 1, 20).Return([]*store.BookRevision{
+		{BookID: 1, Revision: 2, Action: store.BookRevisionUpdated, ChangedFields: []string{store.BookFieldTitle}},
+		{BookID: 1, Revision: 1, Action: store.BookRevisionCreated, ChangedFields: []string{}},
+	}, 2, nil)
+
+	ctx, w := s.newHistoryContext(http.MethodGet, "/books/1/history", gin.Params{{Key: "id", Value: "1"}})
+
+	s.handler.HandleGetBookHistory(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp PaginatedBookHistoryResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Items, 2)
+	s.Equal(2, resp.Items[0].Revision)
+	s.Equal(1, resp.TotalPages)
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookHistory_BookNotFound() {
+	s.mockStore.On("GetBookByID", int64(1)).Return(nil, nil)
+
+	ctx, w := s.newHistoryContext(http.MethodGet, "/books/1/history", gin.Params{{Key: "id", Value: "1"}})
+
+	s.handler.HandleGetBookHistory(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "GetBookHistory", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookRevision_NotFound() {
+	s.mockStore.On("GetBookRevision", int64(1), 9).Return(nil, nil)
+
+	ctx, w := s.newHistoryContext(http.MethodGet, "/books/1/history/9", gin.Params{{Key: "id", Value: "1"}, {Key: "revision", Value: "9"}})
+
+	s.handler.HandleGetBookRevision(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookRevisionDiff_DefaultsToLatest() {
+	s.mockStore.On("GetBookHistory", int64(1), 1, 1).Return([]*store.BookRevision{{BookID: 1, Revision: 3}}, 3, nil)
+	s.mockStore.On("GetBookRevision", int64(1), 1).Return(historyRevision(1, "1984"), nil)
+	s.mockStore.On("GetBookRevision", int64(1), 3).Return(historyRevision(3, "Nineteen Eighty-Four"), nil)
+
+	ctx, w := s.newHistoryContext(http.MethodGet, "/books/1/history/diff?from=1", gin.Params{{Key: "id", Value: "1"}})
+
+	s.handler.HandleGetBookRevisionDiff(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp BookRevisionDiffResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal(3, resp.To)
+	s.Len(resp.Changes, 1)
+	s.Equal(store.BookFieldTitle, resp.Changes[0].Field)
+	s.JSONEq(`"Nineteen Eighty-Four"`, string(resp.Changes[0].To))
+}
+
+func (s *BookHandlerTestSuite) TestHandleGetBookRevisionDiff_MissingFrom() {
+	ctx, w := s.newHistoryContext(http.MethodGet, "/books/1/history/diff", gin.Params{{Key: "id", Value: "1"}})
+
+	s.handler.HandleGetBookRevisionDiff(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandleRevertBook() {
+	admin := &store.User{ID: 1, Role: store.RoleAdmin}
+	s.mockStore.On("RevertBook", int64(1), 2, store.UpdateBookOptions{EditorID: &admin.ID}).Return(nil)
+	s.mockStore.On("GetBookByID", int64(1)).Return(expectedBook, nil)
+
+	ctx, w := s.newHistoryContext(http.MethodPost, "/books/1/history/2/revert", gin.Params{{Key: "id", Value: "1"}, {Key: "revision", Value: "2"}})
+	ctx.Set("user", admin)
+
+	s.handler.HandleRevertBook(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *BookHandlerTestSuite) TestHandleRevertBook_RevisionNotFound() {
+	s.mockStore.On("RevertBook", int64(1), 5, store.UpdateBookOptions{}).Return(sql.ErrNoRows)
+
+	ctx, w := s.newHistoryContext(http.MethodPost, "/books/1/history/5/revert", gin.Params{{Key: "id", Value: "1"}, {Key: "revision", Value: "5"}})
+
+	s.handler.HandleRevertBook(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *BookHandlerTestSuite) TestHandleRevertBook_CommentedChapterDeletion() {
+	s.mockStore.On("RevertBook", int64(1), 2, store.UpdateBookOptions{}).Return(
+		fmt.Errorf("failed to update book's chapters: %w", &store.ChapterDeletionError{ChapterIDs: []int64{4}}),
+	)
+
+	ctx, w := s.newHistoryContext(http.MethodPost, "/books/1/history/2/revert", gin.Params{{Key: "id", Value: "1"}, {Key: "revision", Value: "2"}})
+
+	s.handler.HandleRevertBook(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	var resp ChapterDeletionConflict
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal([]int64{4}, resp.ChapterIDs)
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/isbn"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+)
+
+type BookMetadataHandler struct {
+	provider  store.BookMetadataProvider
+	bookStore store.BookStore
+	logger    *log.Logger
+}
+
+func NewBookMetadataHandler(provider store.BookMetadataProvider, bookStore store.BookStore, logger *log.Logger) *BookMetadataHandler {
+	return &BookMetadataHandler{
+		provider:  provider,
+		bookStore: bookStore,
+		logger:    logger,
+	}
+}
+
+type BookCandidatesResponse struct {
+	Books []*store.BookCandidate `json:"books"`
+}
+
+// HandleSearchBookMetadata godoc
+// @Summary      Search book metadata providers
+// @Description  Searches the configured metadata providers (Google Books, Open Library). The first provider that answers supplies the results; the others fill in missing covers, page counts and descriptions.
+// @Tags         book_metadata
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q query string false "Search query"
+// @Param        title query string false "Words in the title"
+// @Param        author query string false "Author name"
+// @Param        isbn query string false "ISBN-10 or ISBN-13"
+// @Param        limit query int false "Maximum number of results (max 40)" default(20)
+// @Success      200 {object} BookCandidatesResponse
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Failure      503 {object} HTTPError "Error: Metadata providers unavailable"
+// @Router       /books/metadata [get]
+func (mh *BookMetadataHandler) HandleSearchBookMetadata(ctx *gin.Context) {
+	query := store.BookMetadataQuery{
+		Query:  ctx.Query("q"),
+		Title:  ctx.Query("title"),
+		Author: ctx.Query("author"),
+		ISBN:   ctx.Query("isbn"),
+		Limit:  20,
+	}
+	if query.IsEmpty() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "missing search query"})
+		return
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > store.GoogleBooksMaxResults {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		query.Limit = limit
+	}
+
+	books, err := mh.provider.SearchBooks(ctx.Request.Context(), query)
+	if err != nil {
+		mh.logger.Printf("ERROR: searchBookMetadata %v", err)
+		if errors.Is(err, store.ErrProviderUnavailable) {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "book search is temporarily unavailable"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch books"})
+		return
+	}
+
+	if books == nil {
+		books = []*store.BookCandidate{}
+	}
+	ctx.JSON(http.StatusOK, BookCandidatesResponse{Books: books})
+}
+
+// HandleGetBookMetadataByISBN godoc
+// @Summary      Look up book metadata by ISBN
+// @Description  Asks every configured metadata provider for the ISBN and merges the answers in priority order.
+// @Tags         book_metadata
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        isbn path string true "ISBN-10 or ISBN-13"
+// @Success      200 {object} store.BookCandidate
+// @Failure      400 {object} HTTPError "Error: Invalid ISBN"
+// @Failure      404 {object} HTTPError "Error: No provider knows this ISBN"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Failure      503 {object} HTTPError "Error: Metadata providers unavailable"
+// @Router       /books/metadata/isbn/{isbn} [get]
+func (mh *BookMetadataHandler) HandleGetBookMetadataByISBN(ctx *gin.Context) {
+	book, ok := mh.lookupISBN(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, book)
+}
+
+// HandleImportBookByISBN godoc
+// @Summary      Import a book by ISBN
+// @Description  Looks the ISBN up in the configured metadata providers and adds the merged result to the catalog.
+//
+//	If the catalog already has the book's ISBN-13, the existing book is returned with status 200 instead of creating a new one. Books that look like another catalog book are rejected as in POST /books.
+//
+// @Tags         book_metadata
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        isbn path string true "ISBN-10 or ISBN-13"
+// @Param        force query bool false "Skip the duplicate check (catalog editors only)"
+// @Success      200 {object} store.Book "Book already in the catalog"
+// @Success      201 {object} store.Book "Book imported"
+// @Failure      400 {object} HTTPError "Error: Invalid ISBN"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: No provider knows this ISBN"
+// @Failure      409 {object} DuplicateBookError "Error: Duplicate record"
+// @Failure      422 {object} HTTPError "Error: Book has no or invalid ISBNs"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Failure      503 {object} HTTPError "Error: Metadata providers unavailable"
+// @Router       /books/import/isbn/{isbn} [post]
+func (mh *BookMetadataHandler) HandleImportBookByISBN(ctx *gin.Context) {
+	candidate, ok := mh.lookupISBN(ctx)
+	if !ok {
+		return
+	}
+
+	if candidate.ISBN13 == "" {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "book has no ISBN-13"})
+		return
+	}
+
+	importCatalogBook(ctx, mh.bookStore, mh.logger, &candidate.Book)
+}
+
+// lookupISBN writes an error response and returns false when the ISBN cannot
+// be resolved.
+func (mh *BookMetadataHandler) lookupISBN(ctx *gin.Context) (*store.BookCandidate, bool) {
+	isbn13, err := isbn.ToISBN13(ctx.Param("isbn"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid isbn: " + err.Error()})
+		return nil, false
+	}
+
+	book, err := mh.provider.GetBookByISBN(ctx.Request.Context(), isbn13)
+	if err != nil {
+		if errors.Is(err, store.ErrBookMetadataNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return nil, false
+		}
+		mh.logger.Printf("ERROR: getBookMetadataByISBN %v", err)
+		if errors.Is(err, store.ErrProviderUnavailable) {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "book search is temporarily unavailable"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch book"})
+		return nil, false
+	}
+
+	return book, true
+}
+
+// importCatalogBook adds book to the catalog, or returns the catalog's copy
+// when a book with the same ISBN-13 already exists. The book's ISBNs and
+// likely duplicates are checked as for POST /books.
+func importCatalogBook(ctx *gin.Context, bookStore store.BookStore, logger *log.Logger, book *store.Book) {
+	req := AddBookRequest{ISBN13: book.ISBN13, ISBN10: book.ISBN10}
+	if fields := req.normalizeISBNs(); len(fields) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, ValidationError{Error: "invalid book", Fields: fields})
+		return
+	}
+	book.ISBN13, book.ISBN10 = req.ISBN13, req.ISBN10
+
+	existing, err := bookStore.GetBookByISBN13(book.ISBN13)
+	if err != nil {
+		logger.Printf("ERROR: getBookByISBN13 %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if existing != nil {
+		ctx.JSON(http.StatusOK, existing)
+		return
+	}
+
+	if rejectDuplicateBook(ctx, bookStore, logger, book) {
+		return
+	}
+
+	created, err := bookStore.AddBook(book)
+	if err != nil {
+		// Another import of the same ISBN won the race; return that book.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if existing, getErr := bookStore.GetBookByISBN13(book.ISBN13); getErr == nil && existing != nil {
+				ctx.JSON(http.StatusOK, existing)
+				return
+			}
+		}
+		logger.Printf("ERROR: addBook %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type BookMetadataHandlerTestSuite struct {
+	suite.Suite
+	mockProvider  *mocks.MockBookMetadataProvider
+	mockBookStore *mocks.MockBookStore
+	handler       *BookMetadataHandler
+}
+
+func (s *BookMetadataHandlerTestSuite) SetupTest() {
+	s.mockProvider = new(mocks.MockBookMetadataProvider)
+	s.mockBookStore = new(mocks.MockBookStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewBookMetadataHandler(s.mockProvider, s.mockBookStore, logger)
+}
+
+func TestBookMetadataHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(BookMetadataHandlerTestSuite))
+}
+
+func hobbitCandidate() *store.BookCandidate {
+	return &store.BookCandidate{
+		Book:     store.Book{Title: "The Hobbit", ISBN13: "9780261102217"},
+		Source:   "openlibrary",
+		SourceID: "/books/OL7353617M",
+	}
+}
+
+func (s *BookMetadataHandlerTestSuite) isbnContext(method, isbn string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(method, "/books/metadata/isbn/"+isbn, nil)
+	ctx.Params = gin.Params{gin.Param{Key: "isbn", Value: isbn}}
+	return ctx, w
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleSearchBookMetadata_MissingQuery() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/metadata", nil)
+
+	s.handler.HandleSearchBookMetadata(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleSearchBookMetadata_Success() {
+	s.mockProvider.On("SearchBooks", store.BookMetadataQuery{Author: "Tolkien", Limit: 5}).Return([]*store.BookCandidate{hobbitCandidate()}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/metadata?author=Tolkien&limit=5", nil)
+
+	s.handler.HandleSearchBookMetadata(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp BookCandidatesResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Books, 1)
+	s.Equal("openlibrary", resp.Books[0].Source)
+	s.mockProvider.AssertExpectations(s.T())
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleSearchBookMetadata_ProvidersUnavailable() {
+	s.mockProvider.On("SearchBooks", mock.Anything).Return(nil, store.ErrProviderUnavailable)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/metadata?q=hobbit", nil)
+
+	s.handler.HandleSearchBookMetadata(ctx)
+
+	s.Equal(http.StatusServiceUnavailable, w.Code)
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleGetBookMetadataByISBN_StripsHyphens() {
+	s.mockProvider.On("GetBookByISBN", "9780261102217").Return(hobbitCandidate(), nil)
+
+	ctx, w := s.isbnContext(http.MethodGet, "978-0-261-10221-7")
+
+	s.handler.HandleGetBookMetadataByISBN(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"source_id":"/books/OL7353617M"`)
+	s.mockProvider.AssertExpectations(s.T())
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleGetBookMetadataByISBN_NotFound() {
+	s.mockProvider.On("GetBookByISBN", "9780000000002").Return(nil, store.ErrBookMetadataNotFound)
+
+	ctx, w := s.isbnContext(http.MethodGet, "9780000000002")
+
+	s.handler.HandleGetBookMetadataByISBN(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleImportBookByISBN_Creates() {
+	s.mockProvider.On("GetBookByISBN", "9780261102217").Return(hobbitCandidate(), nil)
+	s.mockBookStore.On("GetBookByISBN13", "9780261102217").Return(nil, nil)
+	s.mockBookStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockBookStore.On("AddBook", mock.MatchedBy(func(b *store.Book) bool {
+		return b.Title == "The Hobbit" && *b.ISBN10 == "0261102214"
+	})).Return(&store.Book{ID: 1, Title: "The Hobbit", ISBN13: "9780261102217"}, nil)
+
+	ctx, w := s.isbnContext(http.MethodPost, "9780261102217")
+
+	s.handler.HandleImportBookByISBN(ctx)
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.mockBookStore.AssertExpectations(s.T())
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleImportBookByISBN_Duplicate() {
+	s.mockProvider.On("GetBookByISBN", "9780261102217").Return(hobbitCandidate(), nil)
+	s.mockBookStore.On("GetBookByISBN13", "9780261102217").Return(nil, nil)
+	s.mockBookStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{
+		{Book: &store.Book{ID: 7, Title: "The Hobbit"}, Reason: store.DuplicateReasonTitleAuthor, Similarity: 0.9},
+	}, nil)
+
+	ctx, w := s.isbnContext(http.MethodPost, "9780261102217")
+
+	s.handler.HandleImportBookByISBN(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.mockBookStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleImportBookByISBN_MismatchedISBN10() {
+	candidate := hobbitCandidate()
+	candidate.ISBN10 = strPtr("0618260307")
+	s.mockProvider.On("GetBookByISBN", "9780261102217").Return(candidate, nil)
+
+	ctx, w := s.isbnContext(http.MethodPost, "9780261102217")
+
+	s.handler.HandleImportBookByISBN(ctx)
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.mockBookStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
+}
+
+func (s *BookMetadataHandlerTestSuite) TestHandleImportBookByISBN_ReturnsExisting() {
+	s.mockProvider.On("GetBookByISBN", "9780261102217").Return(hobbitCandidate(), nil)
+	s.mockBookStore.On("GetBookByISBN13", "9780261102217").Return(&store.Book{ID: 7, Title: "The Hobbit"}, nil)
+
+	ctx, w := s.isbnContext(http.MethodPost, "9780261102217")
+
+	s.handler.HandleImportBookByISBN(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"id":7`)
+	s.mockBookStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
+}
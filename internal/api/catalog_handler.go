@@ -0,0 +1,508 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/catalog"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+)
+
+const (
+	maxImportBytes = 20 << 20
+	maxImportRows  = 10000
+	// importBatchSize is how many rows an import job reads and checks
+	// against the catalog at a time.
+	importBatchSize = 100
+	exportPageSize  = 200
+)
+
+type CatalogHandler struct {
+	bookStore      store.BookStore
+	importJobStore store.ImportJobStore
+	logger         *log.Logger
+	// background runs import jobs once they are queued.
+	background func(func())
+}
+
+func NewCatalogHandler(bookStore store.BookStore, importJobStore store.ImportJobStore, logger *log.Logger) *CatalogHandler {
+	return &CatalogHandler{
+		bookStore:      bookStore,
+		importJobStore: importJobStore,
+		logger:         logger,
+		background:     func(run func()) { go run() },
+	}
+}
+
+type PaginatedImportJobsResponse struct {
+	Items      []*store.ImportJob `json:"items"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalItems int                `json:"total_items"`
+	TotalPages int                `json:"total_pages"`
+}
+
+type PaginatedImportRowsResponse struct {
+	Items      []*store.ImportRow `json:"items"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalItems int                `json:"total_items"`
+	TotalPages int                `json:"total_pages"`
+}
+
+// HandleImportCatalog godoc
+// @Summary      Import books in bulk
+// @Description  Queues an import of the books in an uploaded CSV, JSON lines or ONIX-lite file and returns the job, whose progress and per-row results can be followed at /books/imports/{id}.
+//
+//	CSV files need a header row; the columns are title, authors (separated by semicolons), publisher, published_date, description, page_count, isbn_13, isbn_10, cover_url and chapters (a JSON array of chapters, or titles separated by semicolons). JSON lines files have one book per line, in the same shape as the books returned by the API. ONIX-lite files are ONIX for Books 3.0 messages with reference tag names.
+//	Every row is validated like a book added through POST /books. Rows with an ISBN already in the catalog are skipped. With dry_run=true nothing is added, and rows that would be are reported as valid.
+//	The format is taken from the file name when it is not given.
+//
+// @Tags         catalog
+// @Accept       mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file formData file true "Import file, up to 20 MB and 10000 books"
+// @Param        format query string false "File format" Enums(csv, jsonl, onix)
+// @Param        dry_run query bool false "Validate the file without adding any book"
+// @Success      202 {object} store.ImportJob
+// @Failure      400 {object} ValidationError "Error: Missing or unreadable file"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      413 {object} HTTPError "Error: File too large"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/import [post]
+func (ch *CatalogHandler) HandleImportCatalog(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes+1<<20)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file must be at most 20 MB"})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if header.Size > maxImportBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file must be at most 20 MB"})
+		return
+	}
+
+	format := ctx.Query("format")
+	if format == "" {
+		format, _ = catalog.FormatOf(header.Filename)
+	}
+	if !slices.Contains(catalog.Formats, format) {
+		ctx.JSON(http.StatusBadRequest, ValidationError{
+			Error:  "invalid import file",
+			Fields: map[string]string{"format": catalog.ErrUnknownFormat.Error()},
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ch.logger.Printf("ERROR: openImportFile %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "could not read file"})
+		return
+	}
+	defer file.Close()
+
+	records, err := catalog.Read(format, file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ValidationError{
+			Error:  "invalid import file",
+			Fields: map[string]string{"file": err.Error()},
+		})
+		return
+	}
+	if len(records) > maxImportRows {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must have at most %d books", maxImportRows)})
+		return
+	}
+
+	rows, err := importRows(records)
+	if err != nil {
+		ch.logger.Printf("ERROR: importRows %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	job, err := ch.importJobStore.CreateImportJob(&store.ImportJob{
+		Kind:   store.ImportKindCatalog,
+		UserID: editorID(ctx),
+		Format: format,
+		DryRun: ctx.Query("dry_run") == "true",
+	}, rows)
+	if err != nil {
+		ch.logger.Printf("ERROR: createImportJob %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ch.background(func() { ch.runImport(job) })
+
+	ctx.Header("Location", fmt.Sprintf("/books/imports/%d", job.ID))
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// importRows validates the records read from an import file. Rows that pass
+// are left pending for the job, which checks them against the catalog.
+func importRows(records []catalog.Record) ([]store.ImportRow, error) {
+	rows := make([]store.ImportRow, 0, len(records))
+	seen := map[string]int{}
+	for _, record := range records {
+		req := bookUpdateRequest(&record.Book)
+		fields := map[string]string{}
+		if record.Err != nil {
+			fields["row"] = record.Err.Error()
+		} else {
+			fields = validateImportedBook(&req)
+		}
+
+		if row, ok := seen[req.ISBN13]; ok && len(fields) == 0 {
+			fields["isbn_13"] = fmt.Sprintf("same ISBN as row %d", row)
+		} else if len(fields) == 0 {
+			seen[req.ISBN13] = record.Row
+		}
+
+		data, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+		row := store.ImportRow{Row: record.Row, Status: store.ImportRowPending, Data: data}
+		if len(fields) > 0 {
+			row.Status = store.ImportRowInvalid
+			row.Errors = fields
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// validateImportedBook checks a book read from an import file the way
+// HandleAddBook checks a new book, and also requires a title.
+func validateImportedBook(req *AddBookRequest) map[string]string {
+	req.Title = strings.TrimSpace(req.Title)
+	fields := req.normalizeISBNs()
+	if req.Title == "" {
+		fields["title"] = "title is required"
+	}
+	if msg := validateChapters(req.Chapters); msg != "" {
+		fields["chapters"] = msg
+	}
+	return fields
+}
+
+// ResumeImports restarts the catalog imports that were queued or running when
+// the server stopped. Rows already processed are not imported again.
+func (ch *CatalogHandler) ResumeImports() {
+	jobs, err := ch.importJobStore.GetUnfinishedImportJobs(store.ImportKindCatalog)
+	if err != nil {
+		ch.logger.Printf("ERROR: getUnfinishedImportJobs %v", err)
+		return
+	}
+	for _, job := range jobs {
+		ch.background(func() { ch.runImport(job) })
+	}
+}
+
+func (ch *CatalogHandler) runImport(job *store.ImportJob) {
+	runImportJob(ch.importJobStore, ch.logger, job, ch.importPendingRows)
+}
+
+// runImportJob marks the job running, processes its pending rows with
+// importPendingRows and records how the job ended.
+func runImportJob(importJobStore store.ImportJobStore, logger *log.Logger, job *store.ImportJob, importPendingRows func(*store.ImportJob) error) {
+	if err := importJobStore.StartImportJob(job.ID); err != nil {
+		logger.Printf("ERROR: startImportJob %v", err)
+		return
+	}
+
+	err := importPendingRows(job)
+	if err != nil {
+		logger.Printf("ERROR: importPendingRows job %d: %v", job.ID, err)
+		err = errors.New("import stopped by an internal error")
+	}
+	if err := importJobStore.FinishImportJob(job.ID, err); err != nil {
+		logger.Printf("ERROR: finishImportJob %v", err)
+	}
+}
+
+func (ch *CatalogHandler) importPendingRows(job *store.ImportJob) error {
+	for {
+		rows, err := ch.importJobStore.GetPendingImportRows(job.ID, importBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		requests := make([]*AddBookRequest, len(rows))
+		isbns := []string{}
+		for i, row := range rows {
+			requests[i] = &AddBookRequest{}
+			if err := json.Unmarshal(row.Data, requests[i]); err != nil {
+				return err
+			}
+			isbns = append(isbns, requests[i].ISBN13)
+		}
+		existing, err := ch.bookStore.GetBookIDsByISBN13(isbns)
+		if err != nil {
+			return err
+		}
+
+		for i, row := range rows {
+			ch.importRow(job, row, requests[i], existing)
+			if err := ch.importJobStore.UpdateImportRow(row); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// importRow adds the row's book to the catalog, unless the job is a dry run,
+// and records the result in row.
+func (ch *CatalogHandler) importRow(job *store.ImportJob, row *store.ImportRow, req *AddBookRequest, existing map[string]int64) {
+	if id, ok := existing[req.ISBN13]; ok {
+		row.Status = store.ImportRowSkipped
+		row.BookID = &id
+		row.Errors = map[string]string{"isbn_13": "a book with this ISBN is already in the catalog"}
+		return
+	}
+	if job.DryRun {
+		row.Status = store.ImportRowValid
+		return
+	}
+
+	book := req.book()
+	added, err := ch.bookStore.AddBook(&book)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			row.Status = store.ImportRowSkipped
+			row.Errors = map[string]string{"isbn_13": "a book with this ISBN is already in the catalog"}
+			return
+		}
+		ch.logger.Printf("ERROR: addBook job %d row %d: %v", job.ID, row.Row, err)
+		row.Status = store.ImportRowFailed
+		row.Errors = map[string]string{"row": "the book could not be added"}
+		return
+	}
+	row.Status = store.ImportRowImported
+	row.BookID = &added.ID
+}
+
+// HandleGetImportJobs godoc
+// @Summary      List catalog imports
+// @Description  Lists bulk catalog imports, newest first, with the number of rows with each result.
+// @Tags         catalog
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedImportJobsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/imports [get]
+func (ch *CatalogHandler) HandleGetImportJobs(ctx *gin.Context) {
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	jobs, total, err := ch.importJobStore.GetImportJobs(store.ImportJobFilter{Kind: store.ImportKindCatalog}, page, limit)
+	if err != nil {
+		ch.logger.Printf("ERROR: getImportJobs %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PaginatedImportJobsResponse{
+		Items:      jobs,
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// HandleGetImportJob godoc
+// @Summary      Get a catalog import
+// @Description  Retrieves a bulk catalog import with its status and the number of rows with each result.
+// @Tags         catalog
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Import job ID"
+// @Success      200 {object} store.ImportJob
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Import not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/imports/{id} [get]
+func (ch *CatalogHandler) HandleGetImportJob(ctx *gin.Context) {
+	job, ok := ch.readImportJob(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// HandleGetImportRows godoc
+// @Summary      List the rows of a catalog import
+// @Description  Lists the rows of a bulk catalog import in file order, with their results: pending, imported, valid (dry runs), skipped, invalid or failed. Rows that were not imported list their problems by field.
+// @Tags         catalog
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Import job ID"
+// @Param        status query string false "Only rows with this result" Enums(pending, imported, valid, skipped, invalid, failed)
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedImportRowsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid id, status or pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Import not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/imports/{id}/rows [get]
+func (ch *CatalogHandler) HandleGetImportRows(ctx *gin.Context) {
+	status := ctx.Query("status")
+	if status != "" && !slices.Contains(store.ImportRowStatuses, status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of " + strings.Join(store.ImportRowStatuses, ", ")})
+		return
+	}
+
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	job, ok := ch.readImportJob(ctx)
+	if !ok {
+		return
+	}
+
+	rows, total, err := ch.importJobStore.GetImportRows(job.ID, status, page, limit)
+	if err != nil {
+		ch.logger.Printf("ERROR: getImportRows %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PaginatedImportRowsResponse{
+		Items:      rows,
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// readImportJob returns the catalog import named by the id parameter, writing
+// the error response when there is none.
+func (ch *CatalogHandler) readImportJob(ctx *gin.Context) (*store.ImportJob, bool) {
+	jobID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import id"})
+		return nil, false
+	}
+
+	job, err := ch.importJobStore.GetImportJobByID(jobID)
+	if err != nil {
+		ch.logger.Printf("ERROR: getImportJobByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+	if job == nil || job.Kind != store.ImportKindCatalog {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+		return nil, false
+	}
+	return job, true
+}
+
+// HandleExportCatalog godoc
+// @Summary      Export the catalog
+// @Description  Downloads every catalog book in one of the import formats, so the file can be imported again elsewhere. Archived books are exported instead with archived=true.
+// @Tags         catalog
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/xml
+// @Security     BearerAuth
+// @Param        format query string false "File format" Enums(csv, jsonl, onix) default(csv)
+// @Param        archived query bool false "Export the archived books"
+// @Success      200 {file} binary
+// @Failure      400 {object} HTTPError "Error: Unknown format"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/export [get]
+func (ch *CatalogHandler) HandleExportCatalog(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", catalog.FormatCSV)
+	if !slices.Contains(catalog.Formats, format) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": catalog.ErrUnknownFormat.Error()})
+		return
+	}
+	filter := store.BookFilter{Archived: ctx.Query("archived") == "true"}
+
+	// The first page is read before anything is written, so a failing
+	// database still gets an error response.
+	books, total, err := ch.bookStore.GetAllBooks(1, exportPageSize, filter)
+	if err != nil {
+		ch.logger.Printf("ERROR: getAllBooks %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	filename := fmt.Sprintf("catalog-%s.%s", time.Now().UTC().Format("20060102"), catalog.Extension(format))
+	ctx.Header("Content-Type", catalog.ContentType(format))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Status(http.StatusOK)
+
+	writer, err := catalog.NewWriter(format, ctx.Writer)
+	if err != nil {
+		ch.logger.Printf("ERROR: newCatalogWriter %v", err)
+		return
+	}
+	for page := 1; ; page++ {
+		if page > 1 {
+			books, _, err = ch.bookStore.GetAllBooks(page, exportPageSize, filter)
+			if err != nil {
+				// The response is already on its way; it ends early.
+				ch.logger.Printf("ERROR: getAllBooks %v", err)
+				return
+			}
+		}
+		for _, book := range books {
+			if err := writer.Write(book); err != nil {
+				ch.logger.Printf("ERROR: writeCatalog %v", err)
+				return
+			}
+		}
+		if len(books) < exportPageSize || page*exportPageSize >= total {
+			break
+		}
+	}
+	if err := writer.Close(); err != nil {
+		ch.logger.Printf("ERROR: writeCatalog %v", err)
+	}
+}
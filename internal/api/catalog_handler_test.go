@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CatalogHandlerTestSuite struct {
+	suite.Suite
+	mockBookStore      *mocks.MockBookStore
+	mockImportJobStore *mocks.MockImportJobStore
+	handler            *CatalogHandler
+}
+
+func (s *CatalogHandlerTestSuite) SetupTest() {
+	s.mockBookStore = new(mocks.MockBookStore)
+	s.mockImportJobStore = new(mocks.MockImportJobStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewCatalogHandler(s.mockBookStore, s.mockImportJobStore, logger)
+	// Jobs run before the handler returns, so their calls can be asserted.
+	s.handler.background = func(run func()) { run() }
+}
+
+func TestCatalogHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(CatalogHandlerTestSuite))
+}
+
+func (s *CatalogHandlerTestSuite) newImportContext(query, filename, content string) (*gin.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", filename)
+	_, _ = part.Write([]byte(content))
+	_ = writer.Close()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/import"+query, &body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	return ctx, w
+}
+
+const testImportCSV = "title,authors,isbn_13\n" +
+	"The Hobbit,J.R.R. Tolkien,978-0-261-10221-7\n" +
+	",Nobody,9780547928227\n" +
+	"Dune,Frank Herbert,9780441172719\n" +
+	"The Hobbit again,J.R.R. Tolkien,9780261102217\n"
+
+func rowStatuses(rows []store.ImportRow) []string {
+	statuses := []string{}
+	for _, row := range rows {
+		statuses = append(statuses, row.Status)
+	}
+	return statuses
+}
+
+func (s *CatalogHandlerTestSuite) expectQueuedJob(dryRun bool) *store.ImportJob {
+	job := &store.ImportJob{ID: 5, Kind: store.ImportKindCatalog, Format: "csv", DryRun: dryRun, Status: store.ImportJobQueued}
+	s.mockImportJobStore.On("CreateImportJob", mock.MatchedBy(func(j *store.ImportJob) bool {
+		return j.Kind == store.ImportKindCatalog && j.Format == "csv" && j.DryRun == dryRun
+	}), mock.MatchedBy(func(rows []store.ImportRow) bool {
+		want := []string{store.ImportRowPending, store.ImportRowInvalid, store.ImportRowPending, store.ImportRowInvalid}
+		return len(rows) == 4 &&
+			strings.Join(rowStatuses(rows), ",") == strings.Join(want, ",") &&
+			rows[1].Errors["title"] != "" &&
+			rows[3].Errors["isbn_13"] == "same ISBN as row 2" &&
+			strings.Contains(string(rows[0].Data), `"isbn_10":"0261102214"`)
+	})).Return(job, nil)
+
+	s.mockImportJobStore.On("StartImportJob", int64(5)).Return(nil)
+	s.mockImportJobStore.On("GetPendingImportRows", int64(5), importBatchSize).Return([]*store.ImportRow{
+		{JobID: 5, Row: 2, Status: store.ImportRowPending, Data: []byte(`{"title":"The Hobbit","authors":["J.R.R. Tolkien"],"isbn_13":"9780261102217"}`)},
+		{JobID: 5, Row: 4, Status: store.ImportRowPending, Data: []byte(`{"title":"Dune","authors":["Frank Herbert"],"isbn_13":"9780441172719"}`)},
+	}, nil).Once()
+	s.mockImportJobStore.On("GetPendingImportRows", int64(5), importBatchSize).Return([]*store.ImportRow{}, nil).Once()
+	s.mockBookStore.On("GetBookIDsByISBN13", []string{"9780261102217", "9780441172719"}).Return(map[string]int64{"9780441172719": 9}, nil)
+	s.mockImportJobStore.On("UpdateImportRow", mock.MatchedBy(func(row *store.ImportRow) bool {
+		return row.Row == 4 && row.Status == store.ImportRowSkipped && *row.BookID == 9
+	})).Return(nil).Once()
+	s.mockImportJobStore.On("FinishImportJob", int64(5), nil).Return(nil)
+	return job
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleImportCatalog_ImportsRows() {
+	s.expectQueuedJob(false)
+	s.mockBookStore.On("AddBook", mock.MatchedBy(func(book *store.Book) bool {
+		return book.Title == "The Hobbit" && book.ISBN13 == "9780261102217"
+	})).Return(&store.Book{ID: 42}, nil)
+	s.mockImportJobStore.On("UpdateImportRow", mock.MatchedBy(func(row *store.ImportRow) bool {
+		return row.Row == 2 && row.Status == store.ImportRowImported && *row.BookID == 42
+	})).Return(nil).Once()
+
+	ctx, w := s.newImportContext("", "books.csv", testImportCSV)
+
+	s.handler.HandleImportCatalog(ctx)
+
+	s.Equal(http.StatusAccepted, w.Code)
+	s.Equal("/books/imports/5", w.Header().Get("Location"))
+	s.mockImportJobStore.AssertExpectations(s.T())
+	s.mockBookStore.AssertExpectations(s.T())
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleImportCatalog_DryRun() {
+	s.expectQueuedJob(true)
+	s.mockImportJobStore.On("UpdateImportRow", mock.MatchedBy(func(row *store.ImportRow) bool {
+		return row.Row == 2 && row.Status == store.ImportRowValid && row.BookID == nil
+	})).Return(nil).Once()
+
+	ctx, w := s.newImportContext("?format=csv&dry_run=true", "upload", testImportCSV)
+
+	s.handler.HandleImportCatalog(ctx)
+
+	s.Equal(http.StatusAccepted, w.Code)
+	s.mockImportJobStore.AssertExpectations(s.T())
+	s.mockBookStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleImportCatalog_RowFails() {
+	job := &store.ImportJob{ID: 6, Kind: store.ImportKindCatalog, Format: "jsonl"}
+	s.mockImportJobStore.On("StartImportJob", int64(6)).Return(nil)
+	s.mockImportJobStore.On("GetPendingImportRows", int64(6), importBatchSize).Return([]*store.ImportRow{
+		{JobID: 6, Row: 1, Status: store.ImportRowPending, Data: []byte(`{"title":"Dune","isbn_13":"9780441172719"}`)},
+	}, nil).Once()
+	s.mockImportJobStore.On("GetPendingImportRows", int64(6), importBatchSize).Return([]*store.ImportRow{}, nil).Once()
+	s.mockBookStore.On("GetBookIDsByISBN13", []string{"9780441172719"}).Return(map[string]int64{}, nil)
+	s.mockBookStore.On("AddBook", mock.Anything).Return((*store.Book)(nil), errors.New("connection reset"))
+	s.mockImportJobStore.On("UpdateImportRow", mock.MatchedBy(func(row *store.ImportRow) bool {
+		return row.Status == store.ImportRowFailed && row.Errors["row"] != ""
+	})).Return(nil)
+	s.mockImportJobStore.On("FinishImportJob", int64(6), nil).Return(nil)
+
+	s.handler.runImport(job)
+
+	s.mockImportJobStore.AssertExpectations(s.T())
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleImportCatalog_InvalidFile() {
+	ctx, w := s.newImportContext("", "books.csv", "isbn_13,authors\n9780261102217,Tolkien\n")
+
+	s.handler.HandleImportCatalog(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "title")
+	s.mockImportJobStore.AssertNotCalled(s.T(), "CreateImportJob", mock.Anything, mock.Anything)
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleImportCatalog_UnknownFormat() {
+	ctx, w := s.newImportContext("", "books.xlsx", "title\nDune\n")
+
+	s.handler.HandleImportCatalog(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "format")
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleGetImportRows() {
+	s.mockImportJobStore.On("GetImportJobByID", int64(5)).Return(&store.ImportJob{ID: 5, Kind: store.ImportKindCatalog}, nil)
+	s.mockImportJobStore.On("GetImportRows", int64(5), store.ImportRowInvalid, 1, 20).Return([]*store.ImportRow{
+		{Row: 3, Status: store.ImportRowInvalid, Data: []byte(`{"title":""}`), Errors: map[string]string{"title": "title is required"}},
+	}, 1, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/imports/5/rows?status=invalid", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "5"}}
+
+	s.handler.HandleGetImportRows(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"errors":{"title":"title is required"}`)
+	s.Contains(w.Body.String(), `"total_items":1`)
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleGetImportRows_InvalidStatus() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/imports/5/rows?status=done", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "5"}}
+
+	s.handler.HandleGetImportRows(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleGetImportJob_NotFound() {
+	s.mockImportJobStore.On("GetImportJobByID", int64(7)).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/imports/7", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "7"}}
+
+	s.handler.HandleGetImportJob(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleExportCatalog() {
+	filter := store.BookFilter{}
+	s.mockBookStore.On("GetAllBooks", 1, exportPageSize, filter).Return([]*store.Book{
+		{ID: 1, Title: "Dune", Authors: []string{"Frank Herbert"}, Publisher: "Chilton", ISBN13: "9780441172719"},
+	}, 1, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/export?format=csv", nil)
+
+	s.handler.HandleExportCatalog(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	s.Contains(w.Header().Get("Content-Disposition"), ".csv")
+	s.Equal("title,authors,publisher,published_date,description,page_count,isbn_13,isbn_10,cover_url,chapters\n"+
+		"Dune,Frank Herbert,Chilton,,,,9780441172719,,,\n", w.Body.String())
+	s.mockBookStore.AssertNumberOfCalls(s.T(), "GetAllBooks", 1)
+}
+
+func (s *CatalogHandlerTestSuite) TestHandleExportCatalog_UnknownFormat() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/export?format=xlsx", nil)
+
+	s.handler.HandleExportCatalog(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockBookStore.AssertNotCalled(s.T(), "GetAllBooks", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -90,17 +90,20 @@ func (ch *ChapterCommentHandler) HandleAddComment(ctx *gin.Context) {
 
 // HandleUpdateComment godoc
 // @Summary      Update a comment to a book's chapter
-// @Description  Updates a book's chapter comment. Expects a body with the edited comment. Returns the updated comment on success.
+// @Description  Updates a book's chapter comment. Expects a body with the edited comment. Returns the updated comment on success. Send the comment's ETag in If-Match so an edit made elsewhere is not overwritten.
 // @Tags         comments
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        chapter_id path int true "Chapter ID"
 // @Param        id path int true "Comment ID"
+// @Param        If-Match header string false "ETag of the comment being edited"
 // @Param        request body AddChapterCommentRequest true "Edit comment request"
 // @Success      200 {object} store.ChapterComment
+// @Header       200 {string} ETag "Version of the comment"
 // @Failure      401 {object} HTTPError "Error: Unauthorized"
 // @Failure      404 {object} HTTPError "Error: Comment not found"
+// @Failure      412 {object} HTTPError "Error: Comment has been modified"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
 // @Router       /chapters/{chapter_id}/comments/{id} [put]
 func (ch *ChapterCommentHandler) HandleUpdateComment(ctx *gin.Context) {
@@ -156,10 +159,20 @@ func (ch *ChapterCommentHandler) HandleUpdateComment(ctx *gin.Context) {
 		return
 	}
 
-	err = ch.chapterCommentStore.UpdateComment(&comment)
+	ifMatch, ok := readIfMatch(ctx, existingComment.Version)
+	if !ok {
+		return
+	}
+
+	err = ch.chapterCommentStore.UpdateComment(&comment, ifMatch)
 	if err != nil {
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "comment has been modified since it was read"})
+			return
+		}
 		ch.logger.Printf("ERROR: updateComment %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
 	}
 
 	updatedComment, err := ch.chapterCommentStore.GetCommentByID(id)
@@ -169,6 +182,7 @@ func (ch *ChapterCommentHandler) HandleUpdateComment(ctx *gin.Context) {
 		return
 	}
 
+	utils.SetETag(ctx, updatedComment.Version)
 	ctx.JSON(http.StatusOK, updatedComment)
 }
 
@@ -183,7 +197,10 @@ func (ch *ChapterCommentHandler) HandleUpdateComment(ctx *gin.Context) {
 // @Produce      json
 // @Param        chapter_id path int true "Chapter ID"
 // @Param        id path int true "Comment ID"
+// @Param        If-None-Match header string false "ETag of the copy the client has"
 // @Success      200 {object} store.ChapterComment
+// @Success      304 "Not modified"
+// @Header       200 {string} ETag "Version of the comment"
 // @Failure      400 {object} HTTPError "Error: Invalid or missing id"
 // @Failure      404 {object} HTTPError "Error: Comment not found"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
@@ -231,21 +248,27 @@ func (ch *ChapterCommentHandler) HandleGetCommentById(ctx *gin.Context) {
 		return
 	}
 
+	if utils.NotModified(ctx, comment.Version) {
+		return
+	}
+
 	ctx.JSON(http.StatusOK, comment)
 }
 
 // HandleDeleteCommentById godoc
 // @Summary      Delete a comment to a book's chapter by id
-// @Description  Deletes a book's chapter comment. Returns the deleted comment on success.
+// @Description  Deletes a book's chapter comment. Readers can delete their own comments; moderators and admins can delete any comment.
 // @Tags         comments
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        chapter_id path int true "Chapter ID"
 // @Param        id path int true "Comment ID"
+// @Param        If-Match header string false "ETag of the comment being deleted"
 // @Success      200
 // @Failure      401 {object} HTTPError "Error: Unauthorized"
 // @Failure      404 {object} HTTPError "Error: Comment not found"
+// @Failure      412 {object} HTTPError "Error: Comment has been modified"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
 // @Router       /chapters/{chapter_id}/comments/{id} [delete]
 func (ch *ChapterCommentHandler) HandleDeleteCommentById(ctx *gin.Context) {
@@ -284,13 +307,22 @@ func (ch *ChapterCommentHandler) HandleDeleteCommentById(ctx *gin.Context) {
 	user := userValue.(*store.User)
 
 	userID := int64(user.ID)
-	if existingComment.UserID != userID { // TODO: Add admin check
+	if existingComment.UserID != userID && !user.Can(store.PermModerateComments) {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized to edit this comment"})
 		return
 	}
 
-	err = ch.chapterCommentStore.DeleteCommentByID(id)
+	ifMatch, ok := readIfMatch(ctx, existingComment.Version)
+	if !ok {
+		return
+	}
+
+	err = ch.chapterCommentStore.DeleteCommentByID(id, ifMatch)
 	if err != nil {
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "comment has been modified since it was read"})
+			return
+		}
 		ch.logger.Printf("ERROR: updateComment %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 	}
@@ -306,7 +338,7 @@ type PaginatedCommentsResponse struct {
 
 // HandleGetCommentsByChapterID godoc
 // @Summary      Get the comments of a book's chapter
-// @Description  Retrieves the comments of a specific chapter.
+// @Description  Retrieves the comments of a specific chapter, including those left on the same chapter number in other editions of the book.
 //
 //	Provide a valid chapter_id as a path and  parameter. Returns the paginated comments object on success.
 //
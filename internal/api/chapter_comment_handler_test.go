@@ -304,7 +304,7 @@ func (s *ChapterCommentHandlerTestSuite) TestHandleUpdateComment_Unauthorized()
 func (s *ChapterCommentHandlerTestSuite) TestHandleUpdateComment_ErrorUpdating() {
 	c := &store.ChapterComment{ID: 1, ChapterID: 1, UserID: 1}
 	s.mockStore.On("GetCommentByID", int64(1)).Return(c, nil)
-	s.mockStore.On("UpdateComment", mock.Anything).Return(fmt.Errorf("boom"))
+	s.mockStore.On("UpdateComment", mock.Anything, mock.Anything).Return(fmt.Errorf("boom"))
 
 	body, _ := json.Marshal(map[string]string{"body": "edited"})
 	req, _ := http.NewRequest(http.MethodPut, "/chapters/1/comments/1", bytes.NewBuffer(body))
@@ -327,7 +327,7 @@ func (s *ChapterCommentHandlerTestSuite) TestHandleUpdateComment_ErrorUpdating()
 func (s *ChapterCommentHandlerTestSuite) TestHandleUpdateComment_Success() {
 	c := &store.ChapterComment{ID: 1, ChapterID: 1, UserID: 1}
 	s.mockStore.On("GetCommentByID", int64(1)).Return(c, nil)
-	s.mockStore.On("UpdateComment", mock.Anything).Return(nil)
+	s.mockStore.On("UpdateComment", mock.Anything, mock.Anything).Return(nil)
 
 	body, _ := json.Marshal(map[string]string{"body": "edited"})
 	req, _ := http.NewRequest(http.MethodPut, "/chapters/1/comments/1", bytes.NewBuffer(body))
@@ -435,7 +435,7 @@ func (s *ChapterCommentHandlerTestSuite) TestHandleDeleteCommentById_Unauthorize
 func (s *ChapterCommentHandlerTestSuite) TestHandleDeleteCommentById_ErrorDeleting() {
 	c := &store.ChapterComment{ID: 1, ChapterID: 1, UserID: 1}
 	s.mockStore.On("GetCommentByID", int64(1)).Return(c, nil)
-	s.mockStore.On("DeleteCommentByID", int64(1)).Return(fmt.Errorf("boom"))
+	s.mockStore.On("DeleteCommentByID", int64(1), []int(nil)).Return(fmt.Errorf("boom"))
 
 	req, _ := http.NewRequest(http.MethodDelete, "/chapters/1/comments/1", nil)
 	w := httptest.NewRecorder()
@@ -456,7 +456,7 @@ func (s *ChapterCommentHandlerTestSuite) TestHandleDeleteCommentById_ErrorDeleti
 func (s *ChapterCommentHandlerTestSuite) TestHandleDeleteCommentById_Success() {
 	c := &store.ChapterComment{ID: 1, ChapterID: 1, UserID: 1}
 	s.mockStore.On("GetCommentByID", int64(1)).Return(c, nil)
-	s.mockStore.On("DeleteCommentByID", int64(1)).Return(nil)
+	s.mockStore.On("DeleteCommentByID", int64(1), []int(nil)).Return(nil)
 
 	req, _ := http.NewRequest(http.MethodDelete, "/chapters/1/comments/1", nil)
 	w := httptest.NewRecorder()
@@ -474,6 +474,27 @@ func (s *ChapterCommentHandlerTestSuite) TestHandleDeleteCommentById_Success() {
 	s.mockStore.AssertExpectations(s.T())
 }
 
+func (s *ChapterCommentHandlerTestSuite) TestHandleDeleteCommentById_ModeratorDeletesOthersComment() {
+	c := &store.ChapterComment{ID: 1, ChapterID: 1, UserID: 2}
+	s.mockStore.On("GetCommentByID", int64(1)).Return(c, nil)
+	s.mockStore.On("DeleteCommentByID", int64(1), []int(nil)).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/chapters/1/comments/1", nil)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{
+		gin.Param{Key: "chapter_id", Value: "1"},
+		gin.Param{Key: "id", Value: "1"},
+	}
+	ctx.Set("user", &store.User{ID: 1, Role: store.RoleModerator})
+
+	s.handler.HandleDeleteCommentById(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
 // --- Get Comments By Chapter ---
 func (s *ChapterCommentHandlerTestSuite) TestHandleGetCommentsByChapterID_InvalidChapterID() {
 	req, _ := http.NewRequest(http.MethodGet, "/chapters/abc/comments", nil)
@@ -539,3 +560,50 @@ func (s *ChapterCommentHandlerTestSuite) TestHandleGetCommentsByChapterID_Succes
 	s.Contains(w.Body.String(), "total_pages")
 	s.mockStore.AssertExpectations(s.T())
 }
+
+func (s *ChapterCommentHandlerTestSuite) TestHandleUpdateComment_StaleIfMatch() {
+	c := &store.ChapterComment{ID: 1, ChapterID: 1, UserID: 1, Version: 2}
+	s.mockStore.On("GetCommentByID", int64(1)).Return(c, nil)
+
+	body, _ := json.Marshal(map[string]string{"body": "edited"})
+	req, _ := http.NewRequest(http.MethodPut, "/chapters/1/comments/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{
+		gin.Param{Key: "chapter_id", Value: "1"},
+		gin.Param{Key: "id", Value: "1"},
+	}
+	ctx.Set("user", &store.User{ID: 1})
+
+	s.handler.HandleUpdateComment(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "UpdateComment", mock.Anything, mock.Anything)
+}
+
+func (s *ChapterCommentHandlerTestSuite) TestHandleUpdateComment_ChangedBeforeWrite() {
+	c := &store.ChapterComment{ID: 1, ChapterID: 1, UserID: 1, Version: 2}
+	s.mockStore.On("GetCommentByID", int64(1)).Return(c, nil)
+	s.mockStore.On("UpdateComment", mock.Anything, []int{2}).Return(store.ErrVersionMismatch)
+
+	body, _ := json.Marshal(map[string]string{"body": "edited"})
+	req, _ := http.NewRequest(http.MethodPut, "/chapters/1/comments/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Params = gin.Params{
+		gin.Param{Key: "chapter_id", Value: "1"},
+		gin.Param{Key: "id", Value: "1"},
+	}
+	ctx.Set("user", &store.User{ID: 1})
+
+	s.handler.HandleUpdateComment(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
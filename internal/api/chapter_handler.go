@@ -0,0 +1,572 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/toc"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const maxChapterTitleLength = 150
+
+type ChapterHandler struct {
+	chapterStore store.ChapterStore
+	bookStore    store.BookStore
+	logger       *log.Logger
+}
+
+func NewChapterHandler(chapterStore store.ChapterStore, bookStore store.BookStore, logger *log.Logger) *ChapterHandler {
+	return &ChapterHandler{
+		chapterStore: chapterStore,
+		bookStore:    bookStore,
+		logger:       logger,
+	}
+}
+
+type BookChaptersResponse struct {
+	Chapters []store.Chapter `json:"chapters"`
+}
+
+type AddChapterRequest struct {
+	// Number is where the chapter goes; later chapters move down one. Leave
+	// it out to add the chapter at the end.
+	Number    int     `json:"number" example:"3"`
+	Title     string  `json:"title" example:"A Short Rest"`
+	Part      *string `json:"part" example:"Part One"`
+	StartPage *int    `json:"start_page" example:"45"`
+	EndPage   *int    `json:"end_page" example:"60"`
+}
+
+// UpdateChapterRequest changes the fields that are present. An empty part or a
+// page of 0 clears it.
+type UpdateChapterRequest struct {
+	Title     *string `json:"title" example:"A Short Rest"`
+	Part      *string `json:"part" example:"Part One"`
+	StartPage *int    `json:"start_page" example:"45"`
+	EndPage   *int    `json:"end_page" example:"60"`
+}
+
+type ReorderChaptersRequest struct {
+	ChapterIDs []int64 `json:"chapter_ids" example:"12,10,11"`
+}
+
+type ImportChaptersRequest struct {
+	TableOfContents string `json:"table_of_contents" example:"Part One\n1. An Unexpected Party ..... 1\n2. Roast Mutton ..... 25"`
+	// Replace makes the parsed chapters the book's whole chapter list instead
+	// of adding them after the existing chapters.
+	Replace bool `json:"replace"`
+}
+
+// normalizeChapter trims the chapter's text fields and drops an empty part.
+func normalizeChapter(ch *store.Chapter) {
+	ch.Title = strings.TrimSpace(ch.Title)
+	if ch.Part != nil {
+		part := strings.TrimSpace(*ch.Part)
+		ch.Part = &part
+		if part == "" {
+			ch.Part = nil
+		}
+	}
+}
+
+// validateChapter returns what is wrong with a chapter, or "" if nothing is.
+func validateChapter(ch store.Chapter) string {
+	switch {
+	case ch.Title == "":
+		return "title is required"
+	case utf8.RuneCountInString(ch.Title) > maxChapterTitleLength:
+		return fmt.Sprintf("title must be at most %d characters", maxChapterTitleLength)
+	case ch.Part != nil && utf8.RuneCountInString(*ch.Part) > maxChapterTitleLength:
+		return fmt.Sprintf("part must be at most %d characters", maxChapterTitleLength)
+	case ch.StartPage != nil && *ch.StartPage < 1:
+		return "start_page must be >= 1"
+	case ch.EndPage != nil && ch.StartPage == nil:
+		return "end_page requires start_page"
+	case ch.EndPage != nil && *ch.EndPage < *ch.StartPage:
+		return "end_page must be >= start_page"
+	}
+	return ""
+}
+
+// validateChapters normalizes a chapter list and reports the first invalid
+// chapter, or "".
+func validateChapters(chapters []store.Chapter) string {
+	for i := range chapters {
+		normalizeChapter(&chapters[i])
+		if msg := validateChapter(chapters[i]); msg != "" {
+			return fmt.Sprintf("chapter %d: %s", i+1, msg)
+		}
+	}
+	return ""
+}
+
+// HandleGetBookChapters godoc
+// @Summary      List a book's chapters
+// @Description  Retrieves a book's chapters ordered by number, with their parts and page ranges.
+// @Tags         chapters
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Book ID"
+// @Success      200 {object} BookChaptersResponse
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/chapters [get]
+func (ch *ChapterHandler) HandleGetBookChapters(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	chapters, err := ch.chapterStore.GetChaptersByBookID(bookID)
+	if err != nil {
+		ch.logger.Printf("ERROR: getChaptersByBookID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if chapters == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BookChaptersResponse{Chapters: chapters})
+}
+
+// HandleAddChapter godoc
+// @Summary      Add a chapter to a book
+// @Description  Inserts a chapter at the given number, moving the chapters from that number on down by one. Without a number the chapter is added at the end.
+// @Tags         chapters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
+// @Param        request body AddChapterRequest true "Add chapter request"
+// @Success      200 {object} store.Chapter
+// @Failure      400 {object} ValidationError "Error: Invalid chapter"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/chapters [post]
+func (ch *ChapterHandler) HandleAddChapter(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	ifMatch, ok := readBookIfMatch(ctx, ch.bookStore, ch.logger, bookID)
+	if !ok {
+		return
+	}
+
+	var req AddChapterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ch.logger.Printf("ERROR: decodingAddChapter %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if req.Number < 0 {
+		ctx.JSON(http.StatusBadRequest, ValidationError{Error: "invalid chapter", Fields: map[string]string{"number": "number must be >= 1"}})
+		return
+	}
+
+	chapter := store.Chapter{
+		Number:    req.Number,
+		Title:     req.Title,
+		Part:      req.Part,
+		StartPage: req.StartPage,
+		EndPage:   req.EndPage,
+	}
+	normalizeChapter(&chapter)
+	if msg := validateChapter(chapter); msg != "" {
+		ctx.JSON(http.StatusBadRequest, ValidationError{Error: "invalid chapter", Fields: map[string]string{"chapter": msg}})
+		return
+	}
+
+	opts := store.UpdateBookOptions{EditorID: editorID(ctx), IfMatch: ifMatch}
+	created, err := ch.chapterStore.AddChapter(bookID, &chapter, opts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		ch.logger.Printf("ERROR: addChapter %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !setBookETag(ctx, ch.bookStore, ch.logger, bookID) {
+		return
+	}
+	ctx.JSON(http.StatusOK, created)
+}
+
+// HandleUpdateChapter godoc
+// @Summary      Update a chapter
+// @Description  Changes a chapter's title, part or page range. Fields left out are kept; an empty part or a page of 0 clears it. Use PUT /books/{id}/chapters/order to renumber chapters.
+// @Tags         chapters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
+// @Param        chapter_id path int true "Chapter ID"
+// @Param        request body UpdateChapterRequest true "Update chapter request"
+// @Success      200 {object} store.Chapter
+// @Failure      400 {object} ValidationError "Error: Invalid chapter"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Chapter not found"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/chapters/{chapter_id} [patch]
+func (ch *ChapterHandler) HandleUpdateChapter(ctx *gin.Context) {
+	bookID, chapterID, ok := ch.readChapterParams(ctx)
+	if !ok {
+		return
+	}
+
+	ifMatch, ok := readBookIfMatch(ctx, ch.bookStore, ch.logger, bookID)
+	if !ok {
+		return
+	}
+
+	var req UpdateChapterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ch.logger.Printf("ERROR: decodingUpdateChapter %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	chapter, err := ch.chapterStore.GetBookChapter(bookID, chapterID)
+	if err != nil {
+		ch.logger.Printf("ERROR: getBookChapter %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if chapter == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "chapter not found"})
+		return
+	}
+
+	if req.Title != nil {
+		chapter.Title = *req.Title
+	}
+	if req.Part != nil {
+		chapter.Part = req.Part
+	}
+	if req.StartPage != nil {
+		chapter.StartPage = req.StartPage
+		if *req.StartPage == 0 {
+			chapter.StartPage = nil
+		}
+	}
+	if req.EndPage != nil {
+		chapter.EndPage = req.EndPage
+		if *req.EndPage == 0 {
+			chapter.EndPage = nil
+		}
+	}
+
+	normalizeChapter(chapter)
+	if msg := validateChapter(*chapter); msg != "" {
+		ctx.JSON(http.StatusBadRequest, ValidationError{Error: "invalid chapter", Fields: map[string]string{"chapter": msg}})
+		return
+	}
+
+	opts := store.UpdateBookOptions{EditorID: editorID(ctx), IfMatch: ifMatch}
+	if err := ch.chapterStore.UpdateChapter(bookID, chapter, opts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "chapter not found"})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		ch.logger.Printf("ERROR: updateChapter %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !setBookETag(ctx, ch.bookStore, ch.logger, bookID) {
+		return
+	}
+	ctx.JSON(http.StatusOK, chapter)
+}
+
+// HandleDeleteChapter godoc
+// @Summary      Delete a chapter
+// @Description  Deletes a chapter and moves the chapters after it up by one. Chapters with comments are only deleted, along with their comments, when delete_comments=true.
+// @Tags         chapters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
+// @Param        chapter_id path int true "Chapter ID"
+// @Param        delete_comments query bool false "Also delete the chapter's comments"
+// @Success      204 "Deleted successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Chapter not found"
+// @Failure      409 {object} ChapterDeletionConflict "Error: Chapter has comments"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/chapters/{chapter_id} [delete]
+func (ch *ChapterHandler) HandleDeleteChapter(ctx *gin.Context) {
+	bookID, chapterID, ok := ch.readChapterParams(ctx)
+	if !ok {
+		return
+	}
+
+	ifMatch, ok := readBookIfMatch(ctx, ch.bookStore, ch.logger, bookID)
+	if !ok {
+		return
+	}
+
+	opts := store.UpdateBookOptions{
+		DeleteCommentedChapters: ctx.Query("delete_comments") == "true",
+		EditorID:                editorID(ctx),
+		IfMatch:                 ifMatch,
+	}
+	err := ch.chapterStore.DeleteChapter(bookID, chapterID, opts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "chapter not found"})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		var deletionErr *store.ChapterDeletionError
+		if errors.As(err, &deletionErr) {
+			ctx.JSON(http.StatusConflict, ChapterDeletionConflict{
+				Error:      "deleting this chapter would delete its comments; pass delete_comments=true to confirm",
+				ChapterIDs: deletionErr.ChapterIDs,
+			})
+			return
+		}
+		ch.logger.Printf("ERROR: deleteChapter %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !setBookETag(ctx, ch.bookStore, ch.logger, bookID) {
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleReorderChapters godoc
+// @Summary      Reorder a book's chapters
+// @Description  Renumbers the book's chapters 1..n in the order given. Every chapter of the book must be listed exactly once; the new numbers are applied together or not at all.
+// @Tags         chapters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
+// @Param        request body ReorderChaptersRequest true "Chapter ids in their new order"
+// @Success      200 {object} BookChaptersResponse
+// @Failure      400 {object} ValidationError "Error: Invalid chapter order"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/chapters/order [put]
+func (ch *ChapterHandler) HandleReorderChapters(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	ifMatch, ok := readBookIfMatch(ctx, ch.bookStore, ch.logger, bookID)
+	if !ok {
+		return
+	}
+
+	var req ReorderChaptersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ch.logger.Printf("ERROR: decodingReorderChapters %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	opts := store.UpdateBookOptions{EditorID: editorID(ctx), IfMatch: ifMatch}
+	chapters, err := ch.chapterStore.ReorderChapters(bookID, req.ChapterIDs, opts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		if errors.Is(err, store.ErrChapterNotInBook) || errors.Is(err, store.ErrDuplicateChapter) || errors.Is(err, store.ErrIncompleteChapterOrder) {
+			ctx.JSON(http.StatusBadRequest, ValidationError{
+				Error:  "invalid chapter order",
+				Fields: map[string]string{"chapter_ids": err.Error()},
+			})
+			return
+		}
+		ch.logger.Printf("ERROR: reorderChapters %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !setBookETag(ctx, ch.bookStore, ch.logger, bookID) {
+		return
+	}
+	ctx.JSON(http.StatusOK, BookChaptersResponse{Chapters: chapters})
+}
+
+// HandleImportChapters godoc
+// @Summary      Import chapters from a table of contents
+// @Description  Parses a pasted table of contents, one chapter per line, and adds the chapters after the book's existing ones.
+//
+//	Lines starting with "Part" or "Book" and a number ("Part One", "Book II") are headings for the chapters below them. Leading chapter numbers are dropped, and a trailing page number or range ("..... 45", "45-60") sets the chapter's pages; a chapter without an end page ends where the next one starts.
+//	With replace=true the chapters replace the book's chapter list instead. Existing chapters keep their ids, and comments, when their number is reused; chapters that would be removed with comments need delete_commented_chapters=true.
+//
+// @Tags         chapters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
+// @Param        request body ImportChaptersRequest true "Table of contents"
+// @Param        delete_commented_chapters query bool false "Allow replace to delete chapters that have comments"
+// @Success      200 {object} BookChaptersResponse "The imported chapters, or the book's whole chapter list when replacing"
+// @Failure      400 {object} ValidationError "Error: Invalid table of contents"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      409 {object} ChapterDeletionConflict "Error: Chapters with comments would be deleted"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/chapters/import [post]
+func (ch *ChapterHandler) HandleImportChapters(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	ifMatch, ok := readBookIfMatch(ctx, ch.bookStore, ch.logger, bookID)
+	if !ok {
+		return
+	}
+
+	var req ImportChaptersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ch.logger.Printf("ERROR: decodingImportChapters %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	entries, err := toc.Parse(req.TableOfContents)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ValidationError{
+			Error:  "invalid table of contents",
+			Fields: map[string]string{"table_of_contents": err.Error()},
+		})
+		return
+	}
+
+	chapters := make([]store.Chapter, len(entries))
+	for i, entry := range entries {
+		chapters[i] = store.Chapter{
+			Title:     entry.Title,
+			Part:      &entry.Part,
+			StartPage: entry.StartPage,
+			EndPage:   entry.EndPage,
+		}
+	}
+	if msg := validateChapters(chapters); msg != "" {
+		ctx.JSON(http.StatusBadRequest, ValidationError{
+			Error:  "invalid table of contents",
+			Fields: map[string]string{"table_of_contents": msg},
+		})
+		return
+	}
+
+	opts := store.UpdateBookOptions{
+		DeleteCommentedChapters: ctx.Query("delete_commented_chapters") == "true",
+		EditorID:                editorID(ctx),
+		Note:                    "Imported table of contents",
+		IfMatch:                 ifMatch,
+	}
+	if req.Replace {
+		chapters, err = ch.chapterStore.ReplaceChapters(bookID, chapters, opts)
+	} else {
+		chapters, err = ch.chapterStore.AppendChapters(bookID, chapters, opts)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		var deletionErr *store.ChapterDeletionError
+		if errors.As(err, &deletionErr) {
+			ctx.JSON(http.StatusConflict, ChapterDeletionConflict{
+				Error:      "replacing the chapters would delete their comments; pass delete_commented_chapters=true to confirm",
+				ChapterIDs: deletionErr.ChapterIDs,
+			})
+			return
+		}
+		ch.logger.Printf("ERROR: importChapters %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !setBookETag(ctx, ch.bookStore, ch.logger, bookID) {
+		return
+	}
+	ctx.JSON(http.StatusOK, BookChaptersResponse{Chapters: chapters})
+}
+
+// readChapterParams writes an error response and returns false when the book
+// or chapter id is invalid.
+func (ch *ChapterHandler) readChapterParams(ctx *gin.Context) (int64, int64, bool) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return 0, 0, false
+	}
+
+	chapterID, err := utils.ReadChapterIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readChapterIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid chapter id"})
+		return 0, 0, false
+	}
+
+	return bookID, chapterID, true
+}
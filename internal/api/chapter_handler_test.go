@@ -0,0 +1,279 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChapterHandlerTestSuite struct {
+	suite.Suite
+	mockStore     *mocks.MockChapterStore
+	mockBookStore *mocks.MockBookStore
+	handler       *ChapterHandler
+}
+
+func (s *ChapterHandlerTestSuite) SetupTest() {
+	s.mockStore = new(mocks.MockChapterStore)
+	s.mockBookStore = new(mocks.MockBookStore)
+	s.mockBookStore.On("GetBookByID", mock.Anything).Return(&store.Book{ID: 1, Version: 3}, nil)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewChapterHandler(s.mockStore, s.mockBookStore, logger)
+}
+
+func TestChapterHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ChapterHandlerTestSuite))
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// testEditorID is the user making the chapter edits.
+var testEditorID = int64(5)
+
+func (s *ChapterHandlerTestSuite) newContext(method, url string, body interface{}, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	var reqBody bytes.Buffer
+	if body != nil {
+		s.Require().NoError(json.NewEncoder(&reqBody).Encode(body))
+	}
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(method, url, &reqBody)
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = params
+	ctx.Set("user", &store.User{ID: testEditorID})
+	return ctx, w
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleGetBookChapters_Success() {
+	s.mockStore.On("GetChaptersByBookID", int64(1)).Return([]store.Chapter{
+		{ID: 10, Number: 1, Title: "An Unexpected Party", StartPage: intPtr(1), EndPage: intPtr(24)},
+	}, nil)
+
+	ctx, w := s.newContext(http.MethodGet, "/books/1/chapters", nil, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleGetBookChapters(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp BookChaptersResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Require().Len(resp.Chapters, 1)
+	s.Equal(24, *resp.Chapters[0].EndPage)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleGetBookChapters_BookNotFound() {
+	s.mockStore.On("GetChaptersByBookID", int64(9)).Return(nil, nil)
+
+	ctx, w := s.newContext(http.MethodGet, "/books/9/chapters", nil, gin.Params{{Key: "id", Value: "9"}})
+	s.handler.HandleGetBookChapters(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleAddChapter_Success() {
+	s.mockStore.On("AddChapter", int64(1), &store.Chapter{Number: 2, Title: "Roast Mutton", StartPage: intPtr(25)}, store.UpdateBookOptions{EditorID: &testEditorID}).
+		Return(&store.Chapter{ID: 11, Number: 2, Title: "Roast Mutton", StartPage: intPtr(25)}, nil)
+
+	body := AddChapterRequest{Number: 2, Title: "  Roast Mutton ", Part: new(string), StartPage: intPtr(25)}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/chapters", body, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleAddChapter(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleAddChapter_InvalidPageRange() {
+	body := AddChapterRequest{Title: "Roast Mutton", StartPage: intPtr(40), EndPage: intPtr(25)}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/chapters", body, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleAddChapter(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "end_page must be")
+	s.mockStore.AssertNotCalled(s.T(), "AddChapter", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleAddChapter_BookNotFound() {
+	s.mockStore.On("AddChapter", int64(9), mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows)
+
+	ctx, w := s.newContext(http.MethodPost, "/books/9/chapters", AddChapterRequest{Title: "Roast Mutton"}, gin.Params{{Key: "id", Value: "9"}})
+	s.handler.HandleAddChapter(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleUpdateChapter_KeepsOmittedFields() {
+	s.mockStore.On("GetBookChapter", int64(1), int64(11)).
+		Return(&store.Chapter{ID: 11, Number: 2, Title: "Roast Mutton", StartPage: intPtr(25), EndPage: intPtr(40)}, nil)
+	s.mockStore.On("UpdateChapter", int64(1), &store.Chapter{ID: 11, Number: 2, Title: "Roast Mutton", StartPage: intPtr(25)}, store.UpdateBookOptions{EditorID: &testEditorID}).
+		Return(nil)
+
+	body := map[string]interface{}{"end_page": 0}
+	ctx, w := s.newContext(http.MethodPatch, "/books/1/chapters/11", body, gin.Params{
+		{Key: "id", Value: "1"},
+		{Key: "chapter_id", Value: "11"},
+	})
+	s.handler.HandleUpdateChapter(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(`"3"`, w.Header().Get("ETag"))
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleUpdateChapter_StaleIfMatch() {
+	ctx, w := s.newContext(http.MethodPatch, "/books/1/chapters/11", map[string]string{"title": "x"}, gin.Params{
+		{Key: "id", Value: "1"},
+		{Key: "chapter_id", Value: "11"},
+	})
+	ctx.Request.Header.Set("If-Match", `"2"`)
+	s.handler.HandleUpdateChapter(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "UpdateChapter", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleUpdateChapter_NotFound() {
+	s.mockStore.On("GetBookChapter", int64(1), int64(99)).Return(nil, nil)
+
+	ctx, w := s.newContext(http.MethodPatch, "/books/1/chapters/99", map[string]string{"title": "x"}, gin.Params{
+		{Key: "id", Value: "1"},
+		{Key: "chapter_id", Value: "99"},
+	})
+	s.handler.HandleUpdateChapter(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleDeleteChapter_HasComments() {
+	s.mockStore.On("DeleteChapter", int64(1), int64(11), store.UpdateBookOptions{EditorID: &testEditorID}).Return(&store.ChapterDeletionError{ChapterIDs: []int64{11}})
+
+	ctx, w := s.newContext(http.MethodDelete, "/books/1/chapters/11", nil, gin.Params{
+		{Key: "id", Value: "1"},
+		{Key: "chapter_id", Value: "11"},
+	})
+	s.handler.HandleDeleteChapter(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	var resp ChapterDeletionConflict
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal([]int64{11}, resp.ChapterIDs)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleDeleteChapter_DeleteComments() {
+	s.mockStore.On("DeleteChapter", int64(1), int64(11), store.UpdateBookOptions{DeleteCommentedChapters: true, EditorID: &testEditorID}).Return(nil)
+
+	ctx, _ := s.newContext(http.MethodDelete, "/books/1/chapters/11?delete_comments=true", nil, gin.Params{
+		{Key: "id", Value: "1"},
+		{Key: "chapter_id", Value: "11"},
+	})
+	s.handler.HandleDeleteChapter(ctx)
+
+	s.Equal(http.StatusNoContent, ctx.Writer.Status())
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleDeleteChapter_StaleIfMatch() {
+	ctx, w := s.newContext(http.MethodDelete, "/books/1/chapters/11", nil, gin.Params{
+		{Key: "id", Value: "1"},
+		{Key: "chapter_id", Value: "11"},
+	})
+	ctx.Request.Header.Set("If-Match", `"2"`)
+	s.handler.HandleDeleteChapter(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "DeleteChapter", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleReorderChapters_Success() {
+	s.mockStore.On("ReorderChapters", int64(1), []int64{12, 10, 11}, store.UpdateBookOptions{EditorID: &testEditorID}).Return([]store.Chapter{
+		{ID: 12, Number: 1}, {ID: 10, Number: 2}, {ID: 11, Number: 3},
+	}, nil)
+
+	body := ReorderChaptersRequest{ChapterIDs: []int64{12, 10, 11}}
+	ctx, w := s.newContext(http.MethodPut, "/books/1/chapters/order", body, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleReorderChapters(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp BookChaptersResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal(int64(12), resp.Chapters[0].ID)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleReorderChapters_VersionMismatch() {
+	opts := store.UpdateBookOptions{EditorID: &testEditorID, IfMatch: []int{3}}
+	s.mockStore.On("ReorderChapters", int64(1), []int64{12, 10, 11}, opts).Return(nil, store.ErrVersionMismatch)
+
+	body := ReorderChaptersRequest{ChapterIDs: []int64{12, 10, 11}}
+	ctx, w := s.newContext(http.MethodPut, "/books/1/chapters/order", body, gin.Params{{Key: "id", Value: "1"}})
+	ctx.Request.Header.Set("If-Match", `"3"`)
+	s.handler.HandleReorderChapters(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleReorderChapters_Incomplete() {
+	s.mockStore.On("ReorderChapters", int64(1), []int64{12}, mock.Anything).Return(nil, store.ErrIncompleteChapterOrder)
+
+	body := ReorderChaptersRequest{ChapterIDs: []int64{12}}
+	ctx, w := s.newContext(http.MethodPut, "/books/1/chapters/order", body, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleReorderChapters(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "chapter_ids")
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleImportChapters_Append() {
+	part := "Part One"
+	expected := []store.Chapter{
+		{Title: "An Unexpected Party", Part: &part, StartPage: intPtr(1), EndPage: intPtr(24)},
+		{Title: "Roast Mutton", Part: &part, StartPage: intPtr(25)},
+	}
+	s.mockStore.On("AppendChapters", int64(1), expected, store.UpdateBookOptions{
+		EditorID: &testEditorID,
+		Note:     "Imported table of contents",
+	}).Return(expected, nil)
+
+	body := ImportChaptersRequest{TableOfContents: "Part One\n1. An Unexpected Party ..... 1\n2. Roast Mutton ..... 25"}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/chapters/import", body, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleImportChapters(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleImportChapters_ReplaceWithComments() {
+	s.mockStore.On("ReplaceChapters", int64(1), mock.Anything, store.UpdateBookOptions{
+		EditorID: &testEditorID,
+		Note:     "Imported table of contents",
+	}).
+		Return(nil, fmt.Errorf("wrapped: %w", &store.ChapterDeletionError{ChapterIDs: []int64{3}}))
+
+	body := ImportChaptersRequest{TableOfContents: "Prologue", Replace: true}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/chapters/import", body, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleImportChapters(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *ChapterHandlerTestSuite) TestHandleImportChapters_Empty() {
+	body := ImportChaptersRequest{TableOfContents: "\n\n"}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/chapters/import", body, gin.Params{{Key: "id", Value: "1"}})
+	s.handler.HandleImportChapters(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "AppendChapters", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/covers"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type CoverHandler struct {
+	bookStore store.BookStore
+	blobStore store.BlobStore
+	logger    *log.Logger
+}
+
+func NewCoverHandler(bookStore store.BookStore, blobStore store.BlobStore, logger *log.Logger) *CoverHandler {
+	return &CoverHandler{
+		bookStore: bookStore,
+		blobStore: blobStore,
+		logger:    logger,
+	}
+}
+
+// HandleUploadCover godoc
+// @Summary      Upload a book cover
+// @Description  Replaces the book's cover images with an uploaded JPEG, PNG or WebP image of up to 10 MB. The image is stripped of its metadata and stored as thumbnail, small, medium and large JPEGs, whose URLs are saved as the book's images.
+// @Tags         books
+// @Accept       mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
+// @Param        cover formData file true "Cover image"
+// @Success      200 {object} store.Book
+// @Failure      400 {object} HTTPError "Error: Missing or unreadable image"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      413 {object} HTTPError "Error: Image too large"
+// @Failure      415 {object} HTTPError "Error: Unsupported image format"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/cover [post]
+func (ch *CoverHandler) HandleUploadCover(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		ch.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	book, err := ch.bookStore.GetBookByID(bookID)
+	if err != nil {
+		ch.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if book == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	ifMatch, ok := readIfMatch(ctx, book.Version)
+	if !ok {
+		return
+	}
+
+	data, ok := ch.readCover(ctx)
+	if !ok {
+		return
+	}
+
+	images, err := covers.Process(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, covers.ErrUnsupportedFormat):
+			ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, covers.ErrTooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "could not read image: " + err.Error()})
+		}
+		return
+	}
+
+	// Keys are random, so each upload gets new URLs that can be cached forever
+	// and a failed upload only removes its own files, even when the same image
+	// was uploaded before. Earlier covers are kept since the book's history
+	// still links to them.
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		ch.logger.Printf("ERROR: coverKey %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	prefix := fmt.Sprintf("covers/%d/%x", bookID, id)
+	urls := map[string]*string{}
+	written := []string{}
+	for _, img := range images {
+		key := prefix + "-" + img.Variant + ".jpg"
+		if err := ch.blobStore.Put(key, img.Data, covers.ContentType); err != nil {
+			ch.logger.Printf("ERROR: putBlob %v", err)
+			ch.deleteBlobs(written)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		written = append(written, key)
+		url := ch.blobStore.URL(key)
+		urls[img.Variant] = &url
+	}
+
+	update := &store.Book{
+		ID: bookID,
+		Images: store.BookImages{
+			ThumbnailUrl: urls["thumbnail"],
+			SmallUrl:     urls["small"],
+			MediumUrl:    urls["medium"],
+			LargeUrl:     urls["large"],
+		},
+	}
+	opts := store.UpdateBookOptions{
+		EditorID: editorID(ctx),
+		Note:     "Uploaded cover",
+		Fields:   []string{store.BookFieldImages},
+		IfMatch:  ifMatch,
+	}
+	if err := ch.bookStore.UpdateBook(update, opts); err != nil {
+		ch.deleteBlobs(written)
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		ch.logger.Printf("ERROR: updateBook %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	updatedBook, err := ch.bookStore.GetBookByID(bookID)
+	if err != nil {
+		ch.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	utils.SetETag(ctx, updatedBook.Version)
+	ctx.JSON(http.StatusOK, updatedBook)
+}
+
+// readCover reads the uploaded file from the "cover" form field.
+func (ch *CoverHandler) readCover(ctx *gin.Context) ([]byte, bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, covers.MaxBytes+1<<20)
+
+	header, err := ctx.FormFile("cover")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "cover must be at most 10 MB"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "cover file is required"})
+		return nil, false
+	}
+	if header.Size > covers.MaxBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "cover must be at most 10 MB"})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ch.logger.Printf("ERROR: openCover %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "could not read cover file"})
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, covers.MaxBytes))
+	if err != nil {
+		ch.logger.Printf("ERROR: readCover %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "could not read cover file"})
+		return nil, false
+	}
+	return data, true
+}
+
+// deleteBlobs removes the variants of a cover that could not be saved.
+func (ch *CoverHandler) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		if err := ch.blobStore.Delete(key); err != nil {
+			ch.logger.Printf("ERROR: deleteBlob %v", err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CoverHandlerTestSuite struct {
+	suite.Suite
+	mockBookStore *mocks.MockBookStore
+	mockBlobStore *mocks.MockBlobStore
+	handler       *CoverHandler
+}
+
+func (s *CoverHandlerTestSuite) SetupTest() {
+	s.mockBookStore = new(mocks.MockBookStore)
+	s.mockBlobStore = new(mocks.MockBlobStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewCoverHandler(s.mockBookStore, s.mockBlobStore, logger)
+}
+
+func TestCoverHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(CoverHandlerTestSuite))
+}
+
+func (s *CoverHandlerTestSuite) newUploadContext(filename string, data []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("cover", filename)
+	_, _ = part.Write(data)
+	_ = writer.Close()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/1/cover", &body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	return ctx, w
+}
+
+func testPNG() []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 300, 450)))
+	return buf.Bytes()
+}
+
+func (s *CoverHandlerTestSuite) TestHandleUploadCover_Success() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(&store.Book{ID: 1, Version: 2}, nil)
+	s.mockBlobStore.On("Put", mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "covers/1/") && strings.HasSuffix(key, ".jpg")
+	}), mock.Anything, "image/jpeg").Return(nil).Times(4)
+	s.mockBookStore.On("UpdateBook", mock.MatchedBy(func(book *store.Book) bool {
+		images := book.Images
+		return book.ID == 1 &&
+			images.ThumbnailUrl != nil && strings.HasSuffix(*images.ThumbnailUrl, "-thumbnail.jpg") &&
+			images.SmallUrl != nil && images.MediumUrl != nil &&
+			images.LargeUrl != nil && strings.HasPrefix(*images.LargeUrl, "/media/covers/1/")
+	}), mock.MatchedBy(func(opts store.UpdateBookOptions) bool {
+		return len(opts.Fields) == 1 && opts.Fields[0] == store.BookFieldImages
+	})).Return(nil)
+
+	ctx, w := s.newUploadContext("cover.png", testPNG())
+
+	s.handler.HandleUploadCover(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockBookStore.AssertExpectations(s.T())
+	s.mockBlobStore.AssertExpectations(s.T())
+}
+
+func (s *CoverHandlerTestSuite) TestHandleUploadCover_UnsupportedFormat() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(&store.Book{ID: 1}, nil)
+
+	ctx, w := s.newUploadContext("cover.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`))
+
+	s.handler.HandleUploadCover(ctx)
+
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+	s.mockBlobStore.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CoverHandlerTestSuite) TestHandleUploadCover_MissingFile() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(&store.Book{ID: 1}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/1/cover", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleUploadCover(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *CoverHandlerTestSuite) TestHandleUploadCover_BookNotFound() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(nil, nil)
+
+	ctx, w := s.newUploadContext("cover.png", testPNG())
+
+	s.handler.HandleUploadCover(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *CoverHandlerTestSuite) TestHandleUploadCover_UpdateFailsRemovesBlobs() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(&store.Book{ID: 1}, nil)
+	s.mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.mockBlobStore.On("Delete", mock.Anything).Return(nil)
+	s.mockBookStore.On("UpdateBook", mock.Anything, mock.Anything).Return(store.ErrVersionMismatch)
+
+	ctx, w := s.newUploadContext("cover.png", testPNG())
+
+	s.handler.HandleUploadCover(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockBlobStore.AssertNumberOfCalls(s.T(), "Delete", 4)
+}
+
+func (s *CoverHandlerTestSuite) TestHandleUploadCover_FailedReuploadKeepsEarlierBlobs() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(&store.Book{ID: 1}, nil)
+	s.mockBookStore.On("UpdateBook", mock.Anything, mock.Anything).Return(nil).Once()
+	s.mockBookStore.On("UpdateBook", mock.Anything, mock.Anything).Return(store.ErrVersionMismatch).Once()
+	put := map[string]int{}
+	s.mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { put[args.String(0)]++ }).Return(nil)
+	s.mockBlobStore.On("Delete", mock.Anything).
+		Run(func(args mock.Arguments) { put[args.String(0)]-- }).Return(nil)
+
+	for i := 0; i < 2; i++ {
+		ctx, _ := s.newUploadContext("cover.png", testPNG())
+		s.handler.HandleUploadCover(ctx)
+	}
+
+	s.Len(put, 8)
+	kept := 0
+	for _, n := range put {
+		kept += n
+	}
+	s.Equal(4, kept, "the first upload's variants must survive the failed second one")
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+)
+
+type GenreHandler struct {
+	genreStore store.GenreStore
+	bookStore  store.BookStore
+	logger     *log.Logger
+}
+
+func NewGenreHandler(genreStore store.GenreStore, bookStore store.BookStore, logger *log.Logger) *GenreHandler {
+	return &GenreHandler{
+		genreStore: genreStore,
+		bookStore:  bookStore,
+		logger:     logger,
+	}
+}
+
+type GenreRequest struct {
+	Name     string `json:"name" example:"Fantasy"`
+	ParentID *int64 `json:"parent_id,omitempty" example:"1"`
+}
+
+type GenresResponse struct {
+	Genres []*store.Genre `json:"genres"`
+}
+
+type SetBookGenresRequest struct {
+	GenreIDs []int64 `json:"genre_ids" example:"1,2"`
+}
+
+// HandleGetAllGenres godoc
+// @Summary      Get all genres
+// @Description  Retrieves the genre taxonomy as a tree of root genres with their sub-genres.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} GenresResponse
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /genres [get]
+func (gh *GenreHandler) HandleGetAllGenres(ctx *gin.Context) {
+	genres, err := gh.genreStore.GetAllGenres()
+	if err != nil {
+		gh.logger.Printf("ERROR: getAllGenres %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GenresResponse{Genres: store.BuildGenreTree(genres)})
+}
+
+// HandleGetGenreByID godoc
+// @Summary      Get a genre by id
+// @Description  Retrieves a single genre by its id.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Genre ID"
+// @Success      200 {object} store.Genre
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      404 {object} HTTPError "Error: Genre not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /genres/{id} [get]
+func (gh *GenreHandler) HandleGetGenreByID(ctx *gin.Context) {
+	genreID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		gh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid genre id"})
+		return
+	}
+
+	genre, err := gh.genreStore.GetGenreByID(genreID)
+	if err != nil {
+		gh.logger.Printf("ERROR: getGenreByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if genre == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "genre not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, genre)
+}
+
+// HandleAddGenre godoc
+// @Summary      Add a genre
+// @Description  Adds a genre to the taxonomy, optionally under a parent genre.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body GenreRequest true "Add genre request"
+// @Success      200 {object} store.Genre
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      409 {object} HTTPError "Error: Duplicate record"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /genres [post]
+func (gh *GenreHandler) HandleAddGenre(ctx *gin.Context) {
+	var req GenreRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		gh.logger.Printf("ERROR: decodingAddGenre %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	genre, err := gh.genreStore.CreateGenre(&store.Genre{Name: req.Name, ParentID: req.ParentID})
+	if err != nil {
+		gh.writeStoreError(ctx, "createGenre", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, genre)
+}
+
+// HandleUpdateGenre godoc
+// @Summary      Update a genre
+// @Description  Renames a genre or moves it under another parent genre.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Genre ID"
+// @Param        request body GenreRequest true "Update genre request"
+// @Success      200 {object} store.Genre
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Genre not found"
+// @Failure      409 {object} HTTPError "Error: Duplicate record"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /genres/{id} [put]
+func (gh *GenreHandler) HandleUpdateGenre(ctx *gin.Context) {
+	genreID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		gh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid genre id"})
+		return
+	}
+
+	var req GenreRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		gh.logger.Printf("ERROR: decodingUpdateGenre %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	genre := &store.Genre{ID: genreID, Name: req.Name, ParentID: req.ParentID}
+	if err := gh.genreStore.UpdateGenre(genre); err != nil {
+		gh.writeStoreError(ctx, "updateGenre", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, genre)
+}
+
+// HandleDeleteGenreByID godoc
+// @Summary      Delete a genre
+// @Description  Deletes a genre. Genres that still have sub-genres cannot be deleted.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Genre ID"
+// @Success      204 "Deleted successfully"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Genre not found"
+// @Failure      409 {object} HTTPError "Error: Genre has sub-genres"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /genres/{id} [delete]
+func (gh *GenreHandler) HandleDeleteGenreByID(ctx *gin.Context) {
+	genreID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		gh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid genre id"})
+		return
+	}
+
+	if err := gh.genreStore.DeleteGenreByID(genreID); err != nil {
+		gh.writeStoreError(ctx, "deleteGenreByID", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleSetBookGenres godoc
+// @Summary      Set a book's genres
+// @Description  Replaces the genres assigned to a book.
+// @Tags         genres
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        If-Match header string false "ETag of the book being updated"
+// @Param        request body SetBookGenresRequest true "Genre ids"
+// @Success      204 "Updated successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book or genre not found"
+// @Failure      412 {object} HTTPError "Error: Book has been modified"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/genres [put]
+func (gh *GenreHandler) HandleSetBookGenres(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		gh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	ifMatch, ok := readBookIfMatch(ctx, gh.bookStore, gh.logger, bookID)
+	if !ok {
+		return
+	}
+
+	var req SetBookGenresRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		gh.logger.Printf("ERROR: decodingSetBookGenres %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	opts := store.UpdateBookOptions{EditorID: editorID(ctx), IfMatch: ifMatch}
+	if err := gh.genreStore.SetBookGenres(bookID, req.GenreIDs, opts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified since it was read"})
+			return
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book or genre not found"})
+			return
+		}
+		gh.logger.Printf("ERROR: setBookGenres %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !setBookETag(ctx, gh.bookStore, gh.logger, bookID) {
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (gh *GenreHandler) writeStoreError(ctx *gin.Context, op string, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "genre not found"})
+		return
+	}
+	if errors.Is(err, store.ErrGenreCycle) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			ctx.JSON(http.StatusConflict, gin.H{"error": "genre with this name already exists"})
+			return
+		case "23503":
+			if op == "deleteGenreByID" {
+				ctx.JSON(http.StatusConflict, gin.H{"error": "genre has sub-genres"})
+			} else {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "parent genre not found"})
+			}
+			return
+		}
+	}
+
+	gh.logger.Printf("ERROR: %s %v", op, err)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type GenreHandlerTestSuite struct {
+	suite.Suite
+	mockStore     *mocks.MockGenreStore
+	mockBookStore *mocks.MockBookStore
+	handler       *GenreHandler
+}
+
+func (s *GenreHandlerTestSuite) SetupTest() {
+	s.mockStore = new(mocks.MockGenreStore)
+	s.mockBookStore = new(mocks.MockBookStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewGenreHandler(s.mockStore, s.mockBookStore, logger)
+}
+
+func TestGenreHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(GenreHandlerTestSuite))
+}
+
+func (s *GenreHandlerTestSuite) TestHandleGetAllGenres_ReturnsTree() {
+	parentID := int64(1)
+	s.mockStore.On("GetAllGenres").Return([]*store.Genre{
+		{ID: 1, Name: "Fiction"},
+		{ID: 2, Name: "Fantasy", ParentID: &parentID},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/genres", nil)
+
+	s.handler.HandleGetAllGenres(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp GenresResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Genres, 1)
+	s.Equal("Fiction", resp.Genres[0].Name)
+	s.Len(resp.Genres[0].Children, 1)
+	s.Equal("Fantasy", resp.Genres[0].Children[0].Name)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *GenreHandlerTestSuite) TestHandleGetAllGenres_StoreError() {
+	s.mockStore.On("GetAllGenres").Return(nil, fmt.Errorf("test error"))
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/genres", nil)
+
+	s.handler.HandleGetAllGenres(ctx)
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+}
+
+func (s *GenreHandlerTestSuite) TestHandleGetGenreByID_NotFound() {
+	s.mockStore.On("GetGenreByID", int64(7)).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/genres/7", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "7"}}
+
+	s.handler.HandleGetGenreByID(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *GenreHandlerTestSuite) TestHandleAddGenre_MissingName() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/genres", bytes.NewBufferString(`{"name": "  "}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	s.handler.HandleAddGenre(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "name is required")
+}
+
+func (s *GenreHandlerTestSuite) TestHandleAddGenre_Duplicate() {
+	s.mockStore.On("CreateGenre", mock.Anything).Return(nil, &pgconn.PgError{Code: "23505"})
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/genres", bytes.NewBufferString(`{"name": "Fantasy"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	s.handler.HandleAddGenre(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *GenreHandlerTestSuite) TestHandleAddGenre_Success() {
+	parentID := int64(1)
+	s.mockStore.On("CreateGenre", mock.MatchedBy(func(g *store.Genre) bool {
+		return g.Name == "Fantasy" && g.ParentID != nil && *g.ParentID == parentID
+	})).Return(&store.Genre{ID: 2, Name: "Fantasy", ParentID: &parentID}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/genres", bytes.NewBufferString(`{"name": "Fantasy", "parent_id": 1}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	s.handler.HandleAddGenre(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"parent_id":1`)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *GenreHandlerTestSuite) TestHandleUpdateGenre_Cycle() {
+	s.mockStore.On("UpdateGenre", mock.Anything).Return(store.ErrGenreCycle)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/genres/1", bytes.NewBufferString(`{"name": "Fiction", "parent_id": 2}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleUpdateGenre(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *GenreHandlerTestSuite) TestHandleDeleteGenre_HasChildren() {
+	s.mockStore.On("DeleteGenreByID", int64(1)).Return(&pgconn.PgError{Code: "23503"})
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/genres/1", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleDeleteGenreByID(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "sub-genres")
+}
+
+func (s *GenreHandlerTestSuite) TestHandleDeleteGenre_NotFound() {
+	s.mockStore.On("DeleteGenreByID", int64(1)).Return(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/genres/1", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleDeleteGenreByID(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *GenreHandlerTestSuite) TestHandleSetBookGenres_Success() {
+	editorID := int64(5)
+	s.mockBookStore.On("GetBookByID", int64(3)).Return(&store.Book{ID: 3, Version: 2}, nil)
+	s.mockStore.On("SetBookGenres", int64(3), []int64{1, 2}, store.UpdateBookOptions{EditorID: &editorID, IfMatch: []int{2}}).Return(nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/books/3/genres", bytes.NewBufferString(`{"genre_ids": [1, 2]}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "3"}}
+	ctx.Request.Header.Set("If-Match", `"2"`)
+	ctx.Set("user", &store.User{ID: editorID})
+
+	s.handler.HandleSetBookGenres(ctx)
+
+	s.Equal(http.StatusNoContent, ctx.Writer.Status())
+	s.Equal(`"2"`, w.Header().Get("ETag"))
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *GenreHandlerTestSuite) TestHandleSetBookGenres_BookNotFound() {
+	s.mockBookStore.On("GetBookByID", int64(3)).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/books/3/genres", bytes.NewBufferString(`{"genre_ids": [1]}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "3"}}
+
+	s.handler.HandleSetBookGenres(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "SetBookGenres", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *GenreHandlerTestSuite) TestHandleSetBookGenres_StaleIfMatch() {
+	s.mockBookStore.On("GetBookByID", int64(3)).Return(&store.Book{ID: 3, Version: 4}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/books/3/genres", bytes.NewBufferString(`{"genre_ids": [1]}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Request.Header.Set("If-Match", `"3"`)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "3"}}
+
+	s.handler.HandleSetBookGenres(ctx)
+
+	s.Equal(http.StatusPreconditionFailed, w.Code)
+	s.mockStore.AssertNotCalled(s.T(), "SetBookGenres", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -1,23 +1,25 @@
 package api
 
 import (
-	"fmt"
+	"errors"
 	"log"
 	"net/http"
-	"time"
 
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type GoogleBookApiHandler struct {
-	googleBookAPI store.GoogleBookAPI
+	googleBookAPI store.GoogleBookAPICache
+	bookStore     store.BookStore
 	logger        *log.Logger
 }
 
-func NewGoogleBookApiHandler(googleAPiStore store.GoogleBookAPI, logger *log.Logger) *GoogleBookApiHandler {
+func NewGoogleBookApiHandler(googleAPiStore store.GoogleBookAPICache, bookStore store.BookStore, logger *log.Logger) *GoogleBookApiHandler {
 	return &GoogleBookApiHandler{
 		googleBookAPI: googleAPiStore,
+		bookStore:     bookStore,
 		logger:        logger,
 	}
 }
@@ -26,22 +28,68 @@ type GoogleBooksResponse struct {
 	GoogleBooks []*store.GoogleBooksAPISearch `json:"google_books"`
 }
 
+// api returns the Google Books client for this request. Admins can pass
+// no_cache=true to skip cached results.
+func (gbh *GoogleBookApiHandler) api(ctx *gin.Context) store.GoogleBookAPI {
+	if _, isAdmin := ctx.Get("admin"); isAdmin && ctx.Query("no_cache") == "true" {
+		return gbh.googleBookAPI.Fresh()
+	}
+	return gbh.googleBookAPI
+}
+
+// GoogleBookSearchResult is a Google Books volume mapped to a book. VolumeID
+// can be passed to POST /books/import/google/{volume_id}; InCatalog reports
+// whether a book with the same ISBN-13 already exists.
+type GoogleBookSearchResult struct {
+	store.Book
+	VolumeID      string `json:"volume_id" example:"hFfhrCWiLSMC"`
+	InCatalog     bool   `json:"in_catalog"`
+	CatalogBookID *int64 `json:"catalog_book_id,omitempty"`
+}
+
+type GoogleBookSearchResponse struct {
+	Books      []GoogleBookSearchResult `json:"books"`
+	TotalItems int                      `json:"total_items"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+}
+
 // HandleSearchGoogleBooks godoc
 // @Summary      Search Google Books
 // @Description  Searches for books in the Google Books API.
+//
+//	Combine free text (`q`) with field-scoped parameters; at least one is required. Results already in the catalog are flagged with `in_catalog`.
+//
 // @Tags         google_books
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        q query string false "Search query (e.g. title, author)"
-// @Success      200 {object} []store.Book "Successful response with list of books"
-// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Param        title query string false "Words in the title"
+// @Param        author query string false "Author name"
+// @Param        isbn query string false "ISBN-10 or ISBN-13"
+// @Param        publisher query string false "Publisher name"
+// @Param        subject query string false "Subject or category"
+// @Param        lang query string false "Two-letter language code, e.g. en"
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page (max 40)" default(20)
+// @Param        no_cache query bool false "Skip cached results (admins only)"
+// @Success      200 {object} GoogleBookSearchResponse
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Failure      503 {object} HTTPError "Error: Google Books unavailable"
 // @Router       /api/books [get]
 func (gbh *GoogleBookApiHandler) HandleSearchGoogleBooks(ctx *gin.Context) {
-	newBooks := make([]store.Book, 0)
-	query := ctx.Query("q")
-	if query == "" {
+	query := store.GoogleBooksQuery{
+		Query:     ctx.Query("q"),
+		Title:     ctx.Query("title"),
+		Author:    ctx.Query("author"),
+		ISBN:      ctx.Query("isbn"),
+		Publisher: ctx.Query("publisher"),
+		Subject:   ctx.Query("subject"),
+		Lang:      ctx.Query("lang"),
+	}
+	if query.Terms() == "" {
 		gbh.logger.Println("ERROR: missing search query")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": "missing search query",
@@ -49,8 +97,22 @@ func (gbh *GoogleBookApiHandler) HandleSearchGoogleBooks(ctx *gin.Context) {
 		return
 	}
 
-	books, err := gbh.googleBookAPI.SearchGoogleBooks(query)
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil || limit > store.GoogleBooksMaxResults {
+		gbh.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+	query.StartIndex = (page - 1) * limit
+	query.MaxResults = limit
+
+	search, err := gbh.api(ctx).SearchGoogleBooks(ctx.Request.Context(), query)
 	if err != nil {
+		if errors.Is(err, store.ErrProviderUnavailable) {
+			gbh.logger.Printf("ERROR: searching google books: %v", err)
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "book search is temporarily unavailable"})
+			return
+		}
 		gbh.logger.Printf("ERROR: searching google books: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to fetch books",
@@ -58,98 +120,113 @@ func (gbh *GoogleBookApiHandler) HandleSearchGoogleBooks(ctx *gin.Context) {
 		return
 	}
 
-	for index, book := range books {
-		mapped, err := mapGoogleToBook(book, index)
+	results := make([]GoogleBookSearchResult, 0, len(search.Items))
+	isbns := []string{}
+	for _, book := range search.Items {
+		mapped, err := store.GoogleVolumeToBook(book)
 		if err != nil {
 			gbh.logger.Printf("ERROR: mapping google book to internal book: %v", err)
 			continue
 		}
-		newBooks = append(newBooks, *mapped)
+		results = append(results, GoogleBookSearchResult{Book: *mapped, VolumeID: book.ID})
+		if mapped.ISBN13 != "" {
+			isbns = append(isbns, mapped.ISBN13)
+		}
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"books": newBooks,
-	})
-}
-
-func mapGoogleToBook(book store.GoogleBookBasicInfo, index int) (*store.Book, error) {
-	var Isbn10, Isbn13, thumbnail, smallThumbnail string
-
-	if book.VolumeInfo == nil {
-		return nil, fmt.Errorf("missing volume info for book: %s", book.ID)
-	}
-	var descriptionPtr *string
-	if book.VolumeInfo.Description != "" {
-		description := book.VolumeInfo.Description
-		descriptionPtr = &description
+	// The catalog flag is a convenience; search results are still useful
+	// without it.
+	if len(isbns) > 0 {
+		catalogIDs, err := gbh.bookStore.GetBookIDsByISBN13(isbns)
+		if err != nil {
+			gbh.logger.Printf("ERROR: getBookIDsByISBN13 %v", err)
+		}
+		for i := range results {
+			if id, ok := catalogIDs[results[i].ISBN13]; ok {
+				results[i].InCatalog = true
+				results[i].CatalogBookID = &id
+			}
+		}
 	}
 
-	var pageCountPtr *int
-	if book.VolumeInfo.PageCount != 0 {
-		pageCount := book.VolumeInfo.PageCount
-		pageCountPtr = &pageCount
-	}
+	ctx.JSON(http.StatusOK, GoogleBookSearchResponse{
+		Books:      results,
+		TotalItems: search.TotalItems,
+		Page:       page,
+		Limit:      limit,
+	})
+}
 
-	if book.VolumeInfo.ImageLinks != nil {
-		thumbnail = book.VolumeInfo.ImageLinks.Thumbnail
-		smallThumbnail = book.VolumeInfo.ImageLinks.SmallThumbnail
+// HandleImportGoogleBook godoc
+// @Summary      Import a Google Books volume
+// @Description  Fetches a volume from Google Books by its id and adds it to the catalog.
+//
+//	Books are deduplicated by ISBN-13: if the catalog already has the volume's ISBN-13, the existing book is returned with status 200 instead of creating a new one. Books that look like another catalog book are rejected as in POST /books.
+//
+// @Tags         google_books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        volume_id path string true "Google Books volume ID"
+// @Param        no_cache query bool false "Skip cached results"
+// @Param        force query bool false "Skip the duplicate check (catalog editors only)"
+// @Success      200 {object} store.Book "Book already in the catalog"
+// @Success      201 {object} store.Book "Book imported"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Volume not found"
+// @Failure      409 {object} DuplicateBookError "Error: Duplicate record"
+// @Failure      422 {object} HTTPError "Error: Volume has no or invalid ISBNs"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Failure      503 {object} HTTPError "Error: Google Books unavailable"
+// @Router       /books/import/google/{volume_id} [post]
+func (gbh *GoogleBookApiHandler) HandleImportGoogleBook(ctx *gin.Context) {
+	volumeID := ctx.Param("volume_id")
+	if volumeID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "missing volume id"})
+		return
 	}
 
-	publishedDate, err := parseGoogleDate(book.VolumeInfo.PublishedDate)
+	volume, err := gbh.api(ctx).GetGoogleBookByID(ctx.Request.Context(), volumeID)
 	if err != nil {
-		publishedDate = store.JSONDate{}
-	}
-
-	if book.VolumeInfo.IndustryIdentifiers != nil {
-		for _, identifier := range book.VolumeInfo.IndustryIdentifiers {
-			switch identifier.Type {
-			case "ISBN_10":
-				Isbn10 = identifier.Identifier
-			case "ISBN_13":
-				Isbn13 = identifier.Identifier
-			}
+		if errors.Is(err, store.ErrGoogleBookNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "volume not found"})
+			return
+		}
+		if errors.Is(err, store.ErrProviderUnavailable) {
+			gbh.logger.Printf("ERROR: getGoogleBookByID %v", err)
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "book search is temporarily unavailable"})
+			return
 		}
+		gbh.logger.Printf("ERROR: getGoogleBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch book"})
+		return
 	}
 
-	newBook := store.Book{
-		ID:            int64(index),
-		Title:         book.VolumeInfo.Title,
-		Authors:       book.VolumeInfo.Authors,
-		Publisher:     book.VolumeInfo.Publisher,
-		PublishedDate: publishedDate,
-		Description:   descriptionPtr,
-		PageCount:     pageCountPtr,
-		ISBN10:        &Isbn10,
-		ISBN13:        Isbn13,
-
-		Images: store.BookImages{
-			ThumbnailUrl: &thumbnail,
-			SmallUrl:     &smallThumbnail,
-		},
-	}
-	return &newBook, nil
-}
-
-func parseGoogleDate(dateStr string) (store.JSONDate, error) {
-	if dateStr == "" {
-		return store.JSONDate{}, nil
+	book, err := store.GoogleVolumeToBook(*volume)
+	if err != nil {
+		gbh.logger.Printf("ERROR: mapping google book to internal book: %v", err)
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "volume has no book information"})
+		return
 	}
 
-	layouts := []string{
-		"2006-01-02",
-		"2006-01",
-		"2006",
+	if book.ISBN13 == "" {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "volume has no ISBN-13"})
+		return
 	}
 
-	var parsed time.Time
-	var err error
-
-	for _, layout := range layouts {
-		parsed, err = time.Parse(layout, dateStr)
-		if err == nil {
-			return store.JSONDate(parsed), nil
-		}
-	}
+	importCatalogBook(ctx, gbh.bookStore, gbh.logger, book)
+}
 
-	return store.JSONDate{}, err
+// HandleGetGoogleBooksCacheStats godoc
+// @Summary      Google Books cache stats
+// @Description  Reports hit and miss counts for the Google Books cache.
+// @Tags         google_books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} store.GoogleBooksCacheStats
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Router       /api/books/cache [get]
+func (gbh *GoogleBookApiHandler) HandleGetGoogleBooksCacheStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gbh.googleBookAPI.Stats())
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,20 +14,25 @@ import (
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
 type GoogleBooksHandlerTest struct {
 	suite.Suite
 	mockGoogleBookAPI *mocks.MockGoogleBookAPIStore
+	mockBookStore     *mocks.MockBookStore
 	handler           *GoogleBookApiHandler
 }
 
 func (suite *GoogleBooksHandlerTest) SetupTest() {
 	suite.mockGoogleBookAPI = new(mocks.MockGoogleBookAPIStore)
+	suite.mockBookStore = new(mocks.MockBookStore)
 	var buf bytes.Buffer
 	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
-	suite.handler = NewGoogleBookApiHandler(suite.mockGoogleBookAPI, logger)
+	// A zero config disables caching so each test sees the mock directly.
+	cached := store.NewCachedGoogleBookAPI(suite.mockGoogleBookAPI, nil, store.GoogleBooksCacheConfig{})
+	suite.handler = NewGoogleBookApiHandler(cached, suite.mockBookStore, logger)
 }
 
 func TestGoogleBooksHandlerTestSuite(t *testing.T) {
@@ -34,6 +40,10 @@ func TestGoogleBooksHandlerTestSuite(t *testing.T) {
 }
 
 // --- HandleSearchGoogleBooks Tests ---
+func searchQuery(q string) store.GoogleBooksQuery {
+	return store.GoogleBooksQuery{Query: q, MaxResults: 20}
+}
+
 func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_MissingQuery() {
 	req, _ := http.NewRequest(http.MethodGet, "/api/books", nil)
 	w := httptest.NewRecorder()
@@ -47,7 +57,7 @@ func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_MissingQuery() {
 }
 
 func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_ErrorFromAPI() {
-	s.mockGoogleBookAPI.On("SearchGoogleBooks", "test").Return(nil, fmt.Errorf("api error"))
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("test")).Return(nil, fmt.Errorf("api error"))
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/books?q=test", nil)
 	w := httptest.NewRecorder()
@@ -76,7 +86,7 @@ func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_SuccessWithValidBoo
 			},
 		},
 	}
-	s.mockGoogleBookAPI.On("SearchGoogleBooks", "test").Return(books, nil)
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("test")).Return(&store.GoogleBooksAPISearch{Items: books}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/books?q=test", nil)
 	w := httptest.NewRecorder()
@@ -104,7 +114,7 @@ func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_SkipsInvalidBooks()
 			VolumeInfo: nil, // This will cause mapping error
 		},
 	}
-	s.mockGoogleBookAPI.On("SearchGoogleBooks", "test").Return(books, nil)
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("test")).Return(&store.GoogleBooksAPISearch{Items: books}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/books?q=test", nil)
 	w := httptest.NewRecorder()
@@ -120,7 +130,7 @@ func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_SkipsInvalidBooks()
 
 func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_EmptyResults() {
 	books := []store.GoogleBookBasicInfo{}
-	s.mockGoogleBookAPI.On("SearchGoogleBooks", "nonexistent").Return(books, nil)
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("nonexistent")).Return(&store.GoogleBooksAPISearch{Items: books}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/books?q=nonexistent", nil)
 	w := httptest.NewRecorder()
@@ -134,269 +144,223 @@ func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_EmptyResults() {
 	s.mockGoogleBookAPI.AssertExpectations(s.T())
 }
 
-// --- mapGoogleToBook Tests ---
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_MissingVolumeInfo() {
-	book := store.GoogleBookBasicInfo{
-		ID:         "test-id",
-		VolumeInfo: nil,
-	}
-
-	result, err := mapGoogleToBook(book, 0)
-
-	s.Nil(result)
-	s.NotNil(err)
-	s.Contains(err.Error(), "missing volume info")
+// --- HandleImportGoogleBook Tests ---
+func (s *GoogleBooksHandlerTest) importContext(volumeID string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/import/google/"+volumeID, nil)
+	ctx.Params = gin.Params{gin.Param{Key: "volume_id", Value: volumeID}}
+	return ctx, w
 }
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_ValidBookWithAllFields() {
-	thumbnail := "http://example.com/thumb.jpg"
-	smallThumb := "http://example.com/small.jpg"
-
-	book := store.GoogleBookBasicInfo{
-		ID: "valid-id",
+func importableVolume() *store.GoogleBookBasicInfo {
+	return &store.GoogleBookBasicInfo{
+		ID: "abc123",
 		VolumeInfo: &store.VolumeInfo{
-			Title:         "Test Book",
-			Authors:       []string{"Author 1", "Author 2"},
-			Publisher:     "Test Publisher",
-			PublishedDate: "2023-05-15",
-			Description:   "A detailed description",
-			PageCount:     350,
+			Title:         "The Hobbit",
+			Authors:       []string{"J.R.R. Tolkien"},
+			PublishedDate: "1937-09-21",
 			IndustryIdentifiers: []store.IndustryIdentifier{
-				{Type: "ISBN_10", Identifier: "1234567890"},
-				{Type: "ISBN_13", Identifier: "9781234567890"},
-			},
-			ImageLinks: &store.ImageLinks{
-				Thumbnail:      thumbnail,
-				SmallThumbnail: smallThumb,
+				{Type: "ISBN_13", Identifier: "9780261102217"},
 			},
 		},
 	}
-
-	result, err := mapGoogleToBook(book, 5)
-
-	s.NoError(err)
-	s.NotNil(result)
-	s.Equal(int64(5), result.ID)
-	s.Equal("Test Book", result.Title)
-	s.Equal(2, len(result.Authors))
-	s.Equal("Test Publisher", result.Publisher)
-	s.NotNil(result.Description)
-	s.Equal("A detailed description", *result.Description)
-	s.NotNil(result.PageCount)
-	s.Equal(350, *result.PageCount)
-	s.NotNil(result.ISBN10)
-	s.Equal("1234567890", *result.ISBN10)
-	s.Equal("9781234567890", result.ISBN13)
-	s.NotNil(result.Images.ThumbnailUrl)
-	s.Equal(thumbnail, *result.Images.ThumbnailUrl)
-	s.NotNil(result.Images.SmallUrl)
-	s.Equal(smallThumb, *result.Images.SmallUrl)
 }
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_NoDescription() {
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title: "Test Book",
-		},
-	}
+func (s *GoogleBooksHandlerTest) TestHandleImportGoogleBook_Creates() {
+	s.mockGoogleBookAPI.On("GetGoogleBookByID", "abc123").Return(importableVolume(), nil)
+	s.mockBookStore.On("GetBookByISBN13", "9780261102217").Return(nil, nil)
+	s.mockBookStore.On("FindDuplicateBooks", mock.Anything).Return([]*store.DuplicateCandidate{}, nil)
+	s.mockBookStore.On("AddBook", mock.MatchedBy(func(b *store.Book) bool {
+		return b.Title == "The Hobbit" && b.ISBN13 == "9780261102217"
+	})).Return(&store.Book{ID: 42, Title: "The Hobbit", ISBN13: "9780261102217"}, nil)
 
-	result, err := mapGoogleToBook(book, 0)
+	ctx, w := s.importContext("abc123")
+	s.handler.HandleImportGoogleBook(ctx)
 
-	s.NoError(err)
-	s.NotNil(result)
-	s.Nil(result.Description)
+	s.Equal(http.StatusCreated, w.Code)
+	s.Contains(w.Body.String(), `"id":42`)
+	s.mockBookStore.AssertExpectations(s.T())
 }
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_NoPageCount() {
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title:     "Test Book",
-			PageCount: 0,
-		},
-	}
+func (s *GoogleBooksHandlerTest) TestHandleImportGoogleBook_ReturnsExisting() {
+	s.mockGoogleBookAPI.On("GetGoogleBookByID", "abc123").Return(importableVolume(), nil)
+	s.mockBookStore.On("GetBookByISBN13", "9780261102217").Return(&store.Book{ID: 7, ISBN13: "9780261102217"}, nil)
 
-	result, err := mapGoogleToBook(book, 0)
+	ctx, w := s.importContext("abc123")
+	s.handler.HandleImportGoogleBook(ctx)
 
-	s.NoError(err)
-	s.NotNil(result)
-	s.Nil(result.PageCount)
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"id":7`)
+	s.mockBookStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
 }
 
-// func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_NoImageLinks() {
-// 	book := store.GoogleBookBasicInfo{
-// 		ID: "test-id",
-// 		VolumeInfo: &store.VolumeInfo{
-// 			Title:      "Test Book",
-// 			ImageLinks: nil,
-// 		},
-// 	}
-
-// 	result, err := mapGoogleToBook(book, 0)
-
-// 	s.NoError(err)
-// 	s.NotNil(result)
-// 	s.Nil(result.Images.ThumbnailUrl)
-// 	s.Nil(result.Images.SmallUrl)
-// }
-
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_OnlyISBN10() {
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title: "Test Book",
-			IndustryIdentifiers: []store.IndustryIdentifier{
-				{Type: "ISBN_10", Identifier: "1234567890"},
-			},
-		},
-	}
+func (s *GoogleBooksHandlerTest) TestHandleImportGoogleBook_VolumeNotFound() {
+	s.mockGoogleBookAPI.On("GetGoogleBookByID", "missing").Return(nil, store.ErrGoogleBookNotFound)
 
-	result, err := mapGoogleToBook(book, 0)
+	ctx, w := s.importContext("missing")
+	s.handler.HandleImportGoogleBook(ctx)
 
-	s.NoError(err)
-	s.NotNil(result)
-	s.NotNil(result.ISBN10)
-	s.Equal("1234567890", *result.ISBN10)
-	s.Equal("", result.ISBN13)
+	s.Equal(http.StatusNotFound, w.Code)
 }
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_OnlyISBN13() {
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title: "Test Book",
-			IndustryIdentifiers: []store.IndustryIdentifier{
-				{Type: "ISBN_13", Identifier: "9781234567890"},
-			},
-		},
-	}
+func (s *GoogleBooksHandlerTest) TestHandleImportGoogleBook_NoISBN13() {
+	volume := importableVolume()
+	volume.VolumeInfo.IndustryIdentifiers = nil
+	s.mockGoogleBookAPI.On("GetGoogleBookByID", "abc123").Return(volume, nil)
 
-	result, err := mapGoogleToBook(book, 0)
+	ctx, w := s.importContext("abc123")
+	s.handler.HandleImportGoogleBook(ctx)
 
-	s.NoError(err)
-	s.NotNil(result)
-	s.Equal("9781234567890", result.ISBN13)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
 }
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_NoIdentifiers() {
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title:               "Test Book",
-			IndustryIdentifiers: nil,
-		},
+// --- Caching Tests ---
+func (s *GoogleBooksHandlerTest) newCachingHandler() {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+	cached := store.NewCachedGoogleBookAPI(s.mockGoogleBookAPI, nil, store.GoogleBooksCacheConfig{TTL: time.Hour, Size: 10})
+	s.handler = NewGoogleBookApiHandler(cached, s.mockBookStore, logger)
+}
+
+func (s *GoogleBooksHandlerTest) search(query string, admin bool) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/books?"+query, nil)
+	if admin {
+		ctx.Set("admin", &store.User{ID: 1, Role: store.RoleAdmin})
 	}
+	s.handler.HandleSearchGoogleBooks(ctx)
+	return w
+}
 
-	result, err := mapGoogleToBook(book, 0)
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_CachesResults() {
+	s.newCachingHandler()
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("hobbit")).Return(&store.GoogleBooksAPISearch{Items: []store.GoogleBookBasicInfo{*importableVolume()}}, nil).Once()
+	s.mockBookStore.On("GetBookIDsByISBN13", []string{"9780261102217"}).Return(map[string]int64{}, nil)
 
-	s.NoError(err)
-	s.NotNil(result)
-}
+	s.Equal(http.StatusOK, s.search("q=hobbit", false).Code)
+	w := s.search("q=hobbit", false)
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_InvalidPublishedDate() {
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title:         "Test Book",
-			PublishedDate: "invalid-date",
-		},
-	}
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), "The Hobbit")
+	s.mockGoogleBookAPI.AssertNumberOfCalls(s.T(), "SearchGoogleBooks", 1)
 
-	result, err := mapGoogleToBook(book, 0)
+	stats := s.handler.googleBookAPI.Stats()
+	s.Equal(int64(1), stats.Hits)
+	s.Equal(int64(1), stats.Misses)
+}
+
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_DoesNotCacheErrors() {
+	s.newCachingHandler()
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("hobbit")).Return(nil, fmt.Errorf("boom")).Once()
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("hobbit")).Return(&store.GoogleBooksAPISearch{Items: []store.GoogleBookBasicInfo{}}, nil).Once()
 
-	s.NoError(err)
-	s.NotNil(result)
-	// When date parsing fails, it should still return empty JSONDate
-	s.Equal(store.JSONDate(time.Time{}), result.PublishedDate)
+	s.Equal(http.StatusInternalServerError, s.search("q=hobbit", false).Code)
+	s.Equal(http.StatusOK, s.search("q=hobbit", false).Code)
+	s.mockGoogleBookAPI.AssertNumberOfCalls(s.T(), "SearchGoogleBooks", 2)
 }
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_OtherIdentifierTypes() {
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title: "Test Book",
-			IndustryIdentifiers: []store.IndustryIdentifier{
-				{Type: "ISBN_10", Identifier: "1234567890"},
-				{Type: "OTHER_TYPE", Identifier: "should-be-ignored"},
-			},
-		},
-	}
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_AdminBypass() {
+	s.newCachingHandler()
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("hobbit")).Return(&store.GoogleBooksAPISearch{Items: []store.GoogleBookBasicInfo{}}, nil)
 
-	result, err := mapGoogleToBook(book, 0)
+	s.search("q=hobbit", false)
+	s.search("q=hobbit&no_cache=true", true)
 
-	s.NoError(err)
-	s.NotNil(result)
-	s.NotNil(result.ISBN10)
-	s.Equal("1234567890", *result.ISBN10)
+	s.mockGoogleBookAPI.AssertNumberOfCalls(s.T(), "SearchGoogleBooks", 2)
+	s.Equal(int64(1), s.handler.googleBookAPI.Stats().Bypasses)
 }
 
-// --- parseGoogleDate Tests ---
-func (s *GoogleBooksHandlerTest) TestParseGoogleDate_EmptyString() {
-	result, err := parseGoogleDate("")
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_BypassIgnoredForUsers() {
+	s.newCachingHandler()
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("hobbit")).Return(&store.GoogleBooksAPISearch{Items: []store.GoogleBookBasicInfo{}}, nil)
 
-	s.NoError(err)
-	s.Equal(store.JSONDate(time.Time{}), result)
+	s.search("q=hobbit", false)
+	s.search("q=hobbit&no_cache=true", false)
+
+	s.mockGoogleBookAPI.AssertNumberOfCalls(s.T(), "SearchGoogleBooks", 1)
 }
 
-func (s *GoogleBooksHandlerTest) TestParseGoogleDate_FullDate() {
-	result, err := parseGoogleDate("2023-05-15")
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_ProviderUnavailable() {
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("hobbit")).Return(nil, store.ErrProviderUnavailable)
+
+	w := s.search("q=hobbit", false)
 
-	s.NoError(err)
-	s.NotNil(result)
-	expectedTime, _ := time.Parse("2006-01-02", "2023-05-15")
-	s.Equal(store.JSONDate(expectedTime), result)
+	s.Equal(http.StatusServiceUnavailable, w.Code)
 }
 
-func (s *GoogleBooksHandlerTest) TestParseGoogleDate_YearMonth() {
-	result, err := parseGoogleDate("2023-05")
+func (s *GoogleBooksHandlerTest) TestHandleImportGoogleBook_ProviderUnavailable() {
+	s.mockGoogleBookAPI.On("GetGoogleBookByID", "abc123").Return(nil, fmt.Errorf("%w: timeout", store.ErrProviderUnavailable))
 
-	s.NoError(err)
-	expectedTime, _ := time.Parse("2006-01", "2023-05")
-	s.Equal(store.JSONDate(expectedTime), result)
+	ctx, w := s.importContext("abc123")
+	s.handler.HandleImportGoogleBook(ctx)
+
+	s.Equal(http.StatusServiceUnavailable, w.Code)
 }
 
-func (s *GoogleBooksHandlerTest) TestParseGoogleDate_YearOnly() {
-	result, err := parseGoogleDate("2023")
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_StructuredQueryAndPaging() {
+	expected := store.GoogleBooksQuery{
+		Title:      "The Hobbit",
+		Author:     "Tolkien",
+		Lang:       "en",
+		StartIndex: 20,
+		MaxResults: 10,
+	}
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", expected).Return(&store.GoogleBooksAPISearch{TotalItems: 57}, nil)
 
-	s.NoError(err)
-	expectedTime, _ := time.Parse("2006", "2023")
-	s.Equal(store.JSONDate(expectedTime), result)
+	w := s.search("title=The+Hobbit&author=Tolkien&lang=en&page=3&limit=10", false)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp GoogleBookSearchResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal(57, resp.TotalItems)
+	s.Equal(3, resp.Page)
+	s.Equal(10, resp.Limit)
+	s.mockGoogleBookAPI.AssertExpectations(s.T())
 }
 
-func (s *GoogleBooksHandlerTest) TestParseGoogleDate_InvalidString() {
-	result, err := parseGoogleDate("not-a-date")
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_LimitTooLarge() {
+	w := s.search("q=hobbit&limit=41", false)
 
-	s.NotNil(err)
-	s.Equal(store.JSONDate{}, result)
+	s.Equal(http.StatusBadRequest, w.Code)
 }
 
-func (s *GoogleBooksHandlerTest) TestMapGoogleToBook_PartialPageCount() {
-	// Test with non-zero PageCount to ensure it's set correctly
-	book := store.GoogleBookBasicInfo{
-		ID: "test-id",
-		VolumeInfo: &store.VolumeInfo{
-			Title:     "Test Book",
-			PageCount: 100,
+func (s *GoogleBooksHandlerTest) TestHandleSearchGoogleBooks_FlagsCatalogBooks() {
+	other := importableVolume()
+	other.ID = "xyz789"
+	other.VolumeInfo = &store.VolumeInfo{
+		Title: "The Hobbit (Illustrated)",
+		IndustryIdentifiers: []store.IndustryIdentifier{
+			{Type: "ISBN_13", Identifier: "9780547928227"},
 		},
 	}
+	s.mockGoogleBookAPI.On("SearchGoogleBooks", searchQuery("hobbit")).Return(&store.GoogleBooksAPISearch{
+		TotalItems: 2,
+		Items:      []store.GoogleBookBasicInfo{*importableVolume(), *other},
+	}, nil)
+	s.mockBookStore.On("GetBookIDsByISBN13", []string{"9780261102217", "9780547928227"}).Return(map[string]int64{"9780261102217": 42}, nil)
 
-	result, err := mapGoogleToBook(book, 0)
+	w := s.search("q=hobbit", false)
 
-	s.NoError(err)
-	s.NotNil(result)
-	s.NotNil(result.PageCount)
-	s.Equal(100, *result.PageCount)
+	s.Equal(http.StatusOK, w.Code)
+	var resp GoogleBookSearchResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Books, 2)
+	s.Equal("abc123", resp.Books[0].VolumeID)
+	s.True(resp.Books[0].InCatalog)
+	s.Equal(int64(42), *resp.Books[0].CatalogBookID)
+	s.Equal("xyz789", resp.Books[1].VolumeID)
+	s.False(resp.Books[1].InCatalog)
 }
 
-// Additional test using testify/assert for alternative assertion style
-func TestParseGoogleDateDirectly(t *testing.T) {
-	// Test with assert instead of suite for variety
-	result, err := parseGoogleDate("2020-12-25")
-	assert.NoError(t, err)
-	assert.NotNil(t, result)
+func TestGoogleBooksQueryTerms(t *testing.T) {
+	query := store.GoogleBooksQuery{
+		Query:  "dragons",
+		Title:  "The  Hobbit",
+		Author: "Tolkien",
+		ISBN:   "978-0-261-10221-7",
+	}
 
-	result2, err2 := parseGoogleDate("invalid")
-	assert.Error(t, err2)
-	assert.Equal(t, store.JSONDate{}, result2)
+	assert.Equal(t, `dragons intitle:"The Hobbit" inauthor:Tolkien isbn:9780261102217`, query.Terms())
 }
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+type ImageProxyHandler struct {
+	imageProxy store.ImageProxy
+	logger     *log.Logger
+}
+
+func NewImageProxyHandler(imageProxy store.ImageProxy, logger *log.Logger) *ImageProxyHandler {
+	return &ImageProxyHandler{
+		imageProxy: imageProxy,
+		logger:     logger,
+	}
+}
+
+// HandleGetProxiedImage godoc
+// @Summary      Get an external cover image
+// @Description  Serves a cover image from an allowed external host, such as Google Books or Open Library, over HTTPS. Images are cached, so covers keep loading when the source is slow or down. Cover URLs in API responses already point here.
+// @Tags         images
+// @Produce      image/jpeg
+// @Produce      image/png
+// @Produce      image/webp
+// @Produce      image/gif
+// @Param        url query string true "URL of the image"
+// @Success      200 {file} binary
+// @Failure      400 {object} HTTPError "Error: Missing url"
+// @Failure      403 {object} HTTPError "Error: Image host is not allowed"
+// @Failure      502 {object} HTTPError "Error: Image could not be fetched"
+// @Router       /images/proxy [get]
+func (ih *ImageProxyHandler) HandleGetProxiedImage(ctx *gin.Context) {
+	imageURL := ctx.Query("url")
+	if imageURL == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	image, err := ih.imageProxy.GetImage(ctx.Request.Context(), imageURL)
+	if err != nil {
+		if errors.Is(err, store.ErrImageHostNotAllowed) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ih.logger.Printf("ERROR: getImage %v", err)
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "image could not be fetched"})
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, image.ContentType, image.Data)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImageProxyHandlerTestSuite struct {
+	suite.Suite
+	mockImageProxy *mocks.MockImageProxy
+	handler        *ImageProxyHandler
+}
+
+func (s *ImageProxyHandlerTestSuite) SetupTest() {
+	s.mockImageProxy = new(mocks.MockImageProxy)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewImageProxyHandler(s.mockImageProxy, logger)
+}
+
+func TestImageProxyHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ImageProxyHandlerTestSuite))
+}
+
+func (s *ImageProxyHandlerTestSuite) newContext(query string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/images/proxy"+query, nil)
+	return ctx, w
+}
+
+func (s *ImageProxyHandlerTestSuite) TestHandleGetProxiedImage_Success() {
+	s.mockImageProxy.On("GetImage", mock.Anything, "https://covers.openlibrary.org/b/id/1-M.jpg").
+		Return(&store.ProxiedImage{Data: []byte("jpeg"), ContentType: "image/jpeg"}, nil)
+
+	ctx, w := s.newContext("?url=https%3A%2F%2Fcovers.openlibrary.org%2Fb%2Fid%2F1-M.jpg")
+
+	s.handler.HandleGetProxiedImage(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("image/jpeg", w.Header().Get("Content-Type"))
+	s.Equal("nosniff", w.Header().Get("X-Content-Type-Options"))
+	s.Equal("jpeg", w.Body.String())
+	s.mockImageProxy.AssertExpectations(s.T())
+}
+
+func (s *ImageProxyHandlerTestSuite) TestHandleGetProxiedImage_MissingURL() {
+	ctx, w := s.newContext("")
+
+	s.handler.HandleGetProxiedImage(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockImageProxy.AssertNotCalled(s.T(), "GetImage", mock.Anything, mock.Anything)
+}
+
+func (s *ImageProxyHandlerTestSuite) TestHandleGetProxiedImage_HostNotAllowed() {
+	s.mockImageProxy.On("GetImage", mock.Anything, "http://169.254.169.254/").Return(nil, store.ErrImageHostNotAllowed)
+
+	ctx, w := s.newContext("?url=http%3A%2F%2F169.254.169.254%2F")
+
+	s.handler.HandleGetProxiedImage(ctx)
+
+	s.Equal(http.StatusForbidden, w.Code)
+}
+
+func (s *ImageProxyHandlerTestSuite) TestHandleGetProxiedImage_FetchFailed() {
+	s.mockImageProxy.On("GetImage", mock.Anything, "https://books.google.com/a").Return(nil, errors.New("timeout"))
+
+	ctx, w := s.newContext("?url=https%3A%2F%2Fbooks.google.com%2Fa")
+
+	s.handler.HandleGetProxiedImage(ctx)
+
+	s.Equal(http.StatusBadGateway, w.Code)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationHandler struct {
+	recommendationStore store.RecommendationStore
+	config              store.RecommendationConfig
+	logger              *log.Logger
+}
+
+func NewRecommendationHandler(recommendationStore store.RecommendationStore, config store.RecommendationConfig, logger *log.Logger) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationStore: recommendationStore,
+		config:              config,
+		logger:              logger,
+	}
+}
+
+// RefreshRecommendations computes the similar books and user
+// recommendations again. It runs periodically in the background.
+func (rh *RecommendationHandler) RefreshRecommendations() {
+	if err := rh.recommendationStore.RefreshRecommendations(rh.config); err != nil {
+		rh.logger.Printf("ERROR: refreshRecommendations %v", err)
+	}
+}
+
+type SimilarBooksResponse struct {
+	Books []*store.SimilarBook `json:"books"`
+}
+
+// HandleGetSimilarBooks godoc
+// @Summary      Get similar books
+// @Description  Lists the books most often completed by the readers who completed this book, best match first. Similar books are computed periodically, so recently shelved books may take a while to be counted.
+// @Tags         books
+// @Produce      json
+// @Param        id path int true "Book ID"
+// @Success      200 {object} SimilarBooksResponse
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/similar [get]
+func (rh *RecommendationHandler) HandleGetSimilarBooks(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		rh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	books, err := rh.recommendationStore.GetSimilarBooks(bookID)
+	if err != nil {
+		rh.logger.Printf("ERROR: getSimilarBooks %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if books == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SimilarBooksResponse{Books: books})
+}
+
+type RecommendationsResponse struct {
+	Recommendations []*store.RecommendedBook `json:"recommendations"`
+	Page            int                      `json:"page"`
+	Limit           int                      `json:"limit"`
+	TotalItems      int                      `json:"total_items"`
+	TotalPages      int                      `json:"total_pages"`
+}
+
+// HandleGetRecommendations godoc
+// @Summary      Get my recommendations
+// @Description  Lists books recommended to the current user from the books they completed, best match first. Books whose work is already on the user's shelves are left out.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} RecommendationsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/recommendations [get]
+func (rh *RecommendationHandler) HandleGetRecommendations(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		rh.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	recommendations, total, err := rh.recommendationStore.GetRecommendations(user.ID, page, limit)
+	if err != nil {
+		rh.logger.Printf("ERROR: getRecommendations %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RecommendationsResponse{
+		Recommendations: recommendations,
+		Page:            page,
+		Limit:           limit,
+		TotalItems:      total,
+		TotalPages:      (total + limit - 1) / limit,
+	})
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecommendationHandlerTestSuite struct {
+	suite.Suite
+	mockRecommendationStore *mocks.MockRecommendationStore
+	config                  store.RecommendationConfig
+	logs                    *bytes.Buffer
+	handler                 *RecommendationHandler
+}
+
+func (s *RecommendationHandlerTestSuite) SetupTest() {
+	s.mockRecommendationStore = new(mocks.MockRecommendationStore)
+	s.config = store.RecommendationConfig{MinCoReaders: 2, SimilarBooks: 20, UserRecommendations: 50}
+	s.logs = &bytes.Buffer{}
+	logger := log.New(s.logs, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewRecommendationHandler(s.mockRecommendationStore, s.config, logger)
+}
+
+func TestRecommendationHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(RecommendationHandlerTestSuite))
+}
+
+func (s *RecommendationHandlerTestSuite) TestHandleGetSimilarBooks() {
+	s.mockRecommendationStore.On("GetSimilarBooks", int64(7)).Return([]*store.SimilarBook{
+		{Book: &store.Book{ID: 9, Title: "The Lord of the Rings"}, CoReaders: 3, Score: 0.75},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/7/similar", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "7"}}
+
+	s.handler.HandleGetSimilarBooks(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var response SimilarBooksResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Require().Len(response.Books, 1)
+	s.Equal(int64(9), response.Books[0].Book.ID)
+	s.Equal(3, response.Books[0].CoReaders)
+}
+
+func (s *RecommendationHandlerTestSuite) TestHandleGetSimilarBooks_NoneYet() {
+	s.mockRecommendationStore.On("GetSimilarBooks", int64(7)).Return([]*store.SimilarBook{}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/7/similar", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "7"}}
+
+	s.handler.HandleGetSimilarBooks(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"books":[]}`, w.Body.String())
+}
+
+func (s *RecommendationHandlerTestSuite) TestHandleGetSimilarBooks_NotFound() {
+	s.mockRecommendationStore.On("GetSimilarBooks", int64(7)).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/7/similar", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "7"}}
+
+	s.handler.HandleGetSimilarBooks(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *RecommendationHandlerTestSuite) TestHandleGetRecommendations() {
+	s.mockRecommendationStore.On("GetRecommendations", int64(3), 2, 10).Return([]*store.RecommendedBook{
+		{Book: &store.Book{ID: 9}, Score: 1.5},
+	}, 11, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/me/recommendations?page=2&limit=10", nil)
+	ctx.Set("user", &store.User{ID: 3})
+
+	s.handler.HandleGetRecommendations(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var response RecommendationsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Len(response.Recommendations, 1)
+	s.Equal(11, response.TotalItems)
+	s.Equal(2, response.TotalPages)
+}
+
+func (s *RecommendationHandlerTestSuite) TestHandleGetRecommendations_InvalidPagination() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/me/recommendations?limit=0", nil)
+	ctx.Set("user", &store.User{ID: 3})
+
+	s.handler.HandleGetRecommendations(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockRecommendationStore.AssertNotCalled(s.T(), "GetRecommendations")
+}
+
+func (s *RecommendationHandlerTestSuite) TestRefreshRecommendations_LogsErrors() {
+	s.mockRecommendationStore.On("RefreshRecommendations", s.config).Return(errors.New("connection reset"))
+
+	s.handler.RefreshRecommendations()
+
+	s.mockRecommendationStore.AssertExpectations(s.T())
+	s.Contains(s.logs.String(), "ERROR: refreshRecommendations connection reset")
+}
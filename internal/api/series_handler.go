@@ -0,0 +1,299 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+)
+
+type SeriesHandler struct {
+	seriesStore store.SeriesStore
+	logger      *log.Logger
+}
+
+func NewSeriesHandler(seriesStore store.SeriesStore, logger *log.Logger) *SeriesHandler {
+	return &SeriesHandler{
+		seriesStore: seriesStore,
+		logger:      logger,
+	}
+}
+
+type SeriesRequest struct {
+	Title       string  `json:"title" example:"The Lord of the Rings"`
+	Description *string `json:"description,omitempty" example:"Tolkien's epic high-fantasy trilogy."`
+}
+
+type SetSeriesBookRequest struct {
+	Position *float64 `json:"position" example:"2.5"`
+}
+
+// HandleGetSeriesByID godoc
+// @Summary      Get a series by id
+// @Description  Retrieves a series with its books ordered by position. Positions may be fractional (e.g. 2.5 for a novella).
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Series ID"
+// @Success      200 {object} store.Series
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      404 {object} HTTPError "Error: Series not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /series/{id} [get]
+func (sh *SeriesHandler) HandleGetSeriesByID(ctx *gin.Context) {
+	seriesID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid series id"})
+		return
+	}
+
+	series, err := sh.seriesStore.GetSeriesByID(seriesID)
+	if err != nil {
+		sh.logger.Printf("ERROR: getSeriesByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if series == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "series not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
+
+// HandleAddSeries godoc
+// @Summary      Add a series
+// @Description  Creates an empty series. Books are added to it with PUT /series/{id}/books/{book_id}.
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body SeriesRequest true "Add series request"
+// @Success      200 {object} store.Series
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /series [post]
+func (sh *SeriesHandler) HandleAddSeries(ctx *gin.Context) {
+	var req SeriesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		sh.logger.Printf("ERROR: decodingAddSeries %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	series, err := sh.seriesStore.CreateSeries(&store.Series{Title: req.Title, Description: req.Description})
+	if err != nil {
+		sh.logger.Printf("ERROR: createSeries %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
+
+// HandleUpdateSeries godoc
+// @Summary      Update a series
+// @Description  Updates the title and description of a series.
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Series ID"
+// @Param        request body SeriesRequest true "Update series request"
+// @Success      204 "Updated successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Series not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /series/{id} [put]
+func (sh *SeriesHandler) HandleUpdateSeries(ctx *gin.Context) {
+	seriesID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid series id"})
+		return
+	}
+
+	var req SeriesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		sh.logger.Printf("ERROR: decodingUpdateSeries %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	err = sh.seriesStore.UpdateSeries(&store.Series{ID: seriesID, Title: req.Title, Description: req.Description})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "series not found"})
+			return
+		}
+		sh.logger.Printf("ERROR: updateSeries %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleDeleteSeriesByID godoc
+// @Summary      Delete a series
+// @Description  Deletes a series. Its books are kept but no longer belong to a series.
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Series ID"
+// @Success      204 "Deleted successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Series not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /series/{id} [delete]
+func (sh *SeriesHandler) HandleDeleteSeriesByID(ctx *gin.Context) {
+	seriesID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid series id"})
+		return
+	}
+
+	if err := sh.seriesStore.DeleteSeriesByID(seriesID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "series not found"})
+			return
+		}
+		sh.logger.Printf("ERROR: deleteSeriesByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleSetSeriesBook godoc
+// @Summary      Place a book in a series
+// @Description  Adds a book to a series at the given position, or moves it if it already belongs to a series.
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Series ID"
+// @Param        book_id path int true "Book ID"
+// @Param        request body SetSeriesBookRequest true "Position in the series"
+// @Success      204 "Updated successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Series or book not found"
+// @Failure      409 {object} HTTPError "Error: Position already taken"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /series/{id}/books/{book_id} [put]
+func (sh *SeriesHandler) HandleSetSeriesBook(ctx *gin.Context) {
+	seriesID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid series id"})
+		return
+	}
+
+	bookID, err := utils.ReadBookIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readBookIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	var req SetSeriesBookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		sh.logger.Printf("ERROR: decodingSetSeriesBook %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if req.Position == nil || *req.Position < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "position must be >= 0"})
+		return
+	}
+
+	if err := sh.seriesStore.SetSeriesBook(seriesID, bookID, *req.Position); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23503":
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "series or book not found"})
+				return
+			case "23505":
+				ctx.JSON(http.StatusConflict, gin.H{"error": "another book already has this position in the series"})
+				return
+			}
+		}
+		sh.logger.Printf("ERROR: setSeriesBook %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleRemoveSeriesBook godoc
+// @Summary      Remove a book from a series
+// @Description  Removes a book from a series. The book itself is kept.
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Series ID"
+// @Param        book_id path int true "Book ID"
+// @Success      204 "Deleted successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book not in series"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /series/{id}/books/{book_id} [delete]
+func (sh *SeriesHandler) HandleRemoveSeriesBook(ctx *gin.Context) {
+	seriesID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid series id"})
+		return
+	}
+
+	bookID, err := utils.ReadBookIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readBookIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	if err := sh.seriesStore.RemoveSeriesBook(seriesID, bookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not in series"})
+			return
+		}
+		sh.logger.Printf("ERROR: removeSeriesBook %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SeriesHandlerTestSuite struct {
+	suite.Suite
+	mockStore *mocks.MockSeriesStore
+	handler   *SeriesHandler
+}
+
+func (s *SeriesHandlerTestSuite) SetupTest() {
+	s.mockStore = new(mocks.MockSeriesStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewSeriesHandler(s.mockStore, logger)
+}
+
+func TestSeriesHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(SeriesHandlerTestSuite))
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleGetSeriesByID_Success() {
+	s.mockStore.On("GetSeriesByID", int64(1)).Return(&store.Series{
+		ID:    1,
+		Title: "The Lord of the Rings",
+		Books: []store.SeriesEntry{
+			{Position: 1, Book: &store.Book{ID: 10, Title: "The Fellowship of the Ring"}},
+			{Position: 1.5, Book: &store.Book{ID: 12, Title: "A Novella"}},
+		},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/series/1", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleGetSeriesByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp store.Series
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Books, 2)
+	s.Equal(1.5, resp.Books[1].Position)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleGetSeriesByID_NotFound() {
+	s.mockStore.On("GetSeriesByID", int64(7)).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/series/7", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "7"}}
+
+	s.handler.HandleGetSeriesByID(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleAddSeries_MissingTitle() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/series", bytes.NewBufferString(`{"title": " "}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	s.handler.HandleAddSeries(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "title is required")
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleAddSeries_Success() {
+	s.mockStore.On("CreateSeries", mock.MatchedBy(func(series *store.Series) bool {
+		return series.Title == "Discworld"
+	})).Return(&store.Series{ID: 3, Title: "Discworld", Books: []store.SeriesEntry{}}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/series", bytes.NewBufferString(`{"title": "Discworld"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	s.handler.HandleAddSeries(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleSetSeriesBook_FractionalPosition() {
+	s.mockStore.On("SetSeriesBook", int64(1), int64(12), 2.5).Return(nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/series/1/books/12", bytes.NewBufferString(`{"position": 2.5}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "1"},
+		gin.Param{Key: "book_id", Value: "12"},
+	}
+
+	s.handler.HandleSetSeriesBook(ctx)
+
+	s.Equal(http.StatusNoContent, ctx.Writer.Status())
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleSetSeriesBook_MissingPosition() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/series/1/books/12", bytes.NewBufferString(`{}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "1"},
+		gin.Param{Key: "book_id", Value: "12"},
+	}
+
+	s.handler.HandleSetSeriesBook(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleSetSeriesBook_PositionTaken() {
+	s.mockStore.On("SetSeriesBook", int64(1), int64(12), 2.0).Return(&pgconn.PgError{Code: "23505"})
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/series/1/books/12", bytes.NewBufferString(`{"position": 2}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "1"},
+		gin.Param{Key: "book_id", Value: "12"},
+	}
+
+	s.handler.HandleSetSeriesBook(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *SeriesHandlerTestSuite) TestHandleRemoveSeriesBook_NotInSeries() {
+	s.mockStore.On("RemoveSeriesBook", int64(1), int64(12)).Return(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/series/1/books/12", nil)
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "1"},
+		gin.Param{Key: "book_id", Value: "12"},
+	}
+
+	s.handler.HandleRemoveSeriesBook(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
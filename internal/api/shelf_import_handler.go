@@ -0,0 +1,439 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/shelfimport"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+)
+
+// shelfImportLookupTimeout bounds each metadata lookup of a shelf import, so a
+// slow provider fails the row instead of stalling the job.
+const shelfImportLookupTimeout = 30 * time.Second
+
+type ShelfImportHandler struct {
+	bookStore      store.BookStore
+	userBooksStore store.UserBooksStore
+	importJobStore store.ImportJobStore
+	provider       store.BookMetadataProvider
+	logger         *log.Logger
+	// background runs import jobs once they are queued.
+	background func(func())
+}
+
+func NewShelfImportHandler(bookStore store.BookStore, userBooksStore store.UserBooksStore, importJobStore store.ImportJobStore, provider store.BookMetadataProvider, logger *log.Logger) *ShelfImportHandler {
+	return &ShelfImportHandler{
+		bookStore:      bookStore,
+		userBooksStore: userBooksStore,
+		importJobStore: importJobStore,
+		provider:       provider,
+		logger:         logger,
+		background:     func(run func()) { go run() },
+	}
+}
+
+// HandleImportShelves godoc
+// @Summary      Import my shelves from Goodreads or StoryGraph
+// @Description  Queues an import of the library export of another reading site and returns the job, whose report can be followed at /me/imports/{id}.
+//
+//	The file is the CSV from Goodreads' "Export Library" or StoryGraph's export; which one is told from its header. Each row is matched to a catalog book by ISBN. Books missing from the catalog are looked up in the metadata providers and added to it.
+//	Every matched book is shelved with the row's status, reading dates and rating, and tagged with the row's shelves or tags. Books already on your shelf are skipped and left unchanged.
+//	Rows end up matched, created (the book was added to the catalog), unmatched (no book has the row's ISBN, or the row has none), skipped, invalid or failed.
+//
+// @Tags         user_books
+// @Accept       mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file formData file true "Goodreads or StoryGraph CSV export, up to 20 MB and 10000 books"
+// @Success      202 {object} store.ImportJob
+// @Failure      400 {object} ValidationError "Error: Missing or unreadable file"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      413 {object} HTTPError "Error: File too large"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/imports [post]
+func (sh *ShelfImportHandler) HandleImportShelves(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes+1<<20)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file must be at most 20 MB"})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if header.Size > maxImportBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file must be at most 20 MB"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		sh.logger.Printf("ERROR: openImportFile %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "could not read file"})
+		return
+	}
+	defer file.Close()
+
+	source, entries, err := shelfimport.Read(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ValidationError{
+			Error:  "invalid import file",
+			Fields: map[string]string{"file": err.Error()},
+		})
+		return
+	}
+	if len(entries) > maxImportRows {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must have at most %d books", maxImportRows)})
+		return
+	}
+
+	rows, err := shelfImportRows(entries)
+	if err != nil {
+		sh.logger.Printf("ERROR: shelfImportRows %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	job, err := sh.importJobStore.CreateImportJob(&store.ImportJob{
+		Kind:   store.ImportKindShelf,
+		UserID: &user.ID,
+		Format: source,
+	}, rows)
+	if err != nil {
+		sh.logger.Printf("ERROR: createImportJob %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	sh.background(func() { sh.runImport(job) })
+
+	ctx.Header("Location", fmt.Sprintf("/me/imports/%d", job.ID))
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// shelfImportRows turns the entries read from an export into import rows.
+// Entries that could be read are left pending for the job.
+func shelfImportRows(entries []shelfimport.Entry) ([]store.ImportRow, error) {
+	rows := make([]store.ImportRow, 0, len(entries))
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		row := store.ImportRow{Row: entry.Row, Status: store.ImportRowPending, Data: data}
+		if entry.Err != nil {
+			row.Status = store.ImportRowInvalid
+			row.Errors = map[string]string{"row": entry.Err.Error()}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ResumeImports restarts the shelf imports that were queued or running when
+// the server stopped. Rows already processed are not imported again.
+func (sh *ShelfImportHandler) ResumeImports() {
+	jobs, err := sh.importJobStore.GetUnfinishedImportJobs(store.ImportKindShelf)
+	if err != nil {
+		sh.logger.Printf("ERROR: getUnfinishedImportJobs %v", err)
+		return
+	}
+	for _, job := range jobs {
+		sh.background(func() { sh.runImport(job) })
+	}
+}
+
+func (sh *ShelfImportHandler) runImport(job *store.ImportJob) {
+	runImportJob(sh.importJobStore, sh.logger, job, sh.importPendingRows)
+}
+
+func (sh *ShelfImportHandler) importPendingRows(job *store.ImportJob) error {
+	if job.UserID == nil {
+		// The member deleted their account before the import ran.
+		return errors.New("import has no user")
+	}
+
+	for {
+		rows, err := sh.importJobStore.GetPendingImportRows(job.ID, importBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		entries := make([]*shelfimport.Entry, len(rows))
+		isbns := []string{}
+		for i, row := range rows {
+			entries[i] = &shelfimport.Entry{}
+			if err := json.Unmarshal(row.Data, entries[i]); err != nil {
+				return err
+			}
+			if entries[i].ISBN13 != "" {
+				isbns = append(isbns, entries[i].ISBN13)
+			}
+		}
+		existing, err := sh.bookStore.GetBookIDsByISBN13(isbns)
+		if err != nil {
+			return err
+		}
+
+		for i, row := range rows {
+			sh.importRow(job, row, entries[i], existing)
+			if err := sh.importJobStore.UpdateImportRow(row); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// importRow shelves the row's book for the job's user and records the result
+// in row. Books the catalog lacks are added to it first; existing is updated
+// with them so later rows of the same book find them.
+func (sh *ShelfImportHandler) importRow(job *store.ImportJob, row *store.ImportRow, entry *shelfimport.Entry, existing map[string]int64) {
+	if entry.ISBN13 == "" {
+		row.Status = store.ImportRowUnmatched
+		row.Errors = map[string]string{"isbn_13": "the row has no ISBN to match"}
+		return
+	}
+
+	status := store.ImportRowMatched
+	bookID, ok := existing[entry.ISBN13]
+	if !ok {
+		var found bool
+		bookID, found, ok = sh.addMissingBook(job, row, entry.ISBN13)
+		if !ok {
+			return
+		}
+		if !found {
+			status = store.ImportRowCreated
+		}
+		existing[entry.ISBN13] = bookID
+	}
+	row.BookID = &bookID
+
+	_, err := sh.userBooksStore.ImportUserBook(&store.UserBook{
+		UserID:      *job.UserID,
+		BookID:      bookID,
+		Status:      entry.Status,
+		StartedAt:   entry.StartedAt,
+		CompletedAt: entry.CompletedAt,
+		Rating:      entry.Rating,
+	}, entry.Shelves)
+	if errors.Is(err, store.ErrAlreadyShelved) {
+		row.Status = store.ImportRowSkipped
+		row.Errors = map[string]string{"book": "this book or another edition of it is already on your shelf"}
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		row.Status = store.ImportRowUnmatched
+		row.Errors = map[string]string{"isbn_13": "the book with this ISBN has been removed from the catalog"}
+		return
+	}
+	if err != nil {
+		sh.logger.Printf("ERROR: importUserBook job %d row %d: %v", job.ID, row.Row, err)
+		row.Status = store.ImportRowFailed
+		row.Errors = map[string]string{"row": "the book could not be shelved"}
+		return
+	}
+	row.Status = status
+}
+
+// addMissingBook looks isbn13 up in the metadata providers and adds the book
+// to the catalog. found is true when another import added the book first.
+// When it returns false, it has recorded why in row.
+func (sh *ShelfImportHandler) addMissingBook(job *store.ImportJob, row *store.ImportRow, isbn13 string) (id int64, found, ok bool) {
+	lookupCtx, cancel := context.WithTimeout(context.Background(), shelfImportLookupTimeout)
+	defer cancel()
+
+	candidate, err := sh.provider.GetBookByISBN(lookupCtx, isbn13)
+	if err == nil && !candidate.HasISBN(isbn13) {
+		err = store.ErrBookMetadataNotFound
+	}
+	if errors.Is(err, store.ErrBookMetadataNotFound) {
+		row.Status = store.ImportRowUnmatched
+		row.Errors = map[string]string{"isbn_13": "no book with this ISBN was found"}
+		return 0, false, false
+	}
+	if err != nil {
+		sh.logger.Printf("ERROR: getBookMetadataByISBN job %d row %d: %v", job.ID, row.Row, err)
+		row.Status = store.ImportRowFailed
+		row.Errors = map[string]string{"row": "the book could not be looked up"}
+		return 0, false, false
+	}
+
+	book := candidate.Book
+	book.ISBN13 = isbn13
+	added, err := sh.bookStore.AddBook(&book)
+	if err != nil {
+		// Another import of the same ISBN won the race; shelve that book.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if existing, getErr := sh.bookStore.GetBookByISBN13(isbn13); getErr == nil && existing != nil {
+				return existing.ID, true, true
+			}
+		}
+		sh.logger.Printf("ERROR: addBook job %d row %d: %v", job.ID, row.Row, err)
+		row.Status = store.ImportRowFailed
+		row.Errors = map[string]string{"row": "the book could not be added to the catalog"}
+		return 0, false, false
+	}
+	return added.ID, false, true
+}
+
+// HandleGetShelfImports godoc
+// @Summary      List my shelf imports
+// @Description  Lists the current user's Goodreads and StoryGraph imports, newest first, with the number of rows with each result.
+// @Tags         user_books
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedImportJobsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/imports [get]
+func (sh *ShelfImportHandler) HandleGetShelfImports(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	jobs, total, err := sh.importJobStore.GetImportJobs(store.ImportJobFilter{Kind: store.ImportKindShelf, UserID: &user.ID}, page, limit)
+	if err != nil {
+		sh.logger.Printf("ERROR: getImportJobs %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PaginatedImportJobsResponse{
+		Items:      jobs,
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// HandleGetShelfImport godoc
+// @Summary      Get one of my shelf imports
+// @Description  Retrieves a Goodreads or StoryGraph import of the current user with its status and the number of rows matched, created, unmatched, skipped, invalid or failed.
+// @Tags         user_books
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Import job ID"
+// @Success      200 {object} store.ImportJob
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Import not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/imports/{id} [get]
+func (sh *ShelfImportHandler) HandleGetShelfImport(ctx *gin.Context) {
+	job, ok := sh.readShelfImport(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// HandleGetShelfImportRows godoc
+// @Summary      List the rows of one of my shelf imports
+// @Description  Lists the rows of a Goodreads or StoryGraph import in file order, with their results: pending, matched, created, unmatched, skipped, invalid or failed. Rows that were not shelved list their problems by field.
+// @Tags         user_books
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Import job ID"
+// @Param        status query string false "Only rows with this result" Enums(pending, matched, created, unmatched, skipped, invalid, failed)
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedImportRowsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid id, status or pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Import not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/imports/{id}/rows [get]
+func (sh *ShelfImportHandler) HandleGetShelfImportRows(ctx *gin.Context) {
+	status := ctx.Query("status")
+	if status != "" && !slices.Contains(store.ImportRowStatuses, status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of " + strings.Join(store.ImportRowStatuses, ", ")})
+		return
+	}
+
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	job, ok := sh.readShelfImport(ctx)
+	if !ok {
+		return
+	}
+
+	rows, total, err := sh.importJobStore.GetImportRows(job.ID, status, page, limit)
+	if err != nil {
+		sh.logger.Printf("ERROR: getImportRows %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PaginatedImportRowsResponse{
+		Items:      rows,
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// readShelfImport returns the current user's shelf import named by the id
+// parameter, writing the error response when there is none. Other users'
+// imports are not found.
+func (sh *ShelfImportHandler) readShelfImport(ctx *gin.Context) (*store.ImportJob, bool) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	jobID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import id"})
+		return nil, false
+	}
+
+	job, err := sh.importJobStore.GetImportJobByID(jobID)
+	if err != nil {
+		sh.logger.Printf("ERROR: getImportJobByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+	if job == nil || job.Kind != store.ImportKindShelf || job.UserID == nil || *job.UserID != user.ID {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+		return nil, false
+	}
+	return job, true
+}
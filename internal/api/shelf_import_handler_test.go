@@ -0,0 +1,241 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShelfImportHandlerTestSuite struct {
+	suite.Suite
+	mockBookStore      *mocks.MockBookStore
+	mockUserBooksStore *mocks.MockUserBooksStore
+	mockImportJobStore *mocks.MockImportJobStore
+	mockProvider       *mocks.MockBookMetadataProvider
+	handler            *ShelfImportHandler
+}
+
+func (s *ShelfImportHandlerTestSuite) SetupTest() {
+	s.mockBookStore = new(mocks.MockBookStore)
+	s.mockUserBooksStore = new(mocks.MockUserBooksStore)
+	s.mockImportJobStore = new(mocks.MockImportJobStore)
+	s.mockProvider = new(mocks.MockBookMetadataProvider)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewShelfImportHandler(s.mockBookStore, s.mockUserBooksStore, s.mockImportJobStore, s.mockProvider, logger)
+	// Jobs run before the handler returns, so their calls can be asserted.
+	s.handler.background = func(run func()) { run() }
+}
+
+func TestShelfImportHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ShelfImportHandlerTestSuite))
+}
+
+func (s *ShelfImportHandlerTestSuite) newImportContext(content string) (*gin.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "goodreads_library_export.csv")
+	_, _ = part.Write([]byte(content))
+	_ = writer.Close()
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/me/imports", &body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx.Set("user", &store.User{ID: 3})
+	return ctx, w
+}
+
+const testGoodreadsCSV = "Book Id,Title,Author,Additional Authors,ISBN,ISBN13,My Rating,Date Read,Bookshelves,Exclusive Shelf\n" +
+	`1,The Hobbit,J.R.R. Tolkien,,"=""""","=""9780261102217""",5,2021/03/04,"read, fantasy",read` + "\n" +
+	`2,Dune,Frank Herbert,,"=""0441172717""","=""""",0,,to-read,to-read` + "\n" +
+	`3,Gave Up,Someone,,,,2,,did-not-finish,did-not-finish` + "\n"
+
+func (s *ShelfImportHandlerTestSuite) TestHandleImportShelves_QueuesJob() {
+	job := &store.ImportJob{ID: 8, Kind: store.ImportKindShelf, Format: "goodreads", Status: store.ImportJobQueued}
+	s.mockImportJobStore.On("CreateImportJob", mock.MatchedBy(func(j *store.ImportJob) bool {
+		return j.Kind == store.ImportKindShelf && j.Format == "goodreads" && *j.UserID == 3
+	}), mock.MatchedBy(func(rows []store.ImportRow) bool {
+		return len(rows) == 3 &&
+			rows[0].Status == store.ImportRowPending &&
+			string(rows[0].Data) == `{"title":"The Hobbit","authors":["J.R.R. Tolkien"],"isbn_13":"9780261102217","status":"completed","rating":5,"completed_at":"2021-03-04","shelves":["fantasy"]}` &&
+			rows[2].Status == store.ImportRowInvalid && rows[2].Errors["row"] != ""
+	})).Return(job, nil)
+	s.mockImportJobStore.On("StartImportJob", int64(8)).Return(errors.New("connection reset"))
+
+	ctx, w := s.newImportContext(testGoodreadsCSV)
+
+	s.handler.HandleImportShelves(ctx)
+
+	s.Equal(http.StatusAccepted, w.Code)
+	s.Equal("/me/imports/8", w.Header().Get("Location"))
+	s.mockImportJobStore.AssertExpectations(s.T())
+}
+
+func (s *ShelfImportHandlerTestSuite) TestHandleImportShelves_UnknownFile() {
+	ctx, w := s.newImportContext("title,authors,isbn_13\nThe Hobbit,Tolkien,9780261102217\n")
+
+	s.handler.HandleImportShelves(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "Goodreads or StoryGraph")
+	s.mockImportJobStore.AssertNotCalled(s.T(), "CreateImportJob", mock.Anything, mock.Anything)
+}
+
+func (s *ShelfImportHandlerTestSuite) TestRunImport_MatchesCreatesAndReports() {
+	userID := int64(3)
+	job := &store.ImportJob{ID: 8, Kind: store.ImportKindShelf, UserID: &userID}
+	s.mockImportJobStore.On("StartImportJob", int64(8)).Return(nil)
+	s.mockImportJobStore.On("GetPendingImportRows", int64(8), importBatchSize).Return([]*store.ImportRow{
+		{JobID: 8, Row: 2, Status: store.ImportRowPending, Data: []byte(`{"title":"The Hobbit","isbn_13":"9780261102217","status":"completed","rating":4.5,"completed_at":"2021-03-04","shelves":["fantasy"]}`)},
+		{JobID: 8, Row: 3, Status: store.ImportRowPending, Data: []byte(`{"title":"Dune","isbn_13":"9780441172719","status":"wishlist"}`)},
+		{JobID: 8, Row: 4, Status: store.ImportRowPending, Data: []byte(`{"title":"Unknown","isbn_13":"9780000000002","status":"reading"}`)},
+		{JobID: 8, Row: 5, Status: store.ImportRowPending, Data: []byte(`{"title":"No ISBN","status":"reading"}`)},
+		{JobID: 8, Row: 6, Status: store.ImportRowPending, Data: []byte(`{"title":"Shelved","isbn_13":"9780547928227","status":"reading"}`)},
+	}, nil).Once()
+	s.mockImportJobStore.On("GetPendingImportRows", int64(8), importBatchSize).Return([]*store.ImportRow{}, nil).Once()
+	s.mockBookStore.On("GetBookIDsByISBN13", []string{"9780261102217", "9780441172719", "9780000000002", "9780547928227"}).
+		Return(map[string]int64{"9780261102217": 7, "9780547928227": 9}, nil)
+
+	s.mockProvider.On("GetBookByISBN", "9780441172719").Return(&store.BookCandidate{Book: store.Book{Title: "Dune", ISBN13: "9780441172719"}}, nil)
+	s.mockProvider.On("GetBookByISBN", "9780000000002").Return(nil, store.ErrBookMetadataNotFound)
+	s.mockBookStore.On("AddBook", mock.MatchedBy(func(book *store.Book) bool {
+		return book.Title == "Dune" && book.ISBN13 == "9780441172719"
+	})).Return(&store.Book{ID: 12}, nil)
+
+	s.mockUserBooksStore.On("ImportUserBook", mock.MatchedBy(func(ub *store.UserBook) bool {
+		return ub.UserID == 3 && ub.BookID == 7 && ub.Status == "completed" && *ub.Rating == 4.5 && ub.CompletedAt != nil
+	}), []string{"fantasy"}).Return(&store.UserBook{ID: 1}, nil)
+	s.mockUserBooksStore.On("ImportUserBook", mock.MatchedBy(func(ub *store.UserBook) bool {
+		return ub.BookID == 12 && ub.Status == "wishlist"
+	}), []string(nil)).Return(&store.UserBook{ID: 2}, nil)
+	s.mockUserBooksStore.On("ImportUserBook", mock.MatchedBy(func(ub *store.UserBook) bool {
+		return ub.BookID == 9
+	}), []string(nil)).Return(nil, store.ErrAlreadyShelved)
+
+	want := map[int]string{
+		2: store.ImportRowMatched,
+		3: store.ImportRowCreated,
+		4: store.ImportRowUnmatched,
+		5: store.ImportRowUnmatched,
+		6: store.ImportRowSkipped,
+	}
+	for row, status := range want {
+		s.mockImportJobStore.On("UpdateImportRow", mock.MatchedBy(func(r *store.ImportRow) bool {
+			return r.Row == row && r.Status == status
+		})).Return(nil).Once()
+	}
+	s.mockImportJobStore.On("FinishImportJob", int64(8), nil).Return(nil)
+
+	s.handler.runImport(job)
+
+	s.mockImportJobStore.AssertExpectations(s.T())
+	s.mockUserBooksStore.AssertExpectations(s.T())
+	s.mockBookStore.AssertExpectations(s.T())
+}
+
+func (s *ShelfImportHandlerTestSuite) TestRunImport_CandidateWithOtherISBN() {
+	userID := int64(3)
+	job := &store.ImportJob{ID: 10, Kind: store.ImportKindShelf, UserID: &userID}
+	s.mockImportJobStore.On("StartImportJob", int64(10)).Return(nil)
+	s.mockImportJobStore.On("GetPendingImportRows", int64(10), importBatchSize).Return([]*store.ImportRow{
+		{JobID: 10, Row: 2, Status: store.ImportRowPending, Data: []byte(`{"title":"Dune","isbn_13":"9780441172719","status":"wishlist"}`)},
+	}, nil).Once()
+	s.mockImportJobStore.On("GetPendingImportRows", int64(10), importBatchSize).Return([]*store.ImportRow{}, nil).Once()
+	s.mockBookStore.On("GetBookIDsByISBN13", []string{"9780441172719"}).Return(map[string]int64{}, nil)
+	s.mockProvider.On("GetBookByISBN", "9780441172719").
+		Return(&store.BookCandidate{Book: store.Book{Title: "Dune Messiah", ISBN13: "9780441172696"}}, nil)
+	s.mockImportJobStore.On("UpdateImportRow", mock.MatchedBy(func(r *store.ImportRow) bool {
+		return r.Status == store.ImportRowUnmatched && r.BookID == nil
+	})).Return(nil)
+	s.mockImportJobStore.On("FinishImportJob", int64(10), nil).Return(nil)
+
+	s.handler.runImport(job)
+
+	s.mockImportJobStore.AssertExpectations(s.T())
+	s.mockBookStore.AssertNotCalled(s.T(), "AddBook", mock.Anything)
+	s.mockUserBooksStore.AssertNotCalled(s.T(), "ImportUserBook", mock.Anything, mock.Anything)
+}
+
+func (s *ShelfImportHandlerTestSuite) TestRunImport_ProviderUnavailable() {
+	userID := int64(3)
+	job := &store.ImportJob{ID: 9, Kind: store.ImportKindShelf, UserID: &userID}
+	s.mockImportJobStore.On("StartImportJob", int64(9)).Return(nil)
+	s.mockImportJobStore.On("GetPendingImportRows", int64(9), importBatchSize).Return([]*store.ImportRow{
+		{JobID: 9, Row: 2, Status: store.ImportRowPending, Data: []byte(`{"title":"Dune","isbn_13":"9780441172719","status":"wishlist"}`)},
+	}, nil).Once()
+	s.mockImportJobStore.On("GetPendingImportRows", int64(9), importBatchSize).Return([]*store.ImportRow{}, nil).Once()
+	s.mockBookStore.On("GetBookIDsByISBN13", []string{"9780441172719"}).Return(map[string]int64{}, nil)
+	s.mockProvider.On("GetBookByISBN", "9780441172719").Return(nil, store.ErrProviderUnavailable)
+	s.mockImportJobStore.On("UpdateImportRow", mock.MatchedBy(func(r *store.ImportRow) bool {
+		return r.Status == store.ImportRowFailed && r.Errors["row"] != ""
+	})).Return(nil)
+	s.mockImportJobStore.On("FinishImportJob", int64(9), nil).Return(nil)
+
+	s.handler.runImport(job)
+
+	s.mockImportJobStore.AssertExpectations(s.T())
+	s.mockUserBooksStore.AssertNotCalled(s.T(), "ImportUserBook", mock.Anything, mock.Anything)
+}
+
+func (s *ShelfImportHandlerTestSuite) TestHandleGetShelfImport_OtherUsersImport() {
+	owner := int64(4)
+	s.mockImportJobStore.On("GetImportJobByID", int64(8)).Return(&store.ImportJob{ID: 8, Kind: store.ImportKindShelf, UserID: &owner}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/me/imports/8", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "8"}}
+	ctx.Set("user", &store.User{ID: 3})
+
+	s.handler.HandleGetShelfImport(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ShelfImportHandlerTestSuite) TestHandleGetShelfImportRows() {
+	userID := int64(3)
+	s.mockImportJobStore.On("GetImportJobByID", int64(8)).Return(&store.ImportJob{ID: 8, Kind: store.ImportKindShelf, UserID: &userID}, nil)
+	s.mockImportJobStore.On("GetImportRows", int64(8), store.ImportRowUnmatched, 1, 20).Return([]*store.ImportRow{
+		{Row: 4, Status: store.ImportRowUnmatched, Data: []byte(`{"title":"Unknown"}`), Errors: map[string]string{"isbn_13": "no book with this ISBN was found"}},
+	}, 1, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/me/imports/8/rows?status=unmatched", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "8"}}
+	ctx.Set("user", &store.User{ID: 3})
+
+	s.handler.HandleGetShelfImportRows(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"status":"unmatched"`)
+	s.Contains(w.Body.String(), `"total_items":1`)
+}
+
+func (s *ShelfImportHandlerTestSuite) TestHandleGetShelfImports_FiltersByUser() {
+	s.mockImportJobStore.On("GetImportJobs", mock.MatchedBy(func(f store.ImportJobFilter) bool {
+		return f.Kind == store.ImportKindShelf && f.UserID != nil && *f.UserID == 3
+	}), 1, 20).Return([]*store.ImportJob{}, 0, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/me/imports", nil)
+	ctx.Set("user", &store.User{ID: 3})
+
+	s.handler.HandleGetShelfImports(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockImportJobStore.AssertExpectations(s.T())
+}
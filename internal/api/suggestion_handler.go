@@ -0,0 +1,548 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SuggestionHandler struct {
+	suggestionStore store.SuggestionStore
+	bookStore       store.BookStore
+	logger          *log.Logger
+}
+
+func NewSuggestionHandler(suggestionStore store.SuggestionStore, bookStore store.BookStore, logger *log.Logger) *SuggestionHandler {
+	return &SuggestionHandler{
+		suggestionStore: suggestionStore,
+		bookStore:       bookStore,
+		logger:          logger,
+	}
+}
+
+type SuggestBookEditRequest struct {
+	// Changes holds the proposed values; fields left out stay as they are.
+	Changes store.BookChanges `json:"changes"`
+	Comment *string           `json:"comment" example:"The subtitle is missing"`
+}
+
+// ReviewSuggestionRequest is the body of an approval or rejection. Fields
+// picks the suggested fields to apply; leave it out to apply all of them.
+type ReviewSuggestionRequest struct {
+	Fields []string `json:"fields" example:"title,authors"`
+	Note   *string  `json:"note" example:"Thanks!"`
+}
+
+type PaginatedSuggestionsResponse struct {
+	Items      []*store.BookSuggestion `json:"items"`
+	Page       int                     `json:"page"`
+	Limit      int                     `json:"limit"`
+	TotalItems int                     `json:"total_items"`
+	TotalPages int                     `json:"total_pages"`
+}
+
+type BookContributorsResponse struct {
+	Contributors []store.BookContributor `json:"contributors"`
+}
+
+// StaleSuggestionConflict lists the suggested fields that were edited after
+// the suggestion was made.
+type StaleSuggestionConflict struct {
+	Error  string   `json:"error"`
+	Fields []string `json:"fields"`
+}
+
+// validateBookChanges normalizes suggested changes and reports invalid fields.
+func validateBookChanges(changes *store.BookChanges) map[string]string {
+	fields := map[string]string{}
+
+	if changes.Title != nil {
+		title := strings.TrimSpace(*changes.Title)
+		changes.Title = &title
+		if title == "" {
+			fields[store.BookFieldTitle] = "title cannot be empty"
+		}
+	}
+
+	if changes.Description != nil {
+		description := strings.TrimSpace(*changes.Description)
+		changes.Description = &description
+	}
+
+	if changes.Authors != nil {
+		authors := make([]string, 0, len(changes.Authors))
+		for _, author := range changes.Authors {
+			if author = strings.TrimSpace(author); author != "" {
+				authors = append(authors, author)
+			}
+		}
+		changes.Authors = authors
+		if len(authors) == 0 {
+			fields[store.BookFieldAuthors] = "at least one author is required"
+		}
+	}
+
+	if msg := validateChapters(changes.Chapters); msg != "" {
+		fields[store.BookFieldChapters] = msg
+	}
+
+	return fields
+}
+
+// HandleSuggestBookEdit godoc
+// @Summary      Suggest an edit to a book
+// @Description  Proposes changes to a book's title, description, authors, chapters or cover images. Only fields that differ from the book are kept, together with their current values, and the suggestion waits in the review queue until an editor approves or rejects it.
+// @Tags         suggestions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        request body SuggestBookEditRequest true "Suggested changes"
+// @Success      201 {object} store.BookSuggestion
+// @Failure      400 {object} ValidationError "Error: Invalid suggestion"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/suggestions [post]
+func (sh *SuggestionHandler) HandleSuggestBookEdit(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	var req SuggestBookEditRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		sh.logger.Printf("ERROR: decodingSuggestBookEdit %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if fields := validateBookChanges(&req.Changes); len(fields) > 0 {
+		ctx.JSON(http.StatusBadRequest, ValidationError{Error: "invalid suggestion", Fields: fields})
+		return
+	}
+
+	book, err := sh.bookStore.GetBookByID(bookID)
+	if err != nil {
+		sh.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if book == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	changes := req.Changes.Diff(book)
+	if len(changes.Fields()) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "suggestion does not change the book"})
+		return
+	}
+
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	suggestion, err := sh.suggestionStore.CreateSuggestion(&store.BookSuggestion{
+		BookID:  bookID,
+		UserID:  user.ID,
+		Comment: req.Comment,
+		Before:  store.BookValues(book, changes.Fields()),
+		After:   changes,
+	})
+	if err != nil {
+		sh.logger.Printf("ERROR: createSuggestion %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, suggestion)
+}
+
+// HandleGetSuggestions godoc
+// @Summary      List edit suggestions
+// @Description  The review queue: lists suggested book edits, oldest first. Only pending suggestions are listed unless another status, or status=all, is given.
+// @Tags         suggestions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status query string false "pending, approved, partially_applied, rejected or all" default(pending)
+// @Param        book_id query int false "Only suggestions for this book"
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedSuggestionsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid filter or pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /suggestions [get]
+func (sh *SuggestionHandler) HandleGetSuggestions(ctx *gin.Context) {
+	filter := store.SuggestionFilter{}
+	if bookParam := ctx.Query("book_id"); bookParam != "" {
+		bookID, err := strconv.ParseInt(bookParam, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+			return
+		}
+		filter.BookID = &bookID
+	}
+
+	sh.listSuggestions(ctx, filter, store.SuggestionPending)
+}
+
+// HandleGetMySuggestions godoc
+// @Summary      List my edit suggestions
+// @Description  Lists the current user's suggested book edits and how they were reviewed, oldest first.
+// @Tags         suggestions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status query string false "pending, approved, partially_applied, rejected or all" default(all)
+// @Param        page query int false "Page number" default(1)
+// @Param        limit query int false "Items per page" default(20)
+// @Success      200 {object} PaginatedSuggestionsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid filter or pagination parameters"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /users/me/suggestions [get]
+func (sh *SuggestionHandler) HandleGetMySuggestions(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	sh.listSuggestions(ctx, store.SuggestionFilter{UserID: &user.ID}, "all")
+}
+
+func (sh *SuggestionHandler) listSuggestions(ctx *gin.Context, filter store.SuggestionFilter, defaultStatus string) {
+	switch status := ctx.DefaultQuery("status", defaultStatus); status {
+	case "all":
+	case store.SuggestionPending, store.SuggestionApproved, store.SuggestionPartiallyApplied, store.SuggestionRejected:
+		filter.Status = status
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, approved, partially_applied, rejected or all"})
+		return
+	}
+
+	page, limit, err := utils.ReadPaginationParams(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readPaginationParams %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		return
+	}
+
+	suggestions, total, err := sh.suggestionStore.GetSuggestions(filter, page, limit)
+	if err != nil {
+		sh.logger.Printf("ERROR: getSuggestions %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PaginatedSuggestionsResponse{
+		Items:      suggestions,
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
+
+// HandleGetSuggestionByID godoc
+// @Summary      Get an edit suggestion
+// @Description  Retrieves a suggested book edit. Readers can see their own suggestions; editors can see all of them.
+// @Tags         suggestions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Suggestion ID"
+// @Success      200 {object} store.BookSuggestion
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Suggestion not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /suggestions/{id} [get]
+func (sh *SuggestionHandler) HandleGetSuggestionByID(ctx *gin.Context) {
+	suggestion, ok := sh.readSuggestion(ctx)
+	if !ok {
+		return
+	}
+
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+	if suggestion.UserID != user.ID && !user.Can(store.PermReviewSuggestions) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "you can only view your own suggestions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suggestion)
+}
+
+// HandleApproveSuggestion godoc
+// @Summary      Approve an edit suggestion
+// @Description  Applies a pending suggestion to the book and credits its author as a contributor.
+//
+//	Send `fields` to apply only some of the suggested fields; the suggestion is then marked partially_applied. If any of the applied fields were edited after the suggestion was made, nothing is applied and a 409 lists them; pass force=true to apply the suggestion anyway. Chapters with comments are only deleted when delete_commented_chapters=true.
+//
+// @Tags         suggestions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Suggestion ID"
+// @Param        request body ReviewSuggestionRequest false "Fields to apply and a note for the author"
+// @Param        force query bool false "Apply even if the book was edited since the suggestion was made"
+// @Param        delete_commented_chapters query bool false "Allow deleting chapters that have comments"
+// @Success      200 {object} store.BookSuggestion
+// @Failure      400 {object} HTTPError "Error: Invalid request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Suggestion not found"
+// @Failure      409 {object} StaleSuggestionConflict "Error: Already reviewed, book edited since, or chapters with comments would be deleted"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /suggestions/{id}/approve [post]
+func (sh *SuggestionHandler) HandleApproveSuggestion(ctx *gin.Context) {
+	suggestion, ok := sh.readPendingSuggestion(ctx)
+	if !ok {
+		return
+	}
+
+	req, ok := sh.readReviewRequest(ctx)
+	if !ok {
+		return
+	}
+
+	suggested := suggestion.After.Fields()
+	fields := req.Fields
+	if len(fields) == 0 {
+		fields = suggested
+	}
+	for _, field := range fields {
+		if !slices.Contains(suggested, field) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "the suggestion does not change " + field})
+			return
+		}
+	}
+	changes := suggestion.After.Only(fields)
+
+	book, err := sh.bookStore.GetBookByID(suggestion.BookID)
+	if err != nil {
+		sh.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if book == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	if stale := changes.StaleFields(book, suggestion.Before); len(stale) > 0 && ctx.Query("force") != "true" {
+		ctx.JSON(http.StatusConflict, StaleSuggestionConflict{
+			Error:  "the book was edited after this suggestion was made; pass force=true to apply it anyway",
+			Fields: stale,
+		})
+		return
+	}
+
+	status := store.SuggestionApproved
+	if len(fields) < len(suggested) {
+		status = store.SuggestionPartiallyApplied
+	}
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+	review := store.SuggestionReview{
+		ReviewerID:    user.ID,
+		Status:        status,
+		AppliedFields: fields,
+		Note:          req.Note,
+	}
+
+	changes.ApplyTo(book)
+	opts := store.UpdateBookOptions{
+		DeleteCommentedChapters: ctx.Query("delete_commented_chapters") == "true",
+		EditorID:                editorID(ctx),
+		Note:                    fmt.Sprintf("Applied suggestion %d", suggestion.ID),
+		// The stale check above was made against this version.
+		IfMatch: []int{book.Version},
+	}
+	applied, err := sh.suggestionStore.ApplySuggestion(suggestion.ID, review, book, opts)
+	if err != nil {
+		var deletionErr *store.ChapterDeletionError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "suggestion or book not found"})
+		case errors.Is(err, store.ErrSuggestionReviewed):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, store.ErrVersionMismatch):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the book was edited while the suggestion was being applied; try again"})
+		case errors.As(err, &deletionErr):
+			ctx.JSON(http.StatusConflict, ChapterDeletionConflict{
+				Error:      "removing these chapters would delete their comments; pass delete_commented_chapters=true to confirm",
+				ChapterIDs: deletionErr.ChapterIDs,
+			})
+		case errors.Is(err, store.ErrChapterNotInBook) || errors.Is(err, store.ErrDuplicateChapter):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the suggested chapters no longer match the book: " + errors.Unwrap(err).Error()})
+		default:
+			sh.logger.Printf("ERROR: applySuggestion %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, applied)
+}
+
+// HandleRejectSuggestion godoc
+// @Summary      Reject an edit suggestion
+// @Description  Closes a pending suggestion without changing the book. The note is shown to the suggestion's author.
+// @Tags         suggestions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Suggestion ID"
+// @Param        request body ReviewSuggestionRequest false "A note for the author; fields are ignored"
+// @Success      200 {object} store.BookSuggestion
+// @Failure      400 {object} HTTPError "Error: Invalid request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: Suggestion not found"
+// @Failure      409 {object} HTTPError "Error: Suggestion already reviewed"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /suggestions/{id}/reject [post]
+func (sh *SuggestionHandler) HandleRejectSuggestion(ctx *gin.Context) {
+	suggestion, ok := sh.readPendingSuggestion(ctx)
+	if !ok {
+		return
+	}
+
+	req, ok := sh.readReviewRequest(ctx)
+	if !ok {
+		return
+	}
+
+	sh.review(ctx, suggestion.ID, store.SuggestionRejected, []string{}, req.Note)
+}
+
+// HandleGetBookContributors godoc
+// @Summary      List a book's contributors
+// @Description  Lists the readers whose edit suggestions were applied to the book, with how many were applied.
+// @Tags         suggestions
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Book ID"
+// @Success      200 {object} BookContributorsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/contributors [get]
+func (sh *SuggestionHandler) HandleGetBookContributors(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	book, err := sh.bookStore.GetBookByID(bookID)
+	if err != nil {
+		sh.logger.Printf("ERROR: getBookByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if book == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	contributors, err := sh.suggestionStore.GetBookContributors(bookID)
+	if err != nil {
+		sh.logger.Printf("ERROR: getBookContributors %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BookContributorsResponse{Contributors: contributors})
+}
+
+// readSuggestion loads the suggestion named by the id path parameter, writing
+// the error response and returning false when it cannot.
+func (sh *SuggestionHandler) readSuggestion(ctx *gin.Context) (*store.BookSuggestion, bool) {
+	id, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		sh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid suggestion id"})
+		return nil, false
+	}
+
+	suggestion, err := sh.suggestionStore.GetSuggestionByID(id)
+	if err != nil {
+		sh.logger.Printf("ERROR: getSuggestionByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+	if suggestion == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "suggestion not found"})
+		return nil, false
+	}
+
+	return suggestion, true
+}
+
+func (sh *SuggestionHandler) readPendingSuggestion(ctx *gin.Context) (*store.BookSuggestion, bool) {
+	suggestion, ok := sh.readSuggestion(ctx)
+	if !ok {
+		return nil, false
+	}
+	if suggestion.Status != store.SuggestionPending {
+		ctx.JSON(http.StatusConflict, gin.H{"error": store.ErrSuggestionReviewed.Error()})
+		return nil, false
+	}
+	return suggestion, true
+}
+
+// readReviewRequest reads the optional review body.
+func (sh *SuggestionHandler) readReviewRequest(ctx *gin.Context) (ReviewSuggestionRequest, bool) {
+	var req ReviewSuggestionRequest
+	if ctx.Request.ContentLength == 0 {
+		return req, true
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		sh.logger.Printf("ERROR: decodingReviewSuggestion %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return req, false
+	}
+	return req, true
+}
+
+func (sh *SuggestionHandler) review(ctx *gin.Context, id int64, status string, fields []string, note *string) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	suggestion, err := sh.suggestionStore.ReviewSuggestion(id, store.SuggestionReview{
+		ReviewerID:    user.ID,
+		Status:        status,
+		AppliedFields: fields,
+		Note:          note,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "suggestion not found"})
+		case errors.Is(err, store.ErrSuggestionReviewed):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			sh.logger.Printf("ERROR: reviewSuggestion %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suggestion)
+}
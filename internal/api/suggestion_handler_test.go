@@ -0,0 +1,321 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SuggestionHandlerTestSuite struct {
+	suite.Suite
+	mockSuggestionStore *mocks.MockSuggestionStore
+	mockBookStore       *mocks.MockBookStore
+	handler             *SuggestionHandler
+}
+
+func (s *SuggestionHandlerTestSuite) SetupTest() {
+	s.mockSuggestionStore = new(mocks.MockSuggestionStore)
+	s.mockBookStore = new(mocks.MockBookStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewSuggestionHandler(s.mockSuggestionStore, s.mockBookStore, logger)
+}
+
+func TestSuggestionHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(SuggestionHandlerTestSuite))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func (s *SuggestionHandlerTestSuite) newContext(method, url string, body interface{}, params gin.Params, user *store.User) (*gin.Context, *httptest.ResponseRecorder) {
+	var reqBody bytes.Buffer
+	if body != nil {
+		s.Require().NoError(json.NewEncoder(&reqBody).Encode(body))
+	}
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(method, url, &reqBody)
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = params
+	ctx.Set("user", user)
+	return ctx, w
+}
+
+func suggestionBook() *store.Book {
+	return &store.Book{
+		ID:      1,
+		Title:   "The Hobbit",
+		Authors: []string{"J.R.R. Tolkien"},
+		Version: 4,
+	}
+}
+
+func pendingSuggestion() *store.BookSuggestion {
+	return &store.BookSuggestion{
+		ID:     5,
+		BookID: 1,
+		UserID: 2,
+		Status: store.SuggestionPending,
+		Before: store.BookChanges{Title: strPtr("The Hobbit"), Description: strPtr("")},
+		After:  store.BookChanges{Title: strPtr("The Hobbit, or There and Back Again"), Description: strPtr("A fantasy novel")},
+	}
+}
+
+var (
+	suggestionAuthor   = &store.User{ID: 2, Role: store.RoleUser}
+	suggestionReviewer = &store.User{ID: 3, Role: store.RoleEditor}
+)
+
+func (s *SuggestionHandlerTestSuite) TestHandleSuggestBookEdit_Success() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(suggestionBook(), nil)
+	s.mockSuggestionStore.On("CreateSuggestion", mock.MatchedBy(func(suggestion *store.BookSuggestion) bool {
+		return suggestion.BookID == 1 && suggestion.UserID == 2 &&
+			*suggestion.Before.Title == "The Hobbit" &&
+			*suggestion.After.Title == "The Hobbit, or There and Back Again" &&
+			suggestion.After.Authors == nil
+	})).Return(&store.BookSuggestion{ID: 5, Status: store.SuggestionPending}, nil)
+
+	body := SuggestBookEditRequest{Changes: store.BookChanges{
+		Title:   strPtr("  The Hobbit, or There and Back Again "),
+		Authors: []string{"J.R.R. Tolkien"},
+	}}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/suggestions", body, gin.Params{{Key: "id", Value: "1"}}, suggestionAuthor)
+
+	s.handler.HandleSuggestBookEdit(ctx)
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.mockSuggestionStore.AssertExpectations(s.T())
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleSuggestBookEdit_NoChanges() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(suggestionBook(), nil)
+
+	body := SuggestBookEditRequest{Changes: store.BookChanges{Title: strPtr("The Hobbit")}}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/suggestions", body, gin.Params{{Key: "id", Value: "1"}}, suggestionAuthor)
+
+	s.handler.HandleSuggestBookEdit(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "does not change the book")
+	s.mockSuggestionStore.AssertNotCalled(s.T(), "CreateSuggestion", mock.Anything)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleSuggestBookEdit_InvalidChanges() {
+	body := SuggestBookEditRequest{Changes: store.BookChanges{
+		Title:    strPtr(" "),
+		Authors:  []string{""},
+		Chapters: []store.Chapter{{Number: 1}},
+	}}
+	ctx, w := s.newContext(http.MethodPost, "/books/1/suggestions", body, gin.Params{{Key: "id", Value: "1"}}, suggestionAuthor)
+
+	s.handler.HandleSuggestBookEdit(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	var resp ValidationError
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Contains(resp.Fields, "title")
+	s.Contains(resp.Fields, "authors")
+	s.Contains(resp.Fields, "chapters")
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleSuggestBookEdit_BookNotFound() {
+	s.mockBookStore.On("GetBookByID", int64(9)).Return(nil, nil)
+
+	body := SuggestBookEditRequest{Changes: store.BookChanges{Title: strPtr("Anything")}}
+	ctx, w := s.newContext(http.MethodPost, "/books/9/suggestions", body, gin.Params{{Key: "id", Value: "9"}}, suggestionAuthor)
+
+	s.handler.HandleSuggestBookEdit(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleGetSuggestions_DefaultsToPending() {
+	s.mockSuggestionStore.On("GetSuggestions", store.SuggestionFilter{Status: store.SuggestionPending}, 1, 20).
+		Return([]*store.BookSuggestion{pendingSuggestion()}, 1, nil)
+
+	ctx, w := s.newContext(http.MethodGet, "/suggestions", nil, nil, suggestionReviewer)
+
+	s.handler.HandleGetSuggestions(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"total_items":1`)
+	s.mockSuggestionStore.AssertExpectations(s.T())
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleGetSuggestions_InvalidStatus() {
+	ctx, w := s.newContext(http.MethodGet, "/suggestions?status=maybe", nil, nil, suggestionReviewer)
+
+	s.handler.HandleGetSuggestions(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleGetMySuggestions() {
+	userID := int64(2)
+	s.mockSuggestionStore.On("GetSuggestions", store.SuggestionFilter{UserID: &userID}, 1, 20).
+		Return([]*store.BookSuggestion{}, 0, nil)
+
+	ctx, w := s.newContext(http.MethodGet, "/users/me/suggestions", nil, nil, suggestionAuthor)
+
+	s.handler.HandleGetMySuggestions(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockSuggestionStore.AssertExpectations(s.T())
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleGetSuggestionByID_OtherReadersForbidden() {
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(pendingSuggestion(), nil)
+
+	other := &store.User{ID: 4, Role: store.RoleUser}
+	ctx, w := s.newContext(http.MethodGet, "/suggestions/5", nil, gin.Params{{Key: "id", Value: "5"}}, other)
+
+	s.handler.HandleGetSuggestionByID(ctx)
+
+	s.Equal(http.StatusForbidden, w.Code)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleApproveSuggestion_AppliesAllFields() {
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(pendingSuggestion(), nil)
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(suggestionBook(), nil)
+	s.mockSuggestionStore.On("ApplySuggestion", int64(5), store.SuggestionReview{
+		ReviewerID:    3,
+		Status:        store.SuggestionApproved,
+		AppliedFields: []string{store.BookFieldTitle, store.BookFieldDescription},
+	}, mock.MatchedBy(func(book *store.Book) bool {
+		return book.Title == "The Hobbit, or There and Back Again" &&
+			book.Description != nil && *book.Description == "A fantasy novel"
+	}), store.UpdateBookOptions{EditorID: &suggestionReviewer.ID, Note: "Applied suggestion 5", IfMatch: []int{4}}).
+		Return(&store.BookSuggestion{ID: 5, Status: store.SuggestionApproved}, nil)
+
+	ctx, w := s.newContext(http.MethodPost, "/suggestions/5/approve", nil, gin.Params{{Key: "id", Value: "5"}}, suggestionReviewer)
+
+	s.handler.HandleApproveSuggestion(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockBookStore.AssertExpectations(s.T())
+	s.mockSuggestionStore.AssertExpectations(s.T())
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleApproveSuggestion_PartiallyApplied() {
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(pendingSuggestion(), nil)
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(suggestionBook(), nil)
+	s.mockSuggestionStore.On("ApplySuggestion", int64(5), store.SuggestionReview{
+		ReviewerID:    3,
+		Status:        store.SuggestionPartiallyApplied,
+		AppliedFields: []string{store.BookFieldDescription},
+		Note:          strPtr("Keeping the short title"),
+	}, mock.MatchedBy(func(book *store.Book) bool {
+		return book.Title == "The Hobbit" && book.Description != nil && *book.Description == "A fantasy novel"
+	}), store.UpdateBookOptions{EditorID: &suggestionReviewer.ID, Note: "Applied suggestion 5", IfMatch: []int{4}}).
+		Return(&store.BookSuggestion{ID: 5, Status: store.SuggestionPartiallyApplied}, nil)
+
+	body := ReviewSuggestionRequest{Fields: []string{"description"}, Note: strPtr("Keeping the short title")}
+	ctx, w := s.newContext(http.MethodPost, "/suggestions/5/approve", body, gin.Params{{Key: "id", Value: "5"}}, suggestionReviewer)
+
+	s.handler.HandleApproveSuggestion(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockBookStore.AssertExpectations(s.T())
+	s.mockSuggestionStore.AssertExpectations(s.T())
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleApproveSuggestion_UnknownField() {
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(pendingSuggestion(), nil)
+
+	body := ReviewSuggestionRequest{Fields: []string{"authors"}}
+	ctx, w := s.newContext(http.MethodPost, "/suggestions/5/approve", body, gin.Params{{Key: "id", Value: "5"}}, suggestionReviewer)
+
+	s.handler.HandleApproveSuggestion(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockBookStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleApproveSuggestion_StaleBook() {
+	book := suggestionBook()
+	book.Title = "The Hobbit (Illustrated)"
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(pendingSuggestion(), nil)
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(book, nil)
+
+	ctx, w := s.newContext(http.MethodPost, "/suggestions/5/approve", nil, gin.Params{{Key: "id", Value: "5"}}, suggestionReviewer)
+
+	s.handler.HandleApproveSuggestion(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	var resp StaleSuggestionConflict
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal([]string{"title"}, resp.Fields)
+	s.mockBookStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleApproveSuggestion_AlreadyReviewed() {
+	suggestion := pendingSuggestion()
+	suggestion.Status = store.SuggestionRejected
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(suggestion, nil)
+
+	ctx, w := s.newContext(http.MethodPost, "/suggestions/5/approve", nil, gin.Params{{Key: "id", Value: "5"}}, suggestionReviewer)
+
+	s.handler.HandleApproveSuggestion(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleApproveSuggestion_ReviewedConcurrently() {
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(pendingSuggestion(), nil)
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(suggestionBook(), nil)
+	s.mockSuggestionStore.On("ApplySuggestion", int64(5), mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, store.ErrSuggestionReviewed)
+
+	ctx, w := s.newContext(http.MethodPost, "/suggestions/5/approve", nil, gin.Params{{Key: "id", Value: "5"}}, suggestionReviewer)
+
+	s.handler.HandleApproveSuggestion(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.mockBookStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleRejectSuggestion() {
+	s.mockSuggestionStore.On("GetSuggestionByID", int64(5)).Return(pendingSuggestion(), nil)
+	s.mockSuggestionStore.On("ReviewSuggestion", int64(5), store.SuggestionReview{
+		ReviewerID:    3,
+		Status:        store.SuggestionRejected,
+		AppliedFields: []string{},
+		Note:          strPtr("The current title is correct"),
+	}).Return(&store.BookSuggestion{ID: 5, Status: store.SuggestionRejected}, nil)
+
+	body := ReviewSuggestionRequest{Note: strPtr("The current title is correct")}
+	ctx, w := s.newContext(http.MethodPost, "/suggestions/5/reject", body, gin.Params{{Key: "id", Value: "5"}}, suggestionReviewer)
+
+	s.handler.HandleRejectSuggestion(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockSuggestionStore.AssertExpectations(s.T())
+	s.mockBookStore.AssertNotCalled(s.T(), "UpdateBook", mock.Anything, mock.Anything)
+}
+
+func (s *SuggestionHandlerTestSuite) TestHandleGetBookContributors() {
+	s.mockBookStore.On("GetBookByID", int64(1)).Return(suggestionBook(), nil)
+	s.mockSuggestionStore.On("GetBookContributors", int64(1)).Return([]store.BookContributor{
+		{UserID: 2, Username: "jane", AppliedSuggestions: 3},
+	}, nil)
+
+	ctx, w := s.newContext(http.MethodGet, "/books/1/contributors", nil, gin.Params{{Key: "id", Value: "1"}}, nil)
+
+	s.handler.HandleGetBookContributors(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"username":"jane"`)
+}
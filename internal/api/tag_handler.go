@@ -0,0 +1,182 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+)
+
+const maxTagLength = 50
+
+type TagHandler struct {
+	tagStore store.TagStore
+	logger   *log.Logger
+}
+
+func NewTagHandler(tagStore store.TagStore, logger *log.Logger) *TagHandler {
+	return &TagHandler{
+		tagStore: tagStore,
+		logger:   logger,
+	}
+}
+
+type AddBookTagRequest struct {
+	Name string `json:"name" example:"comfort read"`
+}
+
+type BookTagsResponse struct {
+	Tags []*store.BookTag `json:"tags"`
+}
+
+// HandleGetBookTags godoc
+// @Summary      Get a book's tags
+// @Description  Retrieves the tags readers gave to a book, with how many readers used each tag.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Book ID"
+// @Success      200 {object} BookTagsResponse
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/tags [get]
+func (th *TagHandler) HandleGetBookTags(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		th.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	tags, err := th.tagStore.GetBookTags(bookID)
+	if err != nil {
+		th.logger.Printf("ERROR: getBookTags %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BookTagsResponse{Tags: tags})
+}
+
+// HandleGetMyTags godoc
+// @Summary      Get the current user's tags
+// @Description  Retrieves the tags the current user has used, with how many books carry each tag.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} BookTagsResponse
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/tags [get]
+func (th *TagHandler) HandleGetMyTags(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	tags, err := th.tagStore.GetUserTags(user.ID)
+	if err != nil {
+		th.logger.Printf("ERROR: getUserTags %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BookTagsResponse{Tags: tags})
+}
+
+// HandleAddBookTag godoc
+// @Summary      Tag a book
+// @Description  Adds a free-form tag from the current user to a book.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        request body AddBookTagRequest true "Tag"
+// @Success      204 "Tagged successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/tags [post]
+func (th *TagHandler) HandleAddBookTag(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		th.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	var req AddBookTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		th.logger.Printf("ERROR: decodingAddBookTag %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	name := store.NormalizeTag(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "tag must be between 1 and 50 characters"})
+		return
+	}
+
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	if err := th.tagStore.AddBookTag(user.ID, bookID, name); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return
+		}
+		th.logger.Printf("ERROR: addBookTag %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleRemoveBookTag godoc
+// @Summary      Remove a tag from a book
+// @Description  Removes one of the current user's tags from a book.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Param        tag path string true "Tag name"
+// @Success      204 "Deleted successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Tag not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/tags/{tag} [delete]
+func (th *TagHandler) HandleRemoveBookTag(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		th.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	if err := th.tagStore.RemoveBookTag(user.ID, bookID, ctx.Param("tag")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+			return
+		}
+		th.logger.Printf("ERROR: removeBookTag %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/suite"
+)
+
+type TagHandlerTestSuite struct {
+	suite.Suite
+	mockStore *mocks.MockTagStore
+	handler   *TagHandler
+}
+
+func (s *TagHandlerTestSuite) SetupTest() {
+	s.mockStore = new(mocks.MockTagStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewTagHandler(s.mockStore, logger)
+}
+
+func TestTagHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TagHandlerTestSuite))
+}
+
+func (s *TagHandlerTestSuite) TestHandleGetBookTags_Success() {
+	s.mockStore.On("GetBookTags", int64(1)).Return([]*store.BookTag{{Name: "cozy", Count: 3}}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/books/1/tags", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleGetBookTags(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp BookTagsResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Tags, 1)
+	s.Equal(3, resp.Tags[0].Count)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *TagHandlerTestSuite) TestHandleAddBookTag_NormalizesName() {
+	s.mockStore.On("AddBookTag", int64(5), int64(1), "comfort read").Return(nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/1/tags", bytes.NewBufferString(`{"name": "  Comfort   Read "}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	ctx.Set("user", &store.User{ID: 5})
+
+	s.handler.HandleAddBookTag(ctx)
+
+	s.Equal(http.StatusNoContent, ctx.Writer.Status())
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *TagHandlerTestSuite) TestHandleAddBookTag_EmptyName() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/1/tags", bytes.NewBufferString(`{"name": ""}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	ctx.Set("user", &store.User{ID: 5})
+
+	s.handler.HandleAddBookTag(ctx)
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *TagHandlerTestSuite) TestHandleAddBookTag_CountsCharacters() {
+	name := strings.Repeat("ü", 30)
+	s.mockStore.On("AddBookTag", int64(5), int64(1), name).Return(nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/1/tags", bytes.NewBufferString(`{"name": "`+name+`"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	ctx.Set("user", &store.User{ID: 5})
+
+	s.handler.HandleAddBookTag(ctx)
+
+	s.Equal(http.StatusNoContent, ctx.Writer.Status())
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *TagHandlerTestSuite) TestHandleAddBookTag_BookNotFound() {
+	s.mockStore.On("AddBookTag", int64(5), int64(99), "cozy").Return(&pgconn.PgError{Code: "23503"})
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/99/tags", bytes.NewBufferString(`{"name": "cozy"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "99"}}
+	ctx.Set("user", &store.User{ID: 5})
+
+	s.handler.HandleAddBookTag(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *TagHandlerTestSuite) TestHandleRemoveBookTag_NotFound() {
+	s.mockStore.On("RemoveBookTag", int64(5), int64(1), "cozy").Return(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodDelete, "/books/1/tags/cozy", nil)
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "1"},
+		gin.Param{Key: "tag", Value: "cozy"},
+	}
+	ctx.Set("user", &store.User{ID: 5})
+
+	s.handler.HandleRemoveBookTag(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockStore.AssertExpectations(s.T())
+}
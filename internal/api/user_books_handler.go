@@ -10,16 +10,19 @@ import (
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
 )
 
 type UserBooksHandler struct {
 	userBooksStore store.UserBooksStore
+	seriesStore    store.SeriesStore
 	logger         *log.Logger
 }
 
-func NewUserBooksHandler(userBooksStore store.UserBooksStore, logger *log.Logger) *UserBooksHandler {
+func NewUserBooksHandler(userBooksStore store.UserBooksStore, seriesStore store.SeriesStore, logger *log.Logger) *UserBooksHandler {
 	return &UserBooksHandler{
 		userBooksStore: userBooksStore,
+		seriesStore:    seriesStore,
 		logger:         logger,
 	}
 }
@@ -120,6 +123,8 @@ func (h *UserBooksHandler) HandleGetUserBooksStats(ctx *gin.Context) {
 // @Param        status query string false "Filter by status (wishlist|reading|completed)"
 // @Success      200 {object} UserBooksResponse
 // @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      409 {object} HTTPError "Error: Another edition already shelved"
 // @Failure      500 {object} HTTPError "Error: Internal server error"
 // @Router       /users/{user_id}/books [post]
 func (h *UserBooksHandler) HandleAddUserBook(ctx *gin.Context) {
@@ -153,6 +158,15 @@ func (h *UserBooksHandler) HandleAddUserBook(ctx *gin.Context) {
 
 	userBook, err := h.userBooksStore.AddUserBook(user.ID, bookIdVal, status)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "this book or another edition of it is already on your shelf"})
+			return
+		}
 		h.logger.Printf("ERROR: AddUserBook %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
@@ -162,16 +176,19 @@ func (h *UserBooksHandler) HandleAddUserBook(ctx *gin.Context) {
 
 // HandleUpdateUserBook godoc
 // @Summary      Partially update a user-book relationship
-// @Description  Updates only the fields provided in the JSON request.
+// @Description  Updates only the fields provided in the JSON request. When the book is marked completed and belongs to a series, the response includes the next book in that series. The response also maps the progress to the book's chapters.
 // @Tags         user_books
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "UserBook ID"
+// @Param        If-Match header string false "ETag of the entry being updated"
 // @Param        data body store.UpdateUserBookRequest true "Fields to update"
 // @Success      200 {object} store.UserBook
+// @Header       200 {string} ETag "Version of the entry"
 // @Failure      400 {object} HTTPError
 // @Failure      404 {object} HTTPError
+// @Failure      412 {object} HTTPError "Error: Entry has been modified"
 // @Failure      500 {object} HTTPError
 // @Router       /user-books/{id} [patch]
 func (h *UserBooksHandler) HandleUpdateUserBook(ctx *gin.Context) {
@@ -214,19 +231,87 @@ func (h *UserBooksHandler) HandleUpdateUserBook(ctx *gin.Context) {
 		}
 	}
 
+	if req.Rating != nil {
+		if *req.Rating < 0 || *req.Rating > 5 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "rating must be between 0 and 5"})
+			return
+		}
+	}
+
 	// Delegate to store
 	updated, err := h.userBooksStore.UpdateUserBook(
-		user.ID, userBookID, req,
+		user.ID, userBookID, req, utils.ReadIfMatch(ctx),
 	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user book not found"})
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "user book has been modified since it was read"})
+			return
+		}
 		h.logger.Println("ERROR UpdateUserBook:", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	// The hint is best effort; the update itself already succeeded.
+	if updated.Status == "completed" {
+		next, err := h.seriesStore.GetNextInSeries(updated.BookID)
+		if err != nil {
+			h.logger.Printf("ERROR: getNextInSeries %v", err)
+		} else {
+			updated.NextInSeries = next
+		}
+	}
+
+	utils.SetETag(ctx, updated.Version)
 	ctx.JSON(http.StatusOK, updated)
 }
 
+// HandleGetReadingPosition godoc
+// @Summary      Get my position in a book
+// @Description  Maps the user's reading progress to the book's chapters: the chapter they are reading and the chapters they have finished.
+//
+//	Chapter start pages are used when every chapter has one; otherwise the book's chapters are assumed to be of equal length, using page_count to turn pages read into a fraction. Any edition of the work can be requested; the position is measured in the edition the user shelved.
+//
+// @Tags         user_books
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        book_id path int true "Book ID"
+// @Success      200 {object} store.UserBookPosition
+// @Failure      400 {object} HTTPError "Error: Invalid book id"
+// @Failure      404 {object} HTTPError "Error: Book not on the user's shelf"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /users/me/books/{book_id}/position [get]
+func (h *UserBooksHandler) HandleGetReadingPosition(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	bookID, err := utils.ReadBookIDParam(ctx)
+	if err != nil {
+		h.logger.Printf("ERROR: readBookIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	position, err := h.userBooksStore.GetReadingPosition(user.ID, bookID)
+	if err != nil {
+		h.logger.Printf("ERROR: getReadingPosition %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if position == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "book not on your shelf"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, position)
+}
+
 // HandleDeleteUserBook godoc
 // @Summary      Delete a book from a user's shelf
 // @Description  Removes the user-book entry entirely.
@@ -235,9 +320,11 @@ func (h *UserBooksHandler) HandleUpdateUserBook(ctx *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "UserBook ID"
+// @Param        If-Match header string false "ETag of the entry being deleted"
 // @Success      204 "Deleted successfully"
 // @Failure      400 {object} HTTPError "Invalid ID"
 // @Failure      404 {object} HTTPError "Not found"
+// @Failure      412 {object} HTTPError "Entry has been modified"
 // @Failure      500 {object} HTTPError "Internal server error"
 // @Router       /user-books/{id} [delete]
 func (h *UserBooksHandler) HandleDeleteUserBook(ctx *gin.Context) {
@@ -251,12 +338,16 @@ func (h *UserBooksHandler) HandleDeleteUserBook(ctx *gin.Context) {
 		return
 	}
 
-	err = h.userBooksStore.DeleteUserBook(user.ID, userBookID)
+	err = h.userBooksStore.DeleteUserBook(user.ID, userBookID, utils.ReadIfMatch(ctx))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "user book not found"})
 			return
 		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "user book has been modified since it was read"})
+			return
+		}
 
 		h.logger.Println("ERROR DeleteUserBook:", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
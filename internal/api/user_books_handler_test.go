@@ -14,6 +14,7 @@ import (
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -21,13 +22,15 @@ type UserBooksHandlerTestSuite struct {
 	suite.Suite
 	UserBooksHandler *UserBooksHandler
 	MockStore        *mocks.MockUserBooksStore
+	MockSeriesStore  *mocks.MockSeriesStore
 }
 
 func (suite *UserBooksHandlerTestSuite) SetupTest() {
 	suite.MockStore = new(mocks.MockUserBooksStore)
+	suite.MockSeriesStore = new(mocks.MockSeriesStore)
 	var buf bytes.Buffer
 	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
-	suite.UserBooksHandler = NewUserBooksHandler(suite.MockStore, logger)
+	suite.UserBooksHandler = NewUserBooksHandler(suite.MockStore, suite.MockSeriesStore, logger)
 }
 
 func (suite *UserBooksHandlerTestSuite) TestHandleGetUserBooks_InvalidPagination() {
@@ -125,6 +128,33 @@ func (suite *UserBooksHandlerTestSuite) TestHandleAddUserBook_StoreError() {
 	suite.MockStore.AssertExpectations(suite.T())
 }
 
+func (suite *UserBooksHandlerTestSuite) TestHandleAddUserBook_OtherEditionShelved() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	req, _ := http.NewRequest("POST", "/?book_id=2", nil)
+	ctx.Request = req
+	ctx.Set("user", &store.User{ID: 7})
+
+	suite.MockStore.On("AddUserBook", int64(7), int64(2), "wishlist").Return((*store.UserBook)(nil), &pgconn.PgError{Code: "23505"})
+
+	suite.UserBooksHandler.HandleAddUserBook(ctx)
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.MockStore.AssertExpectations(suite.T())
+}
+
+func (suite *UserBooksHandlerTestSuite) TestHandleAddUserBook_BookNotFound() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	req, _ := http.NewRequest("POST", "/?book_id=2", nil)
+	ctx.Request = req
+	ctx.Set("user", &store.User{ID: 7})
+
+	suite.MockStore.On("AddUserBook", int64(7), int64(2), "wishlist").Return((*store.UserBook)(nil), sql.ErrNoRows)
+
+	suite.UserBooksHandler.HandleAddUserBook(ctx)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
 func (suite *UserBooksHandlerTestSuite) TestHandleAddUserBook_SuccessWithDefaultStatus() {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
@@ -213,6 +243,20 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_PercentageOutOf
 	suite.Equal(http.StatusBadRequest, w.Code)
 }
 
+func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_RatingOutOfRange() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	body := map[string]interface{}{"rating": 6}
+	b, _ := json.Marshal(body)
+	reqBody := bytes.NewBuffer(b)
+	req, _ := http.NewRequest("PATCH", "/user-books/10", reqBody)
+	ctx.Request = req
+	ctx.Set("user", &store.User{ID: 1})
+
+	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
 func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_StoreError() {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
@@ -229,7 +273,7 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_StoreError() {
 	}
 
 	reqStruct := store.UpdateUserBookRequest{Status: &status}
-	suite.MockStore.On("UpdateUserBook", int64(9), int64(12), reqStruct).Return((*store.UserBook)(nil), errors.New("fail"))
+	suite.MockStore.On("UpdateUserBook", int64(9), int64(12), reqStruct, []int(nil)).Return((*store.UserBook)(nil), errors.New("fail"))
 
 	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
 	suite.Equal(http.StatusInternalServerError, w.Code)
@@ -254,7 +298,8 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_Success() {
 
 	reqStruct := store.UpdateUserBookRequest{Status: &status, PagesRead: &pages, PercentageRead: &perc}
 	ub := &store.UserBook{ID: 77, UserID: 11, BookID: 5, Status: status, UpdatedAt: store.JSONDate(time.Now())}
-	suite.MockStore.On("UpdateUserBook", int64(11), int64(77), reqStruct).Return(ub, nil)
+	suite.MockStore.On("UpdateUserBook", int64(11), int64(77), reqStruct, []int(nil)).Return(ub, nil)
+	suite.MockSeriesStore.On("GetNextInSeries", int64(5)).Return(nil, nil)
 
 	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
 	suite.Equal(http.StatusOK, w.Code)
@@ -263,7 +308,54 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_Success() {
 	suite.NoError(err)
 	suite.Equal(int64(77), got.ID)
 	suite.Equal(status, got.Status)
+	suite.Nil(got.NextInSeries)
 	suite.MockStore.AssertExpectations(suite.T())
+	suite.MockSeriesStore.AssertExpectations(suite.T())
+}
+
+func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_CompletedIncludesNextInSeries() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	status := "completed"
+	req, _ := http.NewRequest("PATCH", "/user-books/77", bytes.NewBufferString(`{"status": "completed"}`))
+	ctx.Request = req
+	ctx.Set("user", &store.User{ID: 11})
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "77"},
+	}
+
+	reqStruct := store.UpdateUserBookRequest{Status: &status}
+	ub := &store.UserBook{ID: 77, UserID: 11, BookID: 5, Status: status}
+	suite.MockStore.On("UpdateUserBook", int64(11), int64(77), reqStruct, []int(nil)).Return(ub, nil)
+	suite.MockSeriesStore.On("GetNextInSeries", int64(5)).Return(&store.Book{ID: 6, Title: "The Two Towers"}, nil)
+
+	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
+	suite.Equal(http.StatusOK, w.Code)
+	var got store.UserBook
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &got))
+	suite.NotNil(got.NextInSeries)
+	suite.Equal(int64(6), got.NextInSeries.ID)
+	suite.MockSeriesStore.AssertExpectations(suite.T())
+}
+
+func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_ReadingSkipsNextInSeries() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	status := "reading"
+	req, _ := http.NewRequest("PATCH", "/user-books/77", bytes.NewBufferString(`{"status": "reading"}`))
+	ctx.Request = req
+	ctx.Set("user", &store.User{ID: 11})
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "77"},
+	}
+
+	reqStruct := store.UpdateUserBookRequest{Status: &status}
+	ub := &store.UserBook{ID: 77, UserID: 11, BookID: 5, Status: status}
+	suite.MockStore.On("UpdateUserBook", int64(11), int64(77), reqStruct, []int(nil)).Return(ub, nil)
+
+	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.MockSeriesStore.AssertNotCalled(suite.T(), "GetNextInSeries", int64(5))
 }
 
 func (suite *UserBooksHandlerTestSuite) TestHandleDeleteUserBook_InvalidID() {
@@ -278,7 +370,7 @@ func (suite *UserBooksHandlerTestSuite) TestHandleDeleteUserBook_InvalidID() {
 }
 
 func (suite *UserBooksHandlerTestSuite) TestHandleDeleteUserBook_NotFound() {
-	suite.MockStore.On("DeleteUserBook", int64(2), int64(55)).Return(sql.ErrNoRows)
+	suite.MockStore.On("DeleteUserBook", int64(2), int64(55), []int(nil)).Return(sql.ErrNoRows)
 
 	req, _ := http.NewRequest(http.MethodDelete, "/user-books/55", nil)
 	w := httptest.NewRecorder()
@@ -450,7 +542,7 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_ValidPartialUpd
 
 	reqStruct := store.UpdateUserBookRequest{PagesRead: &pages}
 	ub := &store.UserBook{ID: 12, UserID: 9, BookID: 3, Status: "reading", UpdatedAt: store.JSONDate(time.Now())}
-	suite.MockStore.On("UpdateUserBook", int64(9), int64(12), reqStruct).Return(ub, nil)
+	suite.MockStore.On("UpdateUserBook", int64(9), int64(12), reqStruct, []int(nil)).Return(ub, nil)
 
 	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
 	suite.Equal(http.StatusOK, w.Code)
@@ -475,7 +567,8 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_AllFieldsUpdate
 
 	reqStruct := store.UpdateUserBookRequest{Status: &status, PagesRead: &pages, PercentageRead: &perc}
 	ub := &store.UserBook{ID: 15, UserID: 10, BookID: 5, Status: status, UpdatedAt: store.JSONDate(time.Now())}
-	suite.MockStore.On("UpdateUserBook", int64(10), int64(15), reqStruct).Return(ub, nil)
+	suite.MockStore.On("UpdateUserBook", int64(10), int64(15), reqStruct, []int(nil)).Return(ub, nil)
+	suite.MockSeriesStore.On("GetNextInSeries", int64(5)).Return(nil, nil)
 
 	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
 	suite.Equal(http.StatusOK, w.Code)
@@ -484,7 +577,7 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_AllFieldsUpdate
 
 // --- HandleDeleteUserBook Success Test ---
 // func (suite *UserBooksHandlerTestSuite) TestHandleDeleteUserBook_Success() {
-// 	suite.MockStore.On("DeleteUserBook", int64(2), int64(55)).Return(nil)
+// 	suite.MockStore.On("DeleteUserBook", int64(2), int64(55), []int(nil)).Return(nil)
 
 // 	w := httptest.NewRecorder()
 // 	ctx, _ := gin.CreateTestContext(w)
@@ -503,3 +596,68 @@ func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_AllFieldsUpdate
 func TestUserBooksHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(UserBooksHandlerTestSuite))
 }
+
+func (suite *UserBooksHandlerTestSuite) TestHandleGetReadingPosition_Success() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/users/me/books/5/position", nil)
+	ctx.Set("user", &store.User{ID: 11})
+	ctx.Params = gin.Params{gin.Param{Key: "book_id", Value: "5"}}
+
+	pages := 30
+	position := &store.UserBookPosition{
+		UserBookID: 77,
+		BookID:     5,
+		Status:     "reading",
+		PagesRead:  &pages,
+		Position: &store.ReadingPosition{
+			CurrentChapter:   &store.Chapter{ID: 11, Number: 2, Title: "Roast Mutton"},
+			FinishedChapters: []store.Chapter{{ID: 10, Number: 1, Title: "An Unexpected Party"}},
+			Method:           store.PositionByPages,
+		},
+	}
+	suite.MockStore.On("GetReadingPosition", int64(11), int64(5)).Return(position, nil)
+
+	suite.UserBooksHandler.HandleGetReadingPosition(ctx)
+
+	suite.Equal(http.StatusOK, w.Code)
+	var got store.UserBookPosition
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &got))
+	suite.Equal(int64(11), got.Position.CurrentChapter.ID)
+	suite.Len(got.Position.FinishedChapters, 1)
+}
+
+func (suite *UserBooksHandlerTestSuite) TestHandleGetReadingPosition_NotShelved() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/users/me/books/5/position", nil)
+	ctx.Set("user", &store.User{ID: 11})
+	ctx.Params = gin.Params{gin.Param{Key: "book_id", Value: "5"}}
+
+	suite.MockStore.On("GetReadingPosition", int64(11), int64(5)).Return(nil, nil)
+
+	suite.UserBooksHandler.HandleGetReadingPosition(ctx)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+func (suite *UserBooksHandlerTestSuite) TestHandleUpdateUserBook_VersionMismatch() {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	status := "reading"
+	b, _ := json.Marshal(map[string]interface{}{"status": status})
+	req, _ := http.NewRequest("PATCH", "/user-books/12", bytes.NewBuffer(b))
+	req.Header.Set("If-Match", `"3"`)
+	ctx.Request = req
+	ctx.Set("user", &store.User{ID: 9})
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "12"},
+	}
+
+	reqStruct := store.UpdateUserBookRequest{Status: &status}
+	suite.MockStore.On("UpdateUserBook", int64(9), int64(12), reqStruct, []int{3}).Return((*store.UserBook)(nil), store.ErrVersionMismatch)
+
+	suite.UserBooksHandler.HandleUpdateUserBook(ctx)
+	suite.Equal(http.StatusPreconditionFailed, w.Code)
+	suite.MockStore.AssertExpectations(suite.T())
+}
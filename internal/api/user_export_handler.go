@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/takeout"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type UserExportHandler struct {
+	userExportStore store.UserExportStore
+	// archives keeps the export archives. It must not be served publicly.
+	archives   store.BlobStore
+	linkTTL    time.Duration
+	signingKey []byte
+	logger     *log.Logger
+	// background runs exports once they are queued.
+	background func(func())
+}
+
+func NewUserExportHandler(userExportStore store.UserExportStore, archives store.BlobStore, config store.UserExportConfig, logger *log.Logger) *UserExportHandler {
+	return &UserExportHandler{
+		userExportStore: userExportStore,
+		archives:        archives,
+		linkTTL:         config.LinkTTL,
+		signingKey:      config.SigningKey,
+		logger:          logger,
+		background:      func(run func()) { go run() },
+	}
+}
+
+// exportArchiveKey is where the export's archive is kept.
+func exportArchiveKey(exportID int64) string {
+	return fmt.Sprintf("%d.zip", exportID)
+}
+
+// HandleCreateExport godoc
+// @Summary      Export my data
+// @Description  Queues an archive of everything held about the current user and returns the export, whose status can be followed at /me/exports/{id}.
+//
+//	The archive is a zip file with the user's profile, shelved books with their progress, chapter comments with their chapter and book, tags, and the scope and expiry of their tokens, each as JSON and as CSV. Tokens themselves are never exported.
+//	Once the export is completed its download_url can be used, without authentication, until expires_at.
+//
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202 {object} store.UserExport
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      409 {object} HTTPError "Error: An export is already in progress"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/export [post]
+func (eh *UserExportHandler) HandleCreateExport(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	export, err := eh.userExportStore.CreateUserExport(user.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrExportInProgress) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "an export is already in progress"})
+			return
+		}
+		eh.logger.Printf("ERROR: createUserExport %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	eh.background(func() { eh.runExport(export) })
+
+	ctx.Header("Location", fmt.Sprintf("/me/exports/%d", export.ID))
+	ctx.JSON(http.StatusAccepted, export)
+}
+
+// ResumeExports restarts the exports that were queued or running when the
+// server stopped.
+func (eh *UserExportHandler) ResumeExports() {
+	exports, err := eh.userExportStore.GetUnfinishedUserExports()
+	if err != nil {
+		eh.logger.Printf("ERROR: getUnfinishedUserExports %v", err)
+		return
+	}
+	for _, export := range exports {
+		eh.background(func() { eh.runExport(export) })
+	}
+}
+
+func (eh *UserExportHandler) runExport(export *store.UserExport) {
+	if err := eh.userExportStore.StartUserExport(export.ID); err != nil {
+		eh.logger.Printf("ERROR: startUserExport %v", err)
+		return
+	}
+
+	size, err := eh.writeArchive(export)
+	if err != nil {
+		eh.logger.Printf("ERROR: writeExportArchive export %d: %v", export.ID, err)
+		if err := eh.userExportStore.FailUserExport(export.ID, errors.New("export stopped by an internal error")); err != nil {
+			eh.logger.Printf("ERROR: failUserExport %v", err)
+		}
+		return
+	}
+
+	if err := eh.userExportStore.CompleteUserExport(export.ID, size, time.Now().Add(eh.linkTTL)); err != nil {
+		eh.logger.Printf("ERROR: completeUserExport %v", err)
+	}
+}
+
+func (eh *UserExportHandler) writeArchive(export *store.UserExport) (int64, error) {
+	data, err := eh.userExportStore.GetUserExportData(export.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	if err := takeout.Write(&buf, data); err != nil {
+		return 0, err
+	}
+	if err := eh.archives.Put(exportArchiveKey(export.ID), buf.Bytes(), "application/zip"); err != nil {
+		return 0, err
+	}
+	return int64(buf.Len()), nil
+}
+
+// PurgeExpiredExports deletes the archives that can no longer be downloaded.
+func (eh *UserExportHandler) PurgeExpiredExports() {
+	exports, err := eh.userExportStore.GetExpiredUserExports()
+	if err != nil {
+		eh.logger.Printf("ERROR: getExpiredUserExports %v", err)
+		return
+	}
+	for _, export := range exports {
+		if err := eh.archives.Delete(exportArchiveKey(export.ID)); err != nil {
+			eh.logger.Printf("ERROR: deleteExportArchive export %d: %v", export.ID, err)
+			continue
+		}
+		if err := eh.userExportStore.ExpireUserExport(export.ID); err != nil {
+			eh.logger.Printf("ERROR: expireUserExport %v", err)
+		}
+	}
+}
+
+// HandleGetExport godoc
+// @Summary      Get one of my data exports
+// @Description  Retrieves a data export of the current user. Completed exports that have not expired have a download_url.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Export ID"
+// @Success      200 {object} store.UserExport
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Export not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /me/exports/{id} [get]
+func (eh *UserExportHandler) HandleGetExport(ctx *gin.Context) {
+	userValue, _ := ctx.Get("user")
+	user := userValue.(*store.User)
+
+	exportID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		eh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid export id"})
+		return
+	}
+
+	export, err := eh.userExportStore.GetUserExportByID(exportID)
+	if err != nil {
+		eh.logger.Printf("ERROR: getUserExportByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if export == nil || export.UserID != user.ID {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+		return
+	}
+
+	if export.Status == store.UserExportCompleted && export.ExpiresAt != nil && time.Now().Before(*export.ExpiresAt) {
+		export.DownloadURL = fmt.Sprintf("/exports/%d/download?expires=%d&signature=%s",
+			export.ID, export.ExpiresAt.Unix(), takeout.Sign(eh.signingKey, export.ID, *export.ExpiresAt))
+	}
+	ctx.JSON(http.StatusOK, export)
+}
+
+// HandleDownloadExport godoc
+// @Summary      Download a data export
+// @Description  Downloads the archive of a data export. The link comes from the export's download_url and works without authentication until it expires.
+// @Tags         users
+// @Produce      application/zip
+// @Param        id path int true "Export ID"
+// @Param        expires query int true "Expiry of the link, in seconds since the epoch"
+// @Param        signature query string true "Signature of the link"
+// @Success      200 {file} binary
+// @Failure      400 {object} HTTPError "Error: Invalid id"
+// @Failure      403 {object} HTTPError "Error: Invalid signature"
+// @Failure      404 {object} HTTPError "Error: Export not found"
+// @Failure      410 {object} HTTPError "Error: Link expired"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /exports/{id}/download [get]
+func (eh *UserExportHandler) HandleDownloadExport(ctx *gin.Context) {
+	exportID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		eh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid export id"})
+		return
+	}
+
+	seconds, err := strconv.ParseInt(ctx.Query("expires"), 10, 64)
+	expires := time.Unix(seconds, 0)
+	if err != nil || !takeout.Verify(eh.signingKey, exportID, expires, ctx.Query("signature")) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "invalid download link"})
+		return
+	}
+	if !time.Now().Before(expires) {
+		ctx.JSON(http.StatusGone, gin.H{"error": "download link has expired"})
+		return
+	}
+
+	export, err := eh.userExportStore.GetUserExportByID(exportID)
+	if err != nil {
+		eh.logger.Printf("ERROR: getUserExportByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if export == nil || export.Status != store.UserExportCompleted {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+		return
+	}
+
+	archive, err := eh.archives.Get(exportArchiveKey(export.ID))
+	if err != nil {
+		eh.logger.Printf("ERROR: getExportArchive %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	filename := fmt.Sprintf("bookclub-export-%s.zip", export.CreatedAt.UTC().Format("20060102"))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Data(http.StatusOK, "application/zip", archive)
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/takeout"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var testExportSigningKey = []byte("test-signing-key")
+
+type UserExportHandlerTestSuite struct {
+	suite.Suite
+	mockUserExportStore *mocks.MockUserExportStore
+	mockArchives        *mocks.MockBlobStore
+	handler             *UserExportHandler
+}
+
+func (s *UserExportHandlerTestSuite) SetupTest() {
+	s.mockUserExportStore = new(mocks.MockUserExportStore)
+	s.mockArchives = new(mocks.MockBlobStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	config := store.UserExportConfig{LinkTTL: time.Hour, SigningKey: testExportSigningKey}
+	s.handler = NewUserExportHandler(s.mockUserExportStore, s.mockArchives, config, logger)
+	// Exports run before the handler returns, so their calls can be asserted.
+	s.handler.background = func(run func()) { run() }
+}
+
+func TestUserExportHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(UserExportHandlerTestSuite))
+}
+
+func (s *UserExportHandlerTestSuite) newContext(method, url string, id int64) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(method, url, nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: fmt.Sprint(id)}}
+	ctx.Set("user", &store.User{ID: 3})
+	return ctx, w
+}
+
+func (s *UserExportHandlerTestSuite) TestHandleCreateExport_WritesArchive() {
+	export := &store.UserExport{ID: 5, UserID: 3, Status: store.UserExportQueued}
+	s.mockUserExportStore.On("CreateUserExport", int64(3)).Return(export, nil)
+	s.mockUserExportStore.On("StartUserExport", int64(5)).Return(nil)
+	s.mockUserExportStore.On("GetUserExportData", int64(3)).Return(&store.UserExportData{
+		Profile: &store.User{ID: 3, Username: "reader"},
+		Tokens:  []*store.ExportedToken{{Scope: "authentication"}},
+	}, nil)
+	var archive []byte
+	s.mockArchives.On("Put", "5.zip", mock.Anything, "application/zip").Run(func(args mock.Arguments) {
+		archive = args.Get(1).([]byte)
+	}).Return(nil)
+	s.mockUserExportStore.On("CompleteUserExport", int64(5), mock.AnythingOfType("int64"), mock.MatchedBy(func(expiresAt time.Time) bool {
+		return expiresAt.After(time.Now().Add(59 * time.Minute))
+	})).Return(nil)
+
+	ctx, w := s.newContext(http.MethodPost, "/me/export", 0)
+
+	s.handler.HandleCreateExport(ctx)
+
+	s.Equal(http.StatusAccepted, w.Code)
+	s.Equal("/me/exports/5", w.Header().Get("Location"))
+	s.mockUserExportStore.AssertExpectations(s.T())
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	s.Require().NoError(err)
+	s.Len(reader.File, 10)
+}
+
+func (s *UserExportHandlerTestSuite) TestHandleCreateExport_InProgress() {
+	s.mockUserExportStore.On("CreateUserExport", int64(3)).Return(nil, store.ErrExportInProgress)
+
+	ctx, w := s.newContext(http.MethodPost, "/me/export", 0)
+
+	s.handler.HandleCreateExport(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.mockUserExportStore.AssertNotCalled(s.T(), "StartUserExport", mock.Anything)
+}
+
+func (s *UserExportHandlerTestSuite) TestRunExport_Fails() {
+	s.mockUserExportStore.On("StartUserExport", int64(5)).Return(nil)
+	s.mockUserExportStore.On("GetUserExportData", int64(3)).Return(nil, errors.New("connection reset"))
+	s.mockUserExportStore.On("FailUserExport", int64(5), mock.MatchedBy(func(err error) bool {
+		return err.Error() == "export stopped by an internal error"
+	})).Return(nil)
+
+	s.handler.runExport(&store.UserExport{ID: 5, UserID: 3})
+
+	s.mockUserExportStore.AssertExpectations(s.T())
+	s.mockArchives.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UserExportHandlerTestSuite) TestHandleGetExport_DownloadURL() {
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	s.mockUserExportStore.On("GetUserExportByID", int64(5)).Return(&store.UserExport{
+		ID: 5, UserID: 3, Status: store.UserExportCompleted, ExpiresAt: &expiresAt,
+	}, nil)
+
+	ctx, w := s.newContext(http.MethodGet, "/me/exports/5", 5)
+
+	s.handler.HandleGetExport(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var response store.UserExport
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(fmt.Sprintf("/exports/5/download?expires=%d&signature=%s",
+		expiresAt.Unix(), takeout.Sign(testExportSigningKey, 5, expiresAt)), response.DownloadURL)
+}
+
+func (s *UserExportHandlerTestSuite) TestHandleGetExport_OtherUsersExport() {
+	s.mockUserExportStore.On("GetUserExportByID", int64(5)).Return(&store.UserExport{ID: 5, UserID: 4}, nil)
+
+	ctx, w := s.newContext(http.MethodGet, "/me/exports/5", 5)
+
+	s.handler.HandleGetExport(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *UserExportHandlerTestSuite) TestHandleDownloadExport() {
+	expiresAt := time.Now().Add(time.Hour)
+	s.mockUserExportStore.On("GetUserExportByID", int64(5)).Return(&store.UserExport{
+		ID: 5, UserID: 3, Status: store.UserExportCompleted, ExpiresAt: &expiresAt,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil)
+	s.mockArchives.On("Get", "5.zip").Return([]byte("PK"), nil)
+
+	url := fmt.Sprintf("/exports/5/download?expires=%d&signature=%s", expiresAt.Unix(), takeout.Sign(testExportSigningKey, 5, expiresAt))
+	ctx, w := s.newContext(http.MethodGet, url, 5)
+
+	s.handler.HandleDownloadExport(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("application/zip", w.Header().Get("Content-Type"))
+	s.Equal(`attachment; filename="bookclub-export-20240102.zip"`, w.Header().Get("Content-Disposition"))
+	s.Equal("private, no-store", w.Header().Get("Cache-Control"))
+	s.Equal("PK", w.Body.String())
+}
+
+func (s *UserExportHandlerTestSuite) TestHandleDownloadExport_InvalidSignature() {
+	expiresAt := time.Now().Add(time.Hour)
+	signature := takeout.Sign(testExportSigningKey, 5, expiresAt)
+	url := fmt.Sprintf("/exports/5/download?expires=%d&signature=%s", expiresAt.Add(time.Hour).Unix(), signature)
+	ctx, w := s.newContext(http.MethodGet, url, 5)
+
+	s.handler.HandleDownloadExport(ctx)
+
+	s.Equal(http.StatusForbidden, w.Code)
+	s.mockUserExportStore.AssertNotCalled(s.T(), "GetUserExportByID", mock.Anything)
+}
+
+func (s *UserExportHandlerTestSuite) TestHandleDownloadExport_Expired() {
+	expiresAt := time.Now().Add(-time.Minute)
+	url := fmt.Sprintf("/exports/5/download?expires=%d&signature=%s", expiresAt.Unix(), takeout.Sign(testExportSigningKey, 5, expiresAt))
+	ctx, w := s.newContext(http.MethodGet, url, 5)
+
+	s.handler.HandleDownloadExport(ctx)
+
+	s.Equal(http.StatusGone, w.Code)
+}
+
+func (s *UserExportHandlerTestSuite) TestPurgeExpiredExports() {
+	s.mockUserExportStore.On("GetExpiredUserExports").Return([]*store.UserExport{{ID: 5}, {ID: 6}}, nil)
+	s.mockArchives.On("Delete", "5.zip").Return(nil)
+	s.mockArchives.On("Delete", "6.zip").Return(errors.New("permission denied"))
+	s.mockUserExportStore.On("ExpireUserExport", int64(5)).Return(nil)
+
+	s.handler.PurgeExpiredExports()
+
+	s.mockUserExportStore.AssertExpectations(s.T())
+	s.mockUserExportStore.AssertNotCalled(s.T(), "ExpireUserExport", int64(6))
+}
@@ -10,6 +10,7 @@ import (
 	"regexp"
 
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -31,6 +32,10 @@ type RegisterUserRequest struct {
 	Password string `json:"password"`
 }
 
+type UpdateUserRoleRequest struct {
+	Role store.UserRole `json:"role" example:"editor"`
+}
+
 type HTTPError struct {
 	Error string `json:"error"`
 }
@@ -236,3 +241,59 @@ func (uh *UserHandler) GetMe(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, userObj)
 }
+
+// HandleUpdateUserRole godoc
+// @Summary      Change a user's role
+// @Description  Sets a user's role. Editors maintain the catalog (books, chapters, genres, series, editions) and review edit suggestions; moderators can also delete catalog entries and other readers' comments; admins can do everything, including managing roles. Admins cannot change their own role.
+// @Tags         admins
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id path int true "User ID"
+// @Param        request body UpdateUserRoleRequest true "New role (user, editor, moderator or admin)"
+// @Success      200 {object} store.User
+// @Failure      400 {object} HTTPError "Error: Invalid Request"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      403 {object} HTTPError "Error: Forbidden"
+// @Failure      404 {object} HTTPError "Error: User not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /users/{user_id}/role [put]
+func (uh *UserHandler) HandleUpdateUserRole(ctx *gin.Context) {
+	userID, err := utils.ReadUserIDParam(ctx)
+	if err != nil {
+		uh.logger.Printf("ERROR: readUserIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		uh.logger.Printf("ERROR: decodingUpdateUserRole %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if !store.ValidRole(req.Role) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of user, editor, moderator or admin"})
+		return
+	}
+
+	currentValue, _ := ctx.Get("user")
+	if current, ok := currentValue.(*store.User); ok && current.ID == userID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "you cannot change your own role"})
+		return
+	}
+
+	user, err := uh.userStore.UpdateUserRole(userID, req.Role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		uh.logger.Printf("ERROR: updateUserRole %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -217,3 +218,66 @@ func (uhs *UserHandlerTestSuite) TestRegisterUser_Success() {
 
 	uhs.mockUserStore.AssertExpectations(uhs.T())
 }
+
+func (uhs *UserHandlerTestSuite) TestHandleUpdateUserRole_Success() {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/users/2/role", strings.NewReader(`{"role":"editor"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{{Key: "user_id", Value: "2"}}
+	ctx.Set("user", &store.User{ID: 1, Role: store.RoleAdmin})
+
+	uhs.mockUserStore.On("UpdateUserRole", int64(2), store.RoleEditor).
+		Return(&store.User{ID: 2, Username: "jane", Role: store.RoleEditor}, nil)
+
+	uhs.userHandler.HandleUpdateUserRole(ctx)
+
+	uhs.Equal(http.StatusOK, rec.Code)
+	uhs.Contains(rec.Body.String(), `"role":"editor"`)
+	uhs.mockUserStore.AssertExpectations(uhs.T())
+}
+
+func (uhs *UserHandlerTestSuite) TestHandleUpdateUserRole_InvalidRole() {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/users/2/role", strings.NewReader(`{"role":"superuser"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{{Key: "user_id", Value: "2"}}
+	ctx.Set("user", &store.User{ID: 1, Role: store.RoleAdmin})
+
+	uhs.userHandler.HandleUpdateUserRole(ctx)
+
+	uhs.Equal(http.StatusBadRequest, rec.Code)
+	uhs.mockUserStore.AssertNotCalled(uhs.T(), "UpdateUserRole", mock.Anything, mock.Anything)
+}
+
+func (uhs *UserHandlerTestSuite) TestHandleUpdateUserRole_OwnRole() {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/users/1/role", strings.NewReader(`{"role":"user"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{{Key: "user_id", Value: "1"}}
+	ctx.Set("user", &store.User{ID: 1, Role: store.RoleAdmin})
+
+	uhs.userHandler.HandleUpdateUserRole(ctx)
+
+	uhs.Equal(http.StatusBadRequest, rec.Code)
+	uhs.Contains(rec.Body.String(), "your own role")
+	uhs.mockUserStore.AssertNotCalled(uhs.T(), "UpdateUserRole", mock.Anything, mock.Anything)
+}
+
+func (uhs *UserHandlerTestSuite) TestHandleUpdateUserRole_NotFound() {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/users/9/role", strings.NewReader(`{"role":"moderator"}`))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{{Key: "user_id", Value: "9"}}
+	ctx.Set("user", &store.User{ID: 1, Role: store.RoleAdmin})
+
+	uhs.mockUserStore.On("UpdateUserRole", int64(9), store.RoleModerator).Return(nil, sql.ErrNoRows)
+
+	uhs.userHandler.HandleUpdateUserRole(ctx)
+
+	uhs.Equal(http.StatusNotFound, rec.Code)
+	uhs.mockUserStore.AssertExpectations(uhs.T())
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type WorkHandler struct {
+	workStore store.WorkStore
+	logger    *log.Logger
+}
+
+func NewWorkHandler(workStore store.WorkStore, logger *log.Logger) *WorkHandler {
+	return &WorkHandler{
+		workStore: workStore,
+		logger:    logger,
+	}
+}
+
+// HandleGetWorkByID godoc
+// @Summary      Get a work by id
+// @Description  Retrieves a work with all of its editions.
+// @Tags         works
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Work ID"
+// @Success      200 {object} store.Work
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      404 {object} HTTPError "Error: Work not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /works/{id} [get]
+func (wh *WorkHandler) HandleGetWorkByID(ctx *gin.Context) {
+	workID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		wh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid work id"})
+		return
+	}
+
+	work, err := wh.workStore.GetWorkByID(workID)
+	if err != nil {
+		wh.logger.Printf("ERROR: getWorkByID %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if work == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "work not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, work)
+}
+
+// HandleAddEditionToWork godoc
+// @Summary      Group an edition into a work
+// @Description  Moves a book into a work as another edition. Readers who shelved both keep their most recently updated entry.
+// @Tags         works
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Work ID"
+// @Param        book_id path int true "Book ID"
+// @Success      204 "Updated successfully"
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Work or book not found"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /works/{id}/editions/{book_id} [put]
+func (wh *WorkHandler) HandleAddEditionToWork(ctx *gin.Context) {
+	workID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		wh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid work id"})
+		return
+	}
+
+	bookID, err := utils.ReadBookIDParam(ctx)
+	if err != nil {
+		wh.logger.Printf("ERROR: readBookIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	if err := wh.workStore.AddEditionToWork(workID, bookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "work or book not found"})
+			return
+		}
+		wh.logger.Printf("ERROR: addEditionToWork %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// HandleSplitEdition godoc
+// @Summary      Split an edition from its work
+// @Description  Moves a book out of its work into a new work of its own. Readers shelving that edition move with it.
+// @Tags         works
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Book ID"
+// @Success      200 {object} store.Work
+// @Failure      400 {object} HTTPError "Error: Invalid or missing id"
+// @Failure      401 {object} HTTPError "Error: Unauthorized"
+// @Failure      404 {object} HTTPError "Error: Book not found"
+// @Failure      409 {object} HTTPError "Error: Book is the only edition of its work"
+// @Failure      500 {object} HTTPError "Error: Internal server error"
+// @Router       /books/{id}/split [post]
+func (wh *WorkHandler) HandleSplitEdition(ctx *gin.Context) {
+	bookID, err := utils.ReadIDParam(ctx)
+	if err != nil {
+		wh.logger.Printf("ERROR: readIDParam %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid book id"})
+		return
+	}
+
+	work, err := wh.workStore.SplitEdition(bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+			return
+		}
+		if errors.Is(err, store.ErrSingleEdition) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		wh.logger.Printf("ERROR: splitEdition %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, work)
+}
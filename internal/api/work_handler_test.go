@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkHandlerTestSuite struct {
+	suite.Suite
+	mockStore *mocks.MockWorkStore
+	handler   *WorkHandler
+}
+
+func (s *WorkHandlerTestSuite) SetupTest() {
+	s.mockStore = new(mocks.MockWorkStore)
+	var buf bytes.Buffer
+	logger := log.New(&buf, "TEST: ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	s.handler = NewWorkHandler(s.mockStore, logger)
+}
+
+func TestWorkHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkHandlerTestSuite))
+}
+
+func (s *WorkHandlerTestSuite) TestHandleGetWorkByID_Success() {
+	s.mockStore.On("GetWorkByID", int64(1)).Return(&store.Work{
+		ID:    1,
+		Title: "The Hobbit",
+		Editions: []*store.Book{
+			{ID: 1, WorkID: 1, ISBN13: "9780261102217"},
+			{ID: 2, WorkID: 1, ISBN13: "9780547928227"},
+		},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/works/1", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	s.handler.HandleGetWorkByID(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	var resp store.Work
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Editions, 2)
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *WorkHandlerTestSuite) TestHandleGetWorkByID_NotFound() {
+	s.mockStore.On("GetWorkByID", int64(9)).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/works/9", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "9"}}
+
+	s.handler.HandleGetWorkByID(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *WorkHandlerTestSuite) TestHandleAddEditionToWork_Success() {
+	s.mockStore.On("AddEditionToWork", int64(1), int64(2)).Return(nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/works/1/editions/2", nil)
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "1"},
+		gin.Param{Key: "book_id", Value: "2"},
+	}
+
+	s.handler.HandleAddEditionToWork(ctx)
+
+	s.Equal(http.StatusNoContent, ctx.Writer.Status())
+	s.mockStore.AssertExpectations(s.T())
+}
+
+func (s *WorkHandlerTestSuite) TestHandleAddEditionToWork_NotFound() {
+	s.mockStore.On("AddEditionToWork", int64(1), int64(2)).Return(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPut, "/works/1/editions/2", nil)
+	ctx.Params = gin.Params{
+		gin.Param{Key: "id", Value: "1"},
+		gin.Param{Key: "book_id", Value: "2"},
+	}
+
+	s.handler.HandleAddEditionToWork(ctx)
+
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *WorkHandlerTestSuite) TestHandleSplitEdition_Success() {
+	s.mockStore.On("SplitEdition", int64(2)).Return(&store.Work{ID: 5, Title: "The Hobbit"}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/2/split", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "2"}}
+
+	s.handler.HandleSplitEdition(ctx)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"id":5`)
+}
+
+func (s *WorkHandlerTestSuite) TestHandleSplitEdition_SingleEdition() {
+	s.mockStore.On("SplitEdition", int64(2)).Return(nil, store.ErrSingleEdition)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/books/2/split", nil)
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "2"}}
+
+	s.handler.HandleSplitEdition(ctx)
+
+	s.Equal(http.StatusConflict, w.Code)
+}
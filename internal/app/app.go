@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	_ "github.com/SamaraRuizSandoval/BookClubApp/docs"
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/api"
@@ -16,15 +17,32 @@ import (
 
 // Resourses that we can use through our application
 type Application struct {
-	Logger               *log.Logger
-	DB                   *sql.DB
-	Middleware           middleware.UserMiddleware
-	BookHandler          *api.BookHandler
-	UserHandler          *api.UserHandler
-	TokenHandler         *api.TokenHandler
-	UserBooksHandler     *api.UserBooksHandler
-	CommentHandler       *api.ChapterCommentHandler
-	GoogleBookAPIHandler *api.GoogleBookApiHandler
+	Logger                *log.Logger
+	DB                    *sql.DB
+	Middleware            middleware.UserMiddleware
+	BookHandler           *api.BookHandler
+	UserHandler           *api.UserHandler
+	TokenHandler          *api.TokenHandler
+	UserBooksHandler      *api.UserBooksHandler
+	CommentHandler        *api.ChapterCommentHandler
+	GoogleBookAPIHandler  *api.GoogleBookApiHandler
+	GenreHandler          *api.GenreHandler
+	TagHandler            *api.TagHandler
+	SeriesHandler         *api.SeriesHandler
+	WorkHandler           *api.WorkHandler
+	BookMetadataHandler   *api.BookMetadataHandler
+	ChapterHandler        *api.ChapterHandler
+	SuggestionHandler     *api.SuggestionHandler
+	CoverHandler          *api.CoverHandler
+	ImageProxyHandler     *api.ImageProxyHandler
+	CatalogHandler        *api.CatalogHandler
+	ShelfImportHandler    *api.ShelfImportHandler
+	UserExportHandler     *api.UserExportHandler
+	RecommendationHandler *api.RecommendationHandler
+	// ImageProxy rewrites external cover URLs in responses.
+	ImageProxy store.ImageProxy
+	// MediaDir holds uploaded files served under /media.
+	MediaDir string
 }
 
 func NewApplication() (*Application, error) {
@@ -44,7 +62,34 @@ func NewApplication() (*Application, error) {
 	chapterStore := store.NewPostgresChapterStore(pgDB)
 	userBooksStore := store.NewUserBooksStore(pgDB)
 	commentStore := store.NewPostgresChapterCommentStore(pgDB)
-	googleApiStore := store.NewGoogleBooksStore()
+	googleApiStore := store.NewGoogleBooksStore(store.GoogleBooksClientConfigFromEnv())
+	googleBooksCacheConfig := store.GoogleBooksCacheConfigFromEnv()
+	var googleBooksCacheStore store.GoogleBooksCacheStore
+	if googleBooksCacheConfig.Persist {
+		googleBooksCacheStore = store.NewPostgresGoogleBooksCacheStore(pgDB)
+	}
+	cachedGoogleApiStore := store.NewCachedGoogleBookAPI(googleApiStore, googleBooksCacheStore, googleBooksCacheConfig)
+	bookMetadataProvider, err := store.NewMergedBookMetadataProvider(
+		store.BookMetadataConfigFromEnv(),
+		store.NewGoogleBooksMetadataProvider(cachedGoogleApiStore),
+		store.NewOpenLibraryStore(store.OpenLibraryConfigFromEnv()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	genreStore := store.NewPostgresGenreStore(pgDB)
+	tagStore := store.NewPostgresTagStore(pgDB)
+	seriesStore := store.NewPostgresSeriesStore(pgDB)
+	workStore := store.NewPostgresWorkStore(pgDB)
+	suggestionStore := store.NewPostgresSuggestionStore(pgDB)
+	blobStore := store.NewLocalBlobStore(store.LocalBlobStoreConfigFromEnv())
+	imageProxy := store.NewCachedImageProxy(store.ImageProxyConfigFromEnv())
+	importJobStore := store.NewPostgresImportJobStore(pgDB)
+	userExportStore := store.NewPostgresUserExportStore(pgDB)
+	userExportConfig := store.UserExportConfigFromEnv()
+	exportArchives := store.NewLocalBlobStore(store.LocalBlobStoreConfig{Dir: userExportConfig.Dir})
+	recommendationStore := store.NewPostgresRecommendationStore(pgDB)
+	recommendationConfig := store.RecommendationConfigFromEnv()
 
 	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime)
 	middlewareHandler := middleware.UserMiddleware{UserStore: userStore}
@@ -52,25 +97,71 @@ func NewApplication() (*Application, error) {
 	bookHandler := api.NewBookHandler(bookStore, logger)
 	userHandler := api.NewUserHandler(userStore, logger)
 	tokenHandler := api.NewTokenHandler(tokenStore, userStore, logger)
-	userBooksHandler := api.NewUserBooksHandler(userBooksStore, logger)
+	userBooksHandler := api.NewUserBooksHandler(userBooksStore, seriesStore, logger)
 	commentHandler := api.NewChapterCommentHandler(commentStore, chapterStore, logger)
-	googleBookApiHandler := api.NewGoogleBookApiHandler(googleApiStore, logger)
+	googleBookApiHandler := api.NewGoogleBookApiHandler(cachedGoogleApiStore, bookStore, logger)
+	genreHandler := api.NewGenreHandler(genreStore, bookStore, logger)
+	tagHandler := api.NewTagHandler(tagStore, logger)
+	seriesHandler := api.NewSeriesHandler(seriesStore, logger)
+	workHandler := api.NewWorkHandler(workStore, logger)
+	bookMetadataHandler := api.NewBookMetadataHandler(bookMetadataProvider, bookStore, logger)
+	chapterHandler := api.NewChapterHandler(chapterStore, bookStore, logger)
+	suggestionHandler := api.NewSuggestionHandler(suggestionStore, bookStore, logger)
+	coverHandler := api.NewCoverHandler(bookStore, blobStore, logger)
+	imageProxyHandler := api.NewImageProxyHandler(imageProxy, logger)
+	catalogHandler := api.NewCatalogHandler(bookStore, importJobStore, logger)
+	catalogHandler.ResumeImports()
+	shelfImportHandler := api.NewShelfImportHandler(bookStore, userBooksStore, importJobStore, bookMetadataProvider, logger)
+	shelfImportHandler.ResumeImports()
+	userExportHandler := api.NewUserExportHandler(userExportStore, exportArchives, userExportConfig, logger)
+	userExportHandler.ResumeExports()
+	runEvery(time.Hour, userExportHandler.PurgeExpiredExports)
+	recommendationHandler := api.NewRecommendationHandler(recommendationStore, recommendationConfig, logger)
+	go recommendationHandler.RefreshRecommendations()
+	runEvery(recommendationConfig.Interval, recommendationHandler.RefreshRecommendations)
 
 	app := &Application{
-		Logger:               logger,
-		DB:                   pgDB,
-		Middleware:           middlewareHandler,
-		BookHandler:          bookHandler,
-		UserHandler:          userHandler,
-		TokenHandler:         tokenHandler,
-		UserBooksHandler:     userBooksHandler,
-		CommentHandler:       commentHandler,
-		GoogleBookAPIHandler: googleBookApiHandler,
+		Logger:                logger,
+		DB:                    pgDB,
+		Middleware:            middlewareHandler,
+		BookHandler:           bookHandler,
+		UserHandler:           userHandler,
+		TokenHandler:          tokenHandler,
+		UserBooksHandler:      userBooksHandler,
+		CommentHandler:        commentHandler,
+		GoogleBookAPIHandler:  googleBookApiHandler,
+		GenreHandler:          genreHandler,
+		TagHandler:            tagHandler,
+		SeriesHandler:         seriesHandler,
+		WorkHandler:           workHandler,
+		BookMetadataHandler:   bookMetadataHandler,
+		ChapterHandler:        chapterHandler,
+		SuggestionHandler:     suggestionHandler,
+		CoverHandler:          coverHandler,
+		ImageProxyHandler:     imageProxyHandler,
+		CatalogHandler:        catalogHandler,
+		ShelfImportHandler:    shelfImportHandler,
+		UserExportHandler:     userExportHandler,
+		RecommendationHandler: recommendationHandler,
+		ImageProxy:            imageProxy,
+		MediaDir:              blobStore.Dir(),
 	}
 
 	return app, nil
 }
 
+// runEvery runs job in the background once per interval, for as long as the
+// server is up.
+func runEvery(interval time.Duration, job func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			job()
+		}
+	}()
+}
+
 func (a *Application) HealthCheck(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{
 		"status": "ok",
@@ -0,0 +1,106 @@
+// Package cache provides a small in-memory LRU cache whose entries expire
+// after a fixed time-to-live.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU is safe for concurrent use. When it is full, adding a new key evicts the
+// least recently used entry.
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	items    map[K]*list.Element
+	order    *list.List // front is most recently used
+	now      func() time.Time
+}
+
+// NewLRU returns a cache holding at most capacity entries for ttl each. A
+// capacity or ttl of zero or less gives a cache that stores nothing.
+func NewLRU[K comparable, V any](capacity int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value stored for key if it has not expired.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	e := el.Value.(*entry[K, V])
+	if !c.now().Before(e.expiresAt) {
+		c.removeElement(el)
+		return zero, false
+	}
+
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Set stores value for key, replacing any previous value and resetting its TTL.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.SetWithExpiry(key, value, c.now().Add(c.ttl))
+}
+
+// SetWithExpiry stores value for key until expiresAt, e.g. when warming the
+// cache from a persistent copy that is already partway through its TTL.
+func (c *LRU[K, V]) SetWithExpiry(key K, value V, expiresAt time.Time) {
+	if c.capacity <= 0 || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// TTL returns how long entries are kept.
+func (c *LRU[K, V]) TTL() time.Duration {
+	return c.ttl
+}
+
+func (c *LRU[K, V]) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLRU(capacity int, ttl time.Duration) (*LRU[string, int], *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU[string, int](capacity, ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestLRU_GetMissing(t *testing.T) {
+	c, _ := newTestLRU(2, time.Minute)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestLRU_SetAndGet(t *testing.T) {
+	c, _ := newTestLRU(2, time.Minute)
+	c.Set("a", 1)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestLRU_Expires(t *testing.T) {
+	c, now := newTestLRU(2, time.Minute)
+	c.Set("a", 1)
+
+	*now = now.Add(time.Minute)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestLRU(2, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+}
+
+func TestLRU_SetReplacesValue(t *testing.T) {
+	c, _ := newTestLRU(2, time.Minute)
+	c.Set("a", 1)
+	c.Set("a", 2)
+
+	v, _ := c.Get("a")
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestLRU_ZeroCapacityStoresNothing(t *testing.T) {
+	c, _ := newTestLRU(0, time.Minute)
+	c.Set("a", 1)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
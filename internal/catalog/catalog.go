@@ -0,0 +1,163 @@
+// Package catalog reads and writes catalog books in the bulk import and export
+// formats: CSV, JSON lines and ONIX-lite, a subset of ONIX for Books 3.0.
+//
+// All three formats carry the same fields: title, authors, publisher,
+// publication date, description, page count, ISBNs, cover URL and chapters.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+)
+
+const (
+	FormatCSV       = "csv"
+	FormatJSONLines = "jsonl"
+	FormatONIX      = "onix"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{FormatCSV, FormatJSONLines, FormatONIX}
+
+var (
+	ErrUnknownFormat = errors.New("format must be one of csv, jsonl or onix")
+	ErrEmpty         = errors.New("file has no books")
+)
+
+// Record is one book read from an import file. Row is the line of the record
+// in CSV and JSON lines files, and the position of the product in ONIX ones.
+// Err is set when the record could not be read; the other records are still
+// usable.
+type Record struct {
+	Row  int
+	Book store.Book
+	Err  error
+}
+
+// Read reads every record of an import file. It fails only when the file as a
+// whole cannot be read; problems with single records are reported in their
+// Err.
+func Read(format string, r io.Reader) ([]Record, error) {
+	var records []Record
+	var err error
+	switch format {
+	case FormatCSV:
+		records, err = readCSV(r)
+	case FormatJSONLines:
+		records, err = readJSONLines(r)
+	case FormatONIX:
+		records, err = readONIX(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrEmpty
+	}
+	return records, nil
+}
+
+// Writer writes books in one of the export formats. Close must be called
+// after the last book to complete the file.
+type Writer interface {
+	Write(book *store.Book) error
+	Close() error
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSONLines:
+		return newJSONLinesWriter(w), nil
+	case FormatONIX:
+		return newONIXWriter(w)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType returns the media type files of the format are served as.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONLines:
+		return "application/x-ndjson"
+	case FormatONIX:
+		return "application/xml"
+	}
+	return "application/octet-stream"
+}
+
+// Extension returns the file name extension of the format.
+func Extension(format string) string {
+	if format == FormatONIX {
+		return "xml"
+	}
+	return format
+}
+
+// FormatOf guesses the format of a file from its name.
+func FormatOf(filename string) (string, bool) {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV, true
+	case strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".ndjson"):
+		return FormatJSONLines, true
+	case strings.HasSuffix(name, ".xml"), strings.HasSuffix(name, ".onix"):
+		return FormatONIX, true
+	}
+	return "", false
+}
+
+// parseDate reads a publication date. Dates known only to the month or year,
+// common in catalog data, are taken as the first day of that month or year.
+func parseDate(s string, layouts ...string) (store.JSONDate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return store.JSONDate{}, nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return store.JSONDate(t), nil
+		}
+	}
+	return store.JSONDate{}, fmt.Errorf("invalid date %q", s)
+}
+
+// formatDate writes a publication date, or "" when the book has none.
+func formatDate(d store.JSONDate, layout string) string {
+	if d.ToTime().IsZero() {
+		return ""
+	}
+	return d.ToTime().Format(layout)
+}
+
+// numberChapters numbers chapters that were read without a number by their
+// position.
+func numberChapters(chapters []store.Chapter) {
+	for i := range chapters {
+		if chapters[i].Number == 0 {
+			chapters[i].Number = i + 1
+		}
+	}
+}
+
+// coverURL returns the largest of the book's cover images.
+func coverURL(images store.BookImages) string {
+	for _, url := range []*string{images.LargeUrl, images.MediumUrl, images.SmallUrl, images.ThumbnailUrl} {
+		if url != nil && *url != "" {
+			return *url
+		}
+	}
+	return ""
+}
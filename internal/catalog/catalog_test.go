@@ -0,0 +1,191 @@
+package catalog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func testBook() *store.Book {
+	cover := "https://covers.openlibrary.org/b/id/1-L.jpg"
+	return &store.Book{
+		ID:            7,
+		Title:         "The Hobbit",
+		Authors:       []string{"J.R.R. Tolkien", "Christopher Tolkien"},
+		Publisher:     "George Allen & Unwin",
+		PublishedDate: store.JSONDate(time.Date(1937, 9, 21, 0, 0, 0, 0, time.UTC)),
+		Description:   ptr("In a hole in the ground, \"there lived\" a hobbit; <b>really</b>."),
+		PageCount:     ptr(310),
+		ISBN13:        "9780261102217",
+		ISBN10:        ptr("0261102214"),
+		Images:        store.BookImages{ThumbnailUrl: &cover, SmallUrl: &cover, MediumUrl: &cover, LargeUrl: &cover},
+		Chapters: []store.Chapter{
+			{ID: 11, Number: 1, Title: "An Unexpected Party", StartPage: ptr(1), EndPage: ptr(24)},
+			{ID: 12, Number: 2, Title: "Roast Mutton", StartPage: ptr(25)},
+		},
+	}
+}
+
+func TestWriteAndReadRoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewWriter(format, &buf)
+			require.NoError(t, err)
+			require.NoError(t, writer.Write(testBook()))
+			require.NoError(t, writer.Write(&store.Book{ID: 8, Title: "Untitled draft", Authors: []string{}, ISBN13: "9780000000002"}))
+			require.NoError(t, writer.Close())
+
+			records, err := Read(format, &buf)
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			require.NoError(t, records[0].Err)
+			require.NoError(t, records[1].Err)
+
+			want := testBook()
+			want.ID = 0
+			for i := range want.Chapters {
+				want.Chapters[i].ID = 0
+			}
+			assert.Equal(t, *want, records[0].Book)
+
+			assert.Equal(t, "Untitled draft", records[1].Book.Title)
+			assert.True(t, records[1].Book.PublishedDate.ToTime().IsZero())
+			assert.Nil(t, records[1].Book.PageCount)
+			assert.Empty(t, records[1].Book.Chapters)
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "\uFEFFISBN_13,Title,Authors,Published_Date,Chapters,Extra\n" +
+		"978-0-261-10221-7,The Hobbit,J.R.R. Tolkien,1937,An Unexpected Party; Roast Mutton,x\n" +
+		",,,,,\n" +
+		"9780547928227,The Hobbit,Tolkien,September 1937,,\n" +
+		"\"9780547928227\",\"Multi\nline\",Tolkien,1937-09,,\n"
+
+	records, err := Read(FormatCSV, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	first := records[0]
+	require.NoError(t, first.Err)
+	assert.Equal(t, 2, first.Row)
+	assert.Equal(t, "978-0-261-10221-7", first.Book.ISBN13)
+	assert.Equal(t, []string{"J.R.R. Tolkien"}, first.Book.Authors)
+	assert.Equal(t, time.Date(1937, 1, 1, 0, 0, 0, 0, time.UTC), first.Book.PublishedDate.ToTime())
+	require.Len(t, first.Book.Chapters, 2)
+	assert.Equal(t, store.Chapter{Number: 2, Title: "Roast Mutton"}, first.Book.Chapters[1])
+
+	assert.Equal(t, 4, records[1].Row)
+	assert.ErrorContains(t, records[1].Err, "published_date")
+
+	assert.Equal(t, 5, records[2].Row)
+	require.NoError(t, records[2].Err)
+	assert.Equal(t, "Multi\nline", records[2].Book.Title)
+}
+
+func TestReadCSV_RequiresTitleColumn(t *testing.T) {
+	_, err := Read(FormatCSV, strings.NewReader("isbn_13,authors\n9780261102217,Tolkien\n"))
+	assert.ErrorContains(t, err, "title")
+
+	_, err = Read(FormatCSV, strings.NewReader("title,authors\n"))
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestReadJSONLines(t *testing.T) {
+	input := `{"id": 3, "title": "The Hobbit", "authors": ["Tolkien"], "isbn_13": "9780261102217", "published_date": "1937-09-21", "genres": []}` + "\n" +
+		"\n" +
+		`{"title": "Broken", ` + "\n" +
+		`{"title": "Bad date", "published_date": "21/09/1937"}`
+
+	records, err := Read(FormatJSONLines, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, "The Hobbit", records[0].Book.Title)
+	assert.Zero(t, records[0].Book.ID)
+
+	assert.Equal(t, 3, records[1].Row)
+	assert.ErrorContains(t, records[1].Err, "invalid JSON")
+
+	assert.Equal(t, 4, records[2].Row)
+	assert.ErrorContains(t, records[2].Err, "published_date")
+}
+
+func TestReadONIX(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">
+  <Header><Sender><SenderName>Publisher</SenderName></Sender></Header>
+  <Product>
+    <RecordReference>pub.1</RecordReference>
+    <NotificationType>03</NotificationType>
+    <ProductIdentifier><ProductIDType>03</ProductIDType><IDValue>9780261102217</IDValue></ProductIdentifier>
+    <DescriptiveDetail>
+      <TitleDetail>
+        <TitleType>01</TitleType>
+        <TitleElement>
+          <TitleElementLevel>01</TitleElementLevel>
+          <TitlePrefix>The</TitlePrefix><TitleWithoutPrefix>Hobbit</TitleWithoutPrefix>
+          <Subtitle>There and Back Again</Subtitle>
+        </TitleElement>
+      </TitleDetail>
+      <Contributor><SequenceNumber>2</SequenceNumber><ContributorRole>A12</ContributorRole><PersonName>Alan Lee</PersonName></Contributor>
+      <Contributor><SequenceNumber>1</SequenceNumber><ContributorRole>A01</ContributorRole><NamesBeforeKey>J.R.R.</NamesBeforeKey><KeyNames>Tolkien</KeyNames></Contributor>
+    </DescriptiveDetail>
+    <PublishingDetail>
+      <Imprint><ImprintName>Imprint</ImprintName></Imprint>
+      <Publisher><PublishingRole>01</PublishingRole><PublisherName>HarperCollins</PublisherName></Publisher>
+      <PublishingDate><PublishingDateRole>01</PublishingDateRole><Date>199107</Date></PublishingDate>
+    </PublishingDetail>
+  </Product>
+  <Product>
+    <DescriptiveDetail><Extent><ExtentType>00</ExtentType><ExtentValue>many</ExtentValue><ExtentUnit>03</ExtentUnit></Extent></DescriptiveDetail>
+  </Product>
+</ONIXMessage>`
+
+	records, err := Read(FormatONIX, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	book := records[0].Book
+	require.NoError(t, records[0].Err)
+	assert.Equal(t, "The Hobbit: There and Back Again", book.Title)
+	assert.Equal(t, []string{"J.R.R. Tolkien"}, book.Authors)
+	assert.Equal(t, "9780261102217", book.ISBN13)
+	assert.Equal(t, "HarperCollins", book.Publisher)
+	assert.Equal(t, time.Date(1991, 7, 1, 0, 0, 0, 0, time.UTC), book.PublishedDate.ToTime())
+
+	assert.Equal(t, 2, records[1].Row)
+	assert.ErrorContains(t, records[1].Err, "page_count")
+}
+
+func TestRead_RejectsUnreadableFiles(t *testing.T) {
+	_, err := Read(FormatONIX, strings.NewReader(`<ONIXMessage><Product>`))
+	assert.ErrorContains(t, err, "invalid XML")
+
+	_, err = Read(FormatONIX, strings.NewReader(`<feed></feed>`))
+	assert.Error(t, err)
+
+	_, err = Read("xlsx", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestFormatOf(t *testing.T) {
+	format, ok := FormatOf("Backup.NDJSON")
+	assert.True(t, ok)
+	assert.Equal(t, FormatJSONLines, format)
+
+	_, ok = FormatOf("books.xlsx")
+	assert.False(t, ok)
+}
@@ -0,0 +1,208 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+)
+
+// csvColumns are the columns of a CSV file, in the order they are exported.
+// Authors are separated by semicolons. Chapters are a JSON array of chapters
+// or, for hand-written files, chapter titles separated by semicolons.
+var csvColumns = []string{
+	"title", "authors", "publisher", "published_date", "description",
+	"page_count", "isbn_13", "isbn_10", "cover_url", "chapters",
+}
+
+// csvDateLayouts are the publication dates accepted in CSV files.
+var csvDateLayouts = []string{"2006-01-02", "2006-01", "2006", "2006/01/02"}
+
+// readCSV reads a CSV file with a header row. Columns are found by name, in
+// any order; unknown columns are ignored.
+func readCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New(`csv header must have a "title" column`)
+	}
+
+	records := []Record{}
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(fields) {
+			continue
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		book, err := csvBook(value)
+		records = append(records, Record{Row: line, Book: book, Err: err})
+	}
+	return records, nil
+}
+
+func csvBook(value func(column string) string) (store.Book, error) {
+	book := store.Book{
+		Title:     value("title"),
+		Authors:   splitList(value("authors")),
+		Publisher: value("publisher"),
+		ISBN13:    value("isbn_13"),
+	}
+	if description := value("description"); description != "" {
+		book.Description = &description
+	}
+	if isbn10 := value("isbn_10"); isbn10 != "" {
+		book.ISBN10 = &isbn10
+	}
+	if url := value("cover_url"); url != "" {
+		book.Images = store.BookImages{ThumbnailUrl: &url, SmallUrl: &url, MediumUrl: &url, LargeUrl: &url}
+	}
+
+	date, err := parseDate(value("published_date"), csvDateLayouts...)
+	if err != nil {
+		return book, fmt.Errorf("published_date: %w", err)
+	}
+	book.PublishedDate = date
+
+	if pages := value("page_count"); pages != "" {
+		n, err := strconv.Atoi(pages)
+		if err != nil {
+			return book, fmt.Errorf("page_count: invalid number %q", pages)
+		}
+		book.PageCount = &n
+	}
+
+	chapters, err := parseCSVChapters(value("chapters"))
+	if err != nil {
+		return book, fmt.Errorf("chapters: %w", err)
+	}
+	book.Chapters = chapters
+	return book, nil
+}
+
+func parseCSVChapters(s string) ([]store.Chapter, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var chapters []store.Chapter
+	if strings.HasPrefix(s, "[") {
+		if err := json.Unmarshal([]byte(s), &chapters); err != nil {
+			return nil, errors.New("invalid JSON")
+		}
+		for i := range chapters {
+			chapters[i].ID = 0
+		}
+	} else {
+		for _, title := range splitList(s) {
+			chapters = append(chapters, store.Chapter{Title: title})
+		}
+	}
+	numberChapters(chapters)
+	return chapters, nil
+}
+
+// splitList splits a semicolon-separated list, dropping empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isBlank(fields []string) bool {
+	for _, field := range fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (w *csvWriter) Write(book *store.Book) error {
+	chapters := ""
+	if len(book.Chapters) > 0 {
+		data, err := json.Marshal(exportChapters(book.Chapters))
+		if err != nil {
+			return err
+		}
+		chapters = string(data)
+	}
+
+	pageCount := ""
+	if book.PageCount != nil {
+		pageCount = strconv.Itoa(*book.PageCount)
+	}
+
+	return w.writer.Write([]string{
+		book.Title,
+		strings.Join(book.Authors, "; "),
+		book.Publisher,
+		formatDate(book.PublishedDate, "2006-01-02"),
+		deref(book.Description),
+		pageCount,
+		book.ISBN13,
+		deref(book.ISBN10),
+		coverURL(book.Images),
+		chapters,
+	})
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,142 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+)
+
+// jsonRecord is a book in a JSON lines file. Its fields are named like those
+// of store.Book, so books fetched from the API can be imported as they are;
+// fields that are not imported, such as ids, are ignored.
+type jsonRecord struct {
+	Title         string            `json:"title"`
+	Authors       []string          `json:"authors"`
+	Publisher     string            `json:"publisher,omitempty"`
+	PublishedDate string            `json:"published_date,omitempty"`
+	Description   *string           `json:"description,omitempty"`
+	PageCount     *int              `json:"page_count,omitempty"`
+	ISBN13        string            `json:"isbn_13,omitempty"`
+	ISBN10        *string           `json:"isbn_10,omitempty"`
+	Images        *store.BookImages `json:"book_images,omitempty"`
+	Chapters      []exportChapter   `json:"chapters,omitempty"`
+}
+
+// exportChapter is a chapter without the id, which only means something in
+// the catalog it was exported from.
+type exportChapter struct {
+	Number    int     `json:"number"`
+	Title     string  `json:"title"`
+	Part      *string `json:"part,omitempty"`
+	StartPage *int    `json:"start_page,omitempty"`
+	EndPage   *int    `json:"end_page,omitempty"`
+}
+
+func exportChapters(chapters []store.Chapter) []exportChapter {
+	exported := make([]exportChapter, len(chapters))
+	for i, ch := range chapters {
+		exported[i] = exportChapter{
+			Number:    ch.Number,
+			Title:     ch.Title,
+			Part:      ch.Part,
+			StartPage: ch.StartPage,
+			EndPage:   ch.EndPage,
+		}
+	}
+	return exported
+}
+
+// readJSONLines reads one JSON object per line. Blank lines are skipped.
+func readJSONLines(r io.Reader) ([]Record, error) {
+	reader := bufio.NewReader(r)
+	records := []Record{}
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			book, parseErr := jsonLineBook(data)
+			records = append(records, Record{Row: line, Book: book, Err: parseErr})
+		}
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+	}
+}
+
+func jsonLineBook(data []byte) (store.Book, error) {
+	var record jsonRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return store.Book{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	book := store.Book{
+		Title:       record.Title,
+		Authors:     record.Authors,
+		Publisher:   record.Publisher,
+		Description: record.Description,
+		PageCount:   record.PageCount,
+		ISBN13:      record.ISBN13,
+		ISBN10:      record.ISBN10,
+	}
+	if book.Authors == nil {
+		book.Authors = []string{}
+	}
+	if record.Images != nil {
+		book.Images = *record.Images
+	}
+	for _, ch := range record.Chapters {
+		book.Chapters = append(book.Chapters, store.Chapter{
+			Number:    ch.Number,
+			Title:     ch.Title,
+			Part:      ch.Part,
+			StartPage: ch.StartPage,
+			EndPage:   ch.EndPage,
+		})
+	}
+	numberChapters(book.Chapters)
+
+	date, err := parseDate(record.PublishedDate, "2006-01-02", "2006-01", "2006")
+	if err != nil {
+		return book, fmt.Errorf("published_date: %w", err)
+	}
+	book.PublishedDate = date
+	return book, nil
+}
+
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func newJSONLinesWriter(w io.Writer) *jsonLinesWriter {
+	return &jsonLinesWriter{encoder: json.NewEncoder(w)}
+}
+
+func (w *jsonLinesWriter) Write(book *store.Book) error {
+	record := jsonRecord{
+		Title:         book.Title,
+		Authors:       book.Authors,
+		Publisher:     book.Publisher,
+		PublishedDate: formatDate(book.PublishedDate, "2006-01-02"),
+		Description:   book.Description,
+		PageCount:     book.PageCount,
+		ISBN13:        book.ISBN13,
+		ISBN10:        book.ISBN10,
+		Chapters:      exportChapters(book.Chapters),
+	}
+	if coverURL(book.Images) != "" {
+		record.Images = &book.Images
+	}
+	// Encode ends each book with a newline.
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return nil
+}
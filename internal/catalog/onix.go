@@ -0,0 +1,435 @@
+package catalog
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+)
+
+// ONIX code list values used by ONIX-lite.
+const (
+	onixISBN10           = "02"  // List 5: ISBN-10
+	onixGTIN13           = "03"  // List 5: GTIN-13
+	onixISBN13           = "15"  // List 5: ISBN-13
+	onixDistinctiveTitle = "01"  // List 15: distinctive title
+	onixProductLevel     = "01"  // List 149: product
+	onixAuthor           = "A01" // List 17: by (author)
+	onixMainContent      = "00"  // List 23: main content page count
+	onixPages            = "03"  // List 24: pages
+	onixDescription      = "03"  // List 153: description
+	onixFrontCover       = "01"  // List 158: front cover
+	onixMainPublisher    = "01"  // List 45: publisher
+	onixPublicationDate  = "01"  // List 163: publication date
+	onixBodyMatter       = "03"  // List 42: body matter
+)
+
+// onixProduct is the part of an ONIX 3.0 <Product> that ONIX-lite reads and
+// writes, in reference tag names. Chapters are content items; their parts
+// are not carried.
+type onixProduct struct {
+	XMLName            xml.Name                `xml:"Product"`
+	RecordReference    string                  `xml:"RecordReference"`
+	NotificationType   string                  `xml:"NotificationType"`
+	ProductIdentifiers []onixProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  struct {
+		ProductComposition string            `xml:"ProductComposition"`
+		ProductForm        string            `xml:"ProductForm"`
+		TitleDetails       []onixTitleDetail `xml:"TitleDetail"`
+		Contributors       []onixContributor `xml:"Contributor"`
+		Extents            []onixExtent      `xml:"Extent"`
+	} `xml:"DescriptiveDetail"`
+	CollateralDetail *onixCollateralDetail `xml:"CollateralDetail,omitempty"`
+	ContentDetail    *onixContentDetail    `xml:"ContentDetail,omitempty"`
+	PublishingDetail struct {
+		Publishers      []onixPublisher      `xml:"Publisher"`
+		PublishingDates []onixPublishingDate `xml:"PublishingDate"`
+	} `xml:"PublishingDetail"`
+}
+
+type onixProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type onixTitleDetail struct {
+	TitleType     string             `xml:"TitleType"`
+	TitleElements []onixTitleElement `xml:"TitleElement"`
+}
+
+type onixTitleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	TitleText          string `xml:"TitleText,omitempty"`
+	TitlePrefix        string `xml:"TitlePrefix,omitempty"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix,omitempty"`
+	Subtitle           string `xml:"Subtitle,omitempty"`
+}
+
+func (t onixTitleElement) title() string {
+	title := strings.TrimSpace(t.TitleText)
+	if title == "" {
+		title = strings.TrimSpace(t.TitlePrefix + " " + t.TitleWithoutPrefix)
+	}
+	if subtitle := strings.TrimSpace(t.Subtitle); subtitle != "" && title != "" {
+		title += ": " + subtitle
+	}
+	return title
+}
+
+type onixContributor struct {
+	SequenceNumber  int    `xml:"SequenceNumber,omitempty"`
+	ContributorRole string `xml:"ContributorRole"`
+	PersonName      string `xml:"PersonName,omitempty"`
+	NamesBeforeKey  string `xml:"NamesBeforeKey,omitempty"`
+	KeyNames        string `xml:"KeyNames,omitempty"`
+	CorporateName   string `xml:"CorporateName,omitempty"`
+}
+
+func (c onixContributor) name() string {
+	for _, name := range []string{c.PersonName, strings.TrimSpace(c.NamesBeforeKey + " " + c.KeyNames), c.CorporateName} {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+type onixExtent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue string `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+type onixCollateralDetail struct {
+	TextContents        []onixTextContent        `xml:"TextContent"`
+	SupportingResources []onixSupportingResource `xml:"SupportingResource"`
+}
+
+type onixTextContent struct {
+	TextType        string `xml:"TextType"`
+	ContentAudience string `xml:"ContentAudience"`
+	Text            string `xml:"Text"`
+}
+
+type onixSupportingResource struct {
+	ResourceContentType string                `xml:"ResourceContentType"`
+	ContentAudience     string                `xml:"ContentAudience"`
+	ResourceMode        string                `xml:"ResourceMode"`
+	ResourceVersions    []onixResourceVersion `xml:"ResourceVersion"`
+}
+
+type onixResourceVersion struct {
+	ResourceForm string `xml:"ResourceForm"`
+	ResourceLink string `xml:"ResourceLink"`
+}
+
+type onixContentDetail struct {
+	ContentItems []onixContentItem `xml:"ContentItem"`
+}
+
+type onixContentItem struct {
+	LevelSequenceNumber string            `xml:"LevelSequenceNumber,omitempty"`
+	TextItem            *onixTextItem     `xml:"TextItem,omitempty"`
+	ComponentTypeName   string            `xml:"ComponentTypeName,omitempty"`
+	ComponentNumber     string            `xml:"ComponentNumber,omitempty"`
+	TitleDetails        []onixTitleDetail `xml:"TitleDetail"`
+}
+
+type onixTextItem struct {
+	TextItemType string       `xml:"TextItemType"`
+	PageRun      *onixPageRun `xml:"PageRun,omitempty"`
+}
+
+type onixPageRun struct {
+	FirstPageNumber string `xml:"FirstPageNumber"`
+	LastPageNumber  string `xml:"LastPageNumber,omitempty"`
+}
+
+type onixPublisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+type onixPublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               string `xml:"Date"`
+}
+
+// readONIX reads the <Product> elements of an ONIX 3.0 message with reference
+// tag names. Elements ONIX-lite does not know are skipped.
+func readONIX(r io.Reader) ([]Record, error) {
+	decoder := xml.NewDecoder(r)
+	records := []Record{}
+	sawMessage := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "ONIXMessage":
+			sawMessage = true
+		case "Product":
+			var product onixProduct
+			if err := decoder.DecodeElement(&product, &start); err != nil {
+				return nil, fmt.Errorf("invalid XML: %w", err)
+			}
+			book, err := product.book()
+			records = append(records, Record{Row: len(records) + 1, Book: book, Err: err})
+		}
+	}
+	if !sawMessage {
+		return nil, errors.New("file is not an ONIX message")
+	}
+	return records, nil
+}
+
+func (p *onixProduct) book() (store.Book, error) {
+	book := store.Book{Authors: []string{}}
+
+	for _, id := range p.ProductIdentifiers {
+		value := strings.TrimSpace(id.IDValue)
+		switch id.ProductIDType {
+		case onixISBN13:
+			book.ISBN13 = value
+		case onixGTIN13:
+			if book.ISBN13 == "" && strings.HasPrefix(value, "97") {
+				book.ISBN13 = value
+			}
+		case onixISBN10:
+			book.ISBN10 = &value
+		}
+	}
+
+	detail := p.DescriptiveDetail
+	book.Title = productTitle(detail.TitleDetails, onixProductLevel)
+
+	contributors := detail.Contributors
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return contributors[i].SequenceNumber < contributors[j].SequenceNumber
+	})
+	for _, c := range contributors {
+		if name := c.name(); c.ContributorRole == onixAuthor && name != "" {
+			book.Authors = append(book.Authors, name)
+		}
+	}
+
+	for _, publisher := range p.PublishingDetail.Publishers {
+		if publisher.PublishingRole == onixMainPublisher || book.Publisher == "" {
+			book.Publisher = strings.TrimSpace(publisher.PublisherName)
+		}
+	}
+
+	if collateral := p.CollateralDetail; collateral != nil {
+		for _, text := range collateral.TextContents {
+			if description := strings.TrimSpace(text.Text); text.TextType == onixDescription && description != "" {
+				book.Description = &description
+			}
+		}
+		for _, resource := range collateral.SupportingResources {
+			if resource.ResourceContentType != onixFrontCover {
+				continue
+			}
+			for _, version := range resource.ResourceVersions {
+				if url := strings.TrimSpace(version.ResourceLink); url != "" {
+					book.Images = store.BookImages{ThumbnailUrl: &url, SmallUrl: &url, MediumUrl: &url, LargeUrl: &url}
+				}
+			}
+		}
+	}
+
+	if content := p.ContentDetail; content != nil {
+		for _, item := range content.ContentItems {
+			chapter, err := item.chapter()
+			if err != nil {
+				return book, fmt.Errorf("chapters: %w", err)
+			}
+			book.Chapters = append(book.Chapters, chapter)
+		}
+		numberChapters(book.Chapters)
+	}
+
+	for _, extent := range detail.Extents {
+		if extent.ExtentType == onixMainContent && (extent.ExtentUnit == onixPages || extent.ExtentUnit == "") {
+			pages, err := strconv.Atoi(strings.TrimSpace(extent.ExtentValue))
+			if err != nil {
+				return book, fmt.Errorf("page_count: invalid number %q", extent.ExtentValue)
+			}
+			book.PageCount = &pages
+		}
+	}
+
+	for _, date := range p.PublishingDetail.PublishingDates {
+		if date.PublishingDateRole != onixPublicationDate {
+			continue
+		}
+		published, err := parseDate(date.Date, "20060102", "200601", "2006")
+		if err != nil {
+			return book, fmt.Errorf("published_date: %w", err)
+		}
+		book.PublishedDate = published
+	}
+	return book, nil
+}
+
+func productTitle(details []onixTitleDetail, level string) string {
+	for _, detail := range details {
+		if detail.TitleType != onixDistinctiveTitle {
+			continue
+		}
+		for _, element := range detail.TitleElements {
+			if element.TitleElementLevel == level || element.TitleElementLevel == "" {
+				return element.title()
+			}
+		}
+	}
+	return ""
+}
+
+func (item onixContentItem) chapter() (store.Chapter, error) {
+	chapter := store.Chapter{}
+	for _, detail := range item.TitleDetails {
+		for _, element := range detail.TitleElements {
+			if chapter.Title == "" {
+				chapter.Title = element.title()
+			}
+		}
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(item.ComponentNumber)); err == nil {
+		chapter.Number = n
+	}
+	if item.TextItem == nil || item.TextItem.PageRun == nil {
+		return chapter, nil
+	}
+
+	run := item.TextItem.PageRun
+	first, err := strconv.Atoi(strings.TrimSpace(run.FirstPageNumber))
+	if err != nil {
+		return chapter, fmt.Errorf("invalid page number %q", run.FirstPageNumber)
+	}
+	chapter.StartPage = &first
+	if strings.TrimSpace(run.LastPageNumber) != "" {
+		last, err := strconv.Atoi(strings.TrimSpace(run.LastPageNumber))
+		if err != nil {
+			return chapter, fmt.Errorf("invalid page number %q", run.LastPageNumber)
+		}
+		chapter.EndPage = &last
+	}
+	return chapter, nil
+}
+
+type onixWriter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+}
+
+func newONIXWriter(w io.Writer) (*onixWriter, error) {
+	header := xml.Header +
+		`<ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">` + "\n" +
+		"  <Header>\n" +
+		"    <Sender><SenderName>BookClubApp</SenderName></Sender>\n" +
+		"    <SentDateTime>" + time.Now().UTC().Format("20060102T1504Z") + "</SentDateTime>\n" +
+		"  </Header>\n"
+	if _, err := io.WriteString(w, header); err != nil {
+		return nil, err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("  ", "  ")
+	return &onixWriter{w: w, encoder: encoder}, nil
+}
+
+func (w *onixWriter) Write(book *store.Book) error {
+	product := onixProduct{
+		RecordReference:  fmt.Sprintf("bookclubapp.book.%d", book.ID),
+		NotificationType: "03",
+	}
+	if book.ISBN13 != "" {
+		product.ProductIdentifiers = append(product.ProductIdentifiers, onixProductIdentifier{ProductIDType: onixISBN13, IDValue: book.ISBN13})
+	}
+	if book.ISBN10 != nil && *book.ISBN10 != "" {
+		product.ProductIdentifiers = append(product.ProductIdentifiers, onixProductIdentifier{ProductIDType: onixISBN10, IDValue: *book.ISBN10})
+	}
+
+	detail := &product.DescriptiveDetail
+	detail.ProductComposition = "00"
+	detail.ProductForm = "BA"
+	detail.TitleDetails = []onixTitleDetail{{
+		TitleType:     onixDistinctiveTitle,
+		TitleElements: []onixTitleElement{{TitleElementLevel: onixProductLevel, TitleText: book.Title}},
+	}}
+	for i, author := range book.Authors {
+		detail.Contributors = append(detail.Contributors, onixContributor{SequenceNumber: i + 1, ContributorRole: onixAuthor, PersonName: author})
+	}
+	if book.PageCount != nil {
+		detail.Extents = []onixExtent{{ExtentType: onixMainContent, ExtentValue: strconv.Itoa(*book.PageCount), ExtentUnit: onixPages}}
+	}
+
+	collateral := &onixCollateralDetail{}
+	if book.Description != nil && *book.Description != "" {
+		collateral.TextContents = []onixTextContent{{TextType: onixDescription, ContentAudience: "00", Text: *book.Description}}
+	}
+	if url := coverURL(book.Images); url != "" {
+		resource := onixSupportingResource{ResourceContentType: onixFrontCover, ContentAudience: "00", ResourceMode: "03"}
+		resource.ResourceVersions = []onixResourceVersion{{ResourceForm: "02", ResourceLink: url}}
+		collateral.SupportingResources = []onixSupportingResource{resource}
+	}
+	if len(collateral.TextContents) > 0 || len(collateral.SupportingResources) > 0 {
+		product.CollateralDetail = collateral
+	}
+
+	if len(book.Chapters) > 0 {
+		product.ContentDetail = &onixContentDetail{}
+		for _, ch := range book.Chapters {
+			product.ContentDetail.ContentItems = append(product.ContentDetail.ContentItems, chapterContentItem(ch))
+		}
+	}
+
+	product.PublishingDetail.Publishers = []onixPublisher{{PublishingRole: onixMainPublisher, PublisherName: book.Publisher}}
+	if date := formatDate(book.PublishedDate, "20060102"); date != "" {
+		product.PublishingDetail.PublishingDates = []onixPublishingDate{{PublishingDateRole: onixPublicationDate, Date: date}}
+	}
+
+	if err := w.encoder.Encode(product); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+func chapterContentItem(ch store.Chapter) onixContentItem {
+	item := onixContentItem{
+		LevelSequenceNumber: strconv.Itoa(ch.Number),
+		ComponentTypeName:   "Chapter",
+		ComponentNumber:     strconv.Itoa(ch.Number),
+		TitleDetails: []onixTitleDetail{{
+			TitleType:     onixDistinctiveTitle,
+			TitleElements: []onixTitleElement{{TitleElementLevel: "04", TitleText: ch.Title}},
+		}},
+	}
+	if ch.StartPage != nil {
+		run := &onixPageRun{FirstPageNumber: strconv.Itoa(*ch.StartPage)}
+		if ch.EndPage != nil {
+			run.LastPageNumber = strconv.Itoa(*ch.EndPage)
+		}
+		item.TextItem = &onixTextItem{TextItemType: onixBodyMatter, PageRun: run}
+	}
+	return item
+}
+
+func (w *onixWriter) Close() error {
+	_, err := io.WriteString(w.w, "</ONIXMessage>\n")
+	return err
+}
@@ -0,0 +1,232 @@
+// Package covers turns uploaded cover images into the sized variants stored in
+// book_images.
+package covers
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder
+	"slices"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the WebP decoder
+)
+
+var (
+	// ErrUnsupportedFormat is returned for anything but a JPEG, PNG or WebP
+	// image.
+	ErrUnsupportedFormat = errors.New("cover must be a JPEG, PNG or WebP image")
+	// ErrTooLarge is returned for images with more pixels than we are willing
+	// to decode.
+	ErrTooLarge = errors.New("cover image dimensions are too large")
+)
+
+const (
+	// MaxBytes is the largest upload accepted.
+	MaxBytes = 10 << 20
+	// maxPixels bounds the decoded size, so a small file cannot claim huge
+	// dimensions and exhaust memory.
+	maxPixels = 40_000_000
+
+	// ContentType is the type every variant is encoded as.
+	ContentType = "image/jpeg"
+	jpegQuality = 85
+)
+
+// Variant is one of the sizes a cover is stored in. Images are scaled down to
+// fit in MaxWidth by twice that height, keeping their aspect ratio, and are
+// never scaled up.
+type Variant struct {
+	Name     string
+	MaxWidth int
+}
+
+// Variants match the columns of book_images.
+var Variants = []Variant{
+	{Name: "thumbnail", MaxWidth: 128},
+	{Name: "small", MaxWidth: 256},
+	{Name: "medium", MaxWidth: 512},
+	{Name: "large", MaxWidth: 1024},
+}
+
+// Image is an encoded variant of a cover.
+type Image struct {
+	Variant string
+	Width   int
+	Height  int
+	Data    []byte
+}
+
+// Process decodes an uploaded cover and encodes each variant as a JPEG. Only
+// the pixels are carried over, so EXIF and other metadata are dropped; a JPEG's
+// EXIF orientation is applied to the pixels first so the cover stays upright.
+func Process(data []byte) ([]Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, err
+	}
+	if !slices.Contains([]string{"jpeg", "png", "webp"}, format) {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, errors.New("cover image is empty")
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if format == "jpeg" {
+		src = orient(src, exifOrientation(data))
+	}
+
+	images := make([]Image, 0, len(Variants))
+	for _, variant := range Variants {
+		resized := resize(src, variant.MaxWidth, 2*variant.MaxWidth)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		images = append(images, Image{
+			Variant: variant.Name,
+			Width:   resized.Bounds().Dx(),
+			Height:  resized.Bounds().Dy(),
+			Data:    buf.Bytes(),
+		})
+	}
+	return images, nil
+}
+
+// resize scales src down to fit in maxWidth by maxHeight. Transparent areas
+// are flattened onto white, since JPEG has no alpha channel.
+func resize(src image.Image, maxWidth, maxHeight int) image.Image {
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	if width > maxWidth {
+		height = max(1, height*maxWidth/width)
+		width = maxWidth
+	}
+	if height > maxHeight {
+		width = max(1, width*maxHeight/height)
+		height = maxHeight
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// orient applies an EXIF orientation (1 to 8) to the image.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// exifOrientation reads the orientation tag from a JPEG's EXIF data. It
+// returns 1, the default, when there is none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// Start of scan: the image data follows, so there is no EXIF.
+		if marker == 0xDA {
+			return 1
+		}
+		length := int(data[i+2])<<8 | int(data[i+3])
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation finds the orientation tag (0x0112) in the first IFD of the
+// TIFF structure inside an EXIF segment.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var u16 func([]byte) int
+	var u32 func([]byte) int
+	switch string(tiff[:2]) {
+	case "II":
+		u16 = func(b []byte) int { return int(b[0]) | int(b[1])<<8 }
+		u32 = func(b []byte) int { return u16(b) | u16(b[2:])<<16 }
+	case "MM":
+		u16 = func(b []byte) int { return int(b[0])<<8 | int(b[1]) }
+		u32 = func(b []byte) int { return u16(b)<<16 | u16(b[2:]) }
+	default:
+		return 1
+	}
+
+	offset := u32(tiff[4:])
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	entries := u16(tiff[offset:])
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if u16(tiff[entry:]) == 0x0112 {
+			return u16(tiff[entry+8:])
+		}
+	}
+	return 1
+}
@@ -0,0 +1,112 @@
+package covers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF segment with the given orientation after the
+// JPEG's start of image marker.
+func withOrientation(jpegData []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // big endian, IFD0 at offset 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // orientation, SHORT
+		0, 0, 0, 0, // no next IFD
+	}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	length := len(segment) + 2
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, 0xFF, 0xE1, byte(length>>8), byte(length))
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestProcess_PNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(600, 900)))
+
+	images, err := Process(buf.Bytes())
+
+	require.NoError(t, err)
+	require.Len(t, images, 4)
+	sizes := [][2]int{}
+	for _, img := range images {
+		sizes = append(sizes, [2]int{img.Width, img.Height})
+
+		decoded, format, err := image.Decode(bytes.NewReader(img.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, img.Width, decoded.Bounds().Dx())
+	}
+	// The large variant is not scaled up past the original.
+	assert.Equal(t, [][2]int{{128, 192}, {256, 384}, {512, 768}, {600, 900}}, sizes)
+	assert.Equal(t, "thumbnail", images[0].Variant)
+}
+
+func TestProcess_TallImageFitsHeight(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(100, 1000)))
+
+	images, err := Process(buf.Bytes())
+
+	require.NoError(t, err)
+	assert.Equal(t, 25, images[0].Width)
+	assert.Equal(t, 256, images[0].Height)
+}
+
+func TestProcess_AppliesOrientationAndDropsEXIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(40, 20), nil))
+	data := withOrientation(buf.Bytes(), 6)
+	require.Equal(t, 6, exifOrientation(data))
+
+	images, err := Process(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, 20, images[0].Width)
+	assert.Equal(t, 40, images[0].Height)
+	assert.NotContains(t, string(images[0].Data), "Exif")
+}
+
+func TestProcess_UnsupportedFormat(t *testing.T) {
+	_, err := Process([]byte("GIF89a not really an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = Process([]byte("<svg xmlns='http://www.w3.org/2000/svg'/>"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	src.Set(1, 0, color.RGBA{B: 255, A: 255})
+
+	rotated := orient(src, 6)
+	assert.Equal(t, image.Rect(0, 0, 1, 2), rotated.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, rotated.At(0, 0))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, rotated.At(0, 1))
+
+	mirrored := orient(src, 2)
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, mirrored.At(0, 0))
+
+	assert.Same(t, src, orient(src, 1))
+}
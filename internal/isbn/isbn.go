@@ -0,0 +1,130 @@
+// Package isbn validates and converts ISBN-10 and ISBN-13 book numbers.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidLength    = errors.New("isbn must have 10 or 13 digits")
+	ErrInvalidCharacter = errors.New("isbn contains invalid characters")
+	ErrInvalidChecksum  = errors.New("isbn check digit is wrong")
+	// ErrNoISBN10 is returned when converting an ISBN-13 outside the 978
+	// prefix, which has no ISBN-10 form.
+	ErrNoISBN10 = errors.New("isbn-13 has no isbn-10 equivalent")
+)
+
+// Normalize strips hyphens and spaces and upper-cases a trailing x.
+func Normalize(s string) string {
+	s = strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s))
+	return strings.ToUpper(s)
+}
+
+// ValidateISBN10 checks a normalized ISBN-10, including its check digit.
+func ValidateISBN10(s string) error {
+	if len(s) != 10 {
+		return ErrInvalidLength
+	}
+	for i := 0; i < 9; i++ {
+		if !isDigit(s[i]) {
+			return ErrInvalidCharacter
+		}
+	}
+	if !isDigit(s[9]) && s[9] != 'X' {
+		return ErrInvalidCharacter
+	}
+	if s[9] != checkDigit10(s[:9]) {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// ValidateISBN13 checks a normalized ISBN-13, including its check digit.
+func ValidateISBN13(s string) error {
+	if len(s) != 13 {
+		return ErrInvalidLength
+	}
+	for i := 0; i < 13; i++ {
+		if !isDigit(s[i]) {
+			return ErrInvalidCharacter
+		}
+	}
+	if s[12] != checkDigit13(s[:12]) {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// ToISBN13 normalizes s, which may be an ISBN-10 or ISBN-13, and returns it
+// as an ISBN-13.
+func ToISBN13(s string) (string, error) {
+	s = Normalize(s)
+	switch len(s) {
+	case 13:
+		if err := ValidateISBN13(s); err != nil {
+			return "", err
+		}
+		return s, nil
+	case 10:
+		if err := ValidateISBN10(s); err != nil {
+			return "", err
+		}
+		prefix := "978" + s[:9]
+		return prefix + string(checkDigit13(prefix)), nil
+	default:
+		return "", ErrInvalidLength
+	}
+}
+
+// ToISBN10 normalizes s, which may be an ISBN-10 or ISBN-13, and returns it
+// as an ISBN-10.
+func ToISBN10(s string) (string, error) {
+	s = Normalize(s)
+	switch len(s) {
+	case 10:
+		if err := ValidateISBN10(s); err != nil {
+			return "", err
+		}
+		return s, nil
+	case 13:
+		if err := ValidateISBN13(s); err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(s, "978") {
+			return "", ErrNoISBN10
+		}
+		body := s[3:12]
+		return body + string(checkDigit10(body)), nil
+	default:
+		return "", ErrInvalidLength
+	}
+}
+
+func checkDigit10(first9 string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(first9[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+func checkDigit13(first12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(first12[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,50 @@
+package isbn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "9780261102217", Normalize(" 978-0-261 10221-7 "))
+	assert.Equal(t, "080442957X", Normalize("0-8044-2957-x"))
+}
+
+func TestValidateISBN10(t *testing.T) {
+	assert.NoError(t, ValidateISBN10("0261102214"))
+	assert.NoError(t, ValidateISBN10("080442957X"))
+	assert.ErrorIs(t, ValidateISBN10("0261102215"), ErrInvalidChecksum)
+	assert.ErrorIs(t, ValidateISBN10("02611X2214"), ErrInvalidCharacter)
+	assert.ErrorIs(t, ValidateISBN10("026110221"), ErrInvalidLength)
+}
+
+func TestValidateISBN13(t *testing.T) {
+	assert.NoError(t, ValidateISBN13("9780261102217"))
+	assert.ErrorIs(t, ValidateISBN13("9780261102218"), ErrInvalidChecksum)
+	assert.ErrorIs(t, ValidateISBN13("978026110221X"), ErrInvalidCharacter)
+	assert.ErrorIs(t, ValidateISBN13("TEST"), ErrInvalidLength)
+}
+
+func TestToISBN13(t *testing.T) {
+	isbn13, err := ToISBN13("0-261-10221-4")
+	require.NoError(t, err)
+	assert.Equal(t, "9780261102217", isbn13)
+
+	isbn13, err = ToISBN13("080442957X")
+	require.NoError(t, err)
+	assert.Equal(t, "9780804429573", isbn13)
+
+	_, err = ToISBN13("0261102215")
+	assert.ErrorIs(t, err, ErrInvalidChecksum)
+}
+
+func TestToISBN10(t *testing.T) {
+	isbn10, err := ToISBN10("978-0-8044-2957-3")
+	require.NoError(t, err)
+	assert.Equal(t, "080442957X", isbn10)
+
+	_, err = ToISBN10("9791032305690")
+	assert.ErrorIs(t, err, ErrNoISBN10)
+}
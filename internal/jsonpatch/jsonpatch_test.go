@@ -0,0 +1,119 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const book = `{"title":"The Hobbit","authors":["J.R.R. Tolkien"],"description":"Old","book_images":{"small_url":"s","large_url":"l"}}`
+
+func TestMergePatch(t *testing.T) {
+	patched, err := MergePatch([]byte(book), []byte(`{"title":"The Hobbit, or There and Back Again","description":null,"book_images":{"small_url":null}}`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"title":"The Hobbit, or There and Back Again","authors":["J.R.R. Tolkien"],"book_images":{"large_url":"l"}}`, string(patched))
+}
+
+func TestMergePatch_ReplacesArrays(t *testing.T) {
+	patched, err := MergePatch([]byte(book), []byte(`{"authors":["Tolkien"]}`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `["Tolkien"]`, string(mustGet(t, patched, "authors")))
+}
+
+func TestMergePatch_InvalidPatch(t *testing.T) {
+	_, err := MergePatch([]byte(book), []byte(`{`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestApply(t *testing.T) {
+	patched, err := Apply([]byte(book), []byte(`[
+		{"op":"test","path":"/title","value":"The Hobbit"},
+		{"op":"replace","path":"/title","value":"The Hobbit, or There and Back Again"},
+		{"op":"add","path":"/authors/-","value":"Christopher Tolkien"},
+		{"op":"add","path":"/authors/0","value":"Anonymous"},
+		{"op":"remove","path":"/description"},
+		{"op":"copy","from":"/book_images/small_url","path":"/book_images/thumbnail_url"},
+		{"op":"move","from":"/book_images/large_url","path":"/book_images/medium_url"}
+	]`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"title":"The Hobbit, or There and Back Again",
+		"authors":["Anonymous","J.R.R. Tolkien","Christopher Tolkien"],
+		"book_images":{"small_url":"s","thumbnail_url":"s","medium_url":"l"}
+	}`, string(patched))
+}
+
+func TestApply_ReplaceArrayItem(t *testing.T) {
+	patched, err := Apply([]byte(book), []byte(`[{"op":"replace","path":"/authors/0","value":"Tolkien"}]`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `["Tolkien"]`, string(mustGet(t, patched, "authors")))
+}
+
+func TestApply_EscapedPointer(t *testing.T) {
+	patched, err := Apply([]byte(`{"a/b":{"c~d":1}}`), []byte(`[{"op":"replace","path":"/a~1b/c~0d","value":2}]`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"a/b":{"c~d":2}}`, string(patched))
+}
+
+func TestApply_NullValue(t *testing.T) {
+	patched, err := Apply([]byte(book), []byte(`[
+		{"op":"test","path":"/book_images/small_url","value":"s"},
+		{"op":"replace","path":"/description","value":null},
+		{"op":"add","path":"/subtitle","value":null}
+	]`))
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(patched, &doc))
+	assert.Contains(t, doc, "description")
+	assert.Nil(t, doc["description"])
+	assert.Contains(t, doc, "subtitle")
+}
+
+func TestApply_Errors(t *testing.T) {
+	tests := map[string]struct {
+		patch string
+		err   error
+	}{
+		"failed test":        {`[{"op":"test","path":"/title","value":"Dune"}]`, ErrTestFailed},
+		"missing member":     {`[{"op":"replace","path":"/subtitle","value":"x"}]`, ErrPathNotFound},
+		"index out of range": {`[{"op":"remove","path":"/authors/3"}]`, ErrPathNotFound},
+		"bad index":          {`[{"op":"add","path":"/authors/01","value":"x"}]`, ErrInvalidPath},
+		"relative path":      {`[{"op":"remove","path":"title"}]`, ErrInvalidPath},
+		"unknown op":         {`[{"op":"rename","path":"/title"}]`, ErrInvalidPatch},
+		"missing value":      {`[{"op":"add","path":"/title"}]`, ErrInvalidPatch},
+		"not a list":         {`{"op":"remove","path":"/title"}`, ErrInvalidPatch},
+		"move into itself":   {`[{"op":"move","from":"/book_images","path":"/book_images/small"}]`, ErrInvalidPath},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Apply([]byte(book), []byte(tt.patch))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestApply_FailedPatchLeavesNoPartialResult(t *testing.T) {
+	patched, err := Apply([]byte(book), []byte(`[
+		{"op":"replace","path":"/title","value":"Dune"},
+		{"op":"test","path":"/description","value":"New"}
+	]`))
+
+	assert.ErrorIs(t, err, ErrTestFailed)
+	assert.Nil(t, patched)
+}
+
+func mustGet(t *testing.T, doc []byte, member string) []byte {
+	t.Helper()
+	var members map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(doc, &members))
+	return members[member]
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// imageURLFields are the JSON fields holding cover URLs: those of
+// store.BookImages and of Google Books' imageLinks.
+var imageURLFields = map[string]bool{
+	"thumbnail_url":  true,
+	"small_url":      true,
+	"medium_url":     true,
+	"large_url":      true,
+	"smallThumbnail": true,
+	"thumbnail":      true,
+	"small":          true,
+	"medium":         true,
+	"large":          true,
+	"extraLarge":     true,
+}
+
+// maxImageURLBodyBytes bounds the JSON request bodies RewriteImageURLs reads
+// into memory.
+const maxImageURLBodyBytes = 1 << 20
+
+// RewriteImageURLs points the cover URLs in JSON responses at the image proxy.
+// URLs the proxy does not accept, such as uploaded covers, are left as they
+// are. Proxy URLs sent back in JSON requests are turned into the original URLs
+// again, so stored data never refers to the proxy. Only the image URL fields
+// are changed; the rest of each document is passed on byte for byte. It is
+// meant for the routes that return or accept book images.
+func RewriteImageURLs(proxy store.ImageProxy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body != nil && strings.HasSuffix(c.ContentType(), "json") {
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImageURLBodyBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body must be at most 1 MB"})
+				} else {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "could not read request body"})
+				}
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(restoreImageURLs(body, proxy)))
+		}
+
+		writer := &jsonBufferWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+
+		c.Next()
+
+		if writer.body.Len() == 0 {
+			return
+		}
+		body := rewriteImageURLs(writer.body.Bytes(), proxy)
+		if _, err := writer.ResponseWriter.Write(body); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// jsonBufferWriter holds back JSON bodies so they can be rewritten once the
+// handler is done. Other responses, such as images, are written through.
+type jsonBufferWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *jsonBufferWriter) buffering() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+func (w *jsonBufferWriter) Write(data []byte) (int, error) {
+	if w.buffering() {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *jsonBufferWriter) WriteString(s string) (int, error) {
+	if w.buffering() {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// rewriteImageURLs returns body with its image URLs replaced by proxy URLs.
+// The body is returned unchanged when there is nothing to replace.
+func rewriteImageURLs(body []byte, proxy store.ImageProxy) []byte {
+	if !bytes.Contains(body, []byte("http")) {
+		return body
+	}
+	return replaceImageURLs(body, proxy.ProxyURL)
+}
+
+// restoreImageURLs replaces the proxy URLs in a request body with the URLs of
+// the images they serve.
+func restoreImageURLs(body []byte, proxy store.ImageProxy) []byte {
+	if !bytes.Contains(body, []byte("?url=")) {
+		return body
+	}
+	return replaceImageURLs(body, proxy.SourceURL)
+}
+
+// replaceImageURLs replaces each image URL in body for which replace returns
+// true, leaving every other byte of the document as it is. Bodies that are not
+// valid JSON are returned unchanged.
+func replaceImageURLs(body []byte, replace func(string) (string, bool)) []byte {
+	urls, err := findImageURLs(body)
+	if err != nil {
+		return body
+	}
+
+	var out bytes.Buffer
+	last := 0
+	for _, u := range urls {
+		replaced, ok := replace(u.value)
+		if !ok {
+			continue
+		}
+		encoded, err := json.Marshal(replaced)
+		if err != nil {
+			return body
+		}
+		out.Write(body[last:u.start])
+		out.Write(encoded)
+		last = u.end
+	}
+	if last == 0 {
+		return body
+	}
+	out.Write(body[last:])
+	return out.Bytes()
+}
+
+// jsonString is a string value in a JSON document and the bytes it spans,
+// quotes included.
+type jsonString struct {
+	start, end int
+	value      string
+}
+
+// jsonObject tracks the object findImageURLs is reading.
+type jsonObject struct {
+	key       string
+	expectKey bool
+	strings   map[string]jsonString
+}
+
+// findImageURLs returns the image URL fields in body, in document order. A
+// JSON Patch operation's value counts as one when its path ends in an image
+// URL field.
+func findImageURLs(body []byte) ([]jsonString, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var urls []jsonString
+	// A nil entry stands for an array.
+	var stack []*jsonObject
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var object *jsonObject
+		if len(stack) > 0 {
+			object = stack[len(stack)-1]
+		}
+		if object != nil && object.expectKey {
+			if token == json.Delim('}') {
+				stack = stack[:len(stack)-1]
+				urls = append(urls, objectImageURLs(object)...)
+				valueRead(stack)
+				continue
+			}
+			object.key, _ = token.(string)
+			object.expectKey = false
+			continue
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, &jsonObject{expectKey: true, strings: map[string]jsonString{}})
+			case '[':
+				stack = append(stack, nil)
+			case ']':
+				stack = stack[:len(stack)-1]
+				valueRead(stack)
+			}
+		case string:
+			if object != nil {
+				end := int(decoder.InputOffset())
+				start := offset + bytes.IndexByte(body[offset:end], '"')
+				object.strings[object.key] = jsonString{start: start, end: end, value: t}
+			}
+			valueRead(stack)
+		default:
+			valueRead(stack)
+		}
+	}
+
+	sort.Slice(urls, func(i, j int) bool { return urls[i].start < urls[j].start })
+	return urls, nil
+}
+
+// valueRead notes that the value of the innermost object's current key has
+// been read.
+func valueRead(stack []*jsonObject) {
+	if len(stack) > 0 && stack[len(stack)-1] != nil {
+		stack[len(stack)-1].expectKey = true
+	}
+}
+
+func objectImageURLs(object *jsonObject) []jsonString {
+	var urls []jsonString
+	for key, s := range object.strings {
+		if imageURLFields[key] {
+			urls = append(urls, s)
+		}
+	}
+	if path, ok := object.strings["path"]; ok {
+		field := path.value[strings.LastIndex(path.value, "/")+1:]
+		if value, ok := object.strings["value"]; ok && imageURLFields[field] {
+			urls = append(urls, value)
+		}
+	}
+	return urls
+}
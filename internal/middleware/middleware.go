@@ -93,3 +93,31 @@ func (um *UserMiddleware) RequireAdmin() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequirePermission only lets through users whose role grants permission.
+func (um *UserMiddleware) RequirePermission(permission store.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := GetUser(c)
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "you must be logged in"})
+			c.Abort()
+			return
+		}
+
+		if !user.Can(permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "your role does not allow this action"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CacheControl sets the Cache-Control header on every response.
+func CacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
+}
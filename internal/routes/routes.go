@@ -7,6 +7,8 @@ import (
 
 	_ "github.com/SamaraRuizSandoval/BookClubApp/docs"
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/app"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/middleware"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -21,41 +23,138 @@ func SetupRouter(app *app.Application) *gin.Engine {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     strings.Split(allowedOrigins, ","),
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match", "If-None-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}))
 
+	// Routes that return or accept book images serve their covers through the
+	// image proxy.
+	images := middleware.RewriteImageURLs(app.ImageProxy)
+
 	r.GET("/health", app.HealthCheck)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Uploaded files never change under the same name, so they can be cached
+	// for good.
+	media := r.Group("/media", middleware.CacheControl("public, max-age=31536000, immutable"))
+	media.Static("/", app.MediaDir)
+
 	adminAuth := r.Group("/")
 	adminAuth.Use(app.Middleware.AuthMiddleware(), app.Middleware.RequireAdmin())
 	{
-		adminAuth.POST("/books", app.BookHandler.HandleAddBook)
-		adminAuth.POST("/admins", app.UserHandler.RegisterAdminAccount)
+		adminAuth.GET("/api/books/cache", app.GoogleBookAPIHandler.HandleGetGoogleBooksCacheStats)
+		adminAuth.GET("/books/archived", images, app.BookHandler.HandleGetArchivedBooks)
+		adminAuth.POST("/books/:id/restore", images, app.BookHandler.HandleRestoreBook)
+		adminAuth.GET("/books/:id/purge", app.BookHandler.HandleGetBookPurgeReport)
+		adminAuth.DELETE("/books/:id/purge", app.BookHandler.HandlePurgeBook)
+		adminAuth.POST("/books/:id/history/:revision/revert", images, app.BookHandler.HandleRevertBook)
+		adminAuth.POST("/books/import", app.CatalogHandler.HandleImportCatalog)
+		adminAuth.GET("/books/imports", app.CatalogHandler.HandleGetImportJobs)
+		adminAuth.GET("/books/imports/:id", app.CatalogHandler.HandleGetImportJob)
+		adminAuth.GET("/books/imports/:id/rows", app.CatalogHandler.HandleGetImportRows)
+		adminAuth.GET("/books/export", app.CatalogHandler.HandleExportCatalog)
+	}
+
+	userAdmins := r.Group("/")
+	userAdmins.Use(app.Middleware.AuthMiddleware(), app.Middleware.RequirePermission(store.PermManageUsers))
+	{
+		userAdmins.POST("/admins", app.UserHandler.RegisterAdminAccount)
+		userAdmins.PUT("/users/:user_id/role", app.UserHandler.HandleUpdateUserRole)
+	}
+
+	// Catalog editors, moderators and admins maintain the catalog.
+	editors := r.Group("/")
+	editors.Use(app.Middleware.AuthMiddleware(), app.Middleware.RequirePermission(store.PermEditCatalog))
+	{
+		editors.POST("/books", images, app.BookHandler.HandleAddBook)
+		editors.PUT("/books/:id", images, app.BookHandler.HandleUpdateBookByID)
+		editors.PATCH("/books/:id", images, app.BookHandler.HandlePatchBookByID)
+		editors.POST("/books/:id/cover", images, app.CoverHandler.HandleUploadCover)
+		editors.GET("/books/duplicates", images, app.BookHandler.HandleGetSuspectedDuplicates)
+		editors.GET("/books/:id/history", app.BookHandler.HandleGetBookHistory)
+		editors.GET("/books/:id/history/diff", images, app.BookHandler.HandleGetBookRevisionDiff)
+		editors.GET("/books/:id/history/:revision", images, app.BookHandler.HandleGetBookRevision)
+		editors.POST("/books/import/google/:volume_id", images, app.GoogleBookAPIHandler.HandleImportGoogleBook)
+		editors.POST("/books/import/isbn/:isbn", images, app.BookMetadataHandler.HandleImportBookByISBN)
+		editors.PUT("/books/:id/genres", app.GenreHandler.HandleSetBookGenres)
+		editors.POST("/genres", app.GenreHandler.HandleAddGenre)
+		editors.PUT("/genres/:id", app.GenreHandler.HandleUpdateGenre)
+		editors.POST("/series", images, app.SeriesHandler.HandleAddSeries)
+		editors.PUT("/series/:id", app.SeriesHandler.HandleUpdateSeries)
+		editors.PUT("/series/:id/books/:book_id", app.SeriesHandler.HandleSetSeriesBook)
+		editors.DELETE("/series/:id/books/:book_id", app.SeriesHandler.HandleRemoveSeriesBook)
+		editors.PUT("/works/:id/editions/:book_id", app.WorkHandler.HandleAddEditionToWork)
+		editors.POST("/books/:id/split", images, app.WorkHandler.HandleSplitEdition)
+		editors.POST("/books/:id/chapters", app.ChapterHandler.HandleAddChapter)
+		editors.POST("/books/:id/chapters/import", app.ChapterHandler.HandleImportChapters)
+		editors.PUT("/books/:id/chapters/order", app.ChapterHandler.HandleReorderChapters)
+		editors.PATCH("/books/:id/chapters/:chapter_id", app.ChapterHandler.HandleUpdateChapter)
+		editors.DELETE("/books/:id/chapters/:chapter_id", app.ChapterHandler.HandleDeleteChapter)
+	}
+
+	reviewers := r.Group("/")
+	reviewers.Use(app.Middleware.AuthMiddleware(), app.Middleware.RequirePermission(store.PermReviewSuggestions))
+	{
+		reviewers.GET("/suggestions", images, app.SuggestionHandler.HandleGetSuggestions)
+		reviewers.POST("/suggestions/:id/approve", images, app.SuggestionHandler.HandleApproveSuggestion)
+		reviewers.POST("/suggestions/:id/reject", images, app.SuggestionHandler.HandleRejectSuggestion)
+	}
+
+	// Removing catalog entries is left to moderators and admins.
+	moderators := r.Group("/")
+	moderators.Use(app.Middleware.AuthMiddleware(), app.Middleware.RequirePermission(store.PermDeleteCatalog))
+	{
+		moderators.DELETE("/books/:id", app.BookHandler.HandleDeleteBookByID)
+		moderators.DELETE("/genres/:id", app.GenreHandler.HandleDeleteGenreByID)
+		moderators.DELETE("/series/:id", app.SeriesHandler.HandleDeleteSeriesByID)
 	}
 
 	auth := r.Group("/")
 	auth.Use(app.Middleware.AuthMiddleware(), app.Middleware.RequireUser())
 	{
 		auth.GET("/me", app.UserHandler.GetMe)
-		auth.PUT("/books/:id", app.BookHandler.HandleUpdateBookByID)
-		auth.DELETE("/books/:id", app.BookHandler.HandleDeleteBookByID)
+		auth.POST("/books/:id/tags", app.TagHandler.HandleAddBookTag)
+		auth.DELETE("/books/:id/tags/:tag", app.TagHandler.HandleRemoveBookTag)
+		auth.GET("/me/tags", app.TagHandler.HandleGetMyTags)
+		auth.POST("/me/imports", app.ShelfImportHandler.HandleImportShelves)
+		auth.GET("/me/imports", app.ShelfImportHandler.HandleGetShelfImports)
+		auth.GET("/me/imports/:id", app.ShelfImportHandler.HandleGetShelfImport)
+		auth.GET("/me/imports/:id/rows", app.ShelfImportHandler.HandleGetShelfImportRows)
+		auth.POST("/me/export", app.UserExportHandler.HandleCreateExport)
+		auth.GET("/me/exports/:id", app.UserExportHandler.HandleGetExport)
+		auth.GET("/me/recommendations", images, app.RecommendationHandler.HandleGetRecommendations)
+		auth.POST("/books/:id/suggestions", images, app.SuggestionHandler.HandleSuggestBookEdit)
+		auth.GET("/users/me/suggestions", images, app.SuggestionHandler.HandleGetMySuggestions)
+		auth.GET("/suggestions/:id", images, app.SuggestionHandler.HandleGetSuggestionByID)
 
 		auth.POST("/chapters/:chapter_id/comments", app.CommentHandler.HandleAddComment)
 		auth.PUT("/chapters/:chapter_id/comments/:id", app.CommentHandler.HandleUpdateComment)
 		auth.DELETE("/chapters/:chapter_id/comments/:id", app.CommentHandler.HandleDeleteCommentById)
-		auth.POST("/users/:user_id/books", app.UserBooksHandler.HandleAddUserBook)
-		auth.GET("/users/:user_id/books", app.UserBooksHandler.HandleGetUserBooks)
+		auth.POST("/users/:user_id/books", images, app.UserBooksHandler.HandleAddUserBook)
+		auth.GET("/users/:user_id/books", images, app.UserBooksHandler.HandleGetUserBooks)
 		auth.GET("/users/{user_id}/books/stats", app.UserBooksHandler.HandleGetUserBooksStats)
-		auth.PATCH("/user-books/:id", app.UserBooksHandler.HandleUpdateUserBook)
+		auth.GET("/users/me/books/:book_id/position", app.UserBooksHandler.HandleGetReadingPosition)
+		auth.PATCH("/user-books/:id", images, app.UserBooksHandler.HandleUpdateUserBook)
 		auth.DELETE("/user-books/:id", app.UserBooksHandler.HandleDeleteUserBook)
-		auth.GET("/api/books", app.GoogleBookAPIHandler.HandleSearchGoogleBooks)
+		auth.GET("/api/books", images, app.GoogleBookAPIHandler.HandleSearchGoogleBooks)
+		auth.GET("/books/metadata", images, app.BookMetadataHandler.HandleSearchBookMetadata)
+		auth.GET("/books/metadata/isbn/:isbn", images, app.BookMetadataHandler.HandleGetBookMetadataByISBN)
 	}
 
-	r.GET("/books/:id", app.BookHandler.HandleGetBookByID)
-	r.GET("/books", app.BookHandler.HandleGetAllBooks)
+	r.GET("/books/:id", images, app.BookHandler.HandleGetBookByID)
+	r.GET("/books", images, app.BookHandler.HandleGetAllBooks)
+	r.GET("/books/isbn/:isbn", images, app.BookHandler.HandleGetBookByISBN)
+	r.GET("/books/:id/tags", app.TagHandler.HandleGetBookTags)
+	r.GET("/books/:id/chapters", app.ChapterHandler.HandleGetBookChapters)
+	r.GET("/books/:id/contributors", app.SuggestionHandler.HandleGetBookContributors)
+	r.GET("/books/:id/similar", images, app.RecommendationHandler.HandleGetSimilarBooks)
+	r.GET("/genres", app.GenreHandler.HandleGetAllGenres)
+	r.GET("/genres/:id", app.GenreHandler.HandleGetGenreByID)
+	r.GET("/series/:id", images, app.SeriesHandler.HandleGetSeriesByID)
+	r.GET("/works/:id", images, app.WorkHandler.HandleGetWorkByID)
+	r.GET("/images/proxy", app.ImageProxyHandler.HandleGetProxiedImage)
+	r.GET("/exports/:id/download", app.UserExportHandler.HandleDownloadExport)
 
 	r.GET("/chapters/:chapter_id/comments/", app.CommentHandler.HandleGetCommentsByChapterID)
 	r.GET("/chapters/:chapter_id/comments/:id", app.CommentHandler.HandleGetCommentById)
@@ -0,0 +1,59 @@
+package shelfimport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// goodreadsStatuses maps the Goodreads exclusive shelves to statuses. Other
+// exclusive shelves are ones the member made up, and have no status here.
+var goodreadsStatuses = map[string]string{
+	"to-read":           StatusWishlist,
+	"currently-reading": StatusReading,
+	"read":              StatusCompleted,
+}
+
+// goodreadsEntry reads a row of a Goodreads "Export Library" file. Goodreads
+// only exports the date a book was finished.
+func goodreadsEntry(value func(column string) string) Entry {
+	entry := Entry{
+		Title:   value("title"),
+		Authors: splitList(value("author") + "," + value("additional authors")),
+		ISBN13:  parseISBN(value("isbn13")),
+	}
+	if entry.ISBN13 == "" {
+		entry.ISBN13 = parseISBN(value("isbn"))
+	}
+
+	shelf := strings.ToLower(value("exclusive shelf"))
+	status, ok := goodreadsStatuses[shelf]
+	if !ok {
+		entry.Err = fmt.Errorf("status: shelf %q has no matching status", shelf)
+		return entry
+	}
+	entry.Status = status
+
+	entry.Shelves = []string{}
+	for _, name := range splitList(value("bookshelves")) {
+		if _, ok := goodreadsStatuses[name]; !ok {
+			entry.Shelves = append(entry.Shelves, name)
+		}
+	}
+
+	rating, err := parseRating(value("my rating"))
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	entry.Rating = rating
+
+	if status == StatusCompleted {
+		completedAt, err := parseDate(value("date read"))
+		if err != nil {
+			entry.Err = fmt.Errorf("date read: %w", err)
+			return entry
+		}
+		entry.CompletedAt = completedAt
+	}
+	return entry
+}
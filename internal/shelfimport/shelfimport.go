@@ -0,0 +1,195 @@
+// Package shelfimport reads the library exports of other reading sites, so
+// members can bring their shelves with them: the Goodreads "Export Library"
+// CSV and the StoryGraph CSV export.
+//
+// Each row becomes an Entry with the book's ISBN, title and authors and the
+// member's status, reading dates, rating and shelves.
+package shelfimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/isbn"
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+)
+
+const (
+	SourceGoodreads  = "goodreads"
+	SourceStoryGraph = "storygraph"
+)
+
+// Statuses of a shelved book, as stored in user_books.
+const (
+	StatusWishlist  = "wishlist"
+	StatusReading   = "reading"
+	StatusCompleted = "completed"
+)
+
+var (
+	ErrUnknownSource = errors.New("file is not a Goodreads or StoryGraph export")
+	ErrEmpty         = errors.New("file has no books")
+)
+
+// Entry is one book read from an export. Row is its line in the file. Err is
+// set when the row could not be read; the other entries are still usable.
+type Entry struct {
+	Row         int             `json:"-"`
+	Title       string          `json:"title"`
+	Authors     []string        `json:"authors"`
+	ISBN13      string          `json:"isbn_13,omitempty"`
+	Status      string          `json:"status"`
+	Rating      *float64        `json:"rating,omitempty"`
+	StartedAt   *store.JSONDate `json:"started_at,omitempty"`
+	CompletedAt *store.JSONDate `json:"completed_at,omitempty"`
+	// Shelves are the member's own shelves or tags for the book, without the
+	// ones that only set its status.
+	Shelves []string `json:"shelves,omitempty"`
+	Err     error    `json:"-"`
+}
+
+// dateLayouts are the dates found in exports.
+var dateLayouts = []string{"2006/01/02", "2006-01-02"}
+
+// Read reads every entry of an export, telling the site it came from by its
+// header. It fails only when the file as a whole cannot be read; problems
+// with single rows are reported in their Err.
+func Read(r io.Reader) (string, []Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return "", nil, ErrEmpty
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		columns[name] = i
+	}
+
+	var source string
+	var readEntry func(value func(column string) string) Entry
+	switch {
+	case hasColumns(columns, "title", "exclusive shelf"):
+		source, readEntry = SourceGoodreads, goodreadsEntry
+	case hasColumns(columns, "title", "read status"):
+		source, readEntry = SourceStoryGraph, storyGraphEntry
+	default:
+		return "", nil, ErrUnknownSource
+	}
+
+	entries := []Entry{}
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(fields) {
+			continue
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		entry := readEntry(value)
+		entry.Row = line
+		if entry.Err == nil && entry.Title == "" && entry.ISBN13 == "" {
+			entry.Err = errors.New("row has neither a title nor an ISBN")
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return "", nil, ErrEmpty
+	}
+	return source, entries, nil
+}
+
+func hasColumns(columns map[string]int, names ...string) bool {
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseISBN returns the ISBN-13 of an export's ISBN column, or "" when it
+// holds no valid ISBN. Goodreads writes ISBNs as ="9780261102217" so
+// spreadsheets keep the leading zeros; StoryGraph uses the column for its own
+// ids when a book has no ISBN.
+func parseISBN(s string) string {
+	s = strings.Trim(strings.TrimPrefix(s, "="), `"`)
+	isbn13, err := isbn.ToISBN13(s)
+	if err != nil {
+		return ""
+	}
+	return isbn13
+}
+
+// parseRating reads a star rating; no rating and zero stars both mean the
+// book is unrated.
+func parseRating(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	rating, err := strconv.ParseFloat(s, 64)
+	if err != nil || rating < 0 || rating > 5 {
+		return nil, fmt.Errorf("rating: invalid rating %q", s)
+	}
+	if rating == 0 {
+		return nil, nil
+	}
+	return &rating, nil
+}
+
+func parseDate(s string) (*store.JSONDate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			date := store.JSONDate(t)
+			return &date, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date %q", s)
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isBlank(fields []string) bool {
+	for _, field := range fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
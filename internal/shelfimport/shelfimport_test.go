@@ -0,0 +1,108 @@
+package shelfimport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) *store.JSONDate {
+	d := store.JSONDate(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+	return &d
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestReadGoodreads(t *testing.T) {
+	input := "\uFEFFBook Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Average Rating,Date Read,Date Added,Bookshelves,Exclusive Shelf\n" +
+		`1,The Hobbit,J.R.R. Tolkien,"Tolkien, J.R.R.",Christopher Tolkien,"=""0261102214""","=""9780261102217""",5,4.28,2021/03/04,2020/01/02,"favorites, read, fantasy",read` + "\n" +
+		`2,Dune,Frank Herbert,"Herbert, Frank",,"=""0441172717""","=""""",0,4.27,,2022/05/06,to-read,to-read` + "\n" +
+		`3,No ISBN,Someone,"One, Some",,"=""""","=""""",3,3.00,,2022/05/06,,currently-reading` + "\n" +
+		`4,Gave Up,Someone,"One, Some",,,,2,3.00,,2022/05/06,did-not-finish,did-not-finish` + "\n" +
+		`5,Bad Date,Someone,"One, Some",,,,2,3.00,someday,2022/05/06,read,read` + "\n"
+
+	source, entries, err := Read(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, SourceGoodreads, source)
+	require.Len(t, entries, 5)
+
+	hobbit := entries[0]
+	require.NoError(t, hobbit.Err)
+	assert.Equal(t, Entry{
+		Row:         2,
+		Title:       "The Hobbit",
+		Authors:     []string{"J.R.R. Tolkien", "Christopher Tolkien"},
+		ISBN13:      "9780261102217",
+		Status:      StatusCompleted,
+		Rating:      ptr(5.0),
+		CompletedAt: date(2021, 3, 4),
+		Shelves:     []string{"favorites", "fantasy"},
+	}, hobbit)
+
+	dune := entries[1]
+	require.NoError(t, dune.Err)
+	assert.Equal(t, "9780441172719", dune.ISBN13, "falls back to the ISBN-10")
+	assert.Equal(t, StatusWishlist, dune.Status)
+	assert.Nil(t, dune.Rating, "zero stars is unrated")
+	assert.Empty(t, dune.Shelves)
+
+	require.NoError(t, entries[2].Err)
+	assert.Empty(t, entries[2].ISBN13)
+	assert.Equal(t, StatusReading, entries[2].Status)
+
+	assert.ErrorContains(t, entries[3].Err, "did-not-finish")
+	assert.ErrorContains(t, entries[4].Err, "date read")
+}
+
+func TestReadStoryGraph(t *testing.T) {
+	input := "Title,Authors,Contributors,ISBN/UID,Format,Read Status,Date Added,Last Date Read,Dates Read,Read Count,Star Rating,Review,Tags\n" +
+		`The Hobbit,"J.R.R. Tolkien, Christopher Tolkien",,9780261102217,paperback,read,2020/01/02,2023/05/14,"2019/01/01-2019/02/01, 2023/04/01-2023/05/14",2,4.25,,"comfort, fantasy"` + "\n" +
+		`Dune,Frank Herbert,,sg-123abc,digital,currently-reading,2022/05/06,,2024/01/02-,0,,,` + "\n" +
+		`Left,Someone,,,audio,paused,2022/05/06,,,0,,,` + "\n" +
+		`Gave Up,Someone,,,audio,did-not-finish,2022/05/06,,,0,,,` + "\n" +
+		`Too Many Stars,Someone,,,audio,read,2022/05/06,,,0,6,,` + "\n"
+
+	source, entries, err := Read(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, SourceStoryGraph, source)
+	require.Len(t, entries, 5)
+
+	hobbit := entries[0]
+	require.NoError(t, hobbit.Err)
+	assert.Equal(t, []string{"J.R.R. Tolkien", "Christopher Tolkien"}, hobbit.Authors)
+	assert.Equal(t, StatusCompleted, hobbit.Status)
+	assert.Equal(t, ptr(4.25), hobbit.Rating)
+	assert.Equal(t, date(2023, 4, 1), hobbit.StartedAt, "dates of the latest read")
+	assert.Equal(t, date(2023, 5, 14), hobbit.CompletedAt)
+	assert.Equal(t, []string{"comfort", "fantasy"}, hobbit.Shelves)
+
+	dune := entries[1]
+	require.NoError(t, dune.Err)
+	assert.Empty(t, dune.ISBN13, "StoryGraph ids are not ISBNs")
+	assert.Equal(t, StatusReading, dune.Status)
+	assert.Equal(t, date(2024, 1, 2), dune.StartedAt)
+	assert.Nil(t, dune.CompletedAt)
+
+	assert.Equal(t, StatusReading, entries[2].Status)
+	assert.Equal(t, []string{"paused"}, entries[2].Shelves)
+
+	assert.ErrorContains(t, entries[3].Err, "did-not-finish")
+	assert.ErrorContains(t, entries[4].Err, "rating")
+}
+
+func TestRead_RejectsOtherFiles(t *testing.T) {
+	_, _, err := Read(strings.NewReader("title,authors,isbn_13\nThe Hobbit,Tolkien,9780261102217\n"))
+	assert.ErrorIs(t, err, ErrUnknownSource)
+
+	_, _, err = Read(strings.NewReader("Title,Read Status\n\n"))
+	assert.ErrorIs(t, err, ErrEmpty)
+
+	_, _, err = Read(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrEmpty)
+}
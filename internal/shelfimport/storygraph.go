@@ -0,0 +1,78 @@
+package shelfimport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// storyGraphStatuses maps the StoryGraph read statuses to statuses. Paused
+// books are still being read. Books the member did not finish have no status
+// here.
+var storyGraphStatuses = map[string]string{
+	"to-read":           StatusWishlist,
+	"currently-reading": StatusReading,
+	"paused":            StatusReading,
+	"read":              StatusCompleted,
+}
+
+// storyGraphEntry reads a row of a StoryGraph export. Star ratings go in
+// quarter stars.
+func storyGraphEntry(value func(column string) string) Entry {
+	entry := Entry{
+		Title:   value("title"),
+		Authors: splitList(value("authors")),
+		ISBN13:  parseISBN(value("isbn/uid")),
+		Shelves: splitList(value("tags")),
+	}
+
+	readStatus := strings.ToLower(value("read status"))
+	status, ok := storyGraphStatuses[readStatus]
+	if !ok {
+		entry.Err = fmt.Errorf("status: read status %q has no matching status", readStatus)
+		return entry
+	}
+	entry.Status = status
+	if readStatus == "paused" {
+		entry.Shelves = append(entry.Shelves, readStatus)
+	}
+
+	rating, err := parseRating(value("star rating"))
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	entry.Rating = rating
+
+	if err := readStoryGraphDates(&entry, value("dates read"), value("last date read")); err != nil {
+		entry.Err = err
+	}
+	return entry
+}
+
+// readStoryGraphDates sets the dates of the latest read. "Dates Read" lists
+// every read as start-end ranges separated by commas, either end possibly
+// missing; "Last Date Read" is the end of the latest one.
+func readStoryGraphDates(entry *Entry, datesRead, lastDateRead string) error {
+	var start, end string
+	if reads := splitList(datesRead); len(reads) > 0 {
+		start, end, _ = strings.Cut(reads[len(reads)-1], "-")
+	}
+	if end == "" {
+		end = lastDateRead
+	}
+
+	startedAt, err := parseDate(start)
+	if err != nil {
+		return fmt.Errorf("dates read: %w", err)
+	}
+	entry.StartedAt = startedAt
+
+	if entry.Status == StatusCompleted {
+		completedAt, err := parseDate(end)
+		if err != nil {
+			return fmt.Errorf("last date read: %w", err)
+		}
+		entry.CompletedAt = completedAt
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidBlobKey is returned for keys that are empty, absolute or climb out
+// of the store with "..".
+var ErrInvalidBlobKey = errors.New("invalid blob key")
+
+// BlobStore keeps uploaded files, such as cover images, and tells where they
+// are served from. Keys are slash-separated paths. An S3-compatible store can
+// implement it the same way the local one does.
+type BlobStore interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+	URL(key string) string
+}
+
+type LocalBlobStoreConfig struct {
+	// Dir is where blobs are written.
+	Dir string
+	// BaseURL is the URL Dir is served under.
+	BaseURL string
+}
+
+// LocalBlobStoreConfigFromEnv reads MEDIA_DIR and MEDIA_BASE_URL.
+func LocalBlobStoreConfigFromEnv() LocalBlobStoreConfig {
+	return LocalBlobStoreConfig{
+		Dir:     getEnv("MEDIA_DIR", "media"),
+		BaseURL: getEnv("MEDIA_BASE_URL", "/media"),
+	}
+}
+
+// LocalBlobStore is a BlobStore on the local filesystem. The application
+// serves its directory itself.
+type LocalBlobStore struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalBlobStore(config LocalBlobStoreConfig) *LocalBlobStore {
+	return &LocalBlobStore{
+		dir:     config.Dir,
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+	}
+}
+
+// Dir is the directory the blobs are written to.
+func (s *LocalBlobStore) Dir() string {
+	return s.dir
+}
+
+// Put writes the blob to a temporary file first and renames it into place, so
+// a blob is never served half written. The content type is implied by the
+// key's extension when the file is served.
+func (s *LocalBlobStore) Put(key string, data []byte, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Get reads the blob. A missing blob is an error matching os.ErrNotExist.
+func (s *LocalBlobStore) Get(key string) ([]byte, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(name)
+}
+
+// Delete removes the blob. Deleting a missing blob is not an error.
+func (s *LocalBlobStore) Delete(key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidBlobKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
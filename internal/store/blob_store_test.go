@@ -0,0 +1,46 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBlobStore_PutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	blobs := NewLocalBlobStore(LocalBlobStoreConfig{Dir: dir, BaseURL: "/media/"})
+
+	require.NoError(t, blobs.Put("covers/1/abc-small.jpg", []byte("jpeg"), "image/jpeg"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "covers", "1", "abc-small.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+	assert.Equal(t, "/media/covers/1/abc-small.jpg", blobs.URL("covers/1/abc-small.jpg"))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "covers", "1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary file left behind")
+
+	data, err = blobs.Get("covers/1/abc-small.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+
+	require.NoError(t, blobs.Delete("covers/1/abc-small.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "covers", "1", "abc-small.jpg"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, blobs.Delete("covers/1/abc-small.jpg"))
+
+	_, err = blobs.Get("covers/1/abc-small.jpg")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLocalBlobStore_RejectsKeysOutsideDir(t *testing.T) {
+	blobs := NewLocalBlobStore(LocalBlobStoreConfig{Dir: t.TempDir()})
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "covers/../../secret", "covers//a.jpg"} {
+		assert.ErrorIs(t, blobs.Put(key, []byte("x"), "text/plain"), ErrInvalidBlobKey, key)
+	}
+}
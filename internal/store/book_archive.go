@@ -0,0 +1,196 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrBookNotArchived is returned when purging a book that is still in the
+// catalog.
+var ErrBookNotArchived = errors.New("book must be archived before it is purged")
+
+// BookPurgeReport is what purging a book would remove. Readers who shelved the
+// book keep their entry on another edition of the same work when there is
+// one; otherwise the entry is removed.
+type BookPurgeReport struct {
+	BookID         int64      `json:"book_id"`
+	Title          string     `json:"title"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+	ShelvedBy      int        `json:"shelved_by"`
+	ShelvesMoved   int        `json:"shelves_moved"`
+	ShelvesRemoved int        `json:"shelves_removed"`
+	Chapters       int        `json:"chapters"`
+	Comments       int        `json:"comments"`
+	Tags           int        `json:"tags"`
+	Suggestions    int        `json:"suggestions"`
+	InSeries       bool       `json:"in_series"`
+	// WorkDeleted is set when the book is the last edition of its work.
+	WorkDeleted bool `json:"work_deleted"`
+}
+
+// ArchiveBook hides the book from the catalog. Readers who shelved it can still
+// look it up. It returns sql.ErrNoRows when there is no such book in the
+// catalog and ErrVersionMismatch when the book's version is not one of
+// ifMatch. A nil ifMatch archives any version.
+func (pg *PostgresBookStore) ArchiveBook(id int64, ifMatch []int) error {
+	query := `UPDATE books SET archived_at = NOW() WHERE id = $1 AND archived_at IS NULL`
+	args := []interface{}{id}
+	if ifMatch != nil {
+		query += ` AND version = ANY($2)`
+		args = append(args, versionArgs(ifMatch))
+	}
+
+	result, err := pg.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		if ifMatch == nil {
+			return sql.ErrNoRows
+		}
+		var exists bool
+		err := pg.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM books WHERE id = $1 AND archived_at IS NULL)`, id).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrVersionMismatch
+		}
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RestoreBook returns an archived book to the catalog. It returns
+// sql.ErrNoRows when there is no such archived book.
+func (pg *PostgresBookStore) RestoreBook(id int64) error {
+	result, err := pg.db.Exec(`UPDATE books SET archived_at = NULL WHERE id = $1 AND archived_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetBookPurgeReport returns what purging the book would remove, or nil when
+// the book does not exist.
+func (pg *PostgresBookStore) GetBookPurgeReport(id int64) (*BookPurgeReport, error) {
+	report := &BookPurgeReport{BookID: id}
+	var hasOtherEditions bool
+	err := pg.db.QueryRow(`
+		SELECT b.title, b.archived_at,
+		(SELECT COUNT(*) FROM user_books ub WHERE ub.book_id = b.id),
+		EXISTS (SELECT 1 FROM books other WHERE other.work_id = b.work_id AND other.id <> b.id),
+		(SELECT COUNT(*) FROM chapters c WHERE c.book_id = b.id),
+		(SELECT COUNT(*) FROM comments cm JOIN chapters c ON cm.chapter_id = c.id WHERE c.book_id = b.id),
+		(SELECT COUNT(*) FROM book_tags bt WHERE bt.book_id = b.id),
+		(SELECT COUNT(*) FROM book_suggestions s WHERE s.book_id = b.id),
+		EXISTS (SELECT 1 FROM series_books sb WHERE sb.book_id = b.id)
+		FROM books b
+		WHERE b.id = $1`, id,
+	).Scan(
+		&report.Title,
+		&report.ArchivedAt,
+		&report.ShelvedBy,
+		&hasOtherEditions,
+		&report.Chapters,
+		&report.Comments,
+		&report.Tags,
+		&report.Suggestions,
+		&report.InSeries,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if hasOtherEditions {
+		report.ShelvesMoved = report.ShelvedBy
+	} else {
+		report.ShelvesRemoved = report.ShelvedBy
+		report.WorkDeleted = true
+	}
+
+	return report, nil
+}
+
+// PurgeBook permanently deletes an archived book along with its chapters,
+// their comments, and its tags and suggestions. It returns sql.ErrNoRows when
+// the book does not exist and ErrBookNotArchived when it is still in the
+// catalog.
+func (pg *PostgresBookStore) PurgeBook(id int64) error {
+	tx, err := pg.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	var archivedAt *time.Time
+	err = tx.QueryRow(`SELECT archived_at FROM books WHERE id = $1 FOR UPDATE`, id).Scan(&archivedAt)
+	if err != nil {
+		return err
+	}
+	if archivedAt == nil {
+		return ErrBookNotArchived
+	}
+
+	// Readers shelving this edition keep their entry on another edition of the
+	// same work, if there is one.
+	_, err = tx.Exec(`
+        UPDATE user_books ub
+        SET book_id = (
+            SELECT other.id FROM books b
+            JOIN books other ON other.work_id = b.work_id AND other.id <> b.id
+            WHERE b.id = $1
+            ORDER BY other.archived_at IS NOT NULL, other.id
+            LIMIT 1
+        )
+        WHERE ub.book_id = $1
+        AND EXISTS (
+            SELECT 1 FROM books b
+            JOIN books other ON other.work_id = b.work_id AND other.id <> b.id
+            WHERE b.id = $1
+        )`, id)
+	if err != nil {
+		return fmt.Errorf("failed to move user books: %w", err)
+	}
+
+	var workID int64
+	err = tx.QueryRow(`DELETE FROM books WHERE id = $1 RETURNING work_id`, id).Scan(&workID)
+	if err != nil {
+		return fmt.Errorf("failed to delete book: %w", err)
+	}
+
+	if err := deleteWorkIfEmpty(tx, workID); err != nil {
+		return fmt.Errorf("failed to delete work: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	return nil
+}
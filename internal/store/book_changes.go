@@ -0,0 +1,178 @@
+package store
+
+import "slices"
+
+// Fields of a book that readers can suggest changes to.
+const (
+	BookFieldTitle       = "title"
+	BookFieldDescription = "description"
+	BookFieldAuthors     = "authors"
+	BookFieldChapters    = "chapters"
+	BookFieldImages      = "book_images"
+)
+
+// SuggestableBookFields lists the fields in BookChanges, in display order.
+var SuggestableBookFields = []string{
+	BookFieldTitle,
+	BookFieldDescription,
+	BookFieldAuthors,
+	BookFieldChapters,
+	BookFieldImages,
+}
+
+// BookChanges holds values for some of a book's fields. Fields left nil are
+// not part of the change. An empty description clears it.
+type BookChanges struct {
+	Title       *string     `json:"title,omitempty" example:"The Hobbit, or There and Back Again"`
+	Description *string     `json:"description,omitempty"`
+	Authors     []string    `json:"authors,omitempty"`
+	Chapters    []Chapter   `json:"chapters,omitempty"`
+	Images      *BookImages `json:"book_images,omitempty"`
+}
+
+// Fields returns the names of the fields that are set.
+func (c BookChanges) Fields() []string {
+	fields := []string{}
+	for _, field := range SuggestableBookFields {
+		if c.has(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (c BookChanges) has(field string) bool {
+	switch field {
+	case BookFieldTitle:
+		return c.Title != nil
+	case BookFieldDescription:
+		return c.Description != nil
+	case BookFieldAuthors:
+		return c.Authors != nil
+	case BookFieldChapters:
+		return c.Chapters != nil
+	case BookFieldImages:
+		return c.Images != nil
+	}
+	return false
+}
+
+// Only returns the changes to the given fields.
+func (c BookChanges) Only(fields []string) BookChanges {
+	only := BookChanges{}
+	for _, field := range fields {
+		switch field {
+		case BookFieldTitle:
+			only.Title = c.Title
+		case BookFieldDescription:
+			only.Description = c.Description
+		case BookFieldAuthors:
+			only.Authors = c.Authors
+		case BookFieldChapters:
+			only.Chapters = c.Chapters
+		case BookFieldImages:
+			only.Images = c.Images
+		}
+	}
+	return only
+}
+
+// BookValues returns the book's current values of the given fields.
+func BookValues(book *Book, fields []string) BookChanges {
+	values := BookChanges{}
+	for _, field := range fields {
+		switch field {
+		case BookFieldTitle:
+			title := book.Title
+			values.Title = &title
+		case BookFieldDescription:
+			description := ""
+			if book.Description != nil {
+				description = *book.Description
+			}
+			values.Description = &description
+		case BookFieldAuthors:
+			values.Authors = append([]string{}, book.Authors...)
+		case BookFieldChapters:
+			values.Chapters = append([]Chapter{}, book.Chapters...)
+		case BookFieldImages:
+			images := book.Images
+			values.Images = &images
+		}
+	}
+	return values
+}
+
+// Diff drops the changes that would leave the book as it is.
+func (c BookChanges) Diff(book *Book) BookChanges {
+	current := BookValues(book, c.Fields())
+	changed := []string{}
+	for _, field := range c.Fields() {
+		if !sameBookField(field, c, current) {
+			changed = append(changed, field)
+		}
+	}
+	return c.Only(changed)
+}
+
+// ApplyTo sets the book's fields to the changed values.
+func (c BookChanges) ApplyTo(book *Book) {
+	if c.Title != nil {
+		book.Title = *c.Title
+	}
+	if c.Description != nil {
+		book.Description = c.Description
+		if *c.Description == "" {
+			book.Description = nil
+		}
+	}
+	if c.Authors != nil {
+		book.Authors = c.Authors
+	}
+	if c.Chapters != nil {
+		book.Chapters = c.Chapters
+	}
+	if c.Images != nil {
+		book.Images = *c.Images
+	}
+}
+
+// StaleFields returns the fields of c whose value in book no longer matches
+// base, i.e. fields someone else has edited since the changes were made.
+func (c BookChanges) StaleFields(book *Book, base BookChanges) []string {
+	current := BookValues(book, c.Fields())
+	stale := []string{}
+	for _, field := range c.Fields() {
+		if !sameBookField(field, base, current) {
+			stale = append(stale, field)
+		}
+	}
+	return stale
+}
+
+func sameBookField(field string, a, b BookChanges) bool {
+	switch field {
+	case BookFieldTitle:
+		return equalPtr(a.Title, b.Title)
+	case BookFieldDescription:
+		return equalPtr(a.Description, b.Description)
+	case BookFieldAuthors:
+		return slices.Equal(a.Authors, b.Authors)
+	case BookFieldChapters:
+		return slices.EqualFunc(a.Chapters, b.Chapters, func(x, y Chapter) bool {
+			// Suggested chapters may leave out their ids.
+			return (x.ID == 0 || y.ID == 0 || x.ID == y.ID) && sameChapter(x, y)
+		})
+	case BookFieldImages:
+		var x, y BookImages
+		if a.Images != nil {
+			x = *a.Images
+		}
+		if b.Images != nil {
+			y = *b.Images
+		}
+		return equalPtr(x.ThumbnailUrl, y.ThumbnailUrl) && equalPtr(x.SmallUrl, y.SmallUrl) &&
+			equalPtr(x.MediumUrl, y.MediumUrl) && equalPtr(x.LargeUrl, y.LargeUrl)
+	}
+	return true
+}
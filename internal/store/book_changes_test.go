@@ -0,0 +1,89 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func suggestionTestBook() *Book {
+	return &Book{
+		ID:      1,
+		Title:   "The Hobbit",
+		Authors: []string{"J.R.R. Tolkien"},
+		Chapters: []Chapter{
+			{ID: 10, Number: 1, Title: "An Unexpected Party"},
+			{ID: 11, Number: 2, Title: "Roast Mutton"},
+		},
+		Images: BookImages{ThumbnailUrl: strPtr("https://example.com/old.jpg")},
+	}
+}
+
+func TestBookChangesDiff_DropsUnchangedFields(t *testing.T) {
+	changes := BookChanges{
+		Title:   strPtr("The Hobbit"),
+		Authors: []string{"J.R.R. Tolkien", "Christopher Tolkien"},
+		Chapters: []Chapter{
+			{Number: 1, Title: "An Unexpected Party"},
+			{Number: 2, Title: "Roast Mutton"},
+		},
+		Description: strPtr("There and back again."),
+	}
+
+	diff := changes.Diff(suggestionTestBook())
+
+	assert.Equal(t, []string{BookFieldDescription, BookFieldAuthors}, diff.Fields())
+}
+
+func TestBookValues(t *testing.T) {
+	values := BookValues(suggestionTestBook(), []string{BookFieldTitle, BookFieldDescription, BookFieldImages})
+
+	assert.Equal(t, "The Hobbit", *values.Title)
+	assert.Equal(t, "", *values.Description)
+	assert.Equal(t, "https://example.com/old.jpg", *values.Images.ThumbnailUrl)
+	assert.Nil(t, values.Authors)
+	assert.Nil(t, values.Chapters)
+}
+
+func TestBookChangesApplyTo(t *testing.T) {
+	book := suggestionTestBook()
+	book.Description = strPtr("Old description")
+
+	BookChanges{
+		Title:       strPtr("The Hobbit, or There and Back Again"),
+		Description: strPtr(""),
+		Images:      &BookImages{ThumbnailUrl: strPtr("https://example.com/new.jpg")},
+	}.ApplyTo(book)
+
+	assert.Equal(t, "The Hobbit, or There and Back Again", book.Title)
+	assert.Nil(t, book.Description)
+	assert.Equal(t, "https://example.com/new.jpg", *book.Images.ThumbnailUrl)
+	assert.Equal(t, []string{"J.R.R. Tolkien"}, book.Authors)
+	assert.Len(t, book.Chapters, 2)
+}
+
+func TestBookChangesOnly(t *testing.T) {
+	changes := BookChanges{
+		Title:   strPtr("New title"),
+		Authors: []string{"Someone"},
+	}
+
+	only := changes.Only([]string{BookFieldAuthors})
+
+	assert.Equal(t, []string{BookFieldAuthors}, only.Fields())
+	assert.Nil(t, only.Title)
+}
+
+func TestBookChangesStaleFields(t *testing.T) {
+	book := suggestionTestBook()
+	changes := BookChanges{
+		Title:    strPtr("The Hobbit, or There and Back Again"),
+		Chapters: []Chapter{{Number: 1, Title: "A Long-expected Party"}},
+	}
+	base := BookValues(book, changes.Fields())
+
+	assert.Empty(t, changes.StaleFields(book, base))
+
+	book.Chapters[1].Title = "Roast Mutton!"
+	assert.Equal(t, []string{BookFieldChapters}, changes.StaleFields(book, base))
+}
@@ -0,0 +1,203 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	// DuplicateTitleSimilarity is the pg_trgm similarity above which two
+	// titles by the same author are treated as the same book.
+	DuplicateTitleSimilarity = 0.6
+	// duplicateTitleOnlySimilarity applies when the new book has no authors
+	// to compare.
+	duplicateTitleOnlySimilarity = 0.9
+	duplicateAuthorSimilarity    = 0.6
+	maxDuplicateCandidates       = 10
+)
+
+const (
+	DuplicateReasonISBN        = "isbn"
+	DuplicateReasonTitleAuthor = "title_author"
+)
+
+// DuplicateCandidate is a catalog book that looks like the same book as the
+// one being saved.
+type DuplicateCandidate struct {
+	Book       *Book   `json:"book"`
+	Reason     string  `json:"reason" example:"title_author"`
+	Similarity float64 `json:"similarity" example:"0.82"`
+}
+
+// DuplicatePair is two catalog books that look like the same book but are not
+// grouped as editions of one work.
+type DuplicatePair struct {
+	Book       *Book   `json:"book"`
+	Duplicate  *Book   `json:"duplicate"`
+	Reason     string  `json:"reason" example:"title_author"`
+	Similarity float64 `json:"similarity" example:"0.82"`
+}
+
+// bookSummaryJSON selects the fields of book alias that identify it in a
+// duplicate report.
+func bookSummaryJSON(alias string) string {
+	return strings.ReplaceAll(`json_build_object(
+		'id', b.id,
+		'work_id', b.work_id,
+		'title', b.title,
+		'isbn_13', b.isbn_13,
+		'isbn_10', b.isbn_10,
+		'archived_at', b.archived_at,
+		'authors', COALESCE(
+			(SELECT json_agg(a.name)
+			FROM book_authors ba
+			JOIN authors a ON ba.author_id = a.id
+			WHERE ba.book_id = b.id),
+			'[]'
+		)
+	)`, "b.", alias+".")
+}
+
+// FindDuplicateBooks returns catalog books that share book's ISBN-13 or
+// ISBN-10, or whose title and author closely match. A book being updated
+// (book.ID is set) does not match itself or the other editions of its work.
+// Archived books are included, so they are restored rather than added again.
+func (pg *PostgresBookStore) FindDuplicateBooks(book *Book) ([]*DuplicateCandidate, error) {
+	authors := []string{}
+	for _, author := range book.Authors {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, strings.ToLower(author))
+		}
+	}
+
+	rows, err := pg.db.Query(`
+		SELECT `+bookSummaryJSON("b")+`,
+		CASE WHEN b.isbn_13 = $2 OR b.isbn_10 = $3 THEN $8 ELSE $9 END AS reason,
+		similarity(lower(b.title), lower($1)) AS score
+		FROM books b
+		WHERE b.id <> $5
+		AND b.work_id IS DISTINCT FROM (SELECT work_id FROM books WHERE id = $5)
+		AND (
+			b.isbn_13 = $2
+			OR b.isbn_10 = $3
+			OR (
+				lower(b.title) % lower($1)
+				AND (
+					(cardinality($4::text[]) = 0 AND similarity(lower(b.title), lower($1)) >= $6)
+					OR (
+						similarity(lower(b.title), lower($1)) >= $7
+						AND EXISTS (
+							SELECT 1
+							FROM book_authors ba
+							JOIN authors a ON ba.author_id = a.id
+							JOIN unnest($4::text[]) AS candidate(name) ON similarity(lower(a.name), candidate.name) >= $10
+							WHERE ba.book_id = b.id
+						)
+					)
+				)
+			)
+		)
+		ORDER BY reason = $8 DESC, score DESC, b.id
+		LIMIT $11`,
+		book.Title, book.ISBN13, book.ISBN10, authors, book.ID,
+		duplicateTitleOnlySimilarity, DuplicateTitleSimilarity,
+		DuplicateReasonISBN, DuplicateReasonTitleAuthor, duplicateAuthorSimilarity,
+		maxDuplicateCandidates,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	candidates := []*DuplicateCandidate{}
+	for rows.Next() {
+		candidate := &DuplicateCandidate{Book: &Book{}}
+		var bookJSON []byte
+		if err := rows.Scan(&bookJSON, &candidate.Reason, &candidate.Similarity); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bookJSON, candidate.Book); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// GetSuspectedDuplicates lists pairs of books that share an ISBN-10 or an
+// author and have closely matching titles. Books already grouped under the
+// same work, and archived books, are not reported.
+func (pg *PostgresBookStore) GetSuspectedDuplicates(page, limit int) ([]*DuplicatePair, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	rows, err := pg.db.Query(fmt.Sprintf(`
+		WITH pairs AS (
+			SELECT l.id AS left_id, r.id AS right_id,
+			CASE WHEN l.isbn_10 = r.isbn_10 THEN $1 ELSE $2 END AS reason,
+			similarity(lower(l.title), lower(r.title)) AS score
+			FROM books l
+			JOIN books r ON l.id < r.id AND l.work_id <> r.work_id
+			WHERE l.archived_at IS NULL AND r.archived_at IS NULL
+			AND (
+				l.isbn_10 = r.isbn_10
+				OR (
+					lower(l.title) %% lower(r.title)
+					AND similarity(lower(l.title), lower(r.title)) >= $3
+					AND EXISTS (
+						SELECT 1
+						FROM book_authors la
+						JOIN book_authors ra ON la.author_id = ra.author_id
+						WHERE la.book_id = l.id AND ra.book_id = r.id
+					)
+				)
+			)
+		)
+		SELECT %s, %s, p.reason, p.score, COUNT(*) OVER ()
+		FROM pairs p
+		JOIN books l ON l.id = p.left_id
+		JOIN books r ON r.id = p.right_id
+		ORDER BY p.reason = $1 DESC, p.score DESC, p.left_id, p.right_id
+		LIMIT $4 OFFSET $5`, bookSummaryJSON("l"), bookSummaryJSON("r")),
+		DuplicateReasonISBN, DuplicateReasonTitleAuthor, DuplicateTitleSimilarity, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	pairs := []*DuplicatePair{}
+	total := 0
+	for rows.Next() {
+		pair := &DuplicatePair{Book: &Book{}, Duplicate: &Book{}}
+		var leftJSON, rightJSON []byte
+		if err := rows.Scan(&leftJSON, &rightJSON, &pair.Reason, &pair.Similarity, &total); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(leftJSON, pair.Book); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(rightJSON, pair.Duplicate); err != nil {
+			return nil, 0, err
+		}
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, total, rows.Err()
+}
@@ -0,0 +1,511 @@
+package store
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Kinds of change recorded in a book's history.
+const (
+	BookRevisionCreated  = "created"
+	BookRevisionUpdated  = "updated"
+	BookRevisionReverted = "reverted"
+)
+
+// Fields of a book that are recorded in its history, besides the suggestable
+// ones.
+const (
+	BookFieldPublisher     = "publisher"
+	BookFieldPublishedDate = "published_date"
+	BookFieldPageCount     = "page_count"
+	BookFieldISBN13        = "isbn_13"
+	BookFieldISBN10        = "isbn_10"
+	BookFieldGenres        = "genres"
+)
+
+// VersionedBookFields lists the fields in BookSnapshot, in display order.
+var VersionedBookFields = []string{
+	BookFieldTitle,
+	BookFieldAuthors,
+	BookFieldPublisher,
+	BookFieldPublishedDate,
+	BookFieldDescription,
+	BookFieldPageCount,
+	BookFieldISBN13,
+	BookFieldISBN10,
+	BookFieldImages,
+	BookFieldChapters,
+	BookFieldGenres,
+}
+
+// BookSnapshot is a book's catalog data as it was after a change.
+type BookSnapshot struct {
+	Title         string     `json:"title"`
+	Authors       []string   `json:"authors"`
+	Publisher     string     `json:"publisher"`
+	PublishedDate JSONDate   `json:"published_date"`
+	Description   *string    `json:"description"`
+	PageCount     *int       `json:"page_count"`
+	ISBN13        string     `json:"isbn_13"`
+	ISBN10        *string    `json:"isbn_10"`
+	Images        BookImages `json:"book_images"`
+	Chapters      []Chapter  `json:"chapters"`
+	Genres        []Genre    `json:"genres"`
+}
+
+// BookRevision is one entry in a book's history. Revisions are numbered per
+// book from 1 and are unrelated to the book's version, which also changes for
+// edits that are not recorded here. Snapshot is only loaded for a single
+// revision.
+type BookRevision struct {
+	BookID        int64         `json:"book_id"`
+	Revision      int           `json:"revision"`
+	Action        string        `json:"action" example:"updated"`
+	UserID        *int64        `json:"user_id"`
+	Username      *string       `json:"username"`
+	Note          *string       `json:"note"`
+	ChangedFields []string      `json:"changed_fields"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Snapshot      *BookSnapshot `json:"snapshot,omitempty"`
+}
+
+// BookFieldChange is the value of a field before and after a change.
+type BookFieldChange struct {
+	Field string          `json:"field" example:"title"`
+	From  json.RawMessage `json:"from" swaggertype:"object"`
+	To    json.RawMessage `json:"to" swaggertype:"object"`
+}
+
+// DiffBookSnapshots returns the fields that differ between two snapshots. A
+// nil from is treated as a book with no data.
+func DiffBookSnapshots(from, to *BookSnapshot) ([]BookFieldChange, error) {
+	if from == nil {
+		from = &BookSnapshot{}
+	}
+	fromFields, err := snapshotFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := snapshotFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []BookFieldChange{}
+	for _, field := range VersionedBookFields {
+		if !bytes.Equal(fromFields[field], toFields[field]) {
+			changes = append(changes, BookFieldChange{Field: field, From: fromFields[field], To: toFields[field]})
+		}
+	}
+	return changes, nil
+}
+
+// snapshotFields returns the JSON encoding of each field of the snapshot.
+// Empty lists are encoded the same as missing ones.
+func snapshotFields(snapshot *BookSnapshot) (map[string]json.RawMessage, error) {
+	normalized := *snapshot
+	if len(normalized.Authors) == 0 {
+		normalized.Authors = []string{}
+	}
+	if len(normalized.Chapters) == 0 {
+		normalized.Chapters = []Chapter{}
+	}
+	if len(normalized.Genres) == 0 {
+		normalized.Genres = []Genre{}
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// GetBookHistory lists the revisions of a book, newest first, without their
+// snapshots.
+func (pg *PostgresBookStore) GetBookHistory(bookID int64, page, limit int) ([]*BookRevision, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	rows, err := pg.db.Query(`
+		SELECT v.book_id, v.revision, v.action, v.user_id, u.username, v.note,
+		v.changed_fields, v.created_at, COUNT(*) OVER ()
+		FROM book_revisions v
+		LEFT JOIN users u ON u.id = v.user_id
+		WHERE v.book_id = $1
+		ORDER BY v.revision DESC
+		LIMIT $2 OFFSET $3`,
+		bookID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	revisions := []*BookRevision{}
+	total := 0
+	for rows.Next() {
+		revision := &BookRevision{}
+		var changedFields []byte
+		err := rows.Scan(
+			&revision.BookID,
+			&revision.Revision,
+			&revision.Action,
+			&revision.UserID,
+			&revision.Username,
+			&revision.Note,
+			&changedFields,
+			&revision.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(changedFields, &revision.ChangedFields); err != nil {
+			return nil, 0, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, total, rows.Err()
+}
+
+// GetBookRevision returns a revision of a book with its snapshot, or nil when
+// there is no such revision.
+func (pg *PostgresBookStore) GetBookRevision(bookID int64, revision int) (*BookRevision, error) {
+	v := &BookRevision{}
+	var changedFields, snapshot []byte
+	err := pg.db.QueryRow(`
+		SELECT v.book_id, v.revision, v.action, v.user_id, u.username, v.note,
+		v.changed_fields, v.created_at, v.snapshot
+		FROM book_revisions v
+		LEFT JOIN users u ON u.id = v.user_id
+		WHERE v.book_id = $1 AND v.revision = $2`,
+		bookID, revision,
+	).Scan(
+		&v.BookID,
+		&v.Revision,
+		&v.Action,
+		&v.UserID,
+		&v.Username,
+		&v.Note,
+		&changedFields,
+		&v.CreatedAt,
+		&snapshot,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changedFields, &v.ChangedFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(snapshot, &v.Snapshot); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// RevertBook sets the book's catalog data back to what it was at the given
+// revision and records the revert as a new revision. Chapters that have since
+// been deleted are recreated and genres that no longer exist are left out. It
+// returns sql.ErrNoRows when the book or revision does not exist and
+// ErrVersionMismatch when the book does not match opts.IfMatch.
+func (pg *PostgresBookStore) RevertBook(bookID int64, revision int, opts UpdateBookOptions) error {
+	tx, err := pg.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback revert transaction: %v", rbErr)
+		}
+	}()
+
+	if err := lockBook(tx, bookID, opts.IfMatch); err != nil {
+		return err
+	}
+
+	var data []byte
+	err = tx.QueryRow(`SELECT snapshot FROM book_revisions WHERE book_id = $1 AND revision = $2`, bookID, revision).Scan(&data)
+	if err != nil {
+		return err
+	}
+	var snapshot BookSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	book, err := bookFromSnapshot(tx, bookID, &snapshot)
+	if err != nil {
+		return err
+	}
+
+	if opts.Note == "" {
+		opts.Note = fmt.Sprintf("Reverted to revision %d", revision)
+	}
+	if err := applyBookUpdate(tx, book, opts, BookRevisionReverted); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// bookFromSnapshot rebuilds the book saved in a snapshot so it can be written
+// back over the current one.
+func bookFromSnapshot(tx *sql.Tx, bookID int64, snapshot *BookSnapshot) (*Book, error) {
+	book := &Book{
+		ID:            bookID,
+		Title:         snapshot.Title,
+		Authors:       snapshot.Authors,
+		Publisher:     snapshot.Publisher,
+		PublishedDate: snapshot.PublishedDate,
+		Description:   snapshot.Description,
+		PageCount:     snapshot.PageCount,
+		ISBN13:        snapshot.ISBN13,
+		ISBN10:        snapshot.ISBN10,
+		Images:        snapshot.Images,
+		Chapters:      append([]Chapter{}, snapshot.Chapters...),
+	}
+
+	existing, err := selectChaptersForUpdate(tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	existingIDs := map[int64]bool{}
+	for _, ch := range existing {
+		existingIDs[ch.ID] = true
+	}
+	for i, ch := range book.Chapters {
+		if !existingIDs[ch.ID] {
+			book.Chapters[i].ID = 0
+		}
+	}
+
+	for _, genre := range snapshot.Genres {
+		var exists bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM genres WHERE id = $1)`, genre.ID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			book.Genres = append(book.Genres, genre)
+		}
+	}
+
+	return book, nil
+}
+
+// lockBook locks the book's row until the transaction ends, so its revisions
+// are numbered one at a time, and checks its version against ifMatch. It
+// returns sql.ErrNoRows when there is no such book.
+func lockBook(tx *sql.Tx, bookID int64, ifMatch []int) error {
+	var version int
+	err := tx.QueryRow(`SELECT version FROM books WHERE id = $1 FOR UPDATE`, bookID).Scan(&version)
+	if err != nil {
+		return err
+	}
+	return matchVersion(version, ifMatch)
+}
+
+// recordBookRevision saves the book's current data as its next revision.
+// Nothing is recorded when the data is the same as in the latest revision.
+func recordBookRevision(tx *sql.Tx, bookID int64, action string, userID *int64, note string) error {
+	snapshot, err := selectBookSnapshot(tx, bookID)
+	if err != nil {
+		return err
+	}
+
+	var latest int
+	var previousData []byte
+	err = tx.QueryRow(`
+		SELECT revision, snapshot
+		FROM book_revisions
+		WHERE book_id = $1
+		ORDER BY revision DESC
+		LIMIT 1`, bookID,
+	).Scan(&latest, &previousData)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	var previous *BookSnapshot
+	if previousData != nil {
+		if err := json.Unmarshal(previousData, &previous); err != nil {
+			return err
+		}
+	}
+
+	changes, err := DiffBookSnapshots(previous, snapshot)
+	if err != nil {
+		return err
+	}
+	changedFields := []string{}
+	if previous != nil {
+		if len(changes) == 0 {
+			return nil
+		}
+		for _, change := range changes {
+			changedFields = append(changedFields, change.Field)
+		}
+	}
+
+	snapshotData, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	changedFieldsData, err := json.Marshal(changedFields)
+	if err != nil {
+		return err
+	}
+
+	var notePtr *string
+	if note != "" {
+		notePtr = &note
+	}
+	_, err = tx.Exec(`
+		INSERT INTO book_revisions (book_id, revision, action, user_id, note, snapshot, changed_fields)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		bookID, latest+1, action, userID, notePtr, snapshotData, changedFieldsData,
+	)
+	return err
+}
+
+// ensureBookBaseline records the book's current data as its first revision
+// when it has no history yet, so the first recorded edit can be reverted.
+func ensureBookBaseline(tx *sql.Tx, bookID int64) error {
+	var hasHistory bool
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM book_revisions WHERE book_id = $1)`, bookID).Scan(&hasHistory)
+	if err != nil || hasHistory {
+		return err
+	}
+	return recordBookRevision(tx, bookID, BookRevisionCreated, nil, "")
+}
+
+// selectBookSnapshot reads the book's catalog data within the transaction.
+func selectBookSnapshot(tx *sql.Tx, bookID int64) (*BookSnapshot, error) {
+	snapshot := &BookSnapshot{}
+	var publishedDate time.Time
+	err := tx.QueryRow(`
+		SELECT b.title, p.name, b.published_date, b.description, b.page_count, b.isbn_13, b.isbn_10
+		FROM books b
+		JOIN publishers p ON b.publisher_id = p.id
+		WHERE b.id = $1`, bookID,
+	).Scan(
+		&snapshot.Title,
+		&snapshot.Publisher,
+		&publishedDate,
+		&snapshot.Description,
+		&snapshot.PageCount,
+		&snapshot.ISBN13,
+		&snapshot.ISBN10,
+	)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.PublishedDate = JSONDate(publishedDate)
+
+	authors, err := selectBookAuthors(tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Authors = authors
+
+	err = tx.QueryRow(`
+		SELECT thumbnail_url, small_url, medium_url, large_url
+		FROM book_images
+		WHERE book_id = $1`, bookID,
+	).Scan(&snapshot.Images.ThumbnailUrl, &snapshot.Images.SmallUrl, &snapshot.Images.MediumUrl, &snapshot.Images.LargeUrl)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	chapters, err := selectChaptersForUpdate(tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Chapters = chapters
+
+	genres, err := selectBookGenres(tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Genres = genres
+
+	return snapshot, nil
+}
+
+func selectBookAuthors(tx *sql.Tx, bookID int64) ([]string, error) {
+	rows, err := tx.Query(`
+		SELECT a.name
+		FROM book_authors ba
+		JOIN authors a ON a.id = ba.author_id
+		WHERE ba.book_id = $1
+		ORDER BY ba.id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	authors := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		authors = append(authors, name)
+	}
+	return authors, rows.Err()
+}
+
+func selectBookGenres(tx *sql.Tx, bookID int64) ([]Genre, error) {
+	rows, err := tx.Query(`
+		SELECT g.id, g.name, g.parent_id
+		FROM genres g
+		JOIN book_genres bg ON g.id = bg.genre_id
+		WHERE bg.book_id = $1
+		ORDER BY g.name, g.id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	genres := []Genre{}
+	for rows.Next() {
+		var g Genre
+		if err := rows.Scan(&g.ID, &g.Name, &g.ParentID); err != nil {
+			return nil, err
+		}
+		genres = append(genres, g)
+	}
+	return genres, rows.Err()
+}
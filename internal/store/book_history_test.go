@@ -0,0 +1,83 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func historyTestSnapshot() *BookSnapshot {
+	return &BookSnapshot{
+		Title:     "The Hobbit",
+		Authors:   []string{"J.R.R. Tolkien"},
+		Publisher: "Allen & Unwin",
+		ISBN13:    "9780261102217",
+		Chapters: []Chapter{
+			{ID: 10, Number: 1, Title: "An Unexpected Party"},
+		},
+	}
+}
+
+func TestDiffBookSnapshots_ListsChangedFields(t *testing.T) {
+	from := historyTestSnapshot()
+	to := historyTestSnapshot()
+	to.Title = "The Hobbit, or There and Back Again"
+	to.Description = strPtr("A fantasy novel")
+	to.Chapters = append(to.Chapters, Chapter{ID: 11, Number: 2, Title: "Roast Mutton"})
+
+	changes, err := DiffBookSnapshots(from, to)
+	require.NoError(t, err)
+
+	fields := []string{}
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	assert.Equal(t, []string{BookFieldTitle, BookFieldDescription, BookFieldChapters}, fields)
+	assert.JSONEq(t, `"The Hobbit"`, string(changes[0].From))
+	assert.JSONEq(t, `"The Hobbit, or There and Back Again"`, string(changes[0].To))
+	assert.JSONEq(t, `null`, string(changes[1].From))
+}
+
+func TestDiffBookSnapshots_SameSnapshot(t *testing.T) {
+	changes, err := DiffBookSnapshots(historyTestSnapshot(), historyTestSnapshot())
+	require.NoError(t, err)
+
+	assert.Empty(t, changes)
+}
+
+func TestDiffBookSnapshots_EmptyListsMatchMissingOnes(t *testing.T) {
+	from := historyTestSnapshot()
+	to := historyTestSnapshot()
+	from.Genres = nil
+	to.Genres = []Genre{}
+
+	changes, err := DiffBookSnapshots(from, to)
+	require.NoError(t, err)
+
+	assert.Empty(t, changes)
+}
+
+func TestDiffBookSnapshots_FromNothing(t *testing.T) {
+	changes, err := DiffBookSnapshots(nil, historyTestSnapshot())
+	require.NoError(t, err)
+
+	fields := []string{}
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	assert.Equal(t, []string{BookFieldTitle, BookFieldAuthors, BookFieldPublisher, BookFieldISBN13, BookFieldChapters}, fields)
+}
+
+func TestBookSnapshot_RoundTrip(t *testing.T) {
+	data, err := json.Marshal(historyTestSnapshot())
+	require.NoError(t, err)
+
+	var snapshot BookSnapshot
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+
+	changes, err := DiffBookSnapshots(historyTestSnapshot(), &snapshot)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
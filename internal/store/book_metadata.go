@@ -0,0 +1,252 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/isbn"
+)
+
+var ErrBookMetadataNotFound = errors.New("book metadata not found")
+
+// BookMetadataQuery is a provider-neutral book search. Query is free text; the
+// other fields narrow it down when the provider supports them.
+type BookMetadataQuery struct {
+	Query  string
+	Title  string
+	Author string
+	ISBN   string
+	Limit  int
+}
+
+func (q BookMetadataQuery) IsEmpty() bool {
+	return strings.TrimSpace(q.Query+q.Title+q.Author+q.ISBN) == ""
+}
+
+// BookCandidate is a book as described by a metadata provider. SourceID is
+// the provider's own identifier, e.g. a Google Books volume id.
+type BookCandidate struct {
+	Book
+	Source   string `json:"source" example:"google"`
+	SourceID string `json:"source_id" example:"hFfhrCWiLSMC"`
+}
+
+// HasISBN reports whether code, an ISBN-10 or ISBN-13, is the candidate's
+// ISBN-13 or ISBN-10.
+func (c *BookCandidate) HasISBN(code string) bool {
+	want, err := isbn.ToISBN13(code)
+	if err != nil {
+		return false
+	}
+	if have, err := isbn.ToISBN13(c.ISBN13); err == nil && have == want {
+		return true
+	}
+	if c.ISBN10 != nil {
+		if have, err := isbn.ToISBN13(*c.ISBN10); err == nil && have == want {
+			return true
+		}
+	}
+	return false
+}
+
+// BookMetadataProvider looks up books in an external catalog. Implementations
+// return ErrBookMetadataNotFound for unknown ISBNs and wrap
+// ErrProviderUnavailable when the catalog cannot be reached.
+type BookMetadataProvider interface {
+	Name() string
+	SearchBooks(ctx context.Context, query BookMetadataQuery) ([]*BookCandidate, error)
+	GetBookByISBN(ctx context.Context, isbn string) (*BookCandidate, error)
+}
+
+type BookMetadataConfig struct {
+	// Providers are provider names in priority order. The first provider that
+	// answers supplies each book; the rest only fill in missing fields.
+	Providers []string
+}
+
+// BookMetadataConfigFromEnv reads BOOK_METADATA_PROVIDERS, a comma-separated
+// list such as "google,openlibrary".
+func BookMetadataConfigFromEnv() BookMetadataConfig {
+	var providers []string
+	for _, name := range strings.Split(getEnv("BOOK_METADATA_PROVIDERS", "google,openlibrary"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			providers = append(providers, name)
+		}
+	}
+	return BookMetadataConfig{Providers: providers}
+}
+
+// MergedBookMetadataProvider queries several providers and fills gaps in the
+// first provider's answer (covers, page counts, descriptions...) from the
+// others. Providers that are unavailable are skipped.
+type MergedBookMetadataProvider struct {
+	providers []BookMetadataProvider
+}
+
+// NewMergedBookMetadataProvider builds the provider chain named in config from
+// the available providers.
+func NewMergedBookMetadataProvider(config BookMetadataConfig, available ...BookMetadataProvider) (*MergedBookMetadataProvider, error) {
+	byName := map[string]BookMetadataProvider{}
+	for _, provider := range available {
+		byName[provider.Name()] = provider
+	}
+
+	merged := &MergedBookMetadataProvider{}
+	for _, name := range config.Providers {
+		provider, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown book metadata provider %q", name)
+		}
+		merged.providers = append(merged.providers, provider)
+	}
+	if len(merged.providers) == 0 {
+		return nil, errors.New("no book metadata providers configured")
+	}
+
+	return merged, nil
+}
+
+func (m *MergedBookMetadataProvider) Name() string {
+	names := make([]string, len(m.providers))
+	for i, provider := range m.providers {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// SearchBooks returns the results of the first provider that answers. Results
+// from the other providers fill gaps in books with a matching ISBN-13.
+func (m *MergedBookMetadataProvider) SearchBooks(ctx context.Context, query BookMetadataQuery) ([]*BookCandidate, error) {
+	results := make([][]*BookCandidate, len(m.providers))
+	errs := make([]error, len(m.providers))
+	m.each(func(i int, provider BookMetadataProvider) {
+		results[i], errs[i] = provider.SearchBooks(ctx, query)
+	})
+
+	var candidates []*BookCandidate
+	var lastErr error
+	for i := range m.providers {
+		if errs[i] != nil {
+			lastErr = errs[i]
+			continue
+		}
+		if candidates == nil {
+			candidates = results[i]
+			continue
+		}
+
+		byISBN := map[string]*BookCandidate{}
+		for _, candidate := range results[i] {
+			if candidate.ISBN13 != "" {
+				byISBN[candidate.ISBN13] = candidate
+			}
+		}
+		for _, candidate := range candidates {
+			if other, ok := byISBN[candidate.ISBN13]; ok && candidate.ISBN13 != "" {
+				FillBookGaps(&candidate.Book, &other.Book)
+			}
+		}
+	}
+
+	if candidates == nil {
+		return nil, lastErr
+	}
+	return candidates, nil
+}
+
+// GetBookByISBN asks every provider for the ISBN and merges the answers in
+// priority order.
+func (m *MergedBookMetadataProvider) GetBookByISBN(ctx context.Context, isbn string) (*BookCandidate, error) {
+	results := make([]*BookCandidate, len(m.providers))
+	errs := make([]error, len(m.providers))
+	m.each(func(i int, provider BookMetadataProvider) {
+		results[i], errs[i] = provider.GetBookByISBN(ctx, isbn)
+	})
+
+	var merged *BookCandidate
+	var lastErr error
+	for i := range m.providers {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], ErrBookMetadataNotFound) {
+				lastErr = errs[i]
+			}
+			continue
+		}
+		if merged == nil {
+			merged = results[i]
+			continue
+		}
+		FillBookGaps(&merged.Book, &results[i].Book)
+	}
+
+	if merged != nil {
+		return merged, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrBookMetadataNotFound
+}
+
+func (m *MergedBookMetadataProvider) each(fn func(i int, provider BookMetadataProvider)) {
+	var wg sync.WaitGroup
+	for i, provider := range m.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(i, provider)
+		}()
+	}
+	wg.Wait()
+}
+
+// FillBookGaps copies the fields that are missing from dst out of src. Fields
+// dst already has are never overwritten.
+func FillBookGaps(dst, src *Book) {
+	if dst.Title == "" {
+		dst.Title = src.Title
+	}
+	if len(dst.Authors) == 0 {
+		dst.Authors = src.Authors
+	}
+	if dst.Publisher == "" {
+		dst.Publisher = src.Publisher
+	}
+	if dst.PublishedDate == (JSONDate{}) {
+		dst.PublishedDate = src.PublishedDate
+	}
+	if isBlank(dst.Description) {
+		dst.Description = src.Description
+	}
+	if dst.PageCount == nil || *dst.PageCount == 0 {
+		dst.PageCount = src.PageCount
+	}
+	if dst.ISBN13 == "" {
+		dst.ISBN13 = src.ISBN13
+	}
+	if isBlank(dst.ISBN10) {
+		dst.ISBN10 = src.ISBN10
+	}
+	if isBlank(dst.Images.ThumbnailUrl) {
+		dst.Images.ThumbnailUrl = src.Images.ThumbnailUrl
+	}
+	if isBlank(dst.Images.SmallUrl) {
+		dst.Images.SmallUrl = src.Images.SmallUrl
+	}
+	if isBlank(dst.Images.MediumUrl) {
+		dst.Images.MediumUrl = src.Images.MediumUrl
+	}
+	if isBlank(dst.Images.LargeUrl) {
+		dst.Images.LargeUrl = src.Images.LargeUrl
+	}
+	if len(dst.Genres) == 0 {
+		dst.Genres = src.Genres
+	}
+}
+
+func isBlank(s *string) bool {
+	return s == nil || *s == ""
+}
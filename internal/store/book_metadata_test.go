@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMetadataProvider struct {
+	name    string
+	results []*BookCandidate
+	err     error
+}
+
+func (p *stubMetadataProvider) Name() string {
+	return p.name
+}
+
+func (p *stubMetadataProvider) SearchBooks(ctx context.Context, query BookMetadataQuery) ([]*BookCandidate, error) {
+	return p.results, p.err
+}
+
+func (p *stubMetadataProvider) GetBookByISBN(ctx context.Context, isbn string) (*BookCandidate, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	for _, result := range p.results {
+		if result.ISBN13 == isbn {
+			return result, nil
+		}
+	}
+	return nil, ErrBookMetadataNotFound
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func newTestMergedProvider(t *testing.T, providers ...BookMetadataProvider) *MergedBookMetadataProvider {
+	var names []string
+	for _, provider := range providers {
+		names = append(names, provider.Name())
+	}
+	merged, err := NewMergedBookMetadataProvider(BookMetadataConfig{Providers: names}, providers...)
+	require.NoError(t, err)
+	return merged
+}
+
+func TestNewMergedBookMetadataProvider_UnknownProvider(t *testing.T) {
+	_, err := NewMergedBookMetadataProvider(BookMetadataConfig{Providers: []string{"amazon"}}, &stubMetadataProvider{name: "google"})
+
+	assert.Error(t, err)
+}
+
+func TestMergedGetBookByISBN_FillsGapsFromSecondary(t *testing.T) {
+	pages := 310
+	primary := &stubMetadataProvider{name: "google", results: []*BookCandidate{{
+		Book:   Book{Title: "The Hobbit", ISBN13: "9780261102217", Description: strPtr("From Google")},
+		Source: "google",
+	}}}
+	secondary := &stubMetadataProvider{name: "openlibrary", results: []*BookCandidate{{
+		Book: Book{
+			Title:       "Hobbit",
+			ISBN13:      "9780261102217",
+			Description: strPtr("From Open Library"),
+			PageCount:   &pages,
+			Images:      BookImages{LargeUrl: strPtr("https://covers.example.com/l.jpg")},
+		},
+		Source: "openlibrary",
+	}}}
+
+	book, err := newTestMergedProvider(t, primary, secondary).GetBookByISBN(context.Background(), "9780261102217")
+
+	require.NoError(t, err)
+	assert.Equal(t, "google", book.Source)
+	assert.Equal(t, "The Hobbit", book.Title)
+	assert.Equal(t, "From Google", *book.Description)
+	assert.Equal(t, 310, *book.PageCount)
+	assert.Equal(t, "https://covers.example.com/l.jpg", *book.Images.LargeUrl)
+}
+
+func TestMergedGetBookByISBN_FallsBackWhenPrimaryUnavailable(t *testing.T) {
+	primary := &stubMetadataProvider{name: "google", err: ErrProviderUnavailable}
+	secondary := &stubMetadataProvider{name: "openlibrary", results: []*BookCandidate{{
+		Book:   Book{Title: "The Hobbit", ISBN13: "9780261102217"},
+		Source: "openlibrary",
+	}}}
+
+	book, err := newTestMergedProvider(t, primary, secondary).GetBookByISBN(context.Background(), "9780261102217")
+
+	require.NoError(t, err)
+	assert.Equal(t, "openlibrary", book.Source)
+}
+
+func TestMergedGetBookByISBN_NotFoundAnywhere(t *testing.T) {
+	merged := newTestMergedProvider(t, &stubMetadataProvider{name: "google"}, &stubMetadataProvider{name: "openlibrary"})
+
+	_, err := merged.GetBookByISBN(context.Background(), "9780261102217")
+
+	assert.ErrorIs(t, err, ErrBookMetadataNotFound)
+}
+
+func TestMergedGetBookByISBN_AllUnavailable(t *testing.T) {
+	merged := newTestMergedProvider(t,
+		&stubMetadataProvider{name: "google", err: ErrProviderUnavailable},
+		&stubMetadataProvider{name: "openlibrary", err: errors.New("boom")},
+	)
+
+	_, err := merged.GetBookByISBN(context.Background(), "9780261102217")
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrBookMetadataNotFound)
+}
+
+func TestMergedSearchBooks_FillsGapsByISBN(t *testing.T) {
+	pages := 310
+	primary := &stubMetadataProvider{name: "google", results: []*BookCandidate{
+		{Book: Book{Title: "The Hobbit", ISBN13: "9780261102217"}},
+		{Book: Book{Title: "No ISBN"}},
+	}}
+	secondary := &stubMetadataProvider{name: "openlibrary", results: []*BookCandidate{
+		{Book: Book{Title: "Hobbit", ISBN13: "9780261102217", PageCount: &pages}},
+		{Book: Book{Title: "Other", PageCount: &pages}},
+	}}
+
+	books, err := newTestMergedProvider(t, primary, secondary).SearchBooks(context.Background(), BookMetadataQuery{Query: "hobbit"})
+
+	require.NoError(t, err)
+	require.Len(t, books, 2)
+	assert.Equal(t, 310, *books[0].PageCount)
+	assert.Nil(t, books[1].PageCount)
+}
+
+// fakeGoogleISBNSearch answers every search with a related volume followed by
+// the volumes in items.
+func fakeGoogleISBNSearch(t *testing.T, items string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"totalItems": 2, "items": [
+			{"id": "related", "volumeInfo": {"title": "The Annotated Hobbit",
+				"industryIdentifiers": [{"type": "ISBN_13", "identifier": "9780618134700"}]}}` + items + `]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGoogleBooksMetadataProvider_GetBookByISBN(t *testing.T) {
+	server := fakeGoogleISBNSearch(t, `,
+		{"id": "abc", "volumeInfo": {"title": "The Hobbit",
+			"industryIdentifiers": [{"type": "ISBN_10", "identifier": "0261102214"}]}}`)
+	provider := NewGoogleBooksMetadataProvider(newTestGoogleBooksStore(server.URL))
+
+	book, err := provider.GetBookByISBN(context.Background(), "9780261102217")
+
+	require.NoError(t, err)
+	assert.Equal(t, "google", book.Source)
+	assert.Equal(t, "abc", book.SourceID)
+	assert.Equal(t, "The Hobbit", book.Title)
+}
+
+func TestGoogleBooksMetadataProvider_GetBookByISBN_RejectsOtherISBNs(t *testing.T) {
+	server := fakeGoogleISBNSearch(t, "")
+	provider := NewGoogleBooksMetadataProvider(newTestGoogleBooksStore(server.URL))
+
+	_, err := provider.GetBookByISBN(context.Background(), "9780261102217")
+
+	assert.ErrorIs(t, err, ErrBookMetadataNotFound)
+}
+
+func TestFillBookGaps_KeepsExistingValues(t *testing.T) {
+	dst := Book{Title: "Kept", Images: BookImages{ThumbnailUrl: strPtr("")}}
+	src := Book{Title: "Ignored", Publisher: "Filled", Images: BookImages{ThumbnailUrl: strPtr("thumb.jpg")}}
+
+	FillBookGaps(&dst, &src)
+
+	assert.Equal(t, "Kept", dst.Title)
+	assert.Equal(t, "Filled", dst.Publisher)
+	assert.Equal(t, "thumb.jpg", *dst.Images.ThumbnailUrl)
+}
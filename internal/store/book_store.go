@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 )
@@ -13,17 +14,67 @@ import (
 type JSONDate time.Time
 
 type Book struct {
-	ID            int64      `json:"id"`
-	Title         string     `json:"title"`
-	Authors       []string   `json:"authors"`
-	Publisher     string     `json:"publisher"`
-	PublishedDate JSONDate   `json:"published_date"`
-	Description   *string    `json:"description"`
-	PageCount     *int       `json:"page_count"`
-	ISBN13        string     `json:"isbn_13"`
-	ISBN10        *string    `json:"isbn_10"`
-	Images        BookImages `json:"book_images"`
-	Chapters      []Chapter  `json:"chapters"`
+	ID            int64       `json:"id"`
+	WorkID        int64       `json:"work_id"`
+	Title         string      `json:"title"`
+	Authors       []string    `json:"authors"`
+	Publisher     string      `json:"publisher"`
+	PublishedDate JSONDate    `json:"published_date"`
+	Description   *string     `json:"description"`
+	PageCount     *int        `json:"page_count"`
+	ISBN13        string      `json:"isbn_13"`
+	ISBN10        *string     `json:"isbn_10"`
+	Images        BookImages  `json:"book_images"`
+	Chapters      []Chapter   `json:"chapters"`
+	Genres        []Genre     `json:"genres"`
+	Tags          []string    `json:"tags"`
+	Series        *BookSeries `json:"series,omitempty"`
+	// ArchivedAt is set when the book has been removed from the catalog.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// Version is bumped once for each change to the book, including its
+	// chapters, authors and other catalog data. Readers tagging the book do not
+	// change it.
+	Version int `json:"version"`
+}
+
+// BookFilter narrows GetAllBooks. Zero values mean "no filter".
+type BookFilter struct {
+	Query   string // matches title or author name
+	GenreID *int64 // includes books in any sub-genre
+	Tag     string
+	// Archived lists archived books instead of the catalog.
+	Archived bool
+}
+
+// UpdateBookOptions controls how UpdateBook treats chapters that are left out
+// of the update, and what is recorded about the change in the book's history.
+type UpdateBookOptions struct {
+	// DeleteCommentedChapters allows removing chapters that have comments,
+	// deleting the comments with them.
+	DeleteCommentedChapters bool
+	// EditorID is the user making the change, recorded in the book's history.
+	EditorID *int64
+	// Note is shown next to the change in the book's history.
+	Note string
+	// Fields limits the update to the named book fields; the book's other
+	// data is left as it is. Nil updates every field.
+	Fields []string
+	// IfMatch makes the update fail with ErrVersionMismatch unless the book's
+	// version is one of these. Nil updates any version.
+	IfMatch []int
+}
+
+// writes reports whether the update writes any of the fields.
+func (o UpdateBookOptions) writes(fields ...string) bool {
+	if o.Fields == nil {
+		return true
+	}
+	for _, field := range fields {
+		if slices.Contains(o.Fields, field) {
+			return true
+		}
+	}
+	return false
 }
 
 type BookImages struct {
@@ -37,6 +88,12 @@ type Chapter struct {
 	ID     int64  `json:"id"`
 	Number int    `json:"number"`
 	Title  string `json:"title"`
+	// Part is the heading ("Part One: The Shire") the chapter is grouped under.
+	Part *string `json:"part" example:"Part One"`
+	// StartPage and EndPage are the chapter's printed page range, used to map
+	// reading progress to a chapter.
+	StartPage *int `json:"start_page" example:"1"`
+	EndPage   *int `json:"end_page" example:"24"`
 }
 
 type PostgresBookStore struct {
@@ -50,9 +107,19 @@ func NewPostgresBookStore(db *sql.DB) *PostgresBookStore {
 type BookStore interface {
 	AddBook(*Book) (*Book, error)
 	GetBookByID(id int64) (*Book, error)
-	UpdateBook(book *Book) error
-	DeleteBookByID(id int64) error
-	GetAllBooks(page, limit int) ([]*Book, int, error)
+	GetBookByISBN13(isbn13 string) (*Book, error)
+	GetBookIDsByISBN13(isbn13s []string) (map[string]int64, error)
+	UpdateBook(book *Book, opts UpdateBookOptions) error
+	ArchiveBook(id int64, ifMatch []int) error
+	RestoreBook(id int64) error
+	GetBookPurgeReport(id int64) (*BookPurgeReport, error)
+	PurgeBook(id int64) error
+	GetAllBooks(page, limit int, filter BookFilter) ([]*Book, int, error)
+	FindDuplicateBooks(book *Book) ([]*DuplicateCandidate, error)
+	GetSuspectedDuplicates(page, limit int) ([]*DuplicatePair, int, error)
+	GetBookHistory(bookID int64, page, limit int) ([]*BookRevision, int, error)
+	GetBookRevision(bookID int64, revision int) (*BookRevision, error)
+	RevertBook(bookID int64, revision int, opts UpdateBookOptions) error
 }
 
 func (pg *PostgresBookStore) AddBook(book *Book) (_ *Book, err error) {
@@ -78,12 +145,21 @@ func (pg *PostgresBookStore) AddBook(book *Book) (_ *Book, err error) {
 		return nil, err
 	}
 
+	// A new book starts as the only edition of its own work; editions are
+	// grouped afterwards through the WorkStore.
+	var workID int64
+	err = tx.QueryRow(`INSERT INTO works (title) VALUES ($1) RETURNING id`, book.Title).Scan(&workID)
+	if err != nil {
+		return nil, err
+	}
+	book.WorkID = workID
+
 	var bookID int64
 	err = tx.QueryRow(`
-        INSERT INTO books (title, publisher_id, published_date, description, page_count, isbn_13, isbn_10) 
-        VALUES ($1,$2,$3,$4,$5,$6,$7) 
+        INSERT INTO books (title, publisher_id, published_date, description, page_count, isbn_13, isbn_10, work_id) 
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8) 
         RETURNING id`,
-		book.Title, publisherID, book.PublishedDate.ToTime(), book.Description, book.PageCount, book.ISBN13, book.ISBN10,
+		book.Title, publisherID, book.PublishedDate.ToTime(), book.Description, book.PageCount, book.ISBN13, book.ISBN10, workID,
 	).Scan(&bookID)
 	if err != nil {
 		return nil, err
@@ -120,15 +196,29 @@ func (pg *PostgresBookStore) AddBook(book *Book) (_ *Book, err error) {
 
 	for _, ch := range book.Chapters {
 		_, err := tx.Exec(`
-			INSERT INTO chapters (book_id, number, title) 
-			VALUES ($1, $2, $3)`,
-			bookID, ch.Number, ch.Title,
+			INSERT INTO chapters (book_id, number, title, part, start_page, end_page)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			bookID, ch.Number, ch.Title, ch.Part, ch.StartPage, ch.EndPage,
 		)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if err := updateBookGenres(tx, bookID, book.Genres); err != nil {
+		return nil, err
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionCreated, nil, ""); err != nil {
+		return nil, err
+	}
+
+	// Adding the book's authors, images and chapters has bumped its version.
+	err = tx.QueryRow(`SELECT version FROM books WHERE id = $1`, bookID).Scan(&book.Version)
+	if err != nil {
+		return nil, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
@@ -141,11 +231,12 @@ func (pg *PostgresBookStore) GetBookByID(id int64) (*Book, error) {
 	book := &Book{}
 
 	err := pg.db.QueryRow(`
-        SELECT b.id, b.title, b.published_date, b.description, b.page_count, b.isbn_13, b.isbn_10, p.name
+        SELECT b.id, b.work_id, b.title, b.published_date, b.description, b.page_count, b.isbn_13, b.isbn_10, p.name, b.archived_at, b.version
         FROM books b
         JOIN publishers p ON b.publisher_id = p.id
         WHERE b.id = $1`, id).Scan(
 		&book.ID,
+		&book.WorkID,
 		&book.Title,
 		&book.PublishedDate,
 		&book.Description,
@@ -153,6 +244,8 @@ func (pg *PostgresBookStore) GetBookByID(id int64) (*Book, error) {
 		&book.ISBN13,
 		&book.ISBN10,
 		&book.Publisher,
+		&book.ArchivedAt,
+		&book.Version,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -198,7 +291,7 @@ func (pg *PostgresBookStore) GetBookByID(id int64) (*Book, error) {
 	book.Images = images
 
 	chapterRows, err := pg.db.Query(`
-        SELECT id, number, title
+        SELECT id, number, title, part, start_page, end_page
         FROM chapters
         WHERE book_id = $1
         ORDER BY number
@@ -217,17 +310,154 @@ func (pg *PostgresBookStore) GetBookByID(id int64) (*Book, error) {
 	var chapters []Chapter
 	for chapterRows.Next() {
 		var ch Chapter
-		if err := chapterRows.Scan(&ch.ID, &ch.Number, &ch.Title); err != nil {
+		if err := chapterRows.Scan(&ch.ID, &ch.Number, &ch.Title, &ch.Part, &ch.StartPage, &ch.EndPage); err != nil {
 			return nil, err
 		}
 		chapters = append(chapters, ch)
 	}
 	book.Chapters = chapters
 
+	genres, err := pg.getBookGenres(id)
+	if err != nil {
+		return nil, err
+	}
+	book.Genres = genres
+
+	tags, err := pg.getBookTagNames(id)
+	if err != nil {
+		return nil, err
+	}
+	book.Tags = tags
+
+	var series BookSeries
+	err = pg.db.QueryRow(`
+        SELECT s.id, s.title, sb.position
+        FROM series_books sb
+        JOIN series s ON sb.series_id = s.id
+        WHERE sb.book_id = $1
+	`, id).Scan(&series.ID, &series.Title, &series.Position)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		book.Series = &series
+	}
+
 	return book, nil
 }
 
-func (pg *PostgresBookStore) UpdateBook(book *Book) error {
+// GetBookByISBN13 returns the book with the given ISBN-13, or nil when the
+// catalog does not have it.
+func (pg *PostgresBookStore) GetBookByISBN13(isbn13 string) (*Book, error) {
+	var id int64
+	err := pg.db.QueryRow(`SELECT id FROM books WHERE isbn_13 = $1`, isbn13).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.GetBookByID(id)
+}
+
+// GetBookIDsByISBN13 returns the ids of the catalog books matching the given
+// ISBN-13s, keyed by ISBN-13. ISBNs not in the catalog are left out.
+func (pg *PostgresBookStore) GetBookIDsByISBN13(isbn13s []string) (map[string]int64, error) {
+	ids := map[string]int64{}
+	if len(isbn13s) == 0 {
+		return ids, nil
+	}
+
+	placeholders := make([]string, len(isbn13s))
+	args := make([]interface{}, len(isbn13s))
+	for i, isbn := range isbn13s {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = isbn
+	}
+
+	rows, err := pg.db.Query(`
+        SELECT isbn_13, id
+        FROM books
+        WHERE isbn_13 IN (`+strings.Join(placeholders, ", ")+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	for rows.Next() {
+		var isbn string
+		var id int64
+		if err := rows.Scan(&isbn, &id); err != nil {
+			return nil, err
+		}
+		ids[isbn] = id
+	}
+
+	return ids, rows.Err()
+}
+
+func (pg *PostgresBookStore) getBookGenres(bookID int64) ([]Genre, error) {
+	rows, err := pg.db.Query(`
+        SELECT g.id, g.name, g.parent_id
+        FROM genres g
+        JOIN book_genres bg ON g.id = bg.genre_id
+        WHERE bg.book_id = $1
+        ORDER BY g.name
+    `, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	var genres []Genre
+	for rows.Next() {
+		var g Genre
+		if err := rows.Scan(&g.ID, &g.Name, &g.ParentID); err != nil {
+			return nil, err
+		}
+		genres = append(genres, g)
+	}
+	return genres, rows.Err()
+}
+
+func (pg *PostgresBookStore) getBookTagNames(bookID int64) ([]string, error) {
+	rows, err := pg.db.Query(`
+        SELECT DISTINCT t.name
+        FROM tags t
+        JOIN book_tags bt ON t.id = bt.tag_id
+        WHERE bt.book_id = $1
+        ORDER BY t.name
+    `, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
+
+func (pg *PostgresBookStore) UpdateBook(book *Book, opts UpdateBookOptions) error {
 	tx, err := pg.db.Begin()
 	if err != nil {
 		return err
@@ -238,26 +468,63 @@ func (pg *PostgresBookStore) UpdateBook(book *Book) error {
 		}
 	}()
 
-	if err := updateBookCore(tx, book); err != nil {
-		return fmt.Errorf("failed to update core: %w", err)
+	if err := lockBook(tx, book.ID, opts.IfMatch); err != nil {
+		return err
 	}
 
-	if err := updateBookAuthors(tx, book.ID, book.Authors); err != nil {
-		return fmt.Errorf("failed to update book's authors: %w", err)
+	if err := applyBookUpdate(tx, book, opts, BookRevisionUpdated); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applyBookUpdate writes the book's data and records the change in its
+// history. The book must be locked by the transaction.
+func applyBookUpdate(tx *sql.Tx, book *Book, opts UpdateBookOptions, action string) error {
+	if err := ensureBookBaseline(tx, book.ID); err != nil {
+		return fmt.Errorf("failed to record book's history: %w", err)
 	}
 
-	if err := updateBookImages(tx, book.ID, book.Images); err != nil {
-		return fmt.Errorf("failed to update book's images: %w", err)
+	if opts.writes(BookFieldTitle, BookFieldPublisher, BookFieldPublishedDate, BookFieldDescription,
+		BookFieldPageCount, BookFieldISBN13, BookFieldISBN10) {
+		if err := updateBookCore(tx, book); err != nil {
+			return fmt.Errorf("failed to update core: %w", err)
+		}
 	}
 
-	if err := updateBookChapters(tx, book.ID, book.Chapters); err != nil {
-		return fmt.Errorf("failed to update book's chapters: %w", err)
+	if opts.writes(BookFieldAuthors) {
+		if err := updateBookAuthors(tx, book.ID, book.Authors); err != nil {
+			return fmt.Errorf("failed to update book's authors: %w", err)
+		}
 	}
 
-	return tx.Commit()
+	if opts.writes(BookFieldImages) {
+		if err := updateBookImages(tx, book.ID, book.Images); err != nil {
+			return fmt.Errorf("failed to update book's images: %w", err)
+		}
+	}
+
+	if opts.writes(BookFieldChapters) {
+		if err := updateBookChapters(tx, book.ID, book.Chapters, opts); err != nil {
+			return fmt.Errorf("failed to update book's chapters: %w", err)
+		}
+	}
+
+	if opts.writes(BookFieldGenres) {
+		if err := updateBookGenres(tx, book.ID, book.Genres); err != nil {
+			return fmt.Errorf("failed to update book's genres: %w", err)
+		}
+	}
+
+	if err := recordBookRevision(tx, book.ID, action, opts.EditorID, opts.Note); err != nil {
+		return fmt.Errorf("failed to record book's history: %w", err)
+	}
+
+	return nil
 }
 
-func (pg *PostgresBookStore) GetAllBooks(page, limit int) ([]*Book, int, error) {
+func (pg *PostgresBookStore) GetAllBooks(page, limit int, filter BookFilter) ([]*Book, int, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -267,9 +534,11 @@ func (pg *PostgresBookStore) GetAllBooks(page, limit int) ([]*Book, int, error)
 
 	offset := (page - 1) * limit
 
+	where, args := bookFilterClause(filter, 2)
+
 	rows, err := pg.db.Query(`
-		SELECT b.id, b.title, b.published_date, b.description, b.page_count, b.isbn_13, b.isbn_10,
-		p.name AS publisher,
+		SELECT b.id, b.work_id, b.title, b.published_date, b.description, b.page_count, b.isbn_13, b.isbn_10,
+		b.archived_at, b.version, p.name AS publisher,
     
     	COALESCE(
         	json_agg(DISTINCT a.name) FILTER (WHERE a.id IS NOT NULL),
@@ -289,13 +558,41 @@ func (pg *PostgresBookStore) GetAllBooks(page, limit int) ([]*Book, int, error)
 		COALESCE(
 			json_agg(
 				jsonb_build_object(
-					'id',     c.id,
-					'number', c.number,
-					'title',  c.title
+					'id',         c.id,
+					'number',     c.number,
+					'title',      c.title,
+					'part',       c.part,
+					'start_page', c.start_page,
+					'end_page',   c.end_page
 				) ORDER BY c.number
 			) FILTER (WHERE c.id IS NOT NULL),
 			'[]'
-		) AS chapters
+		) AS chapters,
+
+		COALESCE((
+			SELECT json_agg(jsonb_build_object(
+				'id',        g.id,
+				'name',      g.name,
+				'parent_id', g.parent_id
+			) ORDER BY g.name)
+			FROM genres g
+			JOIN book_genres bg ON g.id = bg.genre_id
+			WHERE bg.book_id = b.id
+		), '[]') AS genres,
+
+		COALESCE((
+			SELECT json_agg(DISTINCT t.name)
+			FROM tags t
+			JOIN book_tags bt ON t.id = bt.tag_id
+			WHERE bt.book_id = b.id
+		), '[]') AS tags,
+
+		(
+			SELECT json_build_object('id', s.id, 'title', s.title, 'position', sb.position)
+			FROM series_books sb
+			JOIN series s ON sb.series_id = s.id
+			WHERE sb.book_id = b.id
+		) AS series
 
 		FROM books b
 		LEFT JOIN publishers p ON b.publisher_id = p.id
@@ -303,13 +600,13 @@ func (pg *PostgresBookStore) GetAllBooks(page, limit int) ([]*Book, int, error)
 		LEFT JOIN authors a ON ba.author_id = a.id
 		LEFT JOIN book_images bi ON b.id = bi.book_id
 		LEFT JOIN chapters c ON b.id = c.book_id
-
+		`+where+`
 		GROUP BY 
 			b.id, p.name, bi.thumbnail_url, bi.small_url, bi.medium_url, bi.large_url
 
-		ORDER BY b.published_date DESC
+		ORDER BY b.published_date DESC, b.id DESC
 		LIMIT $1 OFFSET $2;
-	`, limit, offset)
+	`, append([]interface{}{limit, offset}, args...)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -327,19 +624,28 @@ func (pg *PostgresBookStore) GetAllBooks(page, limit int) ([]*Book, int, error)
 		var authorsJSON []byte
 		var imagesJSON []byte
 		var chaptersJSON []byte
+		var genresJSON []byte
+		var tagsJSON []byte
+		var seriesJSON []byte
 
 		err := rows.Scan(
 			&book.ID,
+			&book.WorkID,
 			&book.Title,
 			&book.PublishedDate,
 			&book.Description,
 			&book.PageCount,
 			&book.ISBN13,
 			&book.ISBN10,
+			&book.ArchivedAt,
+			&book.Version,
 			&book.Publisher,
 			&authorsJSON,
 			&imagesJSON,
 			&chaptersJSON,
+			&genresJSON,
+			&tagsJSON,
+			&seriesJSON,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -357,17 +663,75 @@ func (pg *PostgresBookStore) GetAllBooks(page, limit int) ([]*Book, int, error)
 			return nil, 0, err
 		}
 
+		if err := json.Unmarshal(genresJSON, &book.Genres); err != nil {
+			return nil, 0, err
+		}
+
+		if err := json.Unmarshal(tagsJSON, &book.Tags); err != nil {
+			return nil, 0, err
+		}
+
+		if seriesJSON != nil {
+			if err := json.Unmarshal(seriesJSON, &book.Series); err != nil {
+				return nil, 0, err
+			}
+		}
+
 		books = append(books, book)
 	}
 
+	countWhere, countArgs := bookFilterClause(filter, 0)
+
 	var count int
-	err = pg.db.QueryRow(`SELECT COUNT(*) FROM books`).Scan(&count)
+	err = pg.db.QueryRow(`SELECT COUNT(*) FROM books b `+countWhere, countArgs...).Scan(&count)
 	if err != nil {
 		return nil, 0, err
 	}
 	return books, count, nil
 }
 
+// bookFilterClause builds the WHERE clause for a BookFilter. Placeholders are
+// numbered after the first argOffset arguments of the query.
+func bookFilterClause(filter BookFilter, argOffset int) (string, []interface{}) {
+	conditions := []string{"b.archived_at IS NULL"}
+	if filter.Archived {
+		conditions[0] = "b.archived_at IS NOT NULL"
+	}
+	args := []interface{}{}
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		args = append(args, "%"+q+"%")
+		n := argOffset + len(args)
+		conditions = append(conditions, fmt.Sprintf(`(b.title ILIKE $%d OR EXISTS (
+			SELECT 1 FROM book_authors fba
+			JOIN authors fa ON fba.author_id = fa.id
+			WHERE fba.book_id = b.id AND fa.name ILIKE $%d))`, n, n))
+	}
+
+	if filter.GenreID != nil {
+		args = append(args, *filter.GenreID)
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM genres WHERE id = $%d
+				UNION ALL
+				SELECT g.id FROM genres g JOIN subtree s ON g.parent_id = s.id
+			)
+			SELECT 1 FROM book_genres fbg
+			JOIN subtree ON fbg.genre_id = subtree.id
+			WHERE fbg.book_id = b.id)`, argOffset+len(args)))
+	}
+
+	if tag := NormalizeTag(filter.Tag); tag != "" {
+		args = append(args, tag)
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM book_tags fbt
+			JOIN tags ft ON fbt.tag_id = ft.id
+			WHERE fbt.book_id = b.id AND ft.name = $%d)`, argOffset+len(args)))
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
 func updateBookCore(tx *sql.Tx, book *Book) error {
 	var publisherID int
 	err := tx.QueryRow(`
@@ -386,7 +750,7 @@ func updateBookCore(tx *sql.Tx, book *Book) error {
         SET title = $1, publisher_id = $2, published_date = $3,
             description = $4, page_count = $5, isbn_13 = $6, isbn_10 = $7
         WHERE id = $8`,
-		book.Title, publisherID, book.PublishedDate, book.Description,
+		book.Title, publisherID, book.PublishedDate.ToTime(), book.Description,
 		book.PageCount, book.ISBN13, book.ISBN10, book.ID,
 	)
 	return err
@@ -435,54 +799,31 @@ func updateBookImages(tx *sql.Tx, bookID int64, images BookImages) error {
 	return err
 }
 
-func updateBookChapters(tx *sql.Tx, bookID int64, chapters []Chapter) error {
-	_, err := tx.Exec(`DELETE FROM chapters WHERE book_id = $1`, bookID)
-	if err != nil {
-		return err
-	}
-
-	for _, ch := range chapters {
-		_, err = tx.Exec(`
-            INSERT INTO chapters (book_id, number, title)
-            VALUES ($1, $2, $3)`,
-			bookID, ch.Number, ch.Title,
-		)
-		if err != nil {
-			return err
-		}
+// updateBookChapters applies the chapter list of an updated book as a diff so
+// chapter ids, and the discussions attached to them, survive the update. A nil
+// list leaves the chapters untouched.
+func updateBookChapters(tx *sql.Tx, bookID int64, chapters []Chapter, opts UpdateBookOptions) error {
+	if chapters == nil {
+		return nil
 	}
-	return nil
-}
 
-func (pg *PostgresBookStore) DeleteBookByID(id int64) error {
-	tx, err := pg.db.Begin()
+	existing, err := selectChaptersForUpdate(tx, bookID)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
-			log.Printf("failed to rollback transaction: %v", rbErr)
-		}
-	}()
-
-	res, err := tx.Exec(`DELETE FROM books WHERE id = $1`, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete book: %w", err)
-	}
 
-	rowsAffected, err := res.RowsAffected()
+	plan, err := planChapterChanges(existing, chapters)
 	if err != nil {
-		return fmt.Errorf("failed to check deleted rows: %w", err)
-	}
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit delete: %w", err)
+	if len(plan.deletes) > 0 && !opts.DeleteCommentedChapters {
+		if err := checkChaptersWithoutComments(tx, plan.deletes); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return applyChapterPlan(tx, bookID, plan)
 }
 
 func (d *JSONDate) UnmarshalJSON(b []byte) error {
@@ -15,6 +15,7 @@ type ChapterComment struct {
 	User      *User     `json:"user,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
 }
 
 type PostgresChapterCommentStore struct {
@@ -27,9 +28,9 @@ func NewPostgresChapterCommentStore(db *sql.DB) *PostgresChapterCommentStore {
 
 type ChapterCommentStore interface {
 	AddComment(comment *ChapterComment, chapterID int64, userID int64) (*ChapterComment, error)
-	UpdateComment(comment *ChapterComment) error
+	UpdateComment(comment *ChapterComment, ifMatch []int) error
 	GetCommentByID(id int64) (*ChapterComment, error)
-	DeleteCommentByID(id int64) error
+	DeleteCommentByID(id int64, ifMatch []int) error
 	GetCommentsByChapterID(chapterID int64, page, limit int) ([]*ChapterComment, int, error)
 }
 
@@ -37,9 +38,9 @@ func (cs *PostgresChapterCommentStore) AddComment(comment *ChapterComment, chapt
 	err := cs.db.QueryRow(`
 		INSERT INTO comments (body, user_id, chapter_id)
 		VALUES ($1, $2, $3)
-		RETURNING id, created_at, updated_at`,
+		RETURNING id, created_at, updated_at, version`,
 		comment.Body, userID, chapterID,
-	).Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt)
+	).Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt, &comment.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -50,23 +51,41 @@ func (cs *PostgresChapterCommentStore) AddComment(comment *ChapterComment, chapt
 	return comment, nil
 }
 
-func (cs *PostgresChapterCommentStore) UpdateComment(comment *ChapterComment) error {
-	err := cs.db.QueryRow(`
+// UpdateComment saves the comment's body. It returns sql.ErrNoRows when there
+// is no such comment and ErrVersionMismatch when the comment's version is not
+// one of ifMatch. A nil ifMatch updates any version.
+func (cs *PostgresChapterCommentStore) UpdateComment(comment *ChapterComment, ifMatch []int) error {
+	query := `
 		UPDATE comments
 		SET body = $1,
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2
-		RETURNING updated_at`,
-		comment.Body, comment.ID,
-	).Scan(&comment.UpdatedAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return sql.ErrNoRows
-		}
-		return err
+		WHERE id = $2`
+	args := []interface{}{comment.Body, comment.ID}
+	if ifMatch != nil {
+		query += ` AND version = ANY($3)`
+		args = append(args, versionArgs(ifMatch))
 	}
 
-	return nil
+	err := cs.db.QueryRow(query+`
+		RETURNING updated_at, version`, args...,
+	).Scan(&comment.UpdatedAt, &comment.Version)
+	if err == sql.ErrNoRows && ifMatch != nil {
+		return cs.commentMissingOrChanged(comment.ID)
+	}
+	return err
+}
+
+// commentMissingOrChanged explains why a conditional write matched no
+// comment.
+func (cs *PostgresChapterCommentStore) commentMissingOrChanged(id int64) error {
+	var exists bool
+	if err := cs.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM comments WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return sql.ErrNoRows
 }
 
 func (cs *PostgresChapterCommentStore) GetCommentByID(id int64) (*ChapterComment, error) {
@@ -74,7 +93,7 @@ func (cs *PostgresChapterCommentStore) GetCommentByID(id int64) (*ChapterComment
 		User: &User{},
 	}
 	err := cs.db.QueryRow(`
-        SELECT c.id, c.body, c.user_id, c.chapter_id, c.created_at, c.updated_at, u.id, u.username, u.email, u.role
+        SELECT c.id, c.body, c.user_id, c.chapter_id, c.created_at, c.updated_at, c.version, u.id, u.username, u.email, u.role
         FROM comments c
 		JOIN users u ON c.user_id = u.id
 		WHERE c.id = $1`, id).Scan(
@@ -84,6 +103,7 @@ func (cs *PostgresChapterCommentStore) GetCommentByID(id int64) (*ChapterComment
 		&comment.ChapterID,
 		&comment.CreatedAt,
 		&comment.UpdatedAt,
+		&comment.Version,
 		&comment.User.ID,
 		&comment.User.Username,
 		&comment.User.Email,
@@ -96,12 +116,20 @@ func (cs *PostgresChapterCommentStore) GetCommentByID(id int64) (*ChapterComment
 	return comment, nil
 }
 
-func (cs *PostgresChapterCommentStore) DeleteCommentByID(id int64) error {
-	res, err := cs.db.Exec(`
+// DeleteCommentByID deletes the comment. It returns sql.ErrNoRows when there is
+// no such comment and ErrVersionMismatch when the comment's version is not one
+// of ifMatch. A nil ifMatch deletes any version.
+func (cs *PostgresChapterCommentStore) DeleteCommentByID(id int64, ifMatch []int) error {
+	query := `
 		DELETE FROM comments
-		WHERE id = $1`,
-		id,
-	)
+		WHERE id = $1`
+	args := []interface{}{id}
+	if ifMatch != nil {
+		query += ` AND version = ANY($2)`
+		args = append(args, versionArgs(ifMatch))
+	}
+
+	res, err := cs.db.Exec(query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
@@ -111,12 +139,27 @@ func (cs *PostgresChapterCommentStore) DeleteCommentByID(id int64) error {
 		return fmt.Errorf("failed to get affected rows: %w", err)
 	}
 	if rows == 0 {
+		if ifMatch != nil {
+			return cs.commentMissingOrChanged(id)
+		}
 		return sql.ErrNoRows
 	}
 
 	return nil
 }
 
+// editionChaptersQuery selects the chapters sharing a number with chapter $1
+// across every edition of its work, so discussions are not split by edition.
+const editionChaptersQuery = `
+        SELECT oc.id
+        FROM chapters ch
+        JOIN books b ON ch.book_id = b.id
+        JOIN books ob ON ob.work_id = b.work_id
+        JOIN chapters oc ON oc.book_id = ob.id AND oc.number = ch.number
+        WHERE ch.id = $1`
+
+// GetCommentsByChapterID returns the comments on a chapter together with those
+// left on the same chapter number in other editions of the book.
 func (cs *PostgresChapterCommentStore) GetCommentsByChapterID(chapterID int64, page, limit int) ([]*ChapterComment, int, error) {
 	if page < 1 {
 		page = 1
@@ -128,11 +171,11 @@ func (cs *PostgresChapterCommentStore) GetCommentsByChapterID(chapterID int64, p
 	offset := (page - 1) * limit
 
 	rows, err := cs.db.Query(`
-        SELECT c.id, c.body, c.user_id, c.chapter_id, c.created_at, c.updated_at,
+        SELECT c.id, c.body, c.user_id, c.chapter_id, c.created_at, c.updated_at, c.version,
                u.id, u.username, u.email, u.role
         FROM comments c
         JOIN users u ON c.user_id = u.id
-        WHERE c.chapter_id = $1
+        WHERE c.chapter_id IN (`+editionChaptersQuery+`)
         ORDER BY c.created_at ASC
         LIMIT $2 OFFSET $3;
     `, chapterID, limit, offset)
@@ -159,6 +202,7 @@ func (cs *PostgresChapterCommentStore) GetCommentsByChapterID(chapterID int64, p
 			&comment.ChapterID,
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
+			&comment.Version,
 			&comment.User.ID,
 			&comment.User.Username,
 			&comment.User.Email,
@@ -172,7 +216,7 @@ func (cs *PostgresChapterCommentStore) GetCommentsByChapterID(chapterID int64, p
 
 	var total int
 	err = cs.db.QueryRow(`
-        SELECT COUNT(*) FROM comments WHERE chapter_id = $1;
+        SELECT COUNT(*) FROM comments WHERE chapter_id IN (`+editionChaptersQuery+`);
     `, chapterID).Scan(&total)
 	if err != nil {
 		return nil, 0, err
@@ -0,0 +1,203 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	ErrChapterNotInBook = errors.New("chapter does not belong to this book")
+	ErrDuplicateChapter = errors.New("chapter listed more than once")
+)
+
+// ChapterDeletionError is returned when an update would delete chapters that
+// readers have commented on.
+type ChapterDeletionError struct {
+	ChapterIDs []int64
+}
+
+func (e *ChapterDeletionError) Error() string {
+	return fmt.Sprintf("chapters %v have comments", e.ChapterIDs)
+}
+
+type chapterPlan struct {
+	updates []Chapter
+	inserts []Chapter
+	deletes []int64
+}
+
+// planChapterChanges matches the incoming chapters to the existing ones: by
+// id when given, otherwise by chapter number. Matched chapters are updated in
+// place, unmatched incoming chapters are inserted and existing chapters left
+// unmatched are deleted.
+func planChapterChanges(existing, incoming []Chapter) (*chapterPlan, error) {
+	byID := map[int64]Chapter{}
+	for _, ch := range existing {
+		byID[ch.ID] = ch
+	}
+
+	matched := map[int64]bool{}
+	for _, ch := range incoming {
+		if ch.ID == 0 {
+			continue
+		}
+		if _, ok := byID[ch.ID]; !ok {
+			return nil, fmt.Errorf("%w: %d", ErrChapterNotInBook, ch.ID)
+		}
+		if matched[ch.ID] {
+			return nil, fmt.Errorf("%w: id %d", ErrDuplicateChapter, ch.ID)
+		}
+		matched[ch.ID] = true
+	}
+
+	// Chapters without an id claim the unmatched existing chapter with the
+	// same number.
+	byNumber := map[int]int64{}
+	for _, ch := range existing {
+		if _, taken := byNumber[ch.Number]; !matched[ch.ID] && !taken {
+			byNumber[ch.Number] = ch.ID
+		}
+	}
+
+	plan := &chapterPlan{}
+	seenNumbers := map[int]bool{}
+	for _, ch := range incoming {
+		if seenNumbers[ch.Number] {
+			return nil, fmt.Errorf("%w: number %d", ErrDuplicateChapter, ch.Number)
+		}
+		seenNumbers[ch.Number] = true
+
+		if ch.ID == 0 {
+			id, ok := byNumber[ch.Number]
+			if !ok {
+				plan.inserts = append(plan.inserts, ch)
+				continue
+			}
+			delete(byNumber, ch.Number)
+			matched[id] = true
+			ch.ID = id
+		}
+
+		if !sameChapter(byID[ch.ID], ch) {
+			plan.updates = append(plan.updates, ch)
+		}
+	}
+
+	for _, ch := range existing {
+		if !matched[ch.ID] {
+			plan.deletes = append(plan.deletes, ch.ID)
+		}
+	}
+
+	return plan, nil
+}
+
+func sameChapter(a, b Chapter) bool {
+	return a.Number == b.Number && a.Title == b.Title &&
+		equalPtr(a.Part, b.Part) && equalPtr(a.StartPage, b.StartPage) && equalPtr(a.EndPage, b.EndPage)
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// selectChaptersForUpdate locks and returns a book's chapters ordered by
+// number.
+func selectChaptersForUpdate(tx *sql.Tx, bookID int64) ([]Chapter, error) {
+	rows, err := tx.Query(`
+		SELECT id, number, title, part, start_page, end_page
+		FROM chapters
+		WHERE book_id = $1
+		ORDER BY number, id
+		FOR UPDATE`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	chapters := []Chapter{}
+	for rows.Next() {
+		var ch Chapter
+		if err := rows.Scan(&ch.ID, &ch.Number, &ch.Title, &ch.Part, &ch.StartPage, &ch.EndPage); err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, ch)
+	}
+	return chapters, rows.Err()
+}
+
+func applyChapterPlan(tx *sql.Tx, bookID int64, plan *chapterPlan) error {
+	for _, id := range plan.deletes {
+		if _, err := tx.Exec(`DELETE FROM chapters WHERE id = $1`, id); err != nil {
+			return err
+		}
+	}
+
+	for _, ch := range plan.updates {
+		_, err := tx.Exec(`
+			UPDATE chapters
+			SET number = $1, title = $2, part = $3, start_page = $4, end_page = $5
+			WHERE id = $6`,
+			ch.Number, ch.Title, ch.Part, ch.StartPage, ch.EndPage, ch.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ch := range plan.inserts {
+		_, err := tx.Exec(`
+			INSERT INTO chapters (book_id, number, title, part, start_page, end_page)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			bookID, ch.Number, ch.Title, ch.Part, ch.StartPage, ch.EndPage,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkChaptersWithoutComments returns a *ChapterDeletionError listing the
+// chapters that have comments.
+func checkChaptersWithoutComments(tx *sql.Tx, chapterIDs []int64) error {
+	rows, err := tx.Query(`
+		SELECT DISTINCT chapter_id
+		FROM comments
+		WHERE chapter_id = ANY($1)
+		ORDER BY chapter_id`, chapterIDs)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	var commented []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		commented = append(commented, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(commented) > 0 {
+		return &ChapterDeletionError{ChapterIDs: commented}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var existingChapters = []Chapter{
+	{ID: 10, Number: 1, Title: "An Unexpected Party"},
+	{ID: 11, Number: 2, Title: "Roast Mutton"},
+	{ID: 12, Number: 3, Title: "A Short Rest"},
+}
+
+func TestPlanChapterChanges_MatchesByIDAndNumber(t *testing.T) {
+	plan, err := planChapterChanges(existingChapters, []Chapter{
+		{ID: 10, Number: 1, Title: "An Unexpected Party"},
+		{Number: 2, Title: "Roast Mutton (revised)"},
+		{ID: 12, Number: 3, Title: "A Short Rest"},
+		{Number: 4, Title: "Over Hill and Under Hill"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Chapter{{ID: 11, Number: 2, Title: "Roast Mutton (revised)"}}, plan.updates)
+	assert.Equal(t, []Chapter{{Number: 4, Title: "Over Hill and Under Hill"}}, plan.inserts)
+	assert.Empty(t, plan.deletes)
+}
+
+func TestPlanChapterChanges_RenumberKeepsIDs(t *testing.T) {
+	plan, err := planChapterChanges(existingChapters, []Chapter{
+		{ID: 12, Number: 1, Title: "A Short Rest"},
+		{ID: 10, Number: 2, Title: "An Unexpected Party"},
+		{ID: 11, Number: 3, Title: "Roast Mutton"},
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, plan.updates, 3)
+	assert.Empty(t, plan.inserts)
+	assert.Empty(t, plan.deletes)
+}
+
+func TestPlanChapterChanges_DeletesOnlyOmittedChapters(t *testing.T) {
+	plan, err := planChapterChanges(existingChapters, []Chapter{
+		{ID: 10, Number: 1, Title: "An Unexpected Party"},
+		{Number: 3, Title: "A Short Rest"},
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, plan.updates)
+	assert.Equal(t, []int64{11}, plan.deletes)
+}
+
+func TestPlanChapterChanges_RejectsForeignChapter(t *testing.T) {
+	_, err := planChapterChanges(existingChapters, []Chapter{{ID: 99, Number: 1, Title: "Elsewhere"}})
+
+	assert.ErrorIs(t, err, ErrChapterNotInBook)
+}
+
+func TestPlanChapterChanges_RejectsDuplicateNumbers(t *testing.T) {
+	_, err := planChapterChanges(existingChapters, []Chapter{
+		{ID: 10, Number: 1, Title: "An Unexpected Party"},
+		{Number: 1, Title: "Another first chapter"},
+	})
+
+	assert.ErrorIs(t, err, ErrDuplicateChapter)
+}
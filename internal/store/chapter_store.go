@@ -1,6 +1,15 @@
 package store
 
-import "database/sql"
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrIncompleteChapterOrder is returned when a reorder leaves out some of the
+// book's chapters.
+var ErrIncompleteChapterOrder = errors.New("chapter order must list every chapter of the book")
 
 type PostgresChapter struct {
 	db *sql.DB
@@ -12,18 +21,29 @@ func NewPostgresChapterStore(db *sql.DB) *PostgresChapter {
 
 type ChapterStore interface {
 	GetChapterByID(id int64) (*Chapter, error)
+	GetChaptersByBookID(bookID int64) ([]Chapter, error)
+	GetBookChapter(bookID, chapterID int64) (*Chapter, error)
+	AddChapter(bookID int64, chapter *Chapter, opts UpdateBookOptions) (*Chapter, error)
+	UpdateChapter(bookID int64, chapter *Chapter, opts UpdateBookOptions) error
+	DeleteChapter(bookID, chapterID int64, opts UpdateBookOptions) error
+	ReorderChapters(bookID int64, chapterIDs []int64, opts UpdateBookOptions) ([]Chapter, error)
+	AppendChapters(bookID int64, chapters []Chapter, opts UpdateBookOptions) ([]Chapter, error)
+	ReplaceChapters(bookID int64, chapters []Chapter, opts UpdateBookOptions) ([]Chapter, error)
 }
 
 func (cs *PostgresChapter) GetChapterByID(id int64) (*Chapter, error) {
 	chapterInfo := &Chapter{}
 
 	err := cs.db.QueryRow(`
-		SELECT id, number, title
+		SELECT id, number, title, part, start_page, end_page
 		FROM chapters
 		WHERE id = $1`, id).Scan(
 		&chapterInfo.ID,
 		&chapterInfo.Number,
 		&chapterInfo.Title,
+		&chapterInfo.Part,
+		&chapterInfo.StartPage,
+		&chapterInfo.EndPage,
 	)
 	if err != nil {
 		return nil, err
@@ -31,3 +51,397 @@ func (cs *PostgresChapter) GetChapterByID(id int64) (*Chapter, error) {
 
 	return chapterInfo, nil
 }
+
+// GetChaptersByBookID returns a book's chapters ordered by number, or nil when
+// the book does not exist.
+func (cs *PostgresChapter) GetChaptersByBookID(bookID int64) ([]Chapter, error) {
+	var exists bool
+	err := cs.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM books WHERE id = $1)`, bookID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := cs.db.Query(`
+		SELECT id, number, title, part, start_page, end_page
+		FROM chapters
+		WHERE book_id = $1
+		ORDER BY number, id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	chapters := []Chapter{}
+	for rows.Next() {
+		var ch Chapter
+		if err := rows.Scan(&ch.ID, &ch.Number, &ch.Title, &ch.Part, &ch.StartPage, &ch.EndPage); err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, ch)
+	}
+
+	return chapters, rows.Err()
+}
+
+// GetBookChapter returns the chapter if it belongs to the book, or nil.
+func (cs *PostgresChapter) GetBookChapter(bookID, chapterID int64) (*Chapter, error) {
+	ch := &Chapter{}
+	err := cs.db.QueryRow(`
+		SELECT id, number, title, part, start_page, end_page
+		FROM chapters
+		WHERE id = $1 AND book_id = $2`, chapterID, bookID,
+	).Scan(&ch.ID, &ch.Number, &ch.Title, &ch.Part, &ch.StartPage, &ch.EndPage)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+// AddChapter inserts a chapter at chapter.Number, moving the chapters from
+// that number on down by one. A zero number appends the chapter after the
+// book's last chapter. The change is recorded in the book's history.
+func (cs *PostgresChapter) AddChapter(bookID int64, chapter *Chapter, opts UpdateBookOptions) (_ *Chapter, err error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	existing, err := lockBookChapters(tx, bookID, opts.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	if chapter.Number == 0 {
+		chapter.Number = nextChapterNumber(existing)
+	} else {
+		_, err = tx.Exec(`UPDATE chapters SET number = number + 1 WHERE book_id = $1 AND number >= $2`, bookID, chapter.Number)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO chapters (book_id, number, title, part, start_page, end_page)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		bookID, chapter.Number, chapter.Title, chapter.Part, chapter.StartPage, chapter.EndPage,
+	).Scan(&chapter.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionUpdated, opts.EditorID, opts.Note); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return chapter, nil
+}
+
+// UpdateChapter updates a chapter's title, part and pages. Chapters are
+// renumbered with ReorderChapters. The change is recorded in the book's
+// history.
+func (cs *PostgresChapter) UpdateChapter(bookID int64, chapter *Chapter, opts UpdateBookOptions) (err error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	if _, err := lockBookChapters(tx, bookID, opts.IfMatch); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE chapters
+		SET title = $1, part = $2, start_page = $3, end_page = $4
+		WHERE id = $5 AND book_id = $6`,
+		chapter.Title, chapter.Part, chapter.StartPage, chapter.EndPage, chapter.ID, bookID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionUpdated, opts.EditorID, opts.Note); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteChapter deletes a chapter and moves the chapters after it up by one.
+// Unless opts.DeleteCommentedChapters is set, chapters that have comments are
+// kept and a *ChapterDeletionError is returned. The change is recorded in the
+// book's history.
+func (cs *PostgresChapter) DeleteChapter(bookID, chapterID int64, opts UpdateBookOptions) (err error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	existing, err := lockBookChapters(tx, bookID, opts.IfMatch)
+	if err != nil {
+		return err
+	}
+
+	var number int
+	found := false
+	for _, ch := range existing {
+		if ch.ID == chapterID {
+			number, found = ch.Number, true
+		}
+	}
+	if !found {
+		return sql.ErrNoRows
+	}
+
+	if !opts.DeleteCommentedChapters {
+		if err := checkChaptersWithoutComments(tx, []int64{chapterID}); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM chapters WHERE id = $1`, chapterID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE chapters SET number = number - 1 WHERE book_id = $1 AND number > $2`, bookID, number)
+	if err != nil {
+		return err
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionUpdated, opts.EditorID, opts.Note); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReorderChapters renumbers a book's chapters 1..n in the order of
+// chapterIDs, which must list every chapter of the book exactly once. The
+// change is recorded in the book's history.
+func (cs *PostgresChapter) ReorderChapters(bookID int64, chapterIDs []int64, opts UpdateBookOptions) (_ []Chapter, err error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	existing, err := lockBookChapters(tx, bookID, opts.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	reordered, err := orderChapters(existing, chapterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE chapters c
+		SET number = ordered.number
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS ordered(id, number)
+		WHERE c.id = ordered.id AND c.number <> ordered.number`, chapterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionUpdated, opts.EditorID, opts.Note); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return reordered, nil
+}
+
+// AppendChapters adds chapters after the book's last chapter, numbering them
+// in order. The change is recorded in the book's history.
+func (cs *PostgresChapter) AppendChapters(bookID int64, chapters []Chapter, opts UpdateBookOptions) (_ []Chapter, err error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	existing, err := lockBookChapters(tx, bookID, opts.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	number := nextChapterNumber(existing)
+	added := make([]Chapter, 0, len(chapters))
+	for _, ch := range chapters {
+		ch.Number = number
+		err := tx.QueryRow(`
+			INSERT INTO chapters (book_id, number, title, part, start_page, end_page)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id`,
+			bookID, ch.Number, ch.Title, ch.Part, ch.StartPage, ch.EndPage,
+		).Scan(&ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, ch)
+		number++
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionUpdated, opts.EditorID, opts.Note); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+// ReplaceChapters numbers chapters 1..n and makes them the book's chapter
+// list. Existing chapters keep their ids when their number is reused, as with
+// UpdateBook. The change is recorded in the book's history.
+func (cs *PostgresChapter) ReplaceChapters(bookID int64, chapters []Chapter, opts UpdateBookOptions) (_ []Chapter, err error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	if _, err := lockBookChapters(tx, bookID, opts.IfMatch); err != nil {
+		return nil, err
+	}
+
+	numbered := make([]Chapter, len(chapters))
+	for i, ch := range chapters {
+		ch.ID = 0
+		ch.Number = i + 1
+		numbered[i] = ch
+	}
+
+	if err := updateBookChapters(tx, bookID, numbered, opts); err != nil {
+		return nil, err
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionUpdated, opts.EditorID, opts.Note); err != nil {
+		return nil, err
+	}
+
+	replaced, err := selectChaptersForUpdate(tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// lockBookChapters locks the book so concurrent chapter edits are applied one
+// at a time, and returns its chapters. A book without history gets its
+// current data recorded first, so the edit can be reverted. It returns
+// sql.ErrNoRows when the book does not exist and ErrVersionMismatch when its
+// version is not one of ifMatch.
+func lockBookChapters(tx *sql.Tx, bookID int64, ifMatch []int) ([]Chapter, error) {
+	var version int
+	err := tx.QueryRow(`SELECT version FROM books WHERE id = $1 FOR NO KEY UPDATE`, bookID).Scan(&version)
+	if err != nil {
+		return nil, err
+	}
+	if err := matchVersion(version, ifMatch); err != nil {
+		return nil, err
+	}
+
+	if err := ensureBookBaseline(tx, bookID); err != nil {
+		return nil, err
+	}
+
+	return selectChaptersForUpdate(tx, bookID)
+}
+
+func nextChapterNumber(chapters []Chapter) int {
+	if len(chapters) == 0 {
+		return 1
+	}
+	return chapters[len(chapters)-1].Number + 1
+}
+
+// orderChapters returns the chapters in the order of ids, numbered from 1.
+func orderChapters(chapters []Chapter, ids []int64) ([]Chapter, error) {
+	byID := map[int64]Chapter{}
+	for _, ch := range chapters {
+		byID[ch.ID] = ch
+	}
+
+	ordered := make([]Chapter, 0, len(ids))
+	seen := map[int64]bool{}
+	for i, id := range ids {
+		ch, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrChapterNotInBook, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: id %d", ErrDuplicateChapter, id)
+		}
+		seen[id] = true
+		ch.Number = i + 1
+		ordered = append(ordered, ch)
+	}
+
+	if len(ordered) != len(chapters) {
+		return nil, ErrIncompleteChapterOrder
+	}
+
+	return ordered, nil
+}
@@ -0,0 +1,35 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderChapters(t *testing.T) {
+	ordered, err := orderChapters(existingChapters, []int64{12, 10, 11})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Chapter{
+		{ID: 12, Number: 1, Title: "A Short Rest"},
+		{ID: 10, Number: 2, Title: "An Unexpected Party"},
+		{ID: 11, Number: 3, Title: "Roast Mutton"},
+	}, ordered)
+}
+
+func TestOrderChapters_Invalid(t *testing.T) {
+	_, err := orderChapters(existingChapters, []int64{12, 10})
+	assert.ErrorIs(t, err, ErrIncompleteChapterOrder)
+
+	_, err = orderChapters(existingChapters, []int64{12, 10, 10})
+	assert.ErrorIs(t, err, ErrDuplicateChapter)
+
+	_, err = orderChapters(existingChapters, []int64{12, 10, 99})
+	assert.ErrorIs(t, err, ErrChapterNotInBook)
+}
+
+func TestNextChapterNumber(t *testing.T) {
+	assert.Equal(t, 1, nextChapterNumber(nil))
+	assert.Equal(t, 4, nextChapterNumber(existingChapters))
+}
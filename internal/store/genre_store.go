@@ -0,0 +1,274 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+)
+
+// GenreSeparator splits hierarchical genre paths such as the Google Books
+// categories ("Fiction / Fantasy / Epic").
+const GenreSeparator = "/"
+
+type Genre struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	ParentID *int64   `json:"parent_id"`
+	Children []*Genre `json:"children,omitempty"`
+}
+
+var ErrGenreCycle = errors.New("genre cannot be its own ancestor")
+
+type PostgresGenreStore struct {
+	db *sql.DB
+}
+
+func NewPostgresGenreStore(db *sql.DB) *PostgresGenreStore {
+	return &PostgresGenreStore{db: db}
+}
+
+type GenreStore interface {
+	CreateGenre(genre *Genre) (*Genre, error)
+	GetGenreByID(id int64) (*Genre, error)
+	GetAllGenres() ([]*Genre, error)
+	UpdateGenre(genre *Genre) error
+	DeleteGenreByID(id int64) error
+	SetBookGenres(bookID int64, genreIDs []int64, opts UpdateBookOptions) error
+}
+
+func (gs *PostgresGenreStore) CreateGenre(genre *Genre) (*Genre, error) {
+	err := gs.db.QueryRow(`
+		INSERT INTO genres (name, parent_id)
+		VALUES ($1, $2)
+		RETURNING id`,
+		genre.Name, genre.ParentID,
+	).Scan(&genre.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return genre, nil
+}
+
+func (gs *PostgresGenreStore) GetGenreByID(id int64) (*Genre, error) {
+	genre := &Genre{}
+	err := gs.db.QueryRow(`
+		SELECT id, name, parent_id
+		FROM genres
+		WHERE id = $1`, id,
+	).Scan(&genre.ID, &genre.Name, &genre.ParentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return genre, nil
+}
+
+func (gs *PostgresGenreStore) GetAllGenres() ([]*Genre, error) {
+	rows, err := gs.db.Query(`
+		SELECT id, name, parent_id
+		FROM genres
+		ORDER BY lower(name)`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	genres := []*Genre{}
+	for rows.Next() {
+		genre := &Genre{}
+		if err := rows.Scan(&genre.ID, &genre.Name, &genre.ParentID); err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	return genres, rows.Err()
+}
+
+func (gs *PostgresGenreStore) UpdateGenre(genre *Genre) error {
+	if genre.ParentID != nil {
+		// The new parent must not be the genre itself or one of its descendants.
+		var isDescendant bool
+		err := gs.db.QueryRow(`
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM genres WHERE id = $1
+				UNION ALL
+				SELECT g.id FROM genres g JOIN subtree s ON g.parent_id = s.id
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`,
+			genre.ID, *genre.ParentID,
+		).Scan(&isDescendant)
+		if err != nil {
+			return err
+		}
+		if isDescendant {
+			return ErrGenreCycle
+		}
+	}
+
+	res, err := gs.db.Exec(`
+		UPDATE genres
+		SET name = $1, parent_id = $2
+		WHERE id = $3`,
+		genre.Name, genre.ParentID, genre.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (gs *PostgresGenreStore) DeleteGenreByID(id int64) error {
+	res, err := gs.db.Exec(`DELETE FROM genres WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetBookGenres replaces the book's genres and records the change in its
+// history. It returns sql.ErrNoRows when the book does not exist.
+func (gs *PostgresGenreStore) SetBookGenres(bookID int64, genreIDs []int64, opts UpdateBookOptions) error {
+	tx, err := gs.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	if err := lockBook(tx, bookID, opts.IfMatch); err != nil {
+		return err
+	}
+	if err := ensureBookBaseline(tx, bookID); err != nil {
+		return err
+	}
+
+	genres := make([]Genre, 0, len(genreIDs))
+	for _, id := range genreIDs {
+		genres = append(genres, Genre{ID: id})
+	}
+
+	if err := updateBookGenres(tx, bookID, genres); err != nil {
+		return err
+	}
+
+	if err := recordBookRevision(tx, bookID, BookRevisionUpdated, opts.EditorID, opts.Note); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateBookGenres replaces the genres linked to a book. Genres without an ID
+// are resolved by name, creating the missing levels of a "Parent / Child" path.
+func updateBookGenres(tx *sql.Tx, bookID int64, genres []Genre) error {
+	_, err := tx.Exec(`DELETE FROM book_genres WHERE book_id = $1`, bookID)
+	if err != nil {
+		return err
+	}
+
+	for _, genre := range genres {
+		genreID := genre.ID
+		if genreID == 0 {
+			genreID, err = ensureGenrePath(tx, genre.Name)
+			if err != nil {
+				return err
+			}
+			if genreID == 0 {
+				continue
+			}
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO book_genres (book_id, genre_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`,
+			bookID, genreID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureGenrePath(tx *sql.Tx, path string) (int64, error) {
+	var parentID *int64
+	var genreID int64
+
+	for _, part := range strings.Split(path, GenreSeparator) {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO genres (name, parent_id)
+			VALUES ($1, $2)
+			ON CONFLICT ((COALESCE(parent_id, 0)), (lower(name))) DO UPDATE SET name = genres.name
+			RETURNING id`,
+			name, parentID,
+		).Scan(&genreID)
+		if err != nil {
+			return 0, err
+		}
+
+		id := genreID
+		parentID = &id
+	}
+
+	return genreID, nil
+}
+
+// BuildGenreTree nests a flat list of genres under their parents and returns
+// the root genres.
+func BuildGenreTree(genres []*Genre) []*Genre {
+	byID := make(map[int64]*Genre, len(genres))
+	for _, genre := range genres {
+		genre.Children = nil
+		byID[genre.ID] = genre
+	}
+
+	roots := []*Genre{}
+	for _, genre := range genres {
+		if genre.ParentID != nil {
+			if parent, ok := byID[*genre.ParentID]; ok {
+				parent.Children = append(parent.Children, genre)
+				continue
+			}
+		}
+		roots = append(roots, genre)
+	}
+
+	return roots
+}
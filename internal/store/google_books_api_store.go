@@ -1,22 +1,81 @@
 package store
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const PRINT_TYPE = "books"
 
+// GoogleBooksMaxResults is the largest page Google Books returns.
+const GoogleBooksMaxResults = 40
+
+const defaultGoogleBooksBaseURL = "https://www.googleapis.com/books/v1"
+
+var ErrGoogleBookNotFound = errors.New("google book not found")
+
+// ErrProviderUnavailable means a book metadata provider cannot be used right
+// now. For Google Books: the API key is missing, the circuit breaker is open,
+// or retries were exhausted.
+var ErrProviderUnavailable = errors.New("book metadata provider unavailable")
+
 type GoogleBooksAPISearch struct {
 	Kind       string                `json:"kind,omitempty"`
 	TotalItems int                   `json:"totalItems,omitempty"`
 	Items      []GoogleBookBasicInfo `json:"items,omitempty"`
 }
 
+// GoogleBooksQuery is a Google Books search. Query is free text; the other
+// string fields become field qualifiers such as intitle: and inauthor:.
+type GoogleBooksQuery struct {
+	Query      string
+	Title      string
+	Author     string
+	ISBN       string
+	Publisher  string
+	Subject    string
+	Lang       string // ISO 639-1 code, e.g. "en"
+	StartIndex int
+	MaxResults int
+}
+
+// Terms returns the q parameter Google expects.
+func (q GoogleBooksQuery) Terms() string {
+	terms := []string{}
+	if query := strings.TrimSpace(q.Query); query != "" {
+		terms = append(terms, query)
+	}
+
+	qualifiers := []struct{ prefix, value string }{
+		{"intitle:", q.Title},
+		{"inauthor:", q.Author},
+		{"inpublisher:", q.Publisher},
+		{"subject:", q.Subject},
+		{"isbn:", strings.ReplaceAll(q.ISBN, "-", "")},
+	}
+	for _, qualifier := range qualifiers {
+		value := strings.Join(strings.Fields(qualifier.value), " ")
+		if value == "" {
+			continue
+		}
+		if strings.Contains(value, " ") {
+			value = `"` + value + `"`
+		}
+		terms = append(terms, qualifier.prefix+value)
+	}
+
+	return strings.Join(terms, " ")
+}
+
 type GoogleBookBasicInfo struct {
 	ID         string      `json:"id"`
 	VolumeInfo *VolumeInfo `json:"volumeInfo,omitempty"`
@@ -31,6 +90,7 @@ type VolumeInfo struct {
 	IndustryIdentifiers []IndustryIdentifier `json:"industryIdentifiers,omitempty"`
 	ImageLinks          *ImageLinks          `json:"imageLinks,omitempty"`
 	PageCount           int                  `json:"pageCount,omitempty"`
+	Categories          []string             `json:"categories,omitempty"`
 }
 
 type IndustryIdentifier struct {
@@ -38,46 +98,189 @@ type IndustryIdentifier struct {
 	Identifier string `json:"identifier,omitempty"`
 }
 
+// ImageLinks holds the cover URLs Google returns. Search results only include
+// the two thumbnails; fetching a single volume also returns the larger sizes.
 type ImageLinks struct {
 	SmallThumbnail string `json:"smallThumbnail,omitempty"`
 	Thumbnail      string `json:"thumbnail,omitempty"`
+	Small          string `json:"small,omitempty"`
+	Medium         string `json:"medium,omitempty"`
+	Large          string `json:"large,omitempty"`
+	ExtraLarge     string `json:"extraLarge,omitempty"`
+}
+
+type GoogleBooksClientConfig struct {
+	APIKey  string
+	BaseURL string
+	// HTTPClient defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+	// MaxRetries is how many times a request is retried after a 429, 5xx or
+	// network error, waiting RetryBackoff, then twice that, and so on. A
+	// longer Retry-After is honoured up to the longest backoff or the client
+	// timeout; beyond that the request fails with ErrProviderUnavailable.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// After BreakerThreshold consecutive failed requests, calls fail fast with
+	// ErrProviderUnavailable for BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// GoogleBooksClientConfigFromEnv reads GOOGLE_BOOKS_API_KEY, GOOGLE_BOOKS_BASE_URL,
+// GOOGLE_BOOKS_TIMEOUT and GOOGLE_BOOKS_MAX_RETRIES.
+func GoogleBooksClientConfigFromEnv() GoogleBooksClientConfig {
+	timeout := 10 * time.Second
+	if d, err := time.ParseDuration(getEnv("GOOGLE_BOOKS_TIMEOUT", "")); err == nil {
+		timeout = d
+	}
+
+	maxRetries := 2
+	if n, err := strconv.Atoi(getEnv("GOOGLE_BOOKS_MAX_RETRIES", "")); err == nil {
+		maxRetries = n
+	}
+
+	return GoogleBooksClientConfig{
+		APIKey:           getEnv("GOOGLE_BOOKS_API_KEY", ""),
+		BaseURL:          getEnv("GOOGLE_BOOKS_BASE_URL", defaultGoogleBooksBaseURL),
+		HTTPClient:       &http.Client{Timeout: timeout},
+		MaxRetries:       maxRetries,
+		RetryBackoff:     500 * time.Millisecond,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
 }
 
 type GoogleBookAPIStore struct {
-	apiKey string
+	apiKey       string
+	baseURL      string
+	client       *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+	breaker      *circuitBreaker
 }
 
-func NewGoogleBooksStore() *GoogleBookAPIStore {
-	apiKey := os.Getenv("GOOGLE_BOOKS_API_KEY")
-	if apiKey == "" {
-		fmt.Println("WARNING: GOOGLE_BOOKS_API_KEY not set")
-		return nil
+func NewGoogleBooksStore(config GoogleBooksClientConfig) *GoogleBookAPIStore {
+	if config.APIKey == "" {
+		log.Println("WARNING: GOOGLE_BOOKS_API_KEY not set, Google Books is unavailable")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultGoogleBooksBaseURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
 	}
 
 	return &GoogleBookAPIStore{
-		apiKey: apiKey,
+		apiKey:       config.APIKey,
+		baseURL:      config.BaseURL,
+		client:       config.HTTPClient,
+		maxRetries:   config.MaxRetries,
+		retryBackoff: config.RetryBackoff,
+		breaker:      &circuitBreaker{threshold: config.BreakerThreshold, cooldown: config.BreakerCooldown, now: time.Now},
 	}
 }
 
 type GoogleBookAPI interface {
-	SearchGoogleBooks(query string) ([]GoogleBookBasicInfo, error)
+	SearchGoogleBooks(ctx context.Context, query GoogleBooksQuery) (*GoogleBooksAPISearch, error)
+	GetGoogleBookByID(ctx context.Context, volumeID string) (*GoogleBookBasicInfo, error)
 }
 
-func (s *GoogleBookAPIStore) SearchGoogleBooks(query string) ([]GoogleBookBasicInfo, error) {
+func (s *GoogleBookAPIStore) SearchGoogleBooks(ctx context.Context, query GoogleBooksQuery) (*GoogleBooksAPISearch, error) {
 	var googleBooks GoogleBooksAPISearch
 
-	baseURL := "https://www.googleapis.com/books/v1/volumes"
-
 	params := url.Values{}
 	params.Add("printType", PRINT_TYPE)
-	params.Add("q", query)
-	params.Add("key", s.apiKey)
+	params.Add("q", query.Terms())
+	if query.StartIndex > 0 {
+		params.Add("startIndex", strconv.Itoa(query.StartIndex))
+	}
+	if query.MaxResults > 0 {
+		params.Add("maxResults", strconv.Itoa(min(query.MaxResults, GoogleBooksMaxResults)))
+	}
+	if query.Lang != "" {
+		params.Add("langRestrict", query.Lang)
+	}
+
+	if err := s.get(ctx, "/volumes", params, &googleBooks); err != nil {
+		return nil, err
+	}
 
-	fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+	return &googleBooks, nil
+}
+
+func (s *GoogleBookAPIStore) GetGoogleBookByID(ctx context.Context, volumeID string) (*GoogleBookBasicInfo, error) {
+	var googleBook GoogleBookBasicInfo
 
-	resp, err := http.Get(fullURL)
+	if err := s.get(ctx, "/volumes/"+url.PathEscape(volumeID), url.Values{}, &googleBook); err != nil {
+		return nil, err
+	}
+
+	return &googleBook, nil
+}
+
+// get fetches path from the API into out, retrying transient failures.
+func (s *GoogleBookAPIStore) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	if s.apiKey == "" {
+		return ErrProviderUnavailable
+	}
+	if !s.breaker.allow() {
+		return ErrProviderUnavailable
+	}
+
+	params.Set("key", s.apiKey)
+	fullURL := fmt.Sprintf("%s%s?%s", s.baseURL, path, params.Encode())
+
+	err := s.getWithRetries(ctx, fullURL, out)
+	switch {
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		// The caller gave up; that says nothing about the provider.
+		s.breaker.release()
+	case err == nil || errors.Is(err, ErrGoogleBookNotFound):
+		s.breaker.record(true)
+	default:
+		// Rejected keys, exhausted quotas and unreadable answers are failures
+		// too, even though retrying them would not help.
+		s.breaker.record(false)
+	}
+	return err
+}
+
+func (s *GoogleBookAPIStore) getWithRetries(ctx context.Context, fullURL string, out interface{}) error {
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait, ok := s.backoff(attempt, lastErr)
+			if !ok {
+				break
+			}
+			if err := sleepContext(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		err := s.fetch(ctx, fullURL, out)
+		var retryable *retryableError
+		if !errors.As(err, &retryable) {
+			return err
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("%w: %v", ErrProviderUnavailable, lastErr)
+}
+
+func (s *GoogleBookAPIStore) fetch(ctx context.Context, fullURL string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching Google books: %w", err)
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &retryableError{err: fmt.Errorf("error fetching Google books: %w", err)}
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -85,13 +288,121 @@ func (s *GoogleBookAPIStore) SearchGoogleBooks(query string) ([]GoogleBookBasicI
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("google api returned status: %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrGoogleBookNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &retryableError{
+			err:        fmt.Errorf("google api returned status: %d", resp.StatusCode),
+			retryAfter: time.Duration(retryAfter) * time.Second,
+		}
+	default:
+		return fmt.Errorf("google api returned status: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// backoff doubles the wait on each attempt, honouring a longer Retry-After.
+// It returns false when Retry-After asks for a longer wait than the client
+// is willing to spend, so the request gives up instead.
+func (s *GoogleBookAPIStore) backoff(attempt int, lastErr error) (time.Duration, bool) {
+	wait := s.retryBackoff << (attempt - 1)
+
+	var retryable *retryableError
+	if errors.As(lastErr, &retryable) && retryable.retryAfter > wait {
+		if retryable.retryAfter > s.maxRetryWait() {
+			return 0, false
+		}
+		wait = retryable.retryAfter
+	}
+	return wait, true
+}
+
+// maxRetryWait is the longest Retry-After worth waiting for: the longest
+// backoff, or the client's timeout if that is longer.
+func (s *GoogleBookAPIStore) maxRetryWait() time.Duration {
+	return max(s.retryBackoff<<s.maxRetries, s.client.Timeout)
+}
+
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
+
+// circuitBreaker opens after threshold consecutive failures and lets a single
+// trial request through once cooldown has passed. A zero threshold disables it.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool
+	now       func() time.Time
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&googleBooks); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.trial {
+		return false
 	}
 
-	return googleBooks.Items, nil
+	b.trial = true
+	return true
+}
+
+// release ends a trial request without counting it either way.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+func (b *circuitBreaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if success {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
 }
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGoogleBooks serves the given status codes in order, repeating the last
+// one, and answers 200s with a single volume.
+func fakeGoogleBooks(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		assert.Equal(t, "test-key", r.URL.Query().Get("key"))
+
+		w.WriteHeader(statuses[n])
+		if statuses[n] == http.StatusOK {
+			_, _ = w.Write([]byte(`{"totalItems": 1, "items": [{"id": "abc", "volumeInfo": {"title": "The Hobbit"}}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestGoogleBooksStore(baseURL string) *GoogleBookAPIStore {
+	return NewGoogleBooksStore(GoogleBooksClientConfig{
+		APIKey:           "test-key",
+		BaseURL:          baseURL,
+		HTTPClient:       &http.Client{Timeout: time.Second},
+		MaxRetries:       2,
+		RetryBackoff:     time.Millisecond,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	})
+}
+
+func TestSearchGoogleBooks_Success(t *testing.T) {
+	server, _ := fakeGoogleBooks(t, http.StatusOK)
+	s := newTestGoogleBooksStore(server.URL)
+
+	books, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	require.NoError(t, err)
+	require.Len(t, books.Items, 1)
+	assert.Equal(t, "The Hobbit", books.Items[0].VolumeInfo.Title)
+}
+
+func TestSearchGoogleBooks_SendsPagingAndFilters(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"totalItems": 0}`))
+	}))
+	t.Cleanup(server.Close)
+	s := newTestGoogleBooksStore(server.URL)
+
+	_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{
+		Author:     "Le Guin",
+		Lang:       "en",
+		StartIndex: 40,
+		MaxResults: 100,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, `inauthor:"Le Guin"`, query.Get("q"))
+	assert.Equal(t, "40", query.Get("startIndex"))
+	assert.Equal(t, "40", query.Get("maxResults"))
+	assert.Equal(t, "en", query.Get("langRestrict"))
+}
+
+func TestSearchGoogleBooks_RetriesTransientErrors(t *testing.T) {
+	server, calls := fakeGoogleBooks(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	s := newTestGoogleBooksStore(server.URL)
+
+	books, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	require.NoError(t, err)
+	assert.Len(t, books.Items, 1)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestSearchGoogleBooks_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := fakeGoogleBooks(t, http.StatusBadRequest)
+	s := newTestGoogleBooksStore(server.URL)
+
+	_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrProviderUnavailable)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestSearchGoogleBooks_RetriesExhausted(t *testing.T) {
+	server, calls := fakeGoogleBooks(t, http.StatusInternalServerError)
+	s := newTestGoogleBooksStore(server.URL)
+
+	_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestSearchGoogleBooks_CircuitBreakerOpens(t *testing.T) {
+	server, calls := fakeGoogleBooks(t, http.StatusInternalServerError)
+	s := newTestGoogleBooksStore(server.URL)
+
+	for i := 0; i < 2; i++ {
+		_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+		assert.ErrorIs(t, err, ErrProviderUnavailable)
+	}
+	before := calls.Load()
+
+	_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+	assert.Equal(t, before, calls.Load(), "open breaker should not call the provider")
+}
+
+func TestSearchGoogleBooks_CircuitBreakerTrialAfterCooldown(t *testing.T) {
+	server, _ := fakeGoogleBooks(t, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusOK)
+	s := newTestGoogleBooksStore(server.URL)
+	now := time.Now()
+	s.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, _ = s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+	}
+	now = now.Add(2 * time.Minute)
+
+	books, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	require.NoError(t, err)
+	assert.Len(t, books.Items, 1)
+}
+
+func TestSearchGoogleBooks_LongRetryAfterGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+	s := newTestGoogleBooksStore(server.URL)
+
+	start := time.Now()
+	_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestSearchGoogleBooks_RejectedKeyOpensBreaker(t *testing.T) {
+	server, calls := fakeGoogleBooks(t, http.StatusForbidden)
+	s := newTestGoogleBooksStore(server.URL)
+
+	for i := 0; i < 2; i++ {
+		_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+		assert.Error(t, err)
+	}
+
+	_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestSearchGoogleBooks_MissingAPIKey(t *testing.T) {
+	s := NewGoogleBooksStore(GoogleBooksClientConfig{})
+
+	_, err := s.SearchGoogleBooks(context.Background(), GoogleBooksQuery{Query: "hobbit"})
+
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+}
+
+func TestSearchGoogleBooks_ContextCanceled(t *testing.T) {
+	server, _ := fakeGoogleBooks(t, http.StatusServiceUnavailable)
+	s := newTestGoogleBooksStore(server.URL)
+	s.retryBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := s.SearchGoogleBooks(ctx, GoogleBooksQuery{Query: "hobbit"})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, s.breaker.failures)
+}
+
+func TestGetGoogleBookByID_NotFound(t *testing.T) {
+	server, _ := fakeGoogleBooks(t, http.StatusNotFound)
+	s := newTestGoogleBooksStore(server.URL)
+
+	_, err := s.GetGoogleBookByID(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, ErrGoogleBookNotFound)
+}
@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/cache"
+)
+
+const (
+	defaultGoogleBooksCacheTTL  = 24 * time.Hour
+	defaultGoogleBooksCacheSize = 1000
+)
+
+// GoogleBooksCacheConfig controls CachedGoogleBookAPI. A zero TTL or Size
+// disables the in-memory cache; Persist also keeps entries in Postgres so they
+// survive restarts.
+type GoogleBooksCacheConfig struct {
+	TTL     time.Duration
+	Size    int
+	Persist bool
+}
+
+// GoogleBooksCacheConfigFromEnv reads GOOGLE_BOOKS_CACHE_TTL (a Go duration such
+// as "6h"), GOOGLE_BOOKS_CACHE_SIZE and GOOGLE_BOOKS_CACHE_PERSIST.
+func GoogleBooksCacheConfigFromEnv() GoogleBooksCacheConfig {
+	config := GoogleBooksCacheConfig{
+		TTL:  defaultGoogleBooksCacheTTL,
+		Size: defaultGoogleBooksCacheSize,
+	}
+
+	if ttl, err := time.ParseDuration(getEnv("GOOGLE_BOOKS_CACHE_TTL", "")); err == nil {
+		config.TTL = ttl
+	}
+	if size, err := strconv.Atoi(getEnv("GOOGLE_BOOKS_CACHE_SIZE", "")); err == nil {
+		config.Size = size
+	}
+	config.Persist, _ = strconv.ParseBool(getEnv("GOOGLE_BOOKS_CACHE_PERSIST", "false"))
+
+	return config
+}
+
+type GoogleBooksCacheStats struct {
+	Hits           int64  `json:"hits"`
+	PersistentHits int64  `json:"persistent_hits"`
+	Misses         int64  `json:"misses"`
+	Bypasses       int64  `json:"bypasses"`
+	Entries        int    `json:"entries"`
+	TTL            string `json:"ttl"`
+	Persistent     bool   `json:"persistent"`
+}
+
+// GoogleBookAPICache is a GoogleBookAPI that answers repeated calls from a cache.
+type GoogleBookAPICache interface {
+	GoogleBookAPI
+	// Fresh skips cached results, refreshing the cache with the live response.
+	Fresh() GoogleBookAPI
+	Stats() GoogleBooksCacheStats
+}
+
+type GoogleBooksCacheStore interface {
+	GetCachedPayload(key string) ([]byte, time.Time, error)
+	SetCachedPayload(key string, payload []byte, expiresAt time.Time) error
+}
+
+type PostgresGoogleBooksCacheStore struct {
+	db *sql.DB
+}
+
+func NewPostgresGoogleBooksCacheStore(db *sql.DB) *PostgresGoogleBooksCacheStore {
+	return &PostgresGoogleBooksCacheStore{db: db}
+}
+
+// GetCachedPayload returns a nil payload when key is missing or expired.
+func (cs *PostgresGoogleBooksCacheStore) GetCachedPayload(key string) ([]byte, time.Time, error) {
+	var payload []byte
+	var expiresAt time.Time
+	err := cs.db.QueryRow(`
+		SELECT payload, expires_at
+		FROM google_books_cache
+		WHERE cache_key = $1 AND expires_at > NOW()`, key,
+	).Scan(&payload, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return payload, expiresAt, nil
+}
+
+func (cs *PostgresGoogleBooksCacheStore) SetCachedPayload(key string, payload []byte, expiresAt time.Time) error {
+	_, err := cs.db.Exec(`
+		INSERT INTO google_books_cache (cache_key, payload, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (cache_key) DO UPDATE
+		SET payload = EXCLUDED.payload,
+		    expires_at = EXCLUDED.expires_at`,
+		key, payload, expiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = cs.db.Exec(`DELETE FROM google_books_cache WHERE expires_at <= NOW()`)
+	return err
+}
+
+// CachedGoogleBookAPI decorates a GoogleBookAPI with an in-memory LRU cache and,
+// optionally, a persistent GoogleBooksCacheStore. Errors are never cached.
+type CachedGoogleBookAPI struct {
+	api        GoogleBookAPI
+	memory     *cache.LRU[string, []byte]
+	persistent GoogleBooksCacheStore
+
+	hits           atomic.Int64
+	persistentHits atomic.Int64
+	misses         atomic.Int64
+	bypasses       atomic.Int64
+}
+
+// NewCachedGoogleBookAPI wraps api. persistent may be nil.
+func NewCachedGoogleBookAPI(api GoogleBookAPI, persistent GoogleBooksCacheStore, config GoogleBooksCacheConfig) *CachedGoogleBookAPI {
+	return &CachedGoogleBookAPI{
+		api:        api,
+		memory:     cache.NewLRU[string, []byte](config.Size, config.TTL),
+		persistent: persistent,
+	}
+}
+
+func (c *CachedGoogleBookAPI) SearchGoogleBooks(ctx context.Context, query GoogleBooksQuery) (*GoogleBooksAPISearch, error) {
+	var result *GoogleBooksAPISearch
+	err := c.cached(searchCacheKey(query), &result, func() (interface{}, error) {
+		return c.api.SearchGoogleBooks(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *CachedGoogleBookAPI) GetGoogleBookByID(ctx context.Context, volumeID string) (*GoogleBookBasicInfo, error) {
+	var book *GoogleBookBasicInfo
+	err := c.cached(volumeCacheKey(volumeID), &book, func() (interface{}, error) {
+		return c.api.GetGoogleBookByID(ctx, volumeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+func (c *CachedGoogleBookAPI) Fresh() GoogleBookAPI {
+	return freshGoogleBookAPI{c}
+}
+
+func (c *CachedGoogleBookAPI) Stats() GoogleBooksCacheStats {
+	return GoogleBooksCacheStats{
+		Hits:           c.hits.Load(),
+		PersistentHits: c.persistentHits.Load(),
+		Misses:         c.misses.Load(),
+		Bypasses:       c.bypasses.Load(),
+		Entries:        c.memory.Len(),
+		TTL:            c.memory.TTL().String(),
+		Persistent:     c.persistent != nil,
+	}
+}
+
+// cached decodes the cached payload for key into out, calling fetch and storing
+// its result on a miss.
+func (c *CachedGoogleBookAPI) cached(key string, out interface{}, fetch func() (interface{}, error)) error {
+	if payload, ok := c.memory.Get(key); ok {
+		if err := json.Unmarshal(payload, out); err == nil {
+			c.hits.Add(1)
+			return nil
+		}
+	}
+
+	if c.persistent != nil {
+		payload, expiresAt, err := c.persistent.GetCachedPayload(key)
+		if err != nil {
+			log.Printf("failed to read google books cache: %v", err)
+		}
+		if payload != nil && json.Unmarshal(payload, out) == nil {
+			c.persistentHits.Add(1)
+			c.memory.SetWithExpiry(key, payload, expiresAt)
+			return nil
+		}
+	}
+
+	c.misses.Add(1)
+	return c.refresh(key, out, fetch)
+}
+
+func (c *CachedGoogleBookAPI) refresh(key string, out interface{}, fetch func() (interface{}, error)) error {
+	result, err := fetch()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	c.memory.Set(key, payload)
+	if c.persistent != nil {
+		err := c.persistent.SetCachedPayload(key, payload, time.Now().Add(c.memory.TTL()))
+		if err != nil {
+			log.Printf("failed to write google books cache: %v", err)
+		}
+	}
+
+	return json.Unmarshal(payload, out)
+}
+
+type freshGoogleBookAPI struct {
+	c *CachedGoogleBookAPI
+}
+
+func (f freshGoogleBookAPI) SearchGoogleBooks(ctx context.Context, query GoogleBooksQuery) (*GoogleBooksAPISearch, error) {
+	f.c.bypasses.Add(1)
+	var result *GoogleBooksAPISearch
+	err := f.c.refresh(searchCacheKey(query), &result, func() (interface{}, error) {
+		return f.c.api.SearchGoogleBooks(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (f freshGoogleBookAPI) GetGoogleBookByID(ctx context.Context, volumeID string) (*GoogleBookBasicInfo, error) {
+	f.c.bypasses.Add(1)
+	var book *GoogleBookBasicInfo
+	err := f.c.refresh(volumeCacheKey(volumeID), &book, func() (interface{}, error) {
+		return f.c.api.GetGoogleBookByID(ctx, volumeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+func searchCacheKey(query GoogleBooksQuery) string {
+	return fmt.Sprintf("search:%s|lang=%s|start=%d|max=%d",
+		strings.ToLower(query.Terms()), strings.ToLower(query.Lang), query.StartIndex, query.MaxResults)
+}
+
+func volumeCacheKey(volumeID string) string {
+	return "volume:" + volumeID
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/isbn"
+)
+
+// GoogleBooksMetadataProvider adapts the Google Books client to
+// BookMetadataProvider.
+type GoogleBooksMetadataProvider struct {
+	api GoogleBookAPI
+}
+
+func NewGoogleBooksMetadataProvider(api GoogleBookAPI) *GoogleBooksMetadataProvider {
+	return &GoogleBooksMetadataProvider{api: api}
+}
+
+func (p *GoogleBooksMetadataProvider) Name() string {
+	return "google"
+}
+
+func (p *GoogleBooksMetadataProvider) SearchBooks(ctx context.Context, query BookMetadataQuery) ([]*BookCandidate, error) {
+	search, err := p.api.SearchGoogleBooks(ctx, GoogleBooksQuery{
+		Query:      query.Query,
+		Title:      query.Title,
+		Author:     query.Author,
+		ISBN:       query.ISBN,
+		MaxResults: query.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*BookCandidate, 0, len(search.Items))
+	for _, volume := range search.Items {
+		book, err := GoogleVolumeToBook(volume)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, &BookCandidate{Book: *book, Source: p.Name(), SourceID: volume.ID})
+	}
+	return candidates, nil
+}
+
+// GetBookByISBN returns the first volume of an isbn: search that really has
+// the ISBN. Google sometimes answers with related volumes instead.
+func (p *GoogleBooksMetadataProvider) GetBookByISBN(ctx context.Context, isbn string) (*BookCandidate, error) {
+	candidates, err := p.SearchBooks(ctx, BookMetadataQuery{ISBN: isbn, Limit: 5})
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if candidate.HasISBN(isbn) {
+			return candidate, nil
+		}
+	}
+	return nil, ErrBookMetadataNotFound
+}
+
+// GoogleVolumeToBook maps a Google Books volume to a book. It fails only when
+// the volume has no volume info.
+func GoogleVolumeToBook(book GoogleBookBasicInfo) (*Book, error) {
+	var Isbn10, Isbn13, thumbnail, smallThumbnail string
+	var medium, large *string
+
+	if book.VolumeInfo == nil {
+		return nil, fmt.Errorf("missing volume info for book: %s", book.ID)
+	}
+	var descriptionPtr *string
+	if book.VolumeInfo.Description != "" {
+		description := book.VolumeInfo.Description
+		descriptionPtr = &description
+	}
+
+	var pageCountPtr *int
+	if book.VolumeInfo.PageCount != 0 {
+		pageCount := book.VolumeInfo.PageCount
+		pageCountPtr = &pageCount
+	}
+
+	if links := book.VolumeInfo.ImageLinks; links != nil {
+		thumbnail = links.Thumbnail
+		smallThumbnail = links.SmallThumbnail
+		if links.Small != "" {
+			smallThumbnail = links.Small
+		}
+		if links.Medium != "" {
+			medium = &links.Medium
+		}
+		if links.Large != "" {
+			large = &links.Large
+		} else if links.ExtraLarge != "" {
+			large = &links.ExtraLarge
+		}
+	}
+
+	publishedDate, err := parseGoogleDate(book.VolumeInfo.PublishedDate)
+	if err != nil {
+		publishedDate = JSONDate{}
+	}
+
+	if book.VolumeInfo.IndustryIdentifiers != nil {
+		for _, identifier := range book.VolumeInfo.IndustryIdentifiers {
+			switch identifier.Type {
+			case "ISBN_10":
+				Isbn10 = identifier.Identifier
+			case "ISBN_13":
+				Isbn13 = identifier.Identifier
+			}
+		}
+	}
+
+	// Google sometimes lists only the ISBN-10; derive the ISBN-13 the catalog
+	// is keyed on.
+	if Isbn13 == "" && Isbn10 != "" {
+		if converted, err := isbn.ToISBN13(Isbn10); err == nil {
+			Isbn13 = converted
+		}
+	}
+
+	var isbn10Ptr *string
+	if Isbn10 != "" {
+		isbn10Ptr = &Isbn10
+	}
+
+	var genres []Genre
+	for _, category := range book.VolumeInfo.Categories {
+		genres = append(genres, Genre{Name: category})
+	}
+
+	newBook := Book{
+		Title:         book.VolumeInfo.Title,
+		Authors:       book.VolumeInfo.Authors,
+		Publisher:     book.VolumeInfo.Publisher,
+		PublishedDate: publishedDate,
+		Description:   descriptionPtr,
+		PageCount:     pageCountPtr,
+		ISBN10:        isbn10Ptr,
+		ISBN13:        Isbn13,
+
+		Images: BookImages{
+			ThumbnailUrl: &thumbnail,
+			SmallUrl:     &smallThumbnail,
+			MediumUrl:    medium,
+			LargeUrl:     large,
+		},
+		Genres: genres,
+	}
+	return &newBook, nil
+}
+
+func parseGoogleDate(dateStr string) (JSONDate, error) {
+	if dateStr == "" {
+		return JSONDate{}, nil
+	}
+
+	layouts := []string{
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	}
+
+	var parsed time.Time
+	var err error
+
+	for _, layout := range layouts {
+		parsed, err = time.Parse(layout, dateStr)
+		if err == nil {
+			return JSONDate(parsed), nil
+		}
+	}
+
+	return JSONDate{}, err
+}
@@ -0,0 +1,334 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// --- GoogleVolumeToBook Tests ---
+func TestGoogleVolumeToBook_MissingVolumeInfo(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID:         "test-id",
+		VolumeInfo: nil,
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing volume info")
+}
+
+func TestGoogleVolumeToBook_ValidBookWithAllFields(t *testing.T) {
+	thumbnail := "http://example.com/thumb.jpg"
+	smallThumb := "http://example.com/small.jpg"
+
+	book := GoogleBookBasicInfo{
+		ID: "valid-id",
+		VolumeInfo: &VolumeInfo{
+			Title:         "Test Book",
+			Authors:       []string{"Author 1", "Author 2"},
+			Publisher:     "Test Publisher",
+			PublishedDate: "2023-05-15",
+			Description:   "A detailed description",
+			PageCount:     350,
+			IndustryIdentifiers: []IndustryIdentifier{
+				{Type: "ISBN_10", Identifier: "1234567890"},
+				{Type: "ISBN_13", Identifier: "9781234567890"},
+			},
+			ImageLinks: &ImageLinks{
+				Thumbnail:      thumbnail,
+				SmallThumbnail: smallThumb,
+			},
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int64(0), result.ID)
+	assert.Equal(t, "Test Book", result.Title)
+	assert.Equal(t, 2, len(result.Authors))
+	assert.Equal(t, "Test Publisher", result.Publisher)
+	assert.NotNil(t, result.Description)
+	assert.Equal(t, "A detailed description", *result.Description)
+	assert.NotNil(t, result.PageCount)
+	assert.Equal(t, 350, *result.PageCount)
+	assert.NotNil(t, result.ISBN10)
+	assert.Equal(t, "1234567890", *result.ISBN10)
+	assert.Equal(t, "9781234567890", result.ISBN13)
+	assert.NotNil(t, result.Images.ThumbnailUrl)
+	assert.Equal(t, thumbnail, *result.Images.ThumbnailUrl)
+	assert.NotNil(t, result.Images.SmallUrl)
+	assert.Equal(t, smallThumb, *result.Images.SmallUrl)
+}
+
+func TestGoogleVolumeToBook_NoDescription(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title: "Test Book",
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Nil(t, result.Description)
+}
+
+func TestGoogleVolumeToBook_NoPageCount(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title:     "Test Book",
+			PageCount: 0,
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Nil(t, result.PageCount)
+}
+
+// func TestGoogleVolumeToBook_NoImageLinks(t *testing.T) {
+// 	book := GoogleBookBasicInfo{
+// 		ID: "test-id",
+// 		VolumeInfo: &VolumeInfo{
+// 			Title:      "Test Book",
+// 			ImageLinks: nil,
+// 		},
+// 	}
+
+// 	result, err := GoogleVolumeToBook(book)
+
+// 	assert.NoError(t, err)
+// 	assert.NotNil(t, result)
+// 	assert.Nil(t, result.Images.ThumbnailUrl)
+// 	assert.Nil(t, result.Images.SmallUrl)
+// }
+
+func TestGoogleVolumeToBook_OnlyISBN10(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title: "Test Book",
+			IndustryIdentifiers: []IndustryIdentifier{
+				{Type: "ISBN_10", Identifier: "1234567890"},
+			},
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.NotNil(t, result.ISBN10)
+	assert.Equal(t, "1234567890", *result.ISBN10)
+	assert.Equal(t, "", result.ISBN13)
+}
+
+func TestGoogleVolumeToBook_OnlyISBN13(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title: "Test Book",
+			IndustryIdentifiers: []IndustryIdentifier{
+				{Type: "ISBN_13", Identifier: "9781234567890"},
+			},
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "9781234567890", result.ISBN13)
+	assert.Nil(t, result.ISBN10)
+}
+
+func TestGoogleVolumeToBook_DerivesISBN13FromISBN10(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title: "Test Book",
+			IndustryIdentifiers: []IndustryIdentifier{
+				{Type: "ISBN_10", Identifier: "0261102214"},
+			},
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "9780261102217", result.ISBN13)
+	assert.Equal(t, "0261102214", *result.ISBN10)
+}
+
+func TestGoogleVolumeToBook_NoIdentifiers(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title:               "Test Book",
+			IndustryIdentifiers: nil,
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+}
+
+func TestGoogleVolumeToBook_InvalidPublishedDate(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title:         "Test Book",
+			PublishedDate: "invalid-date",
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	// When date parsing fails, it should still return empty JSONDate
+	assert.Equal(t, JSONDate(time.Time{}), result.PublishedDate)
+}
+
+func TestGoogleVolumeToBook_OtherIdentifierTypes(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title: "Test Book",
+			IndustryIdentifiers: []IndustryIdentifier{
+				{Type: "ISBN_10", Identifier: "1234567890"},
+				{Type: "OTHER_TYPE", Identifier: "should-be-ignored"},
+			},
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.NotNil(t, result.ISBN10)
+	assert.Equal(t, "1234567890", *result.ISBN10)
+}
+
+// --- parseGoogleDate Tests ---
+func TestParseGoogleDate_EmptyString(t *testing.T) {
+	result, err := parseGoogleDate("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, JSONDate(time.Time{}), result)
+}
+
+func TestParseGoogleDate_FullDate(t *testing.T) {
+	result, err := parseGoogleDate("2023-05-15")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	expectedTime, _ := time.Parse("2006-01-02", "2023-05-15")
+	assert.Equal(t, JSONDate(expectedTime), result)
+}
+
+func TestParseGoogleDate_YearMonth(t *testing.T) {
+	result, err := parseGoogleDate("2023-05")
+
+	assert.NoError(t, err)
+	expectedTime, _ := time.Parse("2006-01", "2023-05")
+	assert.Equal(t, JSONDate(expectedTime), result)
+}
+
+func TestParseGoogleDate_YearOnly(t *testing.T) {
+	result, err := parseGoogleDate("2023")
+
+	assert.NoError(t, err)
+	expectedTime, _ := time.Parse("2006", "2023")
+	assert.Equal(t, JSONDate(expectedTime), result)
+}
+
+func TestParseGoogleDate_InvalidString(t *testing.T) {
+	result, err := parseGoogleDate("not-a-date")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, JSONDate{}, result)
+}
+
+func TestGoogleVolumeToBook_PartialPageCount(t *testing.T) {
+	// Test with non-zero PageCount to ensure it's set correctly
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title:     "Test Book",
+			PageCount: 100,
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.NotNil(t, result.PageCount)
+	assert.Equal(t, 100, *result.PageCount)
+}
+
+// Additional test using testify/assert for alternative assertion style
+func TestParseGoogleDateDirectly(t *testing.T) {
+	result, err := parseGoogleDate("2020-12-25")
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+
+	result2, err2 := parseGoogleDate("invalid")
+	assert.Error(t, err2)
+	assert.Equal(t, JSONDate{}, result2)
+}
+
+func TestGoogleVolumeToBook_CategoriesBecomeGenres(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "test-id",
+		VolumeInfo: &VolumeInfo{
+			Title:      "Test Book",
+			Categories: []string{"Fiction / Fantasy / Epic", "Juvenile Fiction"},
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Genres, 2)
+	assert.Equal(t, "Fiction / Fantasy / Epic", result.Genres[0].Name)
+	assert.Equal(t, int64(0), result.Genres[0].ID)
+}
+
+func TestGoogleVolumeToBook_AllImageSizes(t *testing.T) {
+	book := GoogleBookBasicInfo{
+		ID: "sizes",
+		VolumeInfo: &VolumeInfo{
+			Title: "Test Book",
+			ImageLinks: &ImageLinks{
+				SmallThumbnail: "http://example.com/smallThumb.jpg",
+				Thumbnail:      "http://example.com/thumb.jpg",
+				Small:          "http://example.com/small.jpg",
+				Medium:         "http://example.com/medium.jpg",
+				ExtraLarge:     "http://example.com/xl.jpg",
+			},
+		},
+	}
+
+	result, err := GoogleVolumeToBook(book)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/thumb.jpg", *result.Images.ThumbnailUrl)
+	assert.Equal(t, "http://example.com/small.jpg", *result.Images.SmallUrl)
+	assert.Equal(t, "http://example.com/medium.jpg", *result.Images.MediumUrl)
+	assert.Equal(t, "http://example.com/xl.jpg", *result.Images.LargeUrl)
+}
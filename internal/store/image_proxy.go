@@ -0,0 +1,343 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	// ErrImageHostNotAllowed is returned for URLs outside the proxy's
+	// allowlist, so the proxy cannot be used to reach arbitrary servers.
+	ErrImageHostNotAllowed = errors.New("image host is not allowed")
+	// ErrInvalidImage is returned when the source does not answer with an
+	// image we serve, or the image is too large.
+	ErrInvalidImage = errors.New("source did not return a valid image")
+)
+
+// proxiedImageTypes are the content types the proxy passes on.
+var proxiedImageTypes = []string{"image/jpeg", "image/png", "image/webp", "image/gif"}
+
+// ProxiedImage is an image served by the proxy.
+type ProxiedImage struct {
+	Data        []byte
+	ContentType string
+	FetchedAt   time.Time
+}
+
+type ImageProxy interface {
+	// ProxyURL returns the proxy URL serving an external image, and false when
+	// the image cannot be proxied.
+	ProxyURL(imageURL string) (string, bool)
+	// SourceURL returns the image URL a proxy URL serves, and false when it is
+	// not a proxy URL.
+	SourceURL(proxyURL string) (string, bool)
+	GetImage(ctx context.Context, imageURL string) (*ProxiedImage, error)
+}
+
+type ImageProxyConfig struct {
+	// AllowedHosts lists the hosts images may be fetched from. An entry
+	// starting with a dot also allows its subdomains.
+	AllowedHosts []string
+	// BaseURL is where the proxy endpoint is served.
+	BaseURL  string
+	CacheDir string
+	CacheTTL time.Duration
+	MaxBytes int64
+	// HTTPClient defaults to a client that follows redirects only to allowed
+	// hosts and refuses to connect to private addresses.
+	HTTPClient *http.Client
+}
+
+// ImageProxyConfigFromEnv reads IMAGE_PROXY_HOSTS (comma separated),
+// IMAGE_PROXY_BASE_URL, IMAGE_PROXY_CACHE_DIR, IMAGE_PROXY_CACHE_TTL and
+// IMAGE_PROXY_MAX_BYTES.
+func ImageProxyConfigFromEnv() ImageProxyConfig {
+	hosts := []string{"books.google.com", "books.googleusercontent.com", "covers.openlibrary.org", ".archive.org"}
+	if value := getEnv("IMAGE_PROXY_HOSTS", ""); value != "" {
+		hosts = strings.Split(value, ",")
+	}
+
+	ttl := 7 * 24 * time.Hour
+	if d, err := time.ParseDuration(getEnv("IMAGE_PROXY_CACHE_TTL", "")); err == nil {
+		ttl = d
+	}
+
+	maxBytes := int64(5 << 20)
+	if n, err := strconv.ParseInt(getEnv("IMAGE_PROXY_MAX_BYTES", ""), 10, 64); err == nil {
+		maxBytes = n
+	}
+
+	return ImageProxyConfig{
+		AllowedHosts: hosts,
+		BaseURL:      getEnv("IMAGE_PROXY_BASE_URL", "/images/proxy"),
+		CacheDir:     getEnv("IMAGE_PROXY_CACHE_DIR", filepath.Join(os.TempDir(), "bookclub-image-cache")),
+		CacheTTL:     ttl,
+		MaxBytes:     maxBytes,
+	}
+}
+
+// CachedImageProxy fetches images from allowed hosts over HTTPS and keeps them
+// on disk for CacheTTL. Once expired, the cached copy is still served if the
+// source cannot be reached.
+type CachedImageProxy struct {
+	allowedHosts []string
+	baseURL      string
+	cacheDir     string
+	ttl          time.Duration
+	maxBytes     int64
+	client       *http.Client
+	fetches      singleflight.Group
+	now          func() time.Time
+}
+
+func NewCachedImageProxy(config ImageProxyConfig) *CachedImageProxy {
+	p := &CachedImageProxy{
+		baseURL:  config.BaseURL,
+		cacheDir: config.CacheDir,
+		ttl:      config.CacheTTL,
+		maxBytes: config.MaxBytes,
+		client:   config.HTTPClient,
+		now:      time.Now,
+	}
+	for _, host := range config.AllowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			p.allowedHosts = append(p.allowedHosts, host)
+		}
+	}
+	if p.maxBytes <= 0 {
+		p.maxBytes = 5 << 20
+	}
+	if p.client == nil {
+		p.client = &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				Proxy:       http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{Timeout: 5 * time.Second, Control: refusePrivateAddresses}).DialContext,
+			},
+		}
+	}
+	// Redirects are checked against the allowlist like the original URL.
+	p.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if !p.allowed(req.URL) {
+			return ErrImageHostNotAllowed
+		}
+		req.URL.Scheme = "https"
+		return nil
+	}
+	return p
+}
+
+// refusePrivateAddresses stops the proxy from connecting to the local network
+// even when an allowed host resolves there.
+func refusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("refusing to connect to %s", address)
+	}
+	return nil
+}
+
+func (p *CachedImageProxy) ProxyURL(imageURL string) (string, bool) {
+	u, err := p.sourceURL(imageURL)
+	if err != nil {
+		return "", false
+	}
+	return p.baseURL + "?url=" + url.QueryEscape(u.String()), true
+}
+
+func (p *CachedImageProxy) SourceURL(proxyURL string) (string, bool) {
+	query, ok := strings.CutPrefix(proxyURL, p.baseURL+"?url=")
+	if !ok {
+		return "", false
+	}
+	source, err := url.QueryUnescape(query)
+	if err != nil {
+		return "", false
+	}
+	return source, true
+}
+
+// GetImage returns the image at imageURL, from the cache when it is fresh.
+func (p *CachedImageProxy) GetImage(ctx context.Context, imageURL string) (*ProxiedImage, error) {
+	u, err := p.sourceURL(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	source := u.String()
+
+	cached, err := p.readCache(source)
+	if err != nil {
+		log.Printf("failed to read image cache: %v", err)
+	}
+	if cached != nil && p.now().Sub(cached.FetchedAt) < p.ttl {
+		return cached, nil
+	}
+
+	result, err, _ := p.fetches.Do(source, func() (interface{}, error) {
+		return p.fetch(ctx, source)
+	})
+	if err != nil {
+		if cached != nil && !errors.Is(err, ErrImageHostNotAllowed) {
+			log.Printf("serving stale image for %s: %v", source, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	return result.(*ProxiedImage), nil
+}
+
+// sourceURL checks an image URL against the allowlist and upgrades it to
+// HTTPS.
+func (p *CachedImageProxy) sourceURL(imageURL string) (*url.URL, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return nil, ErrImageHostNotAllowed
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return nil, ErrImageHostNotAllowed
+	}
+	if !p.allowed(u) {
+		return nil, ErrImageHostNotAllowed
+	}
+	u.Scheme = "https"
+	u.Host = u.Hostname()
+	u.Fragment = ""
+	return u, nil
+}
+
+func (p *CachedImageProxy) allowed(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	return slices.ContainsFunc(p.allowedHosts, func(allowed string) bool {
+		if strings.HasPrefix(allowed, ".") {
+			return strings.HasSuffix(host, allowed) || host == allowed[1:]
+		}
+		return host == allowed
+	})
+}
+
+func (p *CachedImageProxy) fetch(ctx context.Context, source string) (*ProxiedImage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(proxiedImageTypes, ", "))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrImageHostNotAllowed) {
+			return nil, ErrImageHostNotAllowed
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrInvalidImage, resp.StatusCode)
+	}
+	if resp.ContentLength > p.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidImage, resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > p.maxBytes {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidImage, p.maxBytes)
+	}
+
+	// The declared type must agree with the content, so nothing but an image
+	// is ever served from our origin.
+	declared, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	sniffed := http.DetectContentType(data)
+	if !slices.Contains(proxiedImageTypes, strings.TrimSpace(declared)) || !slices.Contains(proxiedImageTypes, sniffed) {
+		return nil, fmt.Errorf("%w: content type %q", ErrInvalidImage, declared)
+	}
+
+	image := &ProxiedImage{Data: data, ContentType: sniffed, FetchedAt: p.now()}
+	if err := p.writeCache(source, data); err != nil {
+		log.Printf("failed to write image cache: %v", err)
+	}
+	return image, nil
+}
+
+func (p *CachedImageProxy) cachePath(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(p.cacheDir, name[:2], name)
+}
+
+// readCache returns the cached copy of the image, or nil when there is none.
+// The file's modification time records when it was fetched.
+func (p *CachedImageProxy) readCache(source string) (*ProxiedImage, error) {
+	if p.cacheDir == "" {
+		return nil, nil
+	}
+	name := p.cachePath(source)
+	info, err := os.Stat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxiedImage{Data: data, ContentType: http.DetectContentType(data), FetchedAt: info.ModTime()}, nil
+}
+
+func (p *CachedImageProxy) writeCache(source string, data []byte) error {
+	if p.cacheDir == "" {
+		return nil
+	}
+	name := p.cachePath(source)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	now := p.now()
+	if err := os.Chtimes(tmp.Name(), now, now); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImagePNG() []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 3)))
+	return buf.Bytes()
+}
+
+// newTestImageProxy returns a proxy allowing example.com, whose requests all
+// go to server.
+func newTestImageProxy(t *testing.T, server *httptest.Server) *CachedImageProxy {
+	client := server.Client()
+	transport := client.Transport.(*http.Transport)
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	return NewCachedImageProxy(ImageProxyConfig{
+		AllowedHosts: []string{"example.com", ".example.org"},
+		BaseURL:      "/images/proxy",
+		CacheDir:     t.TempDir(),
+		CacheTTL:     time.Hour,
+		MaxBytes:     1 << 10,
+		HTTPClient:   client,
+	})
+}
+
+func TestCachedImageProxy_ProxyURL(t *testing.T) {
+	proxy := NewCachedImageProxy(ImageProxyConfig{AllowedHosts: []string{"example.com", ".example.org"}, BaseURL: "/images/proxy"})
+
+	proxied, ok := proxy.ProxyURL("http://example.com/cover.jpg?id=1#top")
+	require.True(t, ok)
+	assert.Equal(t, "/images/proxy?url=https%3A%2F%2Fexample.com%2Fcover.jpg%3Fid%3D1", proxied)
+
+	source, ok := proxy.SourceURL(proxied)
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com/cover.jpg?id=1", source)
+
+	_, ok = proxy.ProxyURL("https://covers.example.org/a.jpg")
+	assert.True(t, ok)
+
+	for _, rejected := range []string{
+		"/media/covers/1/a.jpg",
+		"https://evil.com/a.jpg",
+		"https://example.com.evil.com/a.jpg",
+		"https://user@example.com/a.jpg",
+		"https://example.com:8080/a.jpg",
+		"ftp://example.com/a.jpg",
+	} {
+		_, ok := proxy.ProxyURL(rejected)
+		assert.False(t, ok, rejected)
+	}
+
+	_, ok = proxy.SourceURL("https://example.com/cover.jpg")
+	assert.False(t, ok)
+}
+
+func TestCachedImageProxy_GetImageCachesOnDisk(t *testing.T) {
+	data := testImagePNG()
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+	proxy := newTestImageProxy(t, server)
+
+	image, err := proxy.GetImage(context.Background(), "http://example.com/cover.png")
+	require.NoError(t, err)
+	assert.Equal(t, data, image.Data)
+	assert.Equal(t, "image/png", image.ContentType)
+
+	image, err = proxy.GetImage(context.Background(), "http://example.com/cover.png")
+	require.NoError(t, err)
+	assert.Equal(t, data, image.Data)
+	assert.EqualValues(t, 1, requests.Load())
+}
+
+func TestCachedImageProxy_ServesStaleImageWhenSourceFails(t *testing.T) {
+	data := testImagePNG()
+	failing := atomic.Bool{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+	proxy := newTestImageProxy(t, server)
+
+	_, err := proxy.GetImage(context.Background(), "https://example.com/cover.png")
+	require.NoError(t, err)
+
+	failing.Store(true)
+	proxy.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	image, err := proxy.GetImage(context.Background(), "https://example.com/cover.png")
+	require.NoError(t, err)
+	assert.Equal(t, data, image.Data)
+
+	_, err = proxy.GetImage(context.Background(), "https://example.com/other.png")
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestCachedImageProxy_RejectsInvalidImages(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("<html><script>alert(1)</script></html>"))
+		case "/script.js":
+			w.Header().Set("Content-Type", "text/javascript")
+			_, _ = w.Write(testImagePNG())
+		case "/huge.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(append(testImagePNG(), make([]byte, 2<<10)...))
+		case "/redirect.png":
+			http.Redirect(w, r, "https://evil.com/a.png", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+	proxy := newTestImageProxy(t, server)
+
+	for _, path := range []string{"/page.png", "/script.js", "/huge.png"} {
+		_, err := proxy.GetImage(context.Background(), "https://example.com"+path)
+		assert.ErrorIs(t, err, ErrInvalidImage, path)
+	}
+
+	_, err := proxy.GetImage(context.Background(), "https://example.com/redirect.png")
+	assert.ErrorIs(t, err, ErrImageHostNotAllowed)
+
+	_, err = proxy.GetImage(context.Background(), "https://evil.com/a.png")
+	assert.ErrorIs(t, err, ErrImageHostNotAllowed)
+}
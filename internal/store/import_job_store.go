@@ -0,0 +1,422 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Kinds of import jobs.
+const (
+	ImportKindCatalog = "catalog"
+	ImportKindShelf   = "shelf"
+)
+
+// States of an import job.
+const (
+	ImportJobQueued    = "queued"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+// Results of an import row. Rows start as pending; valid is the result of a
+// row that a dry run would have imported. Shelf imports report whether the
+// row's book was matched in the catalog, created from the metadata providers
+// or not found at all.
+const (
+	ImportRowPending   = "pending"
+	ImportRowImported  = "imported"
+	ImportRowValid     = "valid"
+	ImportRowSkipped   = "skipped"
+	ImportRowInvalid   = "invalid"
+	ImportRowFailed    = "failed"
+	ImportRowMatched   = "matched"
+	ImportRowCreated   = "created"
+	ImportRowUnmatched = "unmatched"
+)
+
+// ImportRowStatuses lists the results of an import row.
+var ImportRowStatuses = []string{
+	ImportRowPending, ImportRowImported, ImportRowValid, ImportRowSkipped, ImportRowInvalid, ImportRowFailed,
+	ImportRowMatched, ImportRowCreated, ImportRowUnmatched,
+}
+
+// ImportJob is a bulk import running in the background. RowCounts holds the
+// number of rows with each result.
+type ImportJob struct {
+	ID         int64          `json:"id"`
+	Kind       string         `json:"kind" example:"catalog"`
+	UserID     *int64         `json:"user_id"`
+	Format     string         `json:"format" example:"csv"`
+	DryRun     bool           `json:"dry_run"`
+	Status     string         `json:"status" example:"running"`
+	Error      *string        `json:"error"`
+	TotalRows  int            `json:"total_rows"`
+	RowCounts  map[string]int `json:"row_counts"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at"`
+	FinishedAt *time.Time     `json:"finished_at"`
+}
+
+// ImportRow is one row of an import file and its result. Data is the row as
+// read from the file, Errors the problems found with it, keyed by field name.
+type ImportRow struct {
+	JobID  int64             `json:"-"`
+	Row    int               `json:"row"`
+	Status string            `json:"status" example:"imported"`
+	Data   json.RawMessage   `json:"data" swaggertype:"object"`
+	BookID *int64            `json:"book_id"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ImportJobFilter narrows GetImportJobs. Zero values mean "no filter".
+type ImportJobFilter struct {
+	Kind   string
+	UserID *int64
+}
+
+type PostgresImportJobStore struct {
+	db *sql.DB
+}
+
+func NewPostgresImportJobStore(db *sql.DB) *PostgresImportJobStore {
+	return &PostgresImportJobStore{db: db}
+}
+
+type ImportJobStore interface {
+	CreateImportJob(job *ImportJob, rows []ImportRow) (*ImportJob, error)
+	GetImportJobByID(id int64) (*ImportJob, error)
+	GetImportJobs(filter ImportJobFilter, page, limit int) ([]*ImportJob, int, error)
+	GetUnfinishedImportJobs(kind string) ([]*ImportJob, error)
+	GetImportRows(jobID int64, status string, page, limit int) ([]*ImportRow, int, error)
+	GetPendingImportRows(jobID int64, limit int) ([]*ImportRow, error)
+	StartImportJob(id int64) error
+	UpdateImportRow(row *ImportRow) error
+	FinishImportJob(id int64, jobErr error) error
+}
+
+const importJobColumns = `
+	j.id, j.kind, j.user_id, j.format, j.dry_run, j.status, j.error,
+	j.created_at, j.started_at, j.finished_at,
+	COALESCE((
+		SELECT json_object_agg(c.status, c.count)
+		FROM (
+			SELECT status, COUNT(*) AS count
+			FROM import_rows
+			WHERE job_id = j.id
+			GROUP BY status
+		) c
+	), '{}')`
+
+// CreateImportJob queues a job with its rows.
+func (is *PostgresImportJobStore) CreateImportJob(job *ImportJob, rows []ImportRow) (_ *ImportJob, err error) {
+	tx, err := is.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	var id int64
+	err = tx.QueryRow(`
+		INSERT INTO import_jobs (kind, user_id, format, dry_run)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		job.Kind, job.UserID, job.Format, job.DryRun,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO import_rows (job_id, row_number, status, data, errors)
+		VALUES ($1, $2, $3, $4, $5)`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		errs, err := importRowErrors(row.Errors)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := stmt.Exec(id, row.Row, row.Status, []byte(row.Data), errs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return is.GetImportJobByID(id)
+}
+
+// GetImportJobByID returns the job, or nil if it does not exist.
+func (is *PostgresImportJobStore) GetImportJobByID(id int64) (*ImportJob, error) {
+	job, err := scanImportJob(is.db.QueryRow(`
+		SELECT `+importJobColumns+`
+		FROM import_jobs j
+		WHERE j.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetImportJobs lists jobs newest first.
+func (is *PostgresImportJobStore) GetImportJobs(filter ImportJobFilter, page, limit int) ([]*ImportJob, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	conditions := []string{}
+	args := []interface{}{limit, offset}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		conditions = append(conditions, fmt.Sprintf("j.kind = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("j.user_id = $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := is.db.Query(`
+		SELECT `+importJobColumns+`, COUNT(*) OVER ()
+		FROM import_jobs j
+		`+where+`
+		ORDER BY j.created_at DESC, j.id DESC
+		LIMIT $1 OFFSET $2`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	jobs := []*ImportJob{}
+	total := 0
+	for rows.Next() {
+		job, err := scanImportJob(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, total, rows.Err()
+}
+
+// GetUnfinishedImportJobs returns the queued jobs and those that were running
+// when the server stopped, oldest first.
+func (is *PostgresImportJobStore) GetUnfinishedImportJobs(kind string) ([]*ImportJob, error) {
+	rows, err := is.db.Query(`
+		SELECT `+importJobColumns+`
+		FROM import_jobs j
+		WHERE j.kind = $1 AND j.status IN ('queued', 'running')
+		ORDER BY j.created_at, j.id`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	jobs := []*ImportJob{}
+	for rows.Next() {
+		job, err := scanImportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// GetImportRows lists a job's rows in file order, only those with the given
+// result unless status is "".
+func (is *PostgresImportJobStore) GetImportRows(jobID int64, status string, page, limit int) ([]*ImportRow, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	rows, err := is.db.Query(`
+		SELECT job_id, row_number, status, data, book_id, errors, COUNT(*) OVER ()
+		FROM import_rows
+		WHERE job_id = $1 AND ($2 = '' OR status::text = $2)
+		ORDER BY row_number
+		LIMIT $3 OFFSET $4`, jobID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	importRows := []*ImportRow{}
+	total := 0
+	for rows.Next() {
+		row, err := scanImportRow(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		importRows = append(importRows, row)
+	}
+	return importRows, total, rows.Err()
+}
+
+// GetPendingImportRows returns the first rows of the job that have not been
+// processed yet.
+func (is *PostgresImportJobStore) GetPendingImportRows(jobID int64, limit int) ([]*ImportRow, error) {
+	rows, err := is.db.Query(`
+		SELECT job_id, row_number, status, data, book_id, errors
+		FROM import_rows
+		WHERE job_id = $1 AND status = 'pending'
+		ORDER BY row_number
+		LIMIT $2`, jobID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	importRows := []*ImportRow{}
+	for rows.Next() {
+		row, err := scanImportRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		importRows = append(importRows, row)
+	}
+	return importRows, rows.Err()
+}
+
+func (is *PostgresImportJobStore) StartImportJob(id int64) error {
+	_, err := is.db.Exec(`
+		UPDATE import_jobs
+		SET status = 'running', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1`, id)
+	return err
+}
+
+// UpdateImportRow records the result of a row.
+func (is *PostgresImportJobStore) UpdateImportRow(row *ImportRow) error {
+	errs, err := importRowErrors(row.Errors)
+	if err != nil {
+		return err
+	}
+	_, err = is.db.Exec(`
+		UPDATE import_rows
+		SET status = $1, book_id = $2, errors = $3
+		WHERE job_id = $4 AND row_number = $5`,
+		row.Status, row.BookID, errs, row.JobID, row.Row,
+	)
+	return err
+}
+
+// FinishImportJob marks the job completed, or failed with jobErr's message
+// when jobErr is not nil.
+func (is *PostgresImportJobStore) FinishImportJob(id int64, jobErr error) error {
+	status := ImportJobCompleted
+	var message *string
+	if jobErr != nil {
+		status = ImportJobFailed
+		msg := jobErr.Error()
+		message = &msg
+	}
+	_, err := is.db.Exec(`
+		UPDATE import_jobs
+		SET status = $1, error = $2, finished_at = NOW()
+		WHERE id = $3`, status, message, id)
+	return err
+}
+
+func importRowErrors(errs map[string]string) ([]byte, error) {
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(errs)
+}
+
+func scanImportJob(row rowScanner, extra ...any) (*ImportJob, error) {
+	job := &ImportJob{}
+	var counts []byte
+	dest := []any{
+		&job.ID,
+		&job.Kind,
+		&job.UserID,
+		&job.Format,
+		&job.DryRun,
+		&job.Status,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&counts,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(counts, &job.RowCounts); err != nil {
+		return nil, err
+	}
+	for _, count := range job.RowCounts {
+		job.TotalRows += count
+	}
+	return job, nil
+}
+
+func scanImportRow(row rowScanner, extra ...any) (*ImportRow, error) {
+	importRow := &ImportRow{}
+	var data, errs []byte
+	dest := []any{
+		&importRow.JobID,
+		&importRow.Row,
+		&importRow.Status,
+		&data,
+		&importRow.BookID,
+		&errs,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	importRow.Data = data
+	if errs != nil {
+		if err := json.Unmarshal(errs, &importRow.Errors); err != nil {
+			return nil, err
+		}
+	}
+	return importRow, nil
+}
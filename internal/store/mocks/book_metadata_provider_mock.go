@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookMetadataProvider struct {
+	mock.Mock
+}
+
+func (m *MockBookMetadataProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockBookMetadataProvider) SearchBooks(ctx context.Context, query store.BookMetadataQuery) ([]*store.BookCandidate, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.BookCandidate), args.Error(1)
+}
+
+func (m *MockBookMetadataProvider) GetBookByISBN(ctx context.Context, isbn string) (*store.BookCandidate, error) {
+	args := m.Called(isbn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BookCandidate), args.Error(1)
+}
@@ -22,20 +22,91 @@ func (m *MockBookStore) GetBookByID(id int64) (*store.Book, error) {
 	return args.Get(0).(*store.Book), args.Error(1)
 }
 
-func (m *MockBookStore) UpdateBook(book *store.Book) error {
-	args := m.Called(book)
+func (m *MockBookStore) GetBookByISBN13(isbn13 string) (*store.Book, error) {
+	args := m.Called(isbn13)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Book), args.Error(1)
+}
+
+func (m *MockBookStore) GetBookIDsByISBN13(isbn13s []string) (map[string]int64, error) {
+	args := m.Called(isbn13s)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockBookStore) UpdateBook(book *store.Book, opts store.UpdateBookOptions) error {
+	args := m.Called(book, opts)
+	return args.Error(0)
+}
+
+func (m *MockBookStore) ArchiveBook(id int64, ifMatch []int) error {
+	args := m.Called(id, ifMatch)
 	return args.Error(0)
 }
 
-func (m *MockBookStore) DeleteBookByID(id int64) error {
+func (m *MockBookStore) RestoreBook(id int64) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockBookStore) GetAllBooks(page, limit int) ([]*store.Book, int, error) {
-	args := m.Called(page, limit)
+func (m *MockBookStore) GetBookPurgeReport(id int64) (*store.BookPurgeReport, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BookPurgeReport), args.Error(1)
+}
+
+func (m *MockBookStore) PurgeBook(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockBookStore) GetAllBooks(page, limit int, filter store.BookFilter) ([]*store.Book, int, error) {
+	args := m.Called(page, limit, filter)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
 	return args.Get(0).([]*store.Book), args.Int(1), args.Error(2)
 }
+
+func (m *MockBookStore) FindDuplicateBooks(book *store.Book) ([]*store.DuplicateCandidate, error) {
+	args := m.Called(book)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.DuplicateCandidate), args.Error(1)
+}
+
+func (m *MockBookStore) GetSuspectedDuplicates(page, limit int) ([]*store.DuplicatePair, int, error) {
+	args := m.Called(page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*store.DuplicatePair), args.Int(1), args.Error(2)
+}
+
+func (m *MockBookStore) GetBookHistory(bookID int64, page, limit int) ([]*store.BookRevision, int, error) {
+	args := m.Called(bookID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*store.BookRevision), args.Int(1), args.Error(2)
+}
+
+func (m *MockBookStore) GetBookRevision(bookID int64, revision int) (*store.BookRevision, error) {
+	args := m.Called(bookID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BookRevision), args.Error(1)
+}
+
+func (m *MockBookStore) RevertBook(bookID int64, revision int, opts store.UpdateBookOptions) error {
+	args := m.Called(bookID, revision, opts)
+	return args.Error(0)
+}
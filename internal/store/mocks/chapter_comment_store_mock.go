@@ -14,8 +14,8 @@ func (mccs *MockChapterCommentStore) AddComment(comment *store.ChapterComment, c
 	return args.Get(0).(*store.ChapterComment), args.Error(1)
 }
 
-func (mccs *MockChapterCommentStore) UpdateComment(comment *store.ChapterComment) error {
-	args := mccs.Called(comment)
+func (mccs *MockChapterCommentStore) UpdateComment(comment *store.ChapterComment, ifMatch []int) error {
+	args := mccs.Called(comment, ifMatch)
 	return args.Error(0)
 }
 
@@ -24,8 +24,8 @@ func (mccs *MockChapterCommentStore) GetCommentByID(id int64) (*store.ChapterCom
 	return args.Get(0).(*store.ChapterComment), args.Error(1)
 }
 
-func (mccs *MockChapterCommentStore) DeleteCommentByID(id int64) error {
-	args := mccs.Called(id)
+func (mccs *MockChapterCommentStore) DeleteCommentByID(id int64, ifMatch []int) error {
+	args := mccs.Called(id, ifMatch)
 	return args.Error(0)
 }
 
@@ -13,3 +13,61 @@ func (mcs *MockChapterStore) GetChapterByID(id int64) (*store.Chapter, error) {
 	args := mcs.Called(id)
 	return args.Get(0).(*store.Chapter), args.Error(1)
 }
+
+func (mcs *MockChapterStore) GetChaptersByBookID(bookID int64) ([]store.Chapter, error) {
+	args := mcs.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.Chapter), args.Error(1)
+}
+
+func (mcs *MockChapterStore) GetBookChapter(bookID, chapterID int64) (*store.Chapter, error) {
+	args := mcs.Called(bookID, chapterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Chapter), args.Error(1)
+}
+
+func (mcs *MockChapterStore) AddChapter(bookID int64, chapter *store.Chapter, opts store.UpdateBookOptions) (*store.Chapter, error) {
+	args := mcs.Called(bookID, chapter, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Chapter), args.Error(1)
+}
+
+func (mcs *MockChapterStore) UpdateChapter(bookID int64, chapter *store.Chapter, opts store.UpdateBookOptions) error {
+	args := mcs.Called(bookID, chapter, opts)
+	return args.Error(0)
+}
+
+func (mcs *MockChapterStore) DeleteChapter(bookID, chapterID int64, opts store.UpdateBookOptions) error {
+	args := mcs.Called(bookID, chapterID, opts)
+	return args.Error(0)
+}
+
+func (mcs *MockChapterStore) ReorderChapters(bookID int64, chapterIDs []int64, opts store.UpdateBookOptions) ([]store.Chapter, error) {
+	args := mcs.Called(bookID, chapterIDs, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.Chapter), args.Error(1)
+}
+
+func (mcs *MockChapterStore) AppendChapters(bookID int64, chapters []store.Chapter, opts store.UpdateBookOptions) ([]store.Chapter, error) {
+	args := mcs.Called(bookID, chapters, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.Chapter), args.Error(1)
+}
+
+func (mcs *MockChapterStore) ReplaceChapters(bookID int64, chapters []store.Chapter, opts store.UpdateBookOptions) ([]store.Chapter, error) {
+	args := mcs.Called(bookID, chapters, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.Chapter), args.Error(1)
+}
@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGenreStore struct {
+	mock.Mock
+}
+
+func (m *MockGenreStore) CreateGenre(genre *store.Genre) (*store.Genre, error) {
+	args := m.Called(genre)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Genre), args.Error(1)
+}
+
+func (m *MockGenreStore) GetGenreByID(id int64) (*store.Genre, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Genre), args.Error(1)
+}
+
+func (m *MockGenreStore) GetAllGenres() ([]*store.Genre, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.Genre), args.Error(1)
+}
+
+func (m *MockGenreStore) UpdateGenre(genre *store.Genre) error {
+	args := m.Called(genre)
+	return args.Error(0)
+}
+
+func (m *MockGenreStore) DeleteGenreByID(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockGenreStore) SetBookGenres(bookID int64, genreIDs []int64, opts store.UpdateBookOptions) error {
+	args := m.Called(bookID, genreIDs, opts)
+	return args.Error(0)
+}
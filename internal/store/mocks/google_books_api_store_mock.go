@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
 	"github.com/stretchr/testify/mock"
 )
@@ -9,10 +11,18 @@ type MockGoogleBookAPIStore struct {
 	mock.Mock
 }
 
-func (m *MockGoogleBookAPIStore) SearchGoogleBooks(query string) ([]store.GoogleBookBasicInfo, error) {
+func (m *MockGoogleBookAPIStore) SearchGoogleBooks(ctx context.Context, query store.GoogleBooksQuery) (*store.GoogleBooksAPISearch, error) {
 	args := m.Called(query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]store.GoogleBookBasicInfo), args.Error(1)
+	return args.Get(0).(*store.GoogleBooksAPISearch), args.Error(1)
+}
+
+func (m *MockGoogleBookAPIStore) GetGoogleBookByID(ctx context.Context, volumeID string) (*store.GoogleBookBasicInfo, error) {
+	args := m.Called(volumeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.GoogleBookBasicInfo), args.Error(1)
 }
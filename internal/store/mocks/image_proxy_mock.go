@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImageProxy struct {
+	mock.Mock
+}
+
+func (m *MockImageProxy) ProxyURL(imageURL string) (string, bool) {
+	args := m.Called(imageURL)
+	return args.String(0), args.Bool(1)
+}
+
+func (m *MockImageProxy) SourceURL(proxyURL string) (string, bool) {
+	args := m.Called(proxyURL)
+	return args.String(0), args.Bool(1)
+}
+
+func (m *MockImageProxy) GetImage(ctx context.Context, imageURL string) (*store.ProxiedImage, error) {
+	args := m.Called(ctx, imageURL)
+	image, _ := args.Get(0).(*store.ProxiedImage)
+	return image, args.Error(1)
+}
@@ -0,0 +1,73 @@
+package mocks
+
+import (
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImportJobStore struct {
+	mock.Mock
+}
+
+func (m *MockImportJobStore) CreateImportJob(job *store.ImportJob, rows []store.ImportRow) (*store.ImportJob, error) {
+	args := m.Called(job, rows)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobStore) GetImportJobByID(id int64) (*store.ImportJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobStore) GetImportJobs(filter store.ImportJobFilter, page, limit int) ([]*store.ImportJob, int, error) {
+	args := m.Called(filter, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*store.ImportJob), args.Int(1), args.Error(2)
+}
+
+func (m *MockImportJobStore) GetUnfinishedImportJobs(kind string) ([]*store.ImportJob, error) {
+	args := m.Called(kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobStore) GetImportRows(jobID int64, status string, page, limit int) ([]*store.ImportRow, int, error) {
+	args := m.Called(jobID, status, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*store.ImportRow), args.Int(1), args.Error(2)
+}
+
+func (m *MockImportJobStore) GetPendingImportRows(jobID int64, limit int) ([]*store.ImportRow, error) {
+	args := m.Called(jobID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.ImportRow), args.Error(1)
+}
+
+func (m *MockImportJobStore) StartImportJob(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockImportJobStore) UpdateImportRow(row *store.ImportRow) error {
+	args := m.Called(row)
+	return args.Error(0)
+}
+
+func (m *MockImportJobStore) FinishImportJob(id int64, jobErr error) error {
+	args := m.Called(id, jobErr)
+	return args.Error(0)
+}
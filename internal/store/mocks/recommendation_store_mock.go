@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRecommendationStore struct {
+	mock.Mock
+}
+
+func (m *MockRecommendationStore) RefreshRecommendations(config store.RecommendationConfig) error {
+	args := m.Called(config)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationStore) GetSimilarBooks(bookID int64) ([]*store.SimilarBook, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.SimilarBook), args.Error(1)
+}
+
+func (m *MockRecommendationStore) GetRecommendations(userID int64, page, limit int) ([]*store.RecommendedBook, int, error) {
+	args := m.Called(userID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*store.RecommendedBook), args.Int(1), args.Error(2)
+}
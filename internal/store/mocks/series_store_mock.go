@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSeriesStore struct {
+	mock.Mock
+}
+
+func (m *MockSeriesStore) CreateSeries(series *store.Series) (*store.Series, error) {
+	args := m.Called(series)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Series), args.Error(1)
+}
+
+func (m *MockSeriesStore) GetSeriesByID(id int64) (*store.Series, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Series), args.Error(1)
+}
+
+func (m *MockSeriesStore) UpdateSeries(series *store.Series) error {
+	args := m.Called(series)
+	return args.Error(0)
+}
+
+func (m *MockSeriesStore) DeleteSeriesByID(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSeriesStore) SetSeriesBook(seriesID, bookID int64, position float64) error {
+	args := m.Called(seriesID, bookID, position)
+	return args.Error(0)
+}
+
+func (m *MockSeriesStore) RemoveSeriesBook(seriesID, bookID int64) error {
+	args := m.Called(seriesID, bookID)
+	return args.Error(0)
+}
+
+func (m *MockSeriesStore) GetNextInSeries(bookID int64) (*store.Book, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Book), args.Error(1)
+}
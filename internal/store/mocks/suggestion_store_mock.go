@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSuggestionStore struct {
+	mock.Mock
+}
+
+func (mss *MockSuggestionStore) CreateSuggestion(suggestion *store.BookSuggestion) (*store.BookSuggestion, error) {
+	args := mss.Called(suggestion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BookSuggestion), args.Error(1)
+}
+
+func (mss *MockSuggestionStore) GetSuggestionByID(id int64) (*store.BookSuggestion, error) {
+	args := mss.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BookSuggestion), args.Error(1)
+}
+
+func (mss *MockSuggestionStore) GetSuggestions(filter store.SuggestionFilter, page, limit int) ([]*store.BookSuggestion, int, error) {
+	args := mss.Called(filter, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*store.BookSuggestion), args.Int(1), args.Error(2)
+}
+
+func (mss *MockSuggestionStore) ReviewSuggestion(id int64, review store.SuggestionReview) (*store.BookSuggestion, error) {
+	args := mss.Called(id, review)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BookSuggestion), args.Error(1)
+}
+
+func (mss *MockSuggestionStore) ApplySuggestion(id int64, review store.SuggestionReview, book *store.Book, opts store.UpdateBookOptions) (*store.BookSuggestion, error) {
+	args := mss.Called(id, review, book, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BookSuggestion), args.Error(1)
+}
+
+func (mss *MockSuggestionStore) GetBookContributors(bookID int64) ([]store.BookContributor, error) {
+	args := mss.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.BookContributor), args.Error(1)
+}
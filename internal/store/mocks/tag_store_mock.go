@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagStore struct {
+	mock.Mock
+}
+
+func (m *MockTagStore) AddBookTag(userID, bookID int64, name string) error {
+	args := m.Called(userID, bookID, name)
+	return args.Error(0)
+}
+
+func (m *MockTagStore) RemoveBookTag(userID, bookID int64, name string) error {
+	args := m.Called(userID, bookID, name)
+	return args.Error(0)
+}
+
+func (m *MockTagStore) GetBookTags(bookID int64) ([]*store.BookTag, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.BookTag), args.Error(1)
+}
+
+func (m *MockTagStore) GetUserTags(userID int64) ([]*store.BookTag, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.BookTag), args.Error(1)
+}
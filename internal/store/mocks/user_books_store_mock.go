@@ -24,12 +24,28 @@ func (mubs *MockUserBooksStore) AddUserBook(userID, bookID int64, status string)
 	return args.Get(0).(*store.UserBook), args.Error(1)
 }
 
-func (mubs *MockUserBooksStore) UpdateUserBook(userID, userBookID int64, req store.UpdateUserBookRequest) (*store.UserBook, error) {
-	args := mubs.Called(userID, userBookID, req)
+func (mubs *MockUserBooksStore) UpdateUserBook(userID, userBookID int64, req store.UpdateUserBookRequest, ifMatch []int) (*store.UserBook, error) {
+	args := mubs.Called(userID, userBookID, req, ifMatch)
 	return args.Get(0).(*store.UserBook), args.Error(1)
 }
 
-func (mubs *MockUserBooksStore) DeleteUserBook(userID, userBookID int64) error {
-	args := mubs.Called(userID, userBookID)
+func (mubs *MockUserBooksStore) DeleteUserBook(userID, userBookID int64, ifMatch []int) error {
+	args := mubs.Called(userID, userBookID, ifMatch)
 	return args.Error(0)
 }
+
+func (mubs *MockUserBooksStore) GetReadingPosition(userID, bookID int64) (*store.UserBookPosition, error) {
+	args := mubs.Called(userID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.UserBookPosition), args.Error(1)
+}
+
+func (mubs *MockUserBooksStore) ImportUserBook(userBook *store.UserBook, tags []string) (*store.UserBook, error) {
+	args := mubs.Called(userBook, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.UserBook), args.Error(1)
+}
@@ -0,0 +1,72 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserExportStore struct {
+	mock.Mock
+}
+
+func (m *MockUserExportStore) CreateUserExport(userID int64) (*store.UserExport, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.UserExport), args.Error(1)
+}
+
+func (m *MockUserExportStore) GetUserExportByID(id int64) (*store.UserExport, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.UserExport), args.Error(1)
+}
+
+func (m *MockUserExportStore) GetUnfinishedUserExports() ([]*store.UserExport, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.UserExport), args.Error(1)
+}
+
+func (m *MockUserExportStore) GetExpiredUserExports() ([]*store.UserExport, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*store.UserExport), args.Error(1)
+}
+
+func (m *MockUserExportStore) StartUserExport(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserExportStore) CompleteUserExport(id, sizeBytes int64, expiresAt time.Time) error {
+	args := m.Called(id, sizeBytes, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUserExportStore) FailUserExport(id int64, jobErr error) error {
+	args := m.Called(id, jobErr)
+	return args.Error(0)
+}
+
+func (m *MockUserExportStore) ExpireUserExport(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserExportStore) GetUserExportData(userID int64) (*store.UserExportData, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.UserExportData), args.Error(1)
+}
@@ -28,3 +28,11 @@ func (mus *MockUserStore) GetUserToken(scope, plainTextToken string) (*store.Use
 	args := mus.Called(scope, plainTextToken)
 	return args.Get(0).(*store.User), args.Error(1)
 }
+
+func (mus *MockUserStore) UpdateUserRole(userID int64, role store.UserRole) (*store.User, error) {
+	args := mus.Called(userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.User), args.Error(1)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWorkStore struct {
+	mock.Mock
+}
+
+func (m *MockWorkStore) GetWorkByID(id int64) (*store.Work, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Work), args.Error(1)
+}
+
+func (m *MockWorkStore) AddEditionToWork(workID, bookID int64) error {
+	args := m.Called(workID, bookID)
+	return args.Error(0)
+}
+
+func (m *MockWorkStore) SplitEdition(bookID int64) (*store.Work, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Work), args.Error(1)
+}
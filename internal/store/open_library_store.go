@@ -0,0 +1,328 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenLibraryBaseURL   = "https://openlibrary.org"
+	defaultOpenLibraryCoversURL = "https://covers.openlibrary.org"
+)
+
+type OpenLibraryConfig struct {
+	BaseURL string
+	// CoversURL is the host cover image URLs point at.
+	CoversURL string
+	// HTTPClient defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+}
+
+// OpenLibraryConfigFromEnv reads OPEN_LIBRARY_BASE_URL and OPEN_LIBRARY_TIMEOUT.
+func OpenLibraryConfigFromEnv() OpenLibraryConfig {
+	timeout := 10 * time.Second
+	if d, err := time.ParseDuration(getEnv("OPEN_LIBRARY_TIMEOUT", "")); err == nil {
+		timeout = d
+	}
+
+	return OpenLibraryConfig{
+		BaseURL:    getEnv("OPEN_LIBRARY_BASE_URL", defaultOpenLibraryBaseURL),
+		CoversURL:  defaultOpenLibraryCoversURL,
+		HTTPClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// OpenLibraryStore is a BookMetadataProvider backed by the Open Library
+// search and books APIs. Open Library needs no API key.
+type OpenLibraryStore struct {
+	baseURL   string
+	coversURL string
+	client    *http.Client
+}
+
+func NewOpenLibraryStore(config OpenLibraryConfig) *OpenLibraryStore {
+	if config.BaseURL == "" {
+		config.BaseURL = defaultOpenLibraryBaseURL
+	}
+	if config.CoversURL == "" {
+		config.CoversURL = defaultOpenLibraryCoversURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &OpenLibraryStore{
+		baseURL:   strings.TrimSuffix(config.BaseURL, "/"),
+		coversURL: strings.TrimSuffix(config.CoversURL, "/"),
+		client:    config.HTTPClient,
+	}
+}
+
+type openLibrarySearch struct {
+	NumFound int                    `json:"numFound"`
+	Docs     []openLibrarySearchDoc `json:"docs"`
+}
+
+type openLibrarySearchDoc struct {
+	Key              string   `json:"key"`
+	Title            string   `json:"title"`
+	AuthorName       []string `json:"author_name"`
+	Publisher        []string `json:"publisher"`
+	FirstPublishYear int      `json:"first_publish_year"`
+	NumberOfPages    int      `json:"number_of_pages_median"`
+	ISBN             []string `json:"isbn"`
+	CoverID          int      `json:"cover_i"`
+	Subject          []string `json:"subject"`
+}
+
+// openLibraryEdition is the "details" record returned by
+// /api/books?jscmd=details.
+type openLibraryEdition struct {
+	Key     string `json:"key"`
+	Title   string `json:"title"`
+	Authors []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Publishers    []string        `json:"publishers"`
+	PublishDate   string          `json:"publish_date"`
+	NumberOfPages int             `json:"number_of_pages"`
+	ISBN13        []string        `json:"isbn_13"`
+	ISBN10        []string        `json:"isbn_10"`
+	Covers        []int           `json:"covers"`
+	Subjects      []string        `json:"subjects"`
+	Description   json.RawMessage `json:"description"`
+}
+
+func (s *OpenLibraryStore) Name() string {
+	return "openlibrary"
+}
+
+func (s *OpenLibraryStore) SearchBooks(ctx context.Context, query BookMetadataQuery) ([]*BookCandidate, error) {
+	params := url.Values{}
+	params.Set("fields", "key,title,author_name,publisher,first_publish_year,number_of_pages_median,isbn,cover_i,subject")
+	if query.Query != "" {
+		params.Set("q", query.Query)
+	}
+	if query.Title != "" {
+		params.Set("title", query.Title)
+	}
+	if query.Author != "" {
+		params.Set("author", query.Author)
+	}
+	if query.ISBN != "" {
+		params.Set("isbn", query.ISBN)
+	}
+	if query.Limit > 0 {
+		params.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	var search openLibrarySearch
+	if err := s.get(ctx, "/search.json", params, &search); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*BookCandidate, 0, len(search.Docs))
+	for _, doc := range search.Docs {
+		candidates = append(candidates, &BookCandidate{
+			Book:     s.searchDocToBook(doc),
+			Source:   s.Name(),
+			SourceID: doc.Key,
+		})
+	}
+	return candidates, nil
+}
+
+func (s *OpenLibraryStore) GetBookByISBN(ctx context.Context, isbn string) (*BookCandidate, error) {
+	bibKey := "ISBN:" + isbn
+	params := url.Values{}
+	params.Set("bibkeys", bibKey)
+	params.Set("format", "json")
+	params.Set("jscmd", "details")
+
+	var response map[string]struct {
+		Details openLibraryEdition `json:"details"`
+	}
+	if err := s.get(ctx, "/api/books", params, &response); err != nil {
+		return nil, err
+	}
+
+	record, ok := response[bibKey]
+	if !ok {
+		return nil, ErrBookMetadataNotFound
+	}
+
+	return &BookCandidate{
+		Book:     s.editionToBook(record.Details),
+		Source:   s.Name(),
+		SourceID: record.Details.Key,
+	}, nil
+}
+
+func (s *OpenLibraryStore) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	fullURL := fmt.Sprintf("%s%s?%s", s.baseURL, path, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return err
+	}
+	// Open Library asks API clients to identify themselves.
+	req.Header.Set("User-Agent", "BookClubApp")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: error fetching Open Library: %v", ErrProviderUnavailable, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Println("error closing response body:", err)
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrBookMetadataNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("%w: open library returned status: %d", ErrProviderUnavailable, resp.StatusCode)
+	default:
+		return fmt.Errorf("open library returned status: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OpenLibraryStore) searchDocToBook(doc openLibrarySearchDoc) Book {
+	book := Book{
+		Title:   doc.Title,
+		Authors: doc.AuthorName,
+		Images:  s.coverImages(doc.CoverID),
+	}
+	if len(doc.Publisher) > 0 {
+		book.Publisher = doc.Publisher[0]
+	}
+	if doc.FirstPublishYear != 0 {
+		book.PublishedDate = JSONDate(time.Date(doc.FirstPublishYear, time.January, 1, 0, 0, 0, 0, time.UTC))
+	}
+	if doc.NumberOfPages != 0 {
+		pageCount := doc.NumberOfPages
+		book.PageCount = &pageCount
+	}
+	for _, isbn := range doc.ISBN {
+		if len(isbn) == 13 && book.ISBN13 == "" {
+			book.ISBN13 = isbn
+		}
+		if len(isbn) == 10 && book.ISBN10 == nil {
+			isbn10 := isbn
+			book.ISBN10 = &isbn10
+		}
+	}
+	for _, subject := range doc.Subject {
+		book.Genres = append(book.Genres, Genre{Name: subject})
+	}
+	return book
+}
+
+func (s *OpenLibraryStore) editionToBook(edition openLibraryEdition) Book {
+	book := Book{
+		Title:         edition.Title,
+		PublishedDate: parseOpenLibraryDate(edition.PublishDate),
+		Description:   parseOpenLibraryText(edition.Description),
+	}
+	for _, author := range edition.Authors {
+		book.Authors = append(book.Authors, author.Name)
+	}
+	if len(edition.Publishers) > 0 {
+		book.Publisher = edition.Publishers[0]
+	}
+	if edition.NumberOfPages != 0 {
+		pageCount := edition.NumberOfPages
+		book.PageCount = &pageCount
+	}
+	if len(edition.ISBN13) > 0 {
+		book.ISBN13 = edition.ISBN13[0]
+	}
+	if len(edition.ISBN10) > 0 {
+		book.ISBN10 = &edition.ISBN10[0]
+	}
+	if len(edition.Covers) > 0 {
+		book.Images = s.coverImages(edition.Covers[0])
+	}
+	for _, subject := range edition.Subjects {
+		book.Genres = append(book.Genres, Genre{Name: subject})
+	}
+	return book
+}
+
+// coverImages builds cover URLs for an Open Library cover id. S, M and L are
+// the sizes the covers API serves.
+func (s *OpenLibraryStore) coverImages(coverID int) BookImages {
+	if coverID <= 0 {
+		return BookImages{}
+	}
+	cover := func(size string) *string {
+		u := fmt.Sprintf("%s/b/id/%d-%s.jpg", s.coversURL, coverID, size)
+		return &u
+	}
+	return BookImages{
+		ThumbnailUrl: cover("M"),
+		SmallUrl:     cover("S"),
+		MediumUrl:    cover("M"),
+		LargeUrl:     cover("L"),
+	}
+}
+
+// parseOpenLibraryText reads fields that Open Library stores either as a
+// plain string or as {"type": "/type/text", "value": "..."}.
+func parseOpenLibraryText(raw json.RawMessage) *string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		var typed struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &typed); err != nil {
+			return nil
+		}
+		text = typed.Value
+	}
+	if text == "" {
+		return nil
+	}
+	return &text
+}
+
+// parseOpenLibraryDate handles the free-form publish dates Open Library
+// records, e.g. "1937", "September 1937" and "Sep 21, 1937".
+func parseOpenLibraryDate(dateStr string) JSONDate {
+	layouts := []string{
+		"2006-01-02",
+		"January 2, 2006",
+		"Jan 2, 2006",
+		"January 2006",
+		"Jan 2006",
+		"2006",
+	}
+
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, strings.TrimSpace(dateStr)); err == nil {
+			return JSONDate(parsed)
+		}
+	}
+	return JSONDate{}
+}
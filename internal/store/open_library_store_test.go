@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const openLibrarySearchFixture = `{
+	"numFound": 1,
+	"docs": [{
+		"key": "/works/OL27482W",
+		"title": "The Hobbit",
+		"author_name": ["J.R.R. Tolkien"],
+		"publisher": ["Allen & Unwin"],
+		"first_publish_year": 1937,
+		"number_of_pages_median": 310,
+		"isbn": ["0261102214", "9780261102217"],
+		"cover_i": 14627509
+	}]
+}`
+
+const openLibraryDetailsFixture = `{
+	"ISBN:9780261102217": {
+		"bib_key": "ISBN:9780261102217",
+		"details": {
+			"key": "/books/OL7353617M",
+			"title": "The Hobbit",
+			"authors": [{"key": "/authors/OL26320A", "name": "J.R.R. Tolkien"}],
+			"publishers": ["HarperCollins"],
+			"publish_date": "September 21, 1937",
+			"number_of_pages": 310,
+			"isbn_13": ["9780261102217"],
+			"isbn_10": ["0261102214"],
+			"covers": [14627509],
+			"description": {"type": "/type/text", "value": "In a hole in the ground there lived a hobbit."}
+		}
+	}
+}`
+
+// fakeOpenLibrary serves the search and books APIs from fixtures.
+func fakeOpenLibrary(t *testing.T, status int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			return
+		}
+
+		switch r.URL.Path {
+		case "/search.json":
+			_, _ = w.Write([]byte(openLibrarySearchFixture))
+		case "/api/books":
+			if r.URL.Query().Get("bibkeys") != "ISBN:9780261102217" {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(openLibraryDetailsFixture))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestOpenLibraryStore(baseURL string) *OpenLibraryStore {
+	return NewOpenLibraryStore(OpenLibraryConfig{
+		BaseURL:    baseURL,
+		CoversURL:  "https://covers.example.com",
+		HTTPClient: &http.Client{Timeout: time.Second},
+	})
+}
+
+func TestOpenLibrarySearchBooks_MapsDocs(t *testing.T) {
+	s := newTestOpenLibraryStore(fakeOpenLibrary(t, http.StatusOK).URL)
+
+	books, err := s.SearchBooks(context.Background(), BookMetadataQuery{Title: "hobbit", Limit: 5})
+
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	book := books[0]
+	assert.Equal(t, "openlibrary", book.Source)
+	assert.Equal(t, "/works/OL27482W", book.SourceID)
+	assert.Equal(t, "The Hobbit", book.Title)
+	assert.Equal(t, []string{"J.R.R. Tolkien"}, book.Authors)
+	assert.Equal(t, "9780261102217", book.ISBN13)
+	assert.Equal(t, "0261102214", *book.ISBN10)
+	assert.Equal(t, 310, *book.PageCount)
+	assert.Equal(t, 1937, time.Time(book.PublishedDate).Year())
+	assert.Equal(t, "https://covers.example.com/b/id/14627509-L.jpg", *book.Images.LargeUrl)
+}
+
+func TestOpenLibraryGetBookByISBN_MapsDetails(t *testing.T) {
+	s := newTestOpenLibraryStore(fakeOpenLibrary(t, http.StatusOK).URL)
+
+	book, err := s.GetBookByISBN(context.Background(), "9780261102217")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/books/OL7353617M", book.SourceID)
+	assert.Equal(t, "HarperCollins", book.Publisher)
+	assert.Equal(t, "In a hole in the ground there lived a hobbit.", *book.Description)
+	assert.Equal(t, time.Date(1937, time.September, 21, 0, 0, 0, 0, time.UTC), time.Time(book.PublishedDate))
+	assert.Equal(t, "https://covers.example.com/b/id/14627509-M.jpg", *book.Images.MediumUrl)
+}
+
+func TestOpenLibraryGetBookByISBN_NotFound(t *testing.T) {
+	s := newTestOpenLibraryStore(fakeOpenLibrary(t, http.StatusOK).URL)
+
+	_, err := s.GetBookByISBN(context.Background(), "9780000000002")
+
+	assert.ErrorIs(t, err, ErrBookMetadataNotFound)
+}
+
+func TestOpenLibrary_ServerErrorIsUnavailable(t *testing.T) {
+	s := newTestOpenLibraryStore(fakeOpenLibrary(t, http.StatusBadGateway).URL)
+
+	_, err := s.SearchBooks(context.Background(), BookMetadataQuery{Query: "hobbit"})
+
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+}
+
+func TestParseOpenLibraryText(t *testing.T) {
+	assert.Equal(t, "plain", *parseOpenLibraryText([]byte(`"plain"`)))
+	assert.Equal(t, "typed", *parseOpenLibraryText([]byte(`{"type": "/type/text", "value": "typed"}`)))
+	assert.Nil(t, parseOpenLibraryText(nil))
+}
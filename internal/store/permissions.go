@@ -0,0 +1,54 @@
+package store
+
+const (
+	// RoleEditor maintains the catalog: books, chapters, genres, series and
+	// editions.
+	RoleEditor UserRole = "editor"
+	// RoleModerator can do everything an editor can, and also delete catalog
+	// entries and other readers' comments.
+	RoleModerator UserRole = "moderator"
+)
+
+// Permission is an action that only some roles may take.
+type Permission string
+
+const (
+	PermEditCatalog       Permission = "catalog:edit"
+	PermDeleteCatalog     Permission = "catalog:delete"
+	PermReviewSuggestions Permission = "suggestions:review"
+	PermModerateComments  Permission = "comments:moderate"
+	PermManageUsers       Permission = "users:manage"
+)
+
+var rolePermissions = map[UserRole][]Permission{
+	RoleEditor: {PermEditCatalog, PermReviewSuggestions},
+	RoleModerator: {
+		PermEditCatalog, PermReviewSuggestions,
+		PermDeleteCatalog, PermModerateComments,
+	},
+	RoleAdmin: {
+		PermEditCatalog, PermReviewSuggestions,
+		PermDeleteCatalog, PermModerateComments,
+		PermManageUsers,
+	},
+}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role UserRole) bool {
+	_, ok := rolePermissions[role]
+	return ok || role == RoleUser
+}
+
+// Can reports whether the user's role grants the permission. Anonymous users
+// and regular users have none.
+func (u *User) Can(permission Permission) bool {
+	if u.IsAnonymus() {
+		return false
+	}
+	for _, p := range rolePermissions[u.Role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
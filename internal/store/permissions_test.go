@@ -0,0 +1,42 @@
+package store
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCan(t *testing.T) {
+	tests := []struct {
+		role    UserRole
+		allowed []Permission
+	}{
+		{RoleUser, nil},
+		{RoleEditor, []Permission{PermEditCatalog, PermReviewSuggestions}},
+		{RoleModerator, []Permission{PermEditCatalog, PermReviewSuggestions, PermDeleteCatalog, PermModerateComments}},
+		{RoleAdmin, []Permission{PermEditCatalog, PermReviewSuggestions, PermDeleteCatalog, PermModerateComments, PermManageUsers}},
+	}
+	all := []Permission{PermEditCatalog, PermDeleteCatalog, PermReviewSuggestions, PermModerateComments, PermManageUsers}
+
+	for _, tt := range tests {
+		user := &User{ID: 1, Role: tt.role}
+		for _, permission := range all {
+			assert.Equal(t, slices.Contains(tt.allowed, permission), user.Can(permission), "%s %s", tt.role, permission)
+		}
+	}
+}
+
+func TestUserCan_Anonymous(t *testing.T) {
+	var nilUser *User
+	assert.False(t, nilUser.Can(PermEditCatalog))
+	assert.False(t, AnonymusUser.Can(PermEditCatalog))
+}
+
+func TestValidRole(t *testing.T) {
+	for _, role := range []UserRole{RoleUser, RoleEditor, RoleModerator, RoleAdmin} {
+		assert.True(t, ValidRole(role), role)
+	}
+	assert.False(t, ValidRole("superuser"))
+	assert.False(t, ValidRole(""))
+}
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"database/sql"
+	"log"
+	"math"
+)
+
+const (
+	// PositionByPages places the reader using the chapters' start pages.
+	PositionByPages = "pages"
+	// PositionProportional assumes the chapters are of equal length.
+	PositionProportional = "proportional"
+	// PositionCompleted is used for finished books: every chapter is done.
+	PositionCompleted = "completed"
+)
+
+// ReadingPosition is where a reader is in a book, in chapters. CurrentChapter
+// is nil once every chapter is finished.
+type ReadingPosition struct {
+	CurrentChapter   *Chapter  `json:"current_chapter"`
+	FinishedChapters []Chapter `json:"finished_chapters"`
+	Method           string    `json:"method" example:"pages"`
+}
+
+// UserBookPosition is a reader's progress in the edition they shelved.
+type UserBookPosition struct {
+	UserBookID     int64            `json:"user_book_id"`
+	BookID         int64            `json:"book_id"`
+	Status         string           `json:"status"`
+	PagesRead      *int             `json:"pages_read,omitempty"`
+	PercentageRead *float64         `json:"percentage_read,omitempty"`
+	Position       *ReadingPosition `json:"position"`
+}
+
+// ComputeReadingPosition maps reading progress to chapters. When every
+// chapter has a start page, pages read (or the percentage of pageCount) are
+// compared with the chapters' page ranges; otherwise the chapters are assumed
+// to be of equal length. A chapter is finished once its last page is read.
+// It returns nil when the book has no chapters or the progress cannot be
+// mapped, e.g. pages read of a book with no page count.
+func ComputeReadingPosition(chapters []Chapter, pageCount *int, status string, pagesRead *int, percentageRead *float64) *ReadingPosition {
+	if len(chapters) == 0 {
+		return nil
+	}
+
+	if status == "completed" {
+		return &ReadingPosition{FinishedChapters: chapters, Method: PositionCompleted}
+	}
+
+	if pagesRead == nil && percentageRead == nil {
+		return newReadingPosition(chapters, 0, PositionProportional)
+	}
+
+	if ends, ok := chapterEndPages(chapters, pageCount); ok {
+		var page float64
+		switch {
+		case pagesRead != nil:
+			page = float64(*pagesRead)
+		case len(ends) > 0 && ends[len(ends)-1] > 0:
+			page = *percentageRead / 100 * float64(ends[len(ends)-1])
+		default:
+			return proportionalPosition(chapters, *percentageRead/100)
+		}
+
+		finished := 0
+		for finished < len(chapters) && ends[finished] > 0 && float64(ends[finished]) <= page {
+			finished++
+		}
+		return newReadingPosition(chapters, finished, PositionByPages)
+	}
+
+	switch {
+	case percentageRead != nil:
+		return proportionalPosition(chapters, *percentageRead/100)
+	case pageCount != nil && *pageCount > 0:
+		return proportionalPosition(chapters, float64(*pagesRead)/float64(*pageCount))
+	}
+	return nil
+}
+
+// chapterEndPages returns the last page of each chapter: its end page, the
+// page before the next chapter starts, or pageCount for the last chapter. The
+// last chapter's end is 0 when none of those is known. ok is false unless
+// every chapter has a start page.
+func chapterEndPages(chapters []Chapter, pageCount *int) ([]int, bool) {
+	ends := make([]int, len(chapters))
+	for i, ch := range chapters {
+		if ch.StartPage == nil {
+			return nil, false
+		}
+		switch {
+		case ch.EndPage != nil:
+			ends[i] = *ch.EndPage
+		case i+1 < len(chapters) && chapters[i+1].StartPage != nil:
+			ends[i] = max(*chapters[i+1].StartPage-1, *ch.StartPage)
+		case i+1 == len(chapters) && pageCount != nil && *pageCount >= *ch.StartPage:
+			ends[i] = *pageCount
+		}
+	}
+	return ends, true
+}
+
+func proportionalPosition(chapters []Chapter, fraction float64) *ReadingPosition {
+	fraction = math.Min(math.Max(fraction, 0), 1)
+	return newReadingPosition(chapters, int(math.Floor(fraction*float64(len(chapters)))), PositionProportional)
+}
+
+func newReadingPosition(chapters []Chapter, finished int, method string) *ReadingPosition {
+	position := &ReadingPosition{FinishedChapters: chapters[:finished], Method: method}
+	if finished < len(chapters) {
+		current := chapters[finished]
+		position.CurrentChapter = &current
+	}
+	return position
+}
+
+// GetReadingPosition returns the user's position in the work that bookID
+// belongs to, measured in the edition they shelved, or nil if the work is not
+// on their shelf.
+func (pub *PostgresUserBooksStore) GetReadingPosition(userID, bookID int64) (*UserBookPosition, error) {
+	position := &UserBookPosition{}
+	err := pub.db.QueryRow(`
+		SELECT ub.id, ub.book_id, ub.status, ub.pages_read, ub.percentage_read
+		FROM user_books ub
+		JOIN books b ON b.work_id = ub.work_id
+		WHERE ub.user_id = $1 AND b.id = $2`, userID, bookID,
+	).Scan(&position.UserBookID, &position.BookID, &position.Status, &position.PagesRead, &position.PercentageRead)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chapters, pageCount, err := pub.getChaptersAndPageCount(position.BookID)
+	if err != nil {
+		return nil, err
+	}
+	position.Position = ComputeReadingPosition(chapters, pageCount, position.Status, position.PagesRead, position.PercentageRead)
+
+	return position, nil
+}
+
+func (pub *PostgresUserBooksStore) getChaptersAndPageCount(bookID int64) ([]Chapter, *int, error) {
+	var pageCount *int
+	err := pub.db.QueryRow(`SELECT page_count FROM books WHERE id = $1`, bookID).Scan(&pageCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := pub.db.Query(`
+		SELECT id, number, title, part, start_page, end_page
+		FROM chapters
+		WHERE book_id = $1
+		ORDER BY number, id`, bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	chapters := []Chapter{}
+	for rows.Next() {
+		var ch Chapter
+		if err := rows.Scan(&ch.ID, &ch.Number, &ch.Title, &ch.Part, &ch.StartPage, &ch.EndPage); err != nil {
+			return nil, nil, err
+		}
+		chapters = append(chapters, ch)
+	}
+
+	return chapters, pageCount, rows.Err()
+}
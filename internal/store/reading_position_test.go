@@ -0,0 +1,108 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pageRange(start, end int) (*int, *int) {
+	var endPtr *int
+	if end > 0 {
+		endPtr = &end
+	}
+	return &start, endPtr
+}
+
+func pagedChapters() []Chapter {
+	chapters := []Chapter{
+		{ID: 10, Number: 1, Title: "An Unexpected Party"},
+		{ID: 11, Number: 2, Title: "Roast Mutton"},
+		{ID: 12, Number: 3, Title: "A Short Rest"},
+	}
+	chapters[0].StartPage, chapters[0].EndPage = pageRange(1, 0)
+	chapters[1].StartPage, chapters[1].EndPage = pageRange(25, 40)
+	chapters[2].StartPage, chapters[2].EndPage = pageRange(41, 0)
+	return chapters
+}
+
+func chapterIDs(chapters []Chapter) []int64 {
+	ids := []int64{}
+	for _, ch := range chapters {
+		ids = append(ids, ch.ID)
+	}
+	return ids
+}
+
+func TestComputeReadingPosition_ByPages(t *testing.T) {
+	pageCount := 60
+	pages := 24
+
+	position := ComputeReadingPosition(pagedChapters(), &pageCount, "reading", &pages, nil)
+
+	require.NotNil(t, position)
+	assert.Equal(t, PositionByPages, position.Method)
+	assert.Equal(t, []int64{10}, chapterIDs(position.FinishedChapters))
+	assert.Equal(t, int64(11), position.CurrentChapter.ID)
+}
+
+func TestComputeReadingPosition_PercentageUsesPageCount(t *testing.T) {
+	pageCount := 60
+	percentage := 70.0
+
+	position := ComputeReadingPosition(pagedChapters(), &pageCount, "reading", nil, &percentage)
+
+	require.NotNil(t, position)
+	assert.Equal(t, []int64{10, 11}, chapterIDs(position.FinishedChapters))
+	assert.Equal(t, int64(12), position.CurrentChapter.ID)
+}
+
+func TestComputeReadingPosition_LastChapterNeedsKnownEnd(t *testing.T) {
+	pages := 500
+
+	position := ComputeReadingPosition(pagedChapters(), nil, "reading", &pages, nil)
+
+	require.NotNil(t, position)
+	assert.Equal(t, []int64{10, 11}, chapterIDs(position.FinishedChapters))
+	assert.Equal(t, int64(12), position.CurrentChapter.ID)
+}
+
+func TestComputeReadingPosition_Proportional(t *testing.T) {
+	chapters := []Chapter{{ID: 1, Number: 1}, {ID: 2, Number: 2}, {ID: 3, Number: 3}, {ID: 4, Number: 4}}
+	pageCount := 200
+	pages := 100
+
+	position := ComputeReadingPosition(chapters, &pageCount, "reading", &pages, nil)
+
+	require.NotNil(t, position)
+	assert.Equal(t, PositionProportional, position.Method)
+	assert.Equal(t, []int64{1, 2}, chapterIDs(position.FinishedChapters))
+	assert.Equal(t, int64(3), position.CurrentChapter.ID)
+
+	percentage := 100.0
+	position = ComputeReadingPosition(chapters, nil, "reading", nil, &percentage)
+	assert.Len(t, position.FinishedChapters, 4)
+	assert.Nil(t, position.CurrentChapter)
+}
+
+func TestComputeReadingPosition_Unmappable(t *testing.T) {
+	chapters := []Chapter{{ID: 1, Number: 1}, {ID: 2, Number: 2}}
+	pages := 10
+
+	assert.Nil(t, ComputeReadingPosition(chapters, nil, "reading", &pages, nil))
+	assert.Nil(t, ComputeReadingPosition(nil, nil, "reading", &pages, nil))
+}
+
+func TestComputeReadingPosition_NoProgressAndCompleted(t *testing.T) {
+	position := ComputeReadingPosition(pagedChapters(), nil, "reading", nil, nil)
+	require.NotNil(t, position)
+	assert.Empty(t, position.FinishedChapters)
+	assert.Equal(t, int64(10), position.CurrentChapter.ID)
+
+	position = ComputeReadingPosition(pagedChapters(), nil, "completed", nil, nil)
+	require.NotNil(t, position)
+	assert.Equal(t, PositionCompleted, position.Method)
+	assert.Len(t, position.FinishedChapters, 3)
+	assert.Nil(t, position.CurrentChapter)
+}
@@ -0,0 +1,297 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+)
+
+// SimilarBook is a book often completed by the readers who completed another.
+type SimilarBook struct {
+	Book *Book `json:"book"`
+	// CoReaders is how many readers completed both books.
+	CoReaders int     `json:"co_readers" example:"12"`
+	Score     float64 `json:"score" example:"0.42"`
+}
+
+// RecommendedBook is a book recommended to a user from the books they
+// completed.
+type RecommendedBook struct {
+	Book  *Book   `json:"book"`
+	Score float64 `json:"score" example:"1.37"`
+}
+
+type RecommendationConfig struct {
+	// Interval is how often recommendations are computed again.
+	Interval time.Duration
+	// MinCoReaders is how many readers must have completed two books for
+	// them to count as similar.
+	MinCoReaders int
+	// SimilarBooks is how many similar books are kept for each book.
+	SimilarBooks int
+	// UserRecommendations is how many recommendations are kept for each user.
+	UserRecommendations int
+}
+
+// RecommendationConfigFromEnv reads RECOMMENDATIONS_INTERVAL,
+// RECOMMENDATIONS_MIN_CO_READERS, RECOMMENDATIONS_SIMILAR_BOOKS and
+// RECOMMENDATIONS_PER_USER.
+func RecommendationConfigFromEnv() RecommendationConfig {
+	config := RecommendationConfig{
+		Interval:            6 * time.Hour,
+		MinCoReaders:        2,
+		SimilarBooks:        20,
+		UserRecommendations: 50,
+	}
+	if d, err := time.ParseDuration(getEnv("RECOMMENDATIONS_INTERVAL", "")); err == nil && d > 0 {
+		config.Interval = d
+	}
+	if n, err := strconv.Atoi(getEnv("RECOMMENDATIONS_MIN_CO_READERS", "")); err == nil && n > 0 {
+		config.MinCoReaders = n
+	}
+	if n, err := strconv.Atoi(getEnv("RECOMMENDATIONS_SIMILAR_BOOKS", "")); err == nil && n > 0 {
+		config.SimilarBooks = n
+	}
+	if n, err := strconv.Atoi(getEnv("RECOMMENDATIONS_PER_USER", "")); err == nil && n > 0 {
+		config.UserRecommendations = n
+	}
+	return config
+}
+
+type PostgresRecommendationStore struct {
+	db *sql.DB
+}
+
+func NewPostgresRecommendationStore(db *sql.DB) *PostgresRecommendationStore {
+	return &PostgresRecommendationStore{db: db}
+}
+
+type RecommendationStore interface {
+	RefreshRecommendations(config RecommendationConfig) error
+	GetSimilarBooks(bookID int64) ([]*SimilarBook, error)
+	GetRecommendations(userID int64, page, limit int) ([]*RecommendedBook, int, error)
+}
+
+// recommendedBookJSON selects the fields of book b shown in recommendations.
+const recommendedBookJSON = `json_build_object(
+	'id', b.id,
+	'work_id', b.work_id,
+	'title', b.title,
+	'published_date', b.published_date,
+	'page_count', b.page_count,
+	'isbn_13', b.isbn_13,
+	'authors', COALESCE(
+		(SELECT json_agg(a.name)
+		FROM book_authors ba
+		JOIN authors a ON ba.author_id = a.id
+		WHERE ba.book_id = b.id),
+		'[]'
+	),
+	'book_images', json_build_object(
+		'thumbnail_url', bi.thumbnail_url,
+		'small_url', bi.small_url
+	)
+)`
+
+// RefreshRecommendations computes the similar books and user recommendations
+// again from the books readers completed. Readers are counted per work, so
+// completing any edition counts towards the work, and each similar work is
+// suggested as a single edition. Two editions of the same work are never
+// similar to each other, and works a user has shelved are not recommended to
+// them.
+func (pg *PostgresRecommendationStore) RefreshRecommendations(config RecommendationConfig) error {
+	tx, err := pg.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM book_similarities`); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		WITH completed AS (
+			SELECT user_id, work_id, book_id
+			FROM user_books
+			WHERE status = 'completed'
+		),
+		readers AS (
+			SELECT work_id, COUNT(*) AS total
+			FROM completed
+			GROUP BY work_id
+		),
+		pairs AS (
+			SELECT x.work_id, y.work_id AS similar_work_id, COUNT(*) AS co_readers
+			FROM completed x
+			JOIN completed y ON x.user_id = y.user_id AND x.work_id <> y.work_id
+			GROUP BY x.work_id, y.work_id
+			HAVING COUNT(*) >= $1
+		),
+		scored AS (
+			SELECT p.work_id, p.similar_work_id, p.co_readers,
+			p.co_readers / sqrt(rx.total * ry.total) AS score
+			FROM pairs p
+			JOIN readers rx ON rx.work_id = p.work_id
+			JOIN readers ry ON ry.work_id = p.similar_work_id
+		),
+		-- The edition of each work that is suggested for it: the one most
+		-- readers completed, leaving out archived editions.
+		editions AS (
+			SELECT DISTINCT ON (b.work_id) b.work_id, b.id AS book_id
+			FROM books b
+			LEFT JOIN completed c ON c.book_id = b.id
+			WHERE b.archived_at IS NULL
+			GROUP BY b.work_id, b.id
+			ORDER BY b.work_id, COUNT(c.user_id) DESC, b.id
+		),
+		ranked AS (
+			SELECT b.id AS book_id, e.book_id AS similar_book_id, s.co_readers, s.score,
+			ROW_NUMBER() OVER (PARTITION BY b.id ORDER BY s.score DESC, e.book_id) AS rank
+			FROM scored s
+			JOIN books b ON b.work_id = s.work_id
+			JOIN editions e ON e.work_id = s.similar_work_id
+		)
+		INSERT INTO book_similarities (book_id, similar_book_id, co_readers, score)
+		SELECT book_id, similar_book_id, co_readers, score
+		FROM ranked
+		WHERE rank <= $2`,
+		config.MinCoReaders, config.SimilarBooks,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_recommendations`); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		WITH candidates AS (
+			SELECT ub.user_id, s.similar_book_id AS book_id, SUM(s.score) AS score
+			FROM user_books ub
+			JOIN book_similarities s ON s.book_id = ub.book_id
+			JOIN books b ON b.id = s.similar_book_id
+			WHERE ub.status = 'completed'
+			AND NOT EXISTS (
+				SELECT 1
+				FROM user_books shelved
+				WHERE shelved.user_id = ub.user_id AND shelved.work_id = b.work_id
+			)
+			GROUP BY ub.user_id, s.similar_book_id
+		),
+		ranked AS (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY score DESC, book_id) AS rank
+			FROM candidates
+		)
+		INSERT INTO user_recommendations (user_id, book_id, score)
+		SELECT user_id, book_id, score
+		FROM ranked
+		WHERE rank <= $1`,
+		config.UserRecommendations,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSimilarBooks returns the books most similar to the book, best first.
+// Archived books are left out. It returns nil if the book does not exist.
+func (pg *PostgresRecommendationStore) GetSimilarBooks(bookID int64) ([]*SimilarBook, error) {
+	var exists bool
+	err := pg.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM books WHERE id = $1)`, bookID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := pg.db.Query(`
+		SELECT `+recommendedBookJSON+`, s.co_readers, s.score
+		FROM book_similarities s
+		JOIN books b ON b.id = s.similar_book_id
+		LEFT JOIN book_images bi ON b.id = bi.book_id
+		WHERE s.book_id = $1 AND b.archived_at IS NULL
+		ORDER BY s.score DESC, b.id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	similar := []*SimilarBook{}
+	for rows.Next() {
+		book := &SimilarBook{Book: &Book{}}
+		var bookJSON []byte
+		if err := rows.Scan(&bookJSON, &book.CoReaders, &book.Score); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bookJSON, book.Book); err != nil {
+			return nil, err
+		}
+		similar = append(similar, book)
+	}
+
+	return similar, rows.Err()
+}
+
+// GetRecommendations returns a page of the user's recommendations, best
+// first, and their total. Works the user shelved since the recommendations
+// were computed, and archived books, are left out.
+func (pg *PostgresRecommendationStore) GetRecommendations(userID int64, page, limit int) ([]*RecommendedBook, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	rows, err := pg.db.Query(`
+		SELECT `+recommendedBookJSON+`, r.score, COUNT(*) OVER ()
+		FROM user_recommendations r
+		JOIN books b ON b.id = r.book_id
+		LEFT JOIN book_images bi ON b.id = bi.book_id
+		WHERE r.user_id = $1 AND b.archived_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1
+			FROM user_books shelved
+			WHERE shelved.user_id = r.user_id AND shelved.work_id = b.work_id
+		)
+		ORDER BY r.score DESC, b.id
+		LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	recommendations := []*RecommendedBook{}
+	total := 0
+	for rows.Next() {
+		recommendation := &RecommendedBook{Book: &Book{}}
+		var bookJSON []byte
+		if err := rows.Scan(&bookJSON, &recommendation.Score, &total); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(bookJSON, recommendation.Book); err != nil {
+			return nil, 0, err
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+
+	return recommendations, total, rows.Err()
+}
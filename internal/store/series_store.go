@@ -0,0 +1,224 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+)
+
+type Series struct {
+	ID          int64         `json:"id"`
+	Title       string        `json:"title"`
+	Description *string       `json:"description"`
+	Books       []SeriesEntry `json:"books"`
+}
+
+type SeriesEntry struct {
+	Position float64 `json:"position"`
+	Book     *Book   `json:"book"`
+}
+
+// BookSeries is the series membership shown on a book.
+type BookSeries struct {
+	ID       int64   `json:"id"`
+	Title    string  `json:"title"`
+	Position float64 `json:"position"`
+}
+
+type PostgresSeriesStore struct {
+	db *sql.DB
+}
+
+func NewPostgresSeriesStore(db *sql.DB) *PostgresSeriesStore {
+	return &PostgresSeriesStore{db: db}
+}
+
+type SeriesStore interface {
+	CreateSeries(series *Series) (*Series, error)
+	GetSeriesByID(id int64) (*Series, error)
+	UpdateSeries(series *Series) error
+	DeleteSeriesByID(id int64) error
+	SetSeriesBook(seriesID, bookID int64, position float64) error
+	RemoveSeriesBook(seriesID, bookID int64) error
+	GetNextInSeries(bookID int64) (*Book, error)
+}
+
+func (ss *PostgresSeriesStore) CreateSeries(series *Series) (*Series, error) {
+	err := ss.db.QueryRow(`
+		INSERT INTO series (title, description)
+		VALUES ($1, $2)
+		RETURNING id`,
+		series.Title, series.Description,
+	).Scan(&series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	series.Books = []SeriesEntry{}
+	return series, nil
+}
+
+func (ss *PostgresSeriesStore) GetSeriesByID(id int64) (*Series, error) {
+	series := &Series{}
+	err := ss.db.QueryRow(`
+		SELECT id, title, description
+		FROM series
+		WHERE id = $1`, id,
+	).Scan(&series.ID, &series.Title, &series.Description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ss.db.Query(`
+		SELECT sb.position,
+		json_build_object(
+			'id', b.id,
+			'title', b.title,
+			'published_date', b.published_date,
+			'page_count', b.page_count,
+			'isbn_13', b.isbn_13,
+			'authors', COALESCE(
+				(SELECT json_agg(a.name)
+				FROM book_authors ba
+				JOIN authors a ON ba.author_id = a.id
+				WHERE ba.book_id = b.id),
+				'[]'
+			),
+			'book_images', json_build_object(
+				'thumbnail_url', bi.thumbnail_url,
+				'small_url', bi.small_url
+			)
+		) AS book
+		FROM series_books sb
+		JOIN books b ON sb.book_id = b.id
+		LEFT JOIN book_images bi ON b.id = bi.book_id
+		WHERE sb.series_id = $1 AND b.archived_at IS NULL
+		ORDER BY sb.position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	series.Books = []SeriesEntry{}
+	for rows.Next() {
+		var entry SeriesEntry
+		var bookJSON []byte
+		if err := rows.Scan(&entry.Position, &bookJSON); err != nil {
+			return nil, err
+		}
+
+		entry.Book = &Book{}
+		if err := json.Unmarshal(bookJSON, entry.Book); err != nil {
+			return nil, err
+		}
+		series.Books = append(series.Books, entry)
+	}
+
+	return series, rows.Err()
+}
+
+func (ss *PostgresSeriesStore) UpdateSeries(series *Series) error {
+	res, err := ss.db.Exec(`
+		UPDATE series
+		SET title = $1, description = $2
+		WHERE id = $3`,
+		series.Title, series.Description, series.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (ss *PostgresSeriesStore) DeleteSeriesByID(id int64) error {
+	res, err := ss.db.Exec(`DELETE FROM series WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetSeriesBook adds a book to a series at the given position, moving it out of
+// any series it previously belonged to.
+func (ss *PostgresSeriesStore) SetSeriesBook(seriesID, bookID int64, position float64) error {
+	_, err := ss.db.Exec(`
+		INSERT INTO series_books (series_id, book_id, position)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (book_id) DO UPDATE
+		SET series_id = EXCLUDED.series_id,
+		    position = EXCLUDED.position`,
+		seriesID, bookID, position,
+	)
+	return err
+}
+
+func (ss *PostgresSeriesStore) RemoveSeriesBook(seriesID, bookID int64) error {
+	res, err := ss.db.Exec(`
+		DELETE FROM series_books
+		WHERE series_id = $1 AND book_id = $2`,
+		seriesID, bookID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetNextInSeries returns the book that follows bookID in its series, or nil
+// when the book is the last one or is not part of a series. Archived books are
+// skipped.
+func (ss *PostgresSeriesStore) GetNextInSeries(bookID int64) (*Book, error) {
+	book := &Book{}
+	err := ss.db.QueryRow(`
+		SELECT b.id, b.title, b.isbn_13, bi.thumbnail_url
+		FROM series_books current
+		JOIN series_books next ON next.series_id = current.series_id AND next.position > current.position
+		JOIN books b ON next.book_id = b.id
+		LEFT JOIN book_images bi ON b.id = bi.book_id
+		WHERE current.book_id = $1 AND b.archived_at IS NULL
+		ORDER BY next.position
+		LIMIT 1`, bookID,
+	).Scan(&book.ID, &book.Title, &book.ISBN13, &book.Images.ThumbnailUrl)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
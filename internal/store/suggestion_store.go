@@ -0,0 +1,345 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Review states of a book edit suggestion.
+const (
+	SuggestionPending          = "pending"
+	SuggestionApproved         = "approved"
+	SuggestionPartiallyApplied = "partially_applied"
+	SuggestionRejected         = "rejected"
+)
+
+// ErrSuggestionReviewed is returned when reviewing a suggestion that is no
+// longer pending.
+var ErrSuggestionReviewed = errors.New("suggestion has already been reviewed")
+
+// BookSuggestion is a reader's proposed edit to a catalog book. Before holds
+// the book's values of the changed fields when the suggestion was made, After
+// the proposed ones.
+type BookSuggestion struct {
+	ID            int64       `json:"id"`
+	BookID        int64       `json:"book_id"`
+	UserID        int64       `json:"user_id"`
+	Username      string      `json:"username"`
+	Status        string      `json:"status" example:"pending"`
+	Comment       *string     `json:"comment"`
+	Before        BookChanges `json:"before"`
+	After         BookChanges `json:"after"`
+	AppliedFields []string    `json:"applied_fields"`
+	ReviewerID    *int64      `json:"reviewer_id"`
+	ReviewNote    *string     `json:"review_note"`
+	CreatedAt     time.Time   `json:"created_at"`
+	ReviewedAt    *time.Time  `json:"reviewed_at"`
+}
+
+// SuggestionFilter narrows GetSuggestions. Zero values mean "no filter".
+type SuggestionFilter struct {
+	Status string
+	BookID *int64
+	UserID *int64
+}
+
+// SuggestionReview is a reviewer's decision on a pending suggestion.
+type SuggestionReview struct {
+	ReviewerID    int64
+	Status        string
+	AppliedFields []string
+	Note          *string
+}
+
+// BookContributor is a reader whose suggestions were applied to a book.
+type BookContributor struct {
+	UserID             int64     `json:"user_id"`
+	Username           string    `json:"username"`
+	AppliedSuggestions int       `json:"applied_suggestions"`
+	LastContributionAt time.Time `json:"last_contribution_at"`
+}
+
+type PostgresSuggestionStore struct {
+	db *sql.DB
+}
+
+func NewPostgresSuggestionStore(db *sql.DB) *PostgresSuggestionStore {
+	return &PostgresSuggestionStore{db: db}
+}
+
+type SuggestionStore interface {
+	CreateSuggestion(suggestion *BookSuggestion) (*BookSuggestion, error)
+	GetSuggestionByID(id int64) (*BookSuggestion, error)
+	GetSuggestions(filter SuggestionFilter, page, limit int) ([]*BookSuggestion, int, error)
+	ReviewSuggestion(id int64, review SuggestionReview) (*BookSuggestion, error)
+	ApplySuggestion(id int64, review SuggestionReview, book *Book, opts UpdateBookOptions) (*BookSuggestion, error)
+	GetBookContributors(bookID int64) ([]BookContributor, error)
+}
+
+const suggestionColumns = `
+	s.id, s.book_id, s.user_id, u.username, s.status, s.comment, s.before, s.after,
+	s.applied_fields, s.reviewer_id, s.review_note, s.created_at, s.reviewed_at`
+
+func (ss *PostgresSuggestionStore) CreateSuggestion(suggestion *BookSuggestion) (*BookSuggestion, error) {
+	before, err := json.Marshal(suggestion.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := json.Marshal(suggestion.After)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int64
+	err = ss.db.QueryRow(`
+		INSERT INTO book_suggestions (book_id, user_id, comment, before, after)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		suggestion.BookID, suggestion.UserID, suggestion.Comment, before, after,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.GetSuggestionByID(id)
+}
+
+// GetSuggestionByID returns the suggestion, or nil if it does not exist.
+func (ss *PostgresSuggestionStore) GetSuggestionByID(id int64) (*BookSuggestion, error) {
+	suggestion, err := scanSuggestion(ss.db.QueryRow(`
+		SELECT `+suggestionColumns+`
+		FROM book_suggestions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestion, nil
+}
+
+// GetSuggestions lists suggestions oldest first, so a review queue is worked
+// through in the order suggestions came in.
+func (ss *PostgresSuggestionStore) GetSuggestions(filter SuggestionFilter, page, limit int) ([]*BookSuggestion, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	where, args := suggestionFilterClause(filter, 2)
+
+	rows, err := ss.db.Query(`
+		SELECT `+suggestionColumns+`, COUNT(*) OVER ()
+		FROM book_suggestions s
+		JOIN users u ON u.id = s.user_id
+		`+where+`
+		ORDER BY s.created_at, s.id
+		LIMIT $1 OFFSET $2`,
+		append([]interface{}{limit, offset}, args...)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	suggestions := []*BookSuggestion{}
+	total := 0
+	for rows.Next() {
+		suggestion, err := scanSuggestion(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, total, rows.Err()
+}
+
+func suggestionFilterClause(filter SuggestionFilter, argOffset int) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("s.status = $%d", argOffset+len(args)))
+	}
+
+	if filter.BookID != nil {
+		args = append(args, *filter.BookID)
+		conditions = append(conditions, fmt.Sprintf("s.book_id = $%d", argOffset+len(args)))
+	}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("s.user_id = $%d", argOffset+len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ReviewSuggestion records the decision on a pending suggestion. It returns
+// sql.ErrNoRows when the suggestion does not exist and ErrSuggestionReviewed
+// when it has already been reviewed.
+func (ss *PostgresSuggestionStore) ReviewSuggestion(id int64, review SuggestionReview) (*BookSuggestion, error) {
+	return ss.reviewSuggestion(id, review, nil)
+}
+
+// ApplySuggestion saves book, which has the suggestion's changes applied, and
+// records the review in the same transaction. The suggestion is claimed
+// first, so concurrent approvals apply it only once. It returns the errors of
+// ReviewSuggestion and UpdateBook.
+func (ss *PostgresSuggestionStore) ApplySuggestion(id int64, review SuggestionReview, book *Book, opts UpdateBookOptions) (*BookSuggestion, error) {
+	return ss.reviewSuggestion(id, review, func(tx *sql.Tx) error {
+		if err := lockBook(tx, book.ID, opts.IfMatch); err != nil {
+			return err
+		}
+		return applyBookUpdate(tx, book, opts, BookRevisionUpdated)
+	})
+}
+
+// reviewSuggestion moves a pending suggestion to its reviewed state and runs
+// apply, if any, in the same transaction.
+func (ss *PostgresSuggestionStore) reviewSuggestion(id int64, review SuggestionReview, apply func(tx *sql.Tx) error) (*BookSuggestion, error) {
+	if review.AppliedFields == nil {
+		review.AppliedFields = []string{}
+	}
+	appliedFields, err := json.Marshal(review.AppliedFields)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback review transaction: %v", rbErr)
+		}
+	}()
+
+	var claimed int64
+	err = tx.QueryRow(`
+		UPDATE book_suggestions
+		SET status = $1, applied_fields = $2,
+		    reviewer_id = $3, review_note = $4, reviewed_at = NOW()
+		WHERE id = $5 AND status = 'pending'
+		RETURNING id`,
+		review.Status, appliedFields, review.ReviewerID, review.Note, id,
+	).Scan(&claimed)
+	if err == sql.ErrNoRows {
+		suggestion, getErr := ss.GetSuggestionByID(id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if suggestion == nil {
+			return nil, sql.ErrNoRows
+		}
+		return nil, ErrSuggestionReviewed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if apply != nil {
+		if err := apply(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ss.GetSuggestionByID(id)
+}
+
+// GetBookContributors lists the readers whose suggestions were applied to the
+// book, most prolific first.
+func (ss *PostgresSuggestionStore) GetBookContributors(bookID int64) ([]BookContributor, error) {
+	rows, err := ss.db.Query(`
+		SELECT u.id, u.username, COUNT(*), MAX(s.reviewed_at)
+		FROM book_suggestions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.book_id = $1 AND s.status IN ($2, $3)
+		GROUP BY u.id, u.username
+		ORDER BY COUNT(*) DESC, MAX(s.reviewed_at) DESC`,
+		bookID, SuggestionApproved, SuggestionPartiallyApplied,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	contributors := []BookContributor{}
+	for rows.Next() {
+		var c BookContributor
+		if err := rows.Scan(&c.UserID, &c.Username, &c.AppliedSuggestions, &c.LastContributionAt); err != nil {
+			return nil, err
+		}
+		contributors = append(contributors, c)
+	}
+
+	return contributors, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSuggestion(row rowScanner, extra ...any) (*BookSuggestion, error) {
+	suggestion := &BookSuggestion{}
+	var before, after, appliedFields []byte
+	dest := []any{
+		&suggestion.ID,
+		&suggestion.BookID,
+		&suggestion.UserID,
+		&suggestion.Username,
+		&suggestion.Status,
+		&suggestion.Comment,
+		&before,
+		&after,
+		&appliedFields,
+		&suggestion.ReviewerID,
+		&suggestion.ReviewNote,
+		&suggestion.CreatedAt,
+		&suggestion.ReviewedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(before, &suggestion.Before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &suggestion.After); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(appliedFields, &suggestion.AppliedFields); err != nil {
+		return nil, err
+	}
+
+	return suggestion, nil
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+)
+
+type BookTag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type PostgresTagStore struct {
+	db *sql.DB
+}
+
+func NewPostgresTagStore(db *sql.DB) *PostgresTagStore {
+	return &PostgresTagStore{db: db}
+}
+
+type TagStore interface {
+	AddBookTag(userID, bookID int64, name string) error
+	RemoveBookTag(userID, bookID int64, name string) error
+	GetBookTags(bookID int64) ([]*BookTag, error)
+	GetUserTags(userID int64) ([]*BookTag, error)
+}
+
+// NormalizeTag trims and lowercases a free-form tag so "Sci-Fi " and "sci-fi"
+// are stored as the same tag.
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func (ts *PostgresTagStore) AddBookTag(userID, bookID int64, name string) error {
+	tx, err := ts.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	if err := addBookTag(tx, userID, bookID, name); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func addBookTag(tx *sql.Tx, userID, bookID int64, name string) error {
+	var tagID int64
+	err := tx.QueryRow(`
+		INSERT INTO tags (name)
+		VALUES ($1)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id`,
+		NormalizeTag(name),
+	).Scan(&tagID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO book_tags (book_id, tag_id, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`,
+		bookID, tagID, userID,
+	)
+	return err
+}
+
+func (ts *PostgresTagStore) RemoveBookTag(userID, bookID int64, name string) error {
+	res, err := ts.db.Exec(`
+		DELETE FROM book_tags bt
+		USING tags t
+		WHERE bt.tag_id = t.id AND bt.book_id = $1 AND bt.user_id = $2 AND t.name = $3`,
+		bookID, userID, NormalizeTag(name),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (ts *PostgresTagStore) GetBookTags(bookID int64) ([]*BookTag, error) {
+	return ts.queryTags(`
+		SELECT t.name, COUNT(*)
+		FROM book_tags bt
+		JOIN tags t ON bt.tag_id = t.id
+		WHERE bt.book_id = $1
+		GROUP BY t.name
+		ORDER BY COUNT(*) DESC, t.name`, bookID)
+}
+
+func (ts *PostgresTagStore) GetUserTags(userID int64) ([]*BookTag, error) {
+	return ts.queryTags(`
+		SELECT t.name, COUNT(*)
+		FROM book_tags bt
+		JOIN tags t ON bt.tag_id = t.id
+		WHERE bt.user_id = $1
+		GROUP BY t.name
+		ORDER BY COUNT(*) DESC, t.name`, userID)
+}
+
+func (ts *PostgresTagStore) queryTags(query string, id int64) ([]*BookTag, error) {
+	rows, err := ts.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	tags := []*BookTag{}
+	for rows.Next() {
+		tag := &BookTag{}
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
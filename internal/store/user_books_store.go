@@ -3,23 +3,35 @@ package store
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 )
 
+// ErrAlreadyShelved is returned when the user already has an edition of the
+// book on their shelf.
+var ErrAlreadyShelved = errors.New("book already on the user's shelf")
+
 type UserBook struct {
 	ID                int64     `json:"id"`
 	UserID            int64     `json:"user_id"`
-	BookID            int64     `json:"book_id"`
+	BookID            int64     `json:"book_id"` // the edition being read
+	WorkID            int64     `json:"work_id"`
 	Status            string    `json:"status"` // "wishlist", "reading", "completed"
 	StartedAt         *JSONDate `json:"started_at,omitempty"`
 	CompletedAt       *JSONDate `json:"completed_at,omitempty"`
 	PagesRead         *int      `json:"pages_read,omitempty"`
 	PercentageRead    *float64  `json:"percentage_read,omitempty"`
+	Rating            *float64  `json:"rating,omitempty"` // 0.25 to 5 stars
 	ProgressUpdatedAt *JSONDate `json:"progress_updated_at,omitempty"`
 	UpdatedAt         JSONDate  `json:"updated_at"`
+	Version           int       `json:"version"`
 	Book              *Book     `json:"book,omitempty"`
+	NextInSeries      *Book     `json:"next_in_series,omitempty"`
+	// Position maps the reading progress to the book's chapters.
+	Position *ReadingPosition `json:"position,omitempty"`
 }
 
 type UserBookStats struct {
@@ -33,15 +45,21 @@ type UpdateUserBookRequest struct {
 	Status         *string    `json:"status"`
 	PagesRead      *int       `json:"pages_read"`
 	PercentageRead *float64   `json:"percentage_read"`
+	Rating         *float64   `json:"rating"`       // 0 removes the rating
 	CompletedAt    **JSONDate `json:"completed_at"` // pointer-to-pointer allows null explicitly
 }
 
 type BasicUserBook struct {
-	ID        int64    `json:"id"`
-	UserID    int64    `json:"user_id"`
-	Status    string   `json:"status"`
-	UpdatedAt JSONDate `json:"updated_at"`
-	Book      *Book    `json:"book,omitempty"`
+	ID             int64            `json:"id"`
+	UserID         int64            `json:"user_id"`
+	Status         string           `json:"status"`
+	PagesRead      *int             `json:"pages_read,omitempty"`
+	PercentageRead *float64         `json:"percentage_read,omitempty"`
+	Rating         *float64         `json:"rating,omitempty"`
+	UpdatedAt      JSONDate         `json:"updated_at"`
+	Version        int              `json:"version"`
+	Book           *Book            `json:"book,omitempty"`
+	Position       *ReadingPosition `json:"position,omitempty"`
 }
 
 type PostgresUserBooksStore struct {
@@ -55,9 +73,11 @@ func NewUserBooksStore(db *sql.DB) *PostgresUserBooksStore {
 type UserBooksStore interface {
 	GetUserBooksByUserID(userID int64, status *string, page, limit int) ([]*BasicUserBook, error)
 	GetUserBookStatsByUserID(userID int64) (*UserBookStats, error)
+	GetReadingPosition(userID, bookID int64) (*UserBookPosition, error)
 	AddUserBook(userid, bookid int64, status string) (*UserBook, error)
-	UpdateUserBook(userID, userBookID int64, req UpdateUserBookRequest) (*UserBook, error)
-	DeleteUserBook(userID, userBookID int64) error
+	ImportUserBook(userBook *UserBook, tags []string) (*UserBook, error)
+	UpdateUserBook(userID, userBookID int64, req UpdateUserBookRequest, ifMatch []int) (*UserBook, error)
+	DeleteUserBook(userID, userBookID int64, ifMatch []int) error
 }
 
 func (pub *PostgresUserBooksStore) GetUserBooksByUserID(userID int64, status *string, page, limit int) ([]*BasicUserBook, error) {
@@ -71,7 +91,7 @@ func (pub *PostgresUserBooksStore) GetUserBooksByUserID(userID int64, status *st
 	offset := (page - 1) * limit
 
 	rows, err := pub.db.Query(`
-        SELECT ub.id, ub.user_id, ub.status, ub.updated_at,
+        SELECT ub.id, ub.user_id, ub.status, ub.pages_read, ub.percentage_read, ub.rating, ub.updated_at, ub.version,
 
 		jsonb_build_object(
 			'id', b.id,
@@ -81,6 +101,7 @@ func (pub *PostgresUserBooksStore) GetUserBooksByUserID(userID int64, status *st
 			'page_count', b.page_count,
 			'isbn_13', b.isbn_13,
 			'isbn_10', b.isbn_10,
+			'archived_at', b.archived_at,
 			'publisher', p.name,
 
 			'authors',
@@ -100,15 +121,22 @@ func (pub *PostgresUserBooksStore) GetUserBooksByUserID(userID int64, status *st
 				'{}'::jsonb
 			),
 
+			-- A subquery, so books with several authors don't list each
+			-- chapter once per author.
 			'chapters',
 			COALESCE(
-				json_agg(
+				(SELECT json_agg(
 					jsonb_build_object(
-						'id',     c.id,
-						'number', c.number,
-						'title',  c.title
+						'id',         c.id,
+						'number',     c.number,
+						'title',      c.title,
+						'part',       c.part,
+						'start_page', c.start_page,
+						'end_page',   c.end_page
 					) ORDER BY c.number
-				) FILTER (WHERE c.id IS NOT NULL),
+				)
+				FROM chapters c
+				WHERE c.book_id = b.id),
 				'[]'::json
 			)
 		) AS book
@@ -119,7 +147,6 @@ func (pub *PostgresUserBooksStore) GetUserBooksByUserID(userID int64, status *st
 	LEFT JOIN book_authors ba ON b.id = ba.book_id
 	LEFT JOIN authors a ON ba.author_id = a.id
 	LEFT JOIN book_images bi ON b.id = bi.book_id
-	LEFT JOIN chapters c ON b.id = c.book_id
 
 	WHERE ub.user_id = $1
 	AND ($4::user_book_status IS NULL OR ub.status = $4::user_book_status)
@@ -150,7 +177,7 @@ func (pub *PostgresUserBooksStore) GetUserBooksByUserID(userID int64, status *st
 		var ub BasicUserBook
 		var bookJson []byte
 
-		err := rows.Scan(&ub.ID, &ub.UserID, &ub.Status, &ub.UpdatedAt, &bookJson)
+		err := rows.Scan(&ub.ID, &ub.UserID, &ub.Status, &ub.PagesRead, &ub.PercentageRead, &ub.Rating, &ub.UpdatedAt, &ub.Version, &bookJson)
 		if err != nil {
 			return nil, err
 		}
@@ -160,6 +187,7 @@ func (pub *PostgresUserBooksStore) GetUserBooksByUserID(userID int64, status *st
 			return nil, err
 		}
 		ub.Book = &book
+		ub.Position = ComputeReadingPosition(book.Chapters, book.PageCount, ub.Status, ub.PagesRead, ub.PercentageRead)
 
 		userBooks = append(userBooks, &ub)
 	}
@@ -196,11 +224,13 @@ func (pub *PostgresUserBooksStore) GetUserBookStatsByUserID(userID int64) (*User
 func (pub *PostgresUserBooksStore) AddUserBook(userid, bookid int64, status string) (*UserBook, error) {
 	userBook := &UserBook{}
 	err := pub.db.QueryRow(`
-		INSERT INTO user_books (user_id, book_id, status)
-		VALUES ($1, $2, $3)
-		RETURNING id, updated_at`,
+		INSERT INTO user_books (user_id, book_id, work_id, status)
+		SELECT $1, b.id, b.work_id, $3
+		FROM books b
+		WHERE b.id = $2 AND b.archived_at IS NULL
+		RETURNING id, work_id, updated_at, version`,
 		userid, bookid, status,
-	).Scan(&userBook.ID, &userBook.UpdatedAt)
+	).Scan(&userBook.ID, &userBook.WorkID, &userBook.UpdatedAt, &userBook.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -211,7 +241,73 @@ func (pub *PostgresUserBooksStore) AddUserBook(userid, bookid int64, status stri
 	return userBook, nil
 }
 
-func (pub *PostgresUserBooksStore) UpdateUserBook(userID, userBookID int64, req UpdateUserBookRequest) (*UserBook, error) {
+// ImportUserBook shelves userBook.BookID for userBook.UserID with the status,
+// dates and rating brought over from another site, and tags the book with
+// tags. It returns sql.ErrNoRows when the book is not in the catalog and
+// ErrAlreadyShelved when the user already has an edition of it; entries the
+// user already has are never overwritten.
+func (pub *PostgresUserBooksStore) ImportUserBook(userBook *UserBook, tags []string) (*UserBook, error) {
+	tx, err := pub.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	imported := *userBook
+	err = tx.QueryRow(`
+		INSERT INTO user_books (user_id, book_id, work_id, status, started_at, completed_at, rating)
+		SELECT $1, b.id, b.work_id, $3, $4, $5, $6
+		FROM books b
+		WHERE b.id = $2 AND b.archived_at IS NULL
+		ON CONFLICT (user_id, work_id) DO NOTHING
+		RETURNING id, work_id, updated_at, version`,
+		userBook.UserID, userBook.BookID, userBook.Status,
+		dateArg(userBook.StartedAt), dateArg(userBook.CompletedAt), userBook.Rating,
+	).Scan(&imported.ID, &imported.WorkID, &imported.UpdatedAt, &imported.Version)
+	if err == sql.ErrNoRows {
+		var exists bool
+		err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM books WHERE id = $1 AND archived_at IS NULL)`, userBook.BookID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrAlreadyShelved
+		}
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		if err := addBookTag(tx, userBook.UserID, userBook.BookID, tag); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &imported, nil
+}
+
+// dateArg turns an optional date into a query argument.
+func dateArg(d *JSONDate) *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.ToTime()
+	return &t
+}
+
+// UpdateUserBook applies the fields set in req to the user's entry. It returns
+// sql.ErrNoRows when the user has no such entry and ErrVersionMismatch when the
+// entry's version is not one of ifMatch. A nil ifMatch updates any version.
+func (pub *PostgresUserBooksStore) UpdateUserBook(userID, userBookID int64, req UpdateUserBookRequest, ifMatch []int) (*UserBook, error) {
 	// "setClauses" collects the SQL pieces for columns that actually change.
 	setClauses := []string{}
 	args := []interface{}{}
@@ -238,6 +334,18 @@ func (pub *PostgresUserBooksStore) UpdateUserBook(userID, userBookID int64, req
 		}
 	}
 
+	// If user sent a rating; 0 clears it
+	if req.Rating != nil {
+		if *req.Rating == 0 {
+			setClauses = append(setClauses, "rating = NULL")
+		} else {
+			setClauses = append(setClauses,
+				fmt.Sprintf("rating = $%d", len(args)+1),
+			)
+			args = append(args, *req.Rating)
+		}
+	}
+
 	if req.Status != nil && *req.Status == "completed" {
 		// Only set completed_at automatically if the user didn't set it manually
 		if req.CompletedAt == nil {
@@ -273,22 +381,32 @@ func (pub *PostgresUserBooksStore) UpdateUserBook(userID, userBookID int64, req
 	// Always update "updated_at"
 	setClauses = append(setClauses, "updated_at = NOW()")
 
+	// Only update the version the client last saw
+	versionClause := ""
+	if ifMatch != nil {
+		versionClause = fmt.Sprintf("AND version = ANY($%d)", len(args)+3)
+	}
+
 	// Build the SQL dynamically
 	query := fmt.Sprintf(`
         UPDATE user_books
         SET %s
-        WHERE id = $%d AND user_id = $%d
-        RETURNING id, user_id, book_id, status, updated_at,
+        WHERE id = $%d AND user_id = $%d %s
+        RETURNING id, user_id, book_id, work_id, status, updated_at,
                   started_at, completed_at, pages_read, percentage_read,
-                  progress_updated_at
+                  rating, progress_updated_at, version
     `,
 		strings.Join(setClauses, ", "),
 		len(args)+1, // ID placeholder
 		len(args)+2, // userID placeholder
+		versionClause,
 	)
 
 	// Add WHERE clause parameters
 	args = append(args, userBookID, userID)
+	if ifMatch != nil {
+		args = append(args, versionArgs(ifMatch))
+	}
 
 	// Execute query and scan result
 	userBook := &UserBook{}
@@ -296,27 +414,50 @@ func (pub *PostgresUserBooksStore) UpdateUserBook(userID, userBookID int64, req
 		&userBook.ID,
 		&userBook.UserID,
 		&userBook.BookID,
+		&userBook.WorkID,
 		&userBook.Status,
 		&userBook.UpdatedAt,
 		&userBook.StartedAt,
 		&userBook.CompletedAt,
 		&userBook.PagesRead,
 		&userBook.PercentageRead,
+		&userBook.Rating,
 		&userBook.ProgressUpdatedAt,
+		&userBook.Version,
 	)
+	if err == sql.ErrNoRows && ifMatch != nil {
+		return nil, pub.userBookMissingOrChanged(userID, userBookID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	// The position is informational; the update has already been saved.
+	chapters, pageCount, err := pub.getChaptersAndPageCount(userBook.BookID)
+	if err != nil {
+		log.Printf("failed to load chapters for reading position: %v", err)
+		return userBook, nil
+	}
+	userBook.Position = ComputeReadingPosition(chapters, pageCount, userBook.Status, userBook.PagesRead, userBook.PercentageRead)
+
 	return userBook, nil
 }
 
-func (pub *PostgresUserBooksStore) DeleteUserBook(userID, userBookID int64) error {
+// DeleteUserBook removes the user's entry. It returns sql.ErrNoRows when the
+// user has no such entry and ErrVersionMismatch when the entry's version is not
+// one of ifMatch. A nil ifMatch deletes any version.
+func (pub *PostgresUserBooksStore) DeleteUserBook(userID, userBookID int64, ifMatch []int) error {
 	// Delete only the row belonging to this user
-	res, err := pub.db.Exec(`
+	query := `
         DELETE FROM user_books
-        WHERE id = $1 AND user_id = $2
-    `, userBookID, userID)
+        WHERE id = $1 AND user_id = $2`
+	args := []interface{}{userBookID, userID}
+	if ifMatch != nil {
+		query += ` AND version = ANY($3)`
+		args = append(args, versionArgs(ifMatch))
+	}
+
+	res, err := pub.db.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -328,8 +469,24 @@ func (pub *PostgresUserBooksStore) DeleteUserBook(userID, userBookID int64) erro
 	}
 
 	if rows == 0 {
+		if ifMatch != nil {
+			return pub.userBookMissingOrChanged(userID, userBookID)
+		}
 		return sql.ErrNoRows
 	}
 
 	return nil
 }
+
+// userBookMissingOrChanged explains why a conditional write matched no entry.
+func (pub *PostgresUserBooksStore) userBookMissingOrChanged(userID, userBookID int64) error {
+	var exists bool
+	err := pub.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM user_books WHERE id = $1 AND user_id = $2)`, userBookID, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return sql.ErrNoRows
+}
@@ -0,0 +1,412 @@
+package store
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// States of a personal data export.
+const (
+	UserExportQueued    = "queued"
+	UserExportRunning   = "running"
+	UserExportCompleted = "completed"
+	UserExportFailed    = "failed"
+	UserExportExpired   = "expired"
+)
+
+// ErrExportInProgress is returned when the user already has an export queued
+// or running.
+var ErrExportInProgress = errors.New("an export is already in progress")
+
+// UserExport is a personal data export built in the background. DownloadURL
+// is set once the archive is ready, until ExpiresAt.
+type UserExport struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Status      string     `json:"status" example:"completed"`
+	Error       *string    `json:"error"`
+	SizeBytes   *int64     `json:"size_bytes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	DownloadURL string     `json:"download_url,omitempty"`
+}
+
+// UserExportData is everything held about a user, as it goes in their export.
+type UserExportData struct {
+	Profile   *User
+	UserBooks []*ExportedUserBook
+	Comments  []*ExportedComment
+	Tags      []*ExportedTag
+	Tokens    []*ExportedToken
+}
+
+type ExportedUserBook struct {
+	ID                int64      `json:"id"`
+	BookID            int64      `json:"book_id"`
+	Title             string     `json:"title"`
+	ISBN13            string     `json:"isbn_13"`
+	Status            string     `json:"status"`
+	StartedAt         *time.Time `json:"started_at"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	PagesRead         *int       `json:"pages_read"`
+	PercentageRead    *float64   `json:"percentage_read"`
+	Rating            *float64   `json:"rating"`
+	ProgressUpdatedAt *time.Time `json:"progress_updated_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// ExportedComment is a chapter comment with the chapter and book it is on.
+type ExportedComment struct {
+	ID            int64     `json:"id"`
+	BookID        int64     `json:"book_id"`
+	BookTitle     string    `json:"book_title"`
+	ChapterID     int64     `json:"chapter_id"`
+	ChapterNumber int       `json:"chapter_number"`
+	ChapterTitle  string    `json:"chapter_title"`
+	Body          string    `json:"body"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ExportedTag struct {
+	BookID    int64  `json:"book_id"`
+	BookTitle string `json:"book_title"`
+	Tag       string `json:"tag"`
+}
+
+// ExportedToken describes a token without the token itself or its hash.
+type ExportedToken struct {
+	Scope  string    `json:"scope"`
+	Expiry time.Time `json:"expiry"`
+}
+
+type UserExportConfig struct {
+	// Dir is where archives are kept. It must not be served publicly.
+	Dir string
+	// LinkTTL is how long an archive can be downloaded once it is ready.
+	LinkTTL time.Duration
+	// SigningKey signs download links.
+	SigningKey []byte
+}
+
+// UserExportConfigFromEnv reads EXPORT_DIR, EXPORT_LINK_TTL and
+// EXPORT_SIGNING_KEY. Without a signing key a random one is used, and links
+// handed out stop working when the server restarts.
+func UserExportConfigFromEnv() UserExportConfig {
+	ttl := 24 * time.Hour
+	if d, err := time.ParseDuration(getEnv("EXPORT_LINK_TTL", "")); err == nil {
+		ttl = d
+	}
+
+	key := []byte(getEnv("EXPORT_SIGNING_KEY", ""))
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+	}
+
+	return UserExportConfig{
+		Dir:        getEnv("EXPORT_DIR", "exports"),
+		LinkTTL:    ttl,
+		SigningKey: key,
+	}
+}
+
+type PostgresUserExportStore struct {
+	db *sql.DB
+}
+
+func NewPostgresUserExportStore(db *sql.DB) *PostgresUserExportStore {
+	return &PostgresUserExportStore{db: db}
+}
+
+type UserExportStore interface {
+	CreateUserExport(userID int64) (*UserExport, error)
+	GetUserExportByID(id int64) (*UserExport, error)
+	GetUnfinishedUserExports() ([]*UserExport, error)
+	GetExpiredUserExports() ([]*UserExport, error)
+	StartUserExport(id int64) error
+	CompleteUserExport(id, sizeBytes int64, expiresAt time.Time) error
+	FailUserExport(id int64, jobErr error) error
+	ExpireUserExport(id int64) error
+	GetUserExportData(userID int64) (*UserExportData, error)
+}
+
+const userExportColumns = `
+	id, user_id, status, error, size_bytes, created_at, started_at, finished_at, expires_at`
+
+// CreateUserExport queues an export for the user. It returns
+// ErrExportInProgress when one is already queued or running.
+func (es *PostgresUserExportStore) CreateUserExport(userID int64) (*UserExport, error) {
+	export, err := scanUserExport(es.db.QueryRow(`
+		INSERT INTO user_exports (user_id)
+		SELECT $1
+		WHERE NOT EXISTS (
+			SELECT 1 FROM user_exports
+			WHERE user_id = $1 AND status IN ('queued', 'running')
+		)
+		RETURNING `+userExportColumns, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrExportInProgress
+	}
+	return export, err
+}
+
+// GetUserExportByID returns the export, or nil if it does not exist.
+func (es *PostgresUserExportStore) GetUserExportByID(id int64) (*UserExport, error) {
+	export, err := scanUserExport(es.db.QueryRow(`
+		SELECT `+userExportColumns+`
+		FROM user_exports
+		WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// GetUnfinishedUserExports returns the queued exports and those that were
+// running when the server stopped, oldest first.
+func (es *PostgresUserExportStore) GetUnfinishedUserExports() ([]*UserExport, error) {
+	return es.queryUserExports(`
+		SELECT ` + userExportColumns + `
+		FROM user_exports
+		WHERE status IN ('queued', 'running')
+		ORDER BY created_at, id`)
+}
+
+// GetExpiredUserExports returns the completed exports whose archives can no
+// longer be downloaded.
+func (es *PostgresUserExportStore) GetExpiredUserExports() ([]*UserExport, error) {
+	return es.queryUserExports(`
+		SELECT ` + userExportColumns + `
+		FROM user_exports
+		WHERE status = 'completed' AND expires_at <= NOW()
+		ORDER BY expires_at, id`)
+}
+
+func (es *PostgresUserExportStore) queryUserExports(query string, args ...any) ([]*UserExport, error) {
+	rows, err := es.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	exports := []*UserExport{}
+	for rows.Next() {
+		export, err := scanUserExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+	return exports, rows.Err()
+}
+
+func (es *PostgresUserExportStore) StartUserExport(id int64) error {
+	_, err := es.db.Exec(`
+		UPDATE user_exports
+		SET status = 'running', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1`, id)
+	return err
+}
+
+// CompleteUserExport records that the archive is ready to download until
+// expiresAt.
+func (es *PostgresUserExportStore) CompleteUserExport(id, sizeBytes int64, expiresAt time.Time) error {
+	_, err := es.db.Exec(`
+		UPDATE user_exports
+		SET status = 'completed', size_bytes = $1, expires_at = $2, finished_at = NOW()
+		WHERE id = $3`, sizeBytes, expiresAt, id)
+	return err
+}
+
+// FailUserExport marks the export failed with jobErr's message.
+func (es *PostgresUserExportStore) FailUserExport(id int64, jobErr error) error {
+	_, err := es.db.Exec(`
+		UPDATE user_exports
+		SET status = 'failed', error = $1, finished_at = NOW()
+		WHERE id = $2`, jobErr.Error(), id)
+	return err
+}
+
+// ExpireUserExport records that the export's archive has been deleted.
+func (es *PostgresUserExportStore) ExpireUserExport(id int64) error {
+	_, err := es.db.Exec(`
+		UPDATE user_exports
+		SET status = 'expired'
+		WHERE id = $1`, id)
+	return err
+}
+
+// GetUserExportData reads everything held about the user, or returns
+// sql.ErrNoRows when there is no such user.
+func (es *PostgresUserExportStore) GetUserExportData(userID int64) (*UserExportData, error) {
+	data := &UserExportData{Profile: &User{}}
+	err := es.db.QueryRow(`
+		SELECT id, username, email, role, created_at
+		FROM users
+		WHERE id = $1`, userID,
+	).Scan(&data.Profile.ID, &data.Profile.Username, &data.Profile.Email, &data.Profile.Role, &data.Profile.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.UserBooks, err = es.getExportedUserBooks(userID); err != nil {
+		return nil, err
+	}
+	if data.Comments, err = es.getExportedComments(userID); err != nil {
+		return nil, err
+	}
+	if data.Tags, err = es.getExportedTags(userID); err != nil {
+		return nil, err
+	}
+	if data.Tokens, err = es.getExportedTokens(userID); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (es *PostgresUserExportStore) getExportedUserBooks(userID int64) ([]*ExportedUserBook, error) {
+	rows, err := es.db.Query(`
+		SELECT ub.id, b.id, b.title, b.isbn_13, ub.status, ub.started_at, ub.completed_at,
+		       ub.pages_read, ub.percentage_read, ub.rating, ub.progress_updated_at, ub.updated_at
+		FROM user_books ub
+		JOIN books b ON b.id = ub.book_id
+		WHERE ub.user_id = $1
+		ORDER BY ub.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	userBooks := []*ExportedUserBook{}
+	for rows.Next() {
+		ub := &ExportedUserBook{}
+		err := rows.Scan(&ub.ID, &ub.BookID, &ub.Title, &ub.ISBN13, &ub.Status, &ub.StartedAt, &ub.CompletedAt,
+			&ub.PagesRead, &ub.PercentageRead, &ub.Rating, &ub.ProgressUpdatedAt, &ub.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		userBooks = append(userBooks, ub)
+	}
+	return userBooks, rows.Err()
+}
+
+func (es *PostgresUserExportStore) getExportedComments(userID int64) ([]*ExportedComment, error) {
+	rows, err := es.db.Query(`
+		SELECT c.id, b.id, b.title, ch.id, ch.number, ch.title, c.body, c.created_at, c.updated_at
+		FROM comments c
+		JOIN chapters ch ON ch.id = c.chapter_id
+		JOIN books b ON b.id = ch.book_id
+		WHERE c.user_id = $1
+		ORDER BY c.created_at, c.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	comments := []*ExportedComment{}
+	for rows.Next() {
+		c := &ExportedComment{}
+		err := rows.Scan(&c.ID, &c.BookID, &c.BookTitle, &c.ChapterID, &c.ChapterNumber, &c.ChapterTitle, &c.Body, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (es *PostgresUserExportStore) getExportedTags(userID int64) ([]*ExportedTag, error) {
+	rows, err := es.db.Query(`
+		SELECT b.id, b.title, t.name
+		FROM book_tags bt
+		JOIN tags t ON t.id = bt.tag_id
+		JOIN books b ON b.id = bt.book_id
+		WHERE bt.user_id = $1
+		ORDER BY b.id, t.name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	tags := []*ExportedTag{}
+	for rows.Next() {
+		tag := &ExportedTag{}
+		if err := rows.Scan(&tag.BookID, &tag.BookTitle, &tag.Tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func (es *PostgresUserExportStore) getExportedTokens(userID int64) ([]*ExportedToken, error) {
+	rows, err := es.db.Query(`
+		SELECT scope, expiry
+		FROM tokens
+		WHERE user_id = $1
+		ORDER BY expiry`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	tokens := []*ExportedToken{}
+	for rows.Next() {
+		token := &ExportedToken{}
+		if err := rows.Scan(&token.Scope, &token.Expiry); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func scanUserExport(row rowScanner) (*UserExport, error) {
+	export := &UserExport{}
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.Error,
+		&export.SizeBytes,
+		&export.CreatedAt,
+		&export.StartedAt,
+		&export.FinishedAt,
+		&export.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
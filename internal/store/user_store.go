@@ -58,6 +58,7 @@ type UserStore interface {
 	GetUserByUsername(username string) (*User, error)
 	UpdateUser(*User) error
 	GetUserToken(scope, plainTextPassword string) (*User, error)
+	UpdateUserRole(userID int64, role UserRole) (*User, error)
 }
 
 func (p *password) Set(plainTextPassword string) error {
@@ -178,3 +179,19 @@ func (us *PostgresUserStore) GetUserToken(scope, plainTextToken string) (*User,
 
 	return user, nil
 }
+
+func (us *PostgresUserStore) UpdateUserRole(userID int64, role UserRole) (*User, error) {
+	user := &User{PasswordHash: password{}}
+	err := us.db.QueryRow(`
+		UPDATE users
+		SET role = $1
+		WHERE id = $2
+		RETURNING id, username, email, role, created_at`,
+		role, userID,
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrVersionMismatch is returned by a conditional write when the record has
+// changed since the client read it.
+var ErrVersionMismatch = errors.New("record has been modified since it was read")
+
+// matchVersion checks a record's version against the versions a client said
+// it last saw. A nil ifMatch matches any version.
+func matchVersion(version int, ifMatch []int) error {
+	if ifMatch != nil && !slices.Contains(ifMatch, version) {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// versionArgs converts ifMatch into a parameter for "version = ANY($n)".
+func versionArgs(ifMatch []int) []int64 {
+	versions := make([]int64, len(ifMatch))
+	for i, v := range ifMatch {
+		versions[i] = int64(v)
+	}
+	return versions
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+)
+
+// ErrSingleEdition is returned when splitting a book that is already the only
+// edition of its work.
+var ErrSingleEdition = errors.New("book is the only edition of its work")
+
+// Work groups the editions (hardcover, paperback, translations...) of the same
+// book so readers' shelves and chapter discussions are shared between them.
+type Work struct {
+	ID       int64   `json:"id"`
+	Title    string  `json:"title"`
+	Editions []*Book `json:"editions"`
+}
+
+type PostgresWorkStore struct {
+	db *sql.DB
+}
+
+func NewPostgresWorkStore(db *sql.DB) *PostgresWorkStore {
+	return &PostgresWorkStore{db: db}
+}
+
+type WorkStore interface {
+	GetWorkByID(id int64) (*Work, error)
+	AddEditionToWork(workID, bookID int64) error
+	SplitEdition(bookID int64) (*Work, error)
+}
+
+func (ws *PostgresWorkStore) GetWorkByID(id int64) (*Work, error) {
+	work := &Work{}
+	err := ws.db.QueryRow(`
+		SELECT id, title
+		FROM works
+		WHERE id = $1`, id,
+	).Scan(&work.ID, &work.Title)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ws.db.Query(`
+		SELECT b.id, b.work_id, b.title, p.name, b.published_date, b.page_count, b.isbn_13, b.isbn_10,
+		bi.thumbnail_url, bi.small_url
+		FROM books b
+		JOIN publishers p ON b.publisher_id = p.id
+		LEFT JOIN book_images bi ON b.id = bi.book_id
+		WHERE b.work_id = $1 AND b.archived_at IS NULL
+		ORDER BY b.published_date, b.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("failed to close transaction: %v", closeErr)
+		}
+	}()
+
+	work.Editions = []*Book{}
+	for rows.Next() {
+		book := &Book{}
+		err := rows.Scan(
+			&book.ID,
+			&book.WorkID,
+			&book.Title,
+			&book.Publisher,
+			&book.PublishedDate,
+			&book.PageCount,
+			&book.ISBN13,
+			&book.ISBN10,
+			&book.Images.ThumbnailUrl,
+			&book.Images.SmallUrl,
+		)
+		if err != nil {
+			return nil, err
+		}
+		work.Editions = append(work.Editions, book)
+	}
+
+	return work, rows.Err()
+}
+
+// AddEditionToWork moves a book into another work. Readers who had shelved
+// both works keep whichever entry they updated most recently, and the book's
+// previous work is removed once it has no editions left.
+func (ws *PostgresWorkStore) AddEditionToWork(workID, bookID int64) (err error) {
+	tx, err := ws.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	var exists bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM works WHERE id = $1)`, workID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	var oldWorkID int64
+	err = tx.QueryRow(`SELECT work_id FROM books WHERE id = $1 FOR UPDATE`, bookID).Scan(&oldWorkID)
+	if err != nil {
+		return err
+	}
+	if oldWorkID == workID {
+		return tx.Commit()
+	}
+
+	// Drop the older of the two shelf entries for readers who had both works.
+	_, err = tx.Exec(`
+		DELETE FROM user_books stale
+		USING user_books moving, user_books target
+		WHERE moving.book_id = $1
+		AND target.work_id = $2
+		AND moving.user_id = target.user_id
+		AND stale.id = CASE WHEN moving.updated_at > target.updated_at THEN target.id ELSE moving.id END`,
+		bookID, workID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE user_books SET work_id = $1 WHERE book_id = $2`, workID, bookID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE books SET work_id = $1 WHERE id = $2`, workID, bookID)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteWorkIfEmpty(tx, oldWorkID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SplitEdition moves a book out of its work into a new work of its own, taking
+// the shelf entries of readers reading that edition with it.
+func (ws *PostgresWorkStore) SplitEdition(bookID int64) (_ *Work, err error) {
+	tx, err := ws.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("failed to rollback transaction: %v", rbErr)
+		}
+	}()
+
+	var title string
+	var editions int
+	err = tx.QueryRow(`
+		SELECT b.title, (SELECT COUNT(*) FROM books other WHERE other.work_id = b.work_id)
+		FROM books b
+		WHERE b.id = $1
+		FOR UPDATE OF b`, bookID,
+	).Scan(&title, &editions)
+	if err != nil {
+		return nil, err
+	}
+	if editions < 2 {
+		return nil, ErrSingleEdition
+	}
+
+	work := &Work{Title: title}
+	err = tx.QueryRow(`INSERT INTO works (title) VALUES ($1) RETURNING id`, title).Scan(&work.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE books SET work_id = $1 WHERE id = $2`, work.ID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE user_books SET work_id = $1 WHERE book_id = $2`, work.ID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return work, nil
+}
+
+func deleteWorkIfEmpty(tx *sql.Tx, workID int64) error {
+	_, err := tx.Exec(`
+		DELETE FROM works w
+		WHERE w.id = $1
+		AND NOT EXISTS (SELECT 1 FROM books b WHERE b.work_id = w.id)`, workID)
+	return err
+}
@@ -0,0 +1,169 @@
+// Package takeout writes a member's personal data export: a zip archive with
+// each part of their data twice, as JSON and as CSV. It also signs the links
+// the archives are downloaded from.
+package takeout
+
+import (
+	"archive/zip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+)
+
+// file is one part of the export, written as <name>.json and <name>.csv.
+type file struct {
+	name   string
+	data   any
+	header []string
+	rows   [][]string
+}
+
+// Write writes the archive of data to w.
+func Write(w io.Writer, data *store.UserExportData) error {
+	archive := zip.NewWriter(w)
+	for _, f := range files(data) {
+		if err := writeJSON(archive, f.name+".json", f.data); err != nil {
+			return err
+		}
+		if err := writeCSV(archive, f.name+".csv", f.header, f.rows); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func files(data *store.UserExportData) []file {
+	profile := data.Profile
+	profileFile := file{
+		name:   "profile",
+		data:   profile,
+		header: []string{"id", "username", "email", "role", "created_at"},
+		rows: [][]string{{
+			strconv.FormatInt(profile.ID, 10), profile.Username, profile.Email, string(profile.Role), formatTime(&profile.CreatedAt),
+		}},
+	}
+
+	userBooks := file{
+		name: "user_books",
+		data: data.UserBooks,
+		header: []string{
+			"id", "book_id", "title", "isbn_13", "status", "started_at", "completed_at",
+			"pages_read", "percentage_read", "rating", "progress_updated_at", "updated_at",
+		},
+	}
+	for _, ub := range data.UserBooks {
+		userBooks.rows = append(userBooks.rows, []string{
+			strconv.FormatInt(ub.ID, 10), strconv.FormatInt(ub.BookID, 10), ub.Title, ub.ISBN13, ub.Status,
+			formatTime(ub.StartedAt), formatTime(ub.CompletedAt), formatInt(ub.PagesRead),
+			formatFloat(ub.PercentageRead), formatFloat(ub.Rating), formatTime(ub.ProgressUpdatedAt), formatTime(&ub.UpdatedAt),
+		})
+	}
+
+	comments := file{
+		name: "comments",
+		data: data.Comments,
+		header: []string{
+			"id", "book_id", "book_title", "chapter_id", "chapter_number", "chapter_title", "body", "created_at", "updated_at",
+		},
+	}
+	for _, c := range data.Comments {
+		comments.rows = append(comments.rows, []string{
+			strconv.FormatInt(c.ID, 10), strconv.FormatInt(c.BookID, 10), c.BookTitle,
+			strconv.FormatInt(c.ChapterID, 10), strconv.Itoa(c.ChapterNumber), c.ChapterTitle, c.Body,
+			formatTime(&c.CreatedAt), formatTime(&c.UpdatedAt),
+		})
+	}
+
+	tags := file{
+		name:   "tags",
+		data:   data.Tags,
+		header: []string{"book_id", "book_title", "tag"},
+	}
+	for _, tag := range data.Tags {
+		tags.rows = append(tags.rows, []string{strconv.FormatInt(tag.BookID, 10), tag.BookTitle, tag.Tag})
+	}
+
+	tokens := file{
+		name:   "tokens",
+		data:   data.Tokens,
+		header: []string{"scope", "expiry"},
+	}
+	for _, token := range data.Tokens {
+		tokens.rows = append(tokens.rows, []string{token.Scope, formatTime(&token.Expiry)})
+	}
+
+	return []file{profileFile, userBooks, comments, tags, tokens}
+}
+
+func writeJSON(archive *zip.Writer, name string, data any) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func writeCSV(archive *zip.Writer, name string, header []string, rows [][]string) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+// Sign returns the signature of a download link for the export, valid until
+// expires.
+func Sign(key []byte, exportID int64, expires time.Time) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%d:%d", exportID, expires.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the export's signature for expires.
+// It does not check whether the link has expired.
+func Verify(key []byte, exportID int64, expires time.Time, signature string) bool {
+	want, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	got, _ := hex.DecodeString(Sign(key, exportID, expires))
+	return hmac.Equal(got, want)
+}
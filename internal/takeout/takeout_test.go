@@ -0,0 +1,86 @@
+package takeout
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/SamaraRuizSandoval/BookClubApp/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFile(t *testing.T, archive *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := archive.Open(name)
+	require.NoError(t, err, name)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return data
+}
+
+func TestWrite(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	pages := 120
+	data := &store.UserExportData{
+		Profile: &store.User{ID: 3, Username: "reader", Email: "reader@example.com", Role: store.RoleUser, CreatedAt: created},
+		UserBooks: []*store.ExportedUserBook{
+			{ID: 1, BookID: 7, Title: "The Hobbit", ISBN13: "9780261102217", Status: "reading", PagesRead: &pages, UpdatedAt: created},
+		},
+		Comments: []*store.ExportedComment{
+			{ID: 4, BookID: 7, BookTitle: "The Hobbit", ChapterID: 11, ChapterNumber: 1, ChapterTitle: "An Unexpected Party", Body: "Loved it,\n\"really\"", CreatedAt: created, UpdatedAt: created},
+		},
+		Tags:   []*store.ExportedTag{},
+		Tokens: []*store.ExportedToken{{Scope: "authentication", Expiry: created}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, data))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	names := []string{}
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{
+		"profile.json", "profile.csv", "user_books.json", "user_books.csv", "comments.json", "comments.csv",
+		"tags.json", "tags.csv", "tokens.json", "tokens.csv",
+	}, names)
+
+	var profile map[string]any
+	require.NoError(t, json.Unmarshal(readFile(t, archive, "profile.json"), &profile))
+	assert.Equal(t, "reader@example.com", profile["email"])
+	assert.NotContains(t, string(readFile(t, archive, "profile.json")), "password")
+
+	userBooks, err := csv.NewReader(bytes.NewReader(readFile(t, archive, "user_books.csv"))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, userBooks, 2)
+	assert.Equal(t, "pages_read", userBooks[0][7])
+	assert.Equal(t, "120", userBooks[1][7])
+	assert.Equal(t, "", userBooks[1][9], "no rating")
+
+	comments, err := csv.NewReader(bytes.NewReader(readFile(t, archive, "comments.csv"))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"4", "7", "The Hobbit", "11", "1", "An Unexpected Party", "Loved it,\n\"really\"", "2024-01-02T03:04:05Z", "2024-01-02T03:04:05Z"}, comments[1])
+
+	assert.JSONEq(t, `[]`, string(readFile(t, archive, "tags.json")))
+	assert.JSONEq(t, `[{"scope":"authentication","expiry":"2024-01-02T03:04:05Z"}]`, string(readFile(t, archive, "tokens.json")))
+}
+
+func TestSignAndVerify(t *testing.T) {
+	key := []byte("secret")
+	expires := time.Unix(1700000000, 0)
+	signature := Sign(key, 5, expires)
+
+	assert.True(t, Verify(key, 5, expires, signature))
+	assert.False(t, Verify(key, 6, expires, signature), "other export")
+	assert.False(t, Verify(key, 5, expires.Add(time.Hour), signature), "extended expiry")
+	assert.False(t, Verify([]byte("other"), 5, expires, signature), "other key")
+	assert.False(t, Verify(key, 5, expires, "not-hex"))
+}
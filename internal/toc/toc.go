@@ -0,0 +1,122 @@
+// Package toc parses tables of contents pasted from a book or a retailer's
+// page into chapters.
+package toc
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrEmpty = errors.New("table of contents has no chapters")
+
+// Entry is one chapter of a parsed table of contents. Pages are nil when the
+// line has no page number.
+type Entry struct {
+	// Part is the most recent "Part ..." or "Book ..." heading, or "".
+	Part      string
+	Title     string
+	StartPage *int
+	EndPage   *int
+}
+
+const numberWords = `one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|thirteen|fourteen|fifteen|sixteen|seventeen|eighteen|nineteen|twenty|thirty|forty|fifty|sixty|seventy|eighty|ninety`
+
+var (
+	// ordinal matches "12", "XII" or "Twenty-One".
+	ordinal = `(?:[0-9]+|[ivxlcdm]+|(?:` + numberWords + `)(?:-(?:` + numberWords + `))?)`
+
+	bulletPrefix = regexp.MustCompile(`^[•*·\-–—]\s+`)
+	partHeading  = regexp.MustCompile(`(?i)^(?:part|book)\s+` + ordinal + `\b`)
+	// chapterPrefix matches "Chapter 3:", "Ch. XII", "3." and "3 ". Bare roman
+	// numerals need punctuation so titles like "I Am Legend" are kept whole.
+	chapterPrefix = regexp.MustCompile(`(?i)^(?:(?:chapter|ch\.?)\s+` + ordinal + `\b\s*[.:)\-–—]?|[0-9]+\s*[.:)\-–—]?|[ivxlcdm]+\s*[.:)\-–—])\s*`)
+	// pageSuffix matches a trailing page or page range, optionally preceded
+	// by dot leaders: "..... 12", " 12", "\t12-30".
+	pageSuffix = regexp.MustCompile(`(?:\s*(?:\.{2,}|…+)\s*|\s+)([0-9]+)(?:\s*[-–]\s*([0-9]+))?$`)
+)
+
+// Parse reads one chapter per line. Blank lines are skipped, "Part One" and
+// "Book II" style lines set the part of the chapters that follow, and leading
+// chapter numbers are dropped since chapters are numbered in order.
+//
+// A trailing number is read as the chapter's start page, or a range like
+// "12-30" as its page range. When only start pages are given, each chapter is
+// assumed to end where the next one starts. Titles that end in a number
+// ("Catch 22") need a page number after them to be read correctly.
+func Parse(text string) ([]Entry, error) {
+	var entries []Entry
+	part := ""
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(bulletPrefix.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line == "" {
+			continue
+		}
+
+		if partHeading.MatchString(line) {
+			part, _, _ = splitPages(line)
+			continue
+		}
+
+		label := ""
+		if prefix := chapterPrefix.FindString(line); prefix != "" {
+			label = strings.TrimRight(strings.TrimSpace(prefix), ".:)-–—")
+			line = line[len(prefix):]
+		}
+
+		title, start, end := splitPages(line)
+		if title == "" {
+			// "Chapter 7 ..... 80": the label is all the title there is.
+			title = strings.TrimSpace(label)
+		}
+		if title == "" {
+			continue
+		}
+
+		entries = append(entries, Entry{Part: part, Title: title, StartPage: start, EndPage: end})
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrEmpty
+	}
+
+	for i := range entries[:len(entries)-1] {
+		current, next := &entries[i], entries[i+1]
+		if current.StartPage == nil || current.EndPage != nil || next.StartPage == nil {
+			continue
+		}
+		if *next.StartPage > *current.StartPage {
+			end := *next.StartPage - 1
+			current.EndPage = &end
+		} else if *next.StartPage == *current.StartPage {
+			end := *current.StartPage
+			current.EndPage = &end
+		}
+	}
+
+	return entries, nil
+}
+
+// splitPages separates a trailing page number or range from the title.
+func splitPages(line string) (string, *int, *int) {
+	match := pageSuffix.FindStringSubmatchIndex(line)
+	if match == nil {
+		return strings.TrimSpace(line), nil, nil
+	}
+
+	title := strings.TrimSpace(strings.TrimRight(line[:match[0]], ". …"))
+	start, err := strconv.Atoi(line[match[2]:match[3]])
+	if err != nil {
+		return strings.TrimSpace(line), nil, nil
+	}
+
+	var end *int
+	if match[4] >= 0 {
+		if n, err := strconv.Atoi(line[match[4]:match[5]]); err == nil {
+			end = &n
+		}
+	}
+	return title, &start, end
+}
@@ -0,0 +1,61 @@
+package toc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(`
+Part One: The Shire
+Chapter 1: A Long-expected Party ........ 21
+Chapter Two - The Shadow of the Past .... 44
+
+Book II
+III. Three is Company	70
+4 A Short Cut to Mushrooms 92-104
+`)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Part: "Part One: The Shire", Title: "A Long-expected Party", StartPage: intPtr(21), EndPage: intPtr(43)},
+		{Part: "Part One: The Shire", Title: "The Shadow of the Past", StartPage: intPtr(44), EndPage: intPtr(69)},
+		{Part: "Book II", Title: "Three is Company", StartPage: intPtr(70), EndPage: intPtr(91)},
+		{Part: "Book II", Title: "A Short Cut to Mushrooms", StartPage: intPtr(92), EndPage: intPtr(104)},
+	}, entries)
+}
+
+func TestParse_WithoutPages(t *testing.T) {
+	entries, err := Parse("Prologue\n• I Am Legend\nChapter 7\n1984")
+
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "Prologue", entries[0].Title)
+	assert.Equal(t, "I Am Legend", entries[1].Title)
+	assert.Equal(t, "Chapter 7", entries[2].Title)
+	assert.Equal(t, "1984", entries[3].Title)
+	for _, entry := range entries {
+		assert.Nil(t, entry.StartPage)
+		assert.Nil(t, entry.EndPage)
+		assert.Empty(t, entry.Part)
+	}
+}
+
+func TestParse_LabelOnlyChapterWithPage(t *testing.T) {
+	entries, err := Parse("Chapter 7 ..... 80")
+
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Title: "Chapter 7", StartPage: intPtr(80)}}, entries)
+}
+
+func TestParse_Empty(t *testing.T) {
+	_, err := Parse("\n  \nPart One\n")
+
+	assert.ErrorIs(t, err, ErrEmpty)
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag formats a record version as a strong entity tag.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// SetETag sets the response's ETag header to the record version.
+func SetETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", ETag(version))
+}
+
+// NotModified sets the ETag header for version and, when the request's
+// If-None-Match header already lists it, responds 304 Not Modified and
+// returns true.
+func NotModified(ctx *gin.Context, version int) bool {
+	SetETag(ctx, version)
+
+	header := ctx.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-None-Match uses weak comparison.
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == ETag(version) {
+			ctx.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ReadIfMatch returns the record versions listed in the request's If-Match
+// header. It returns nil when the header is missing or "*", so any version
+// matches, and an empty list when it lists no strong ETag we issued, so no
+// version matches. Weak ETags never match.
+func ReadIfMatch(ctx *gin.Context) []int {
+	header := ctx.GetHeader("If-Match")
+	if header == "" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions
+}
@@ -51,6 +51,20 @@ func ReadBookIDParam(ctx *gin.Context) (int64, error) {
 	return id, nil
 }
 
+func ReadUserIDParam(ctx *gin.Context) (int64, error) {
+	idParam := ctx.Param("user_id")
+	if idParam == "" {
+		return 0, errors.New("invalid id parameter")
+	}
+
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid id parameter type")
+	}
+
+	return id, nil
+}
+
 func ReadPaginationParams(ctx *gin.Context) (int, int, error) {
 	pageParam := ctx.DefaultQuery("page", "1")
 	limitParam := ctx.DefaultQuery("limit", "20")
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS genres (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    parent_id BIGINT REFERENCES genres(id) ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS genres_parent_name_key ON genres ((COALESCE(parent_id, 0)), (lower(name)));
+
+CREATE TABLE IF NOT EXISTS book_genres (
+    book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    genre_id BIGINT NOT NULL REFERENCES genres(id) ON DELETE CASCADE,
+    PRIMARY KEY (book_id, genre_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS book_genres;
+DROP TABLE IF EXISTS genres;
+-- +goose StatementEnd
\ No newline at end of file
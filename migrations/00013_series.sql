@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS series (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(150) NOT NULL,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS series_books (
+    series_id BIGINT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    book_id BIGINT NOT NULL UNIQUE REFERENCES books(id) ON DELETE CASCADE,
+    -- Fractional positions place novellas between volumes (e.g. 2.5)
+    position NUMERIC(6,2) NOT NULL,
+
+    PRIMARY KEY (series_id, book_id),
+    CONSTRAINT series_position_unique UNIQUE (series_id, position),
+    CONSTRAINT series_position_valid CHECK (position >= 0)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS series_books;
+DROP TABLE IF EXISTS series;
+-- +goose StatementEnd
\ No newline at end of file
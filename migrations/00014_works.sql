@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS works (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(150) NOT NULL
+);
+
+-- Every existing book starts out as the only edition of its own work.
+ALTER TABLE books ADD COLUMN work_id BIGINT REFERENCES works(id) ON DELETE RESTRICT;
+
+INSERT INTO works (id, title)
+SELECT id, title FROM books;
+
+SELECT setval('works_id_seq', COALESCE((SELECT MAX(id) FROM works), 0) + 1, false);
+
+UPDATE books SET work_id = id;
+
+ALTER TABLE books ALTER COLUMN work_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS books_work_id_idx ON books(work_id);
+
+-- A user shelves a work once; book_id records the edition they are reading.
+ALTER TABLE user_books ADD COLUMN work_id BIGINT REFERENCES works(id) ON DELETE CASCADE;
+
+UPDATE user_books ub SET work_id = b.work_id
+FROM books b
+WHERE ub.book_id = b.id;
+
+ALTER TABLE user_books ALTER COLUMN work_id SET NOT NULL;
+ALTER TABLE user_books DROP CONSTRAINT IF EXISTS user_book_unique;
+ALTER TABLE user_books ADD CONSTRAINT user_work_unique UNIQUE (user_id, work_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_books DROP CONSTRAINT IF EXISTS user_work_unique;
+ALTER TABLE user_books ADD CONSTRAINT user_book_unique UNIQUE (user_id, book_id);
+ALTER TABLE user_books DROP COLUMN IF EXISTS work_id;
+ALTER TABLE books DROP COLUMN IF EXISTS work_id;
+DROP TABLE IF EXISTS works;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS google_books_cache (
+    cache_key TEXT PRIMARY KEY,
+    payload JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS google_books_cache_expires_at_idx ON google_books_cache(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS google_books_cache;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Strip hyphens and spaces from stored ISBNs so lookups by normalized ISBN
+-- find them. ISBN-13s that would collide with another book once normalized
+-- are left alone.
+UPDATE books b
+SET isbn_13 = regexp_replace(b.isbn_13, '[-[:space:]]', '', 'g')
+WHERE b.isbn_13 ~ '[-[:space:]]'
+AND NOT EXISTS (
+    SELECT 1 FROM books other
+    WHERE other.id <> b.id
+    AND regexp_replace(other.isbn_13, '[-[:space:]]', '', 'g') = regexp_replace(b.isbn_13, '[-[:space:]]', '', 'g')
+);
+
+UPDATE books
+SET isbn_10 = upper(regexp_replace(isbn_10, '[-[:space:]]', '', 'g'))
+WHERE isbn_10 ~ '[-[:space:]x]';
+
+UPDATE books SET isbn_10 = NULL WHERE isbn_10 = '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Normalized ISBNs cannot be turned back into their original formatting.
+SELECT 1;
+-- +goose StatementEnd
\ No newline at end of file
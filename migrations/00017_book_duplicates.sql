@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS books_title_trgm_idx ON books USING GIN (lower(title) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS books_isbn_10_idx ON books(isbn_10);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS books_isbn_10_idx;
+DROP INDEX IF EXISTS books_title_trgm_idx;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chapters ADD COLUMN part VARCHAR(150);
+ALTER TABLE chapters ADD COLUMN start_page INT;
+ALTER TABLE chapters ADD COLUMN end_page INT;
+
+ALTER TABLE chapters ADD CONSTRAINT chapters_start_page_check CHECK (start_page > 0);
+ALTER TABLE chapters ADD CONSTRAINT chapters_page_range_check CHECK (end_page >= start_page);
+
+CREATE INDEX IF NOT EXISTS chapters_book_id_number_idx ON chapters(book_id, number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS chapters_book_id_number_idx;
+ALTER TABLE chapters DROP CONSTRAINT IF EXISTS chapters_page_range_check;
+ALTER TABLE chapters DROP CONSTRAINT IF EXISTS chapters_start_page_check;
+ALTER TABLE chapters DROP COLUMN IF EXISTS end_page;
+ALTER TABLE chapters DROP COLUMN IF EXISTS start_page;
+ALTER TABLE chapters DROP COLUMN IF EXISTS part;
+-- +goose StatementEnd
\ No newline at end of file
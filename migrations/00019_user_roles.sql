@@ -0,0 +1,19 @@
+-- +goose NO TRANSACTION
+
+-- +goose Up
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'editor';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'moderator';
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE users SET role = 'user' WHERE role IN ('editor', 'moderator');
+
+ALTER TYPE user_role RENAME TO user_role_old;
+CREATE TYPE user_role AS ENUM ('admin', 'user');
+
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE user_role USING role::text::user_role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+
+DROP TYPE user_role_old;
+-- +goose StatementEnd
\ No newline at end of file
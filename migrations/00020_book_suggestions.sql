@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE book_suggestion_status AS ENUM ('pending', 'approved', 'partially_applied', 'rejected');
+
+CREATE TABLE IF NOT EXISTS book_suggestions (
+    id BIGSERIAL PRIMARY KEY,
+    book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status book_suggestion_status NOT NULL DEFAULT 'pending',
+    comment TEXT,
+
+    -- The book's values of the changed fields when the suggestion was made,
+    -- and the proposed values, keyed by field name.
+    before JSONB NOT NULL,
+    after JSONB NOT NULL,
+    applied_fields JSONB NOT NULL DEFAULT '[]',
+
+    reviewer_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    review_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS book_suggestions_status_idx ON book_suggestions(status, created_at);
+CREATE INDEX IF NOT EXISTS book_suggestions_book_id_idx ON book_suggestions(book_id);
+CREATE INDEX IF NOT EXISTS book_suggestions_user_id_idx ON book_suggestions(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS book_suggestions;
+DROP TYPE IF EXISTS book_suggestion_status;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Archived books are hidden from the catalog but kept for the readers who
+-- shelved them. Only archived books can be purged.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS books_archived_at_idx ON books(archived_at) WHERE archived_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS books_archived_at_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
\ No newline at end of file
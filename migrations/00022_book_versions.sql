@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE book_version_action AS ENUM ('created', 'updated', 'reverted');
+
+-- Each row is a snapshot of a book's catalog data after a change, numbered
+-- per book. Books created before history was recorded get their first
+-- snapshot on their next update.
+CREATE TABLE IF NOT EXISTS book_versions (
+    id BIGSERIAL PRIMARY KEY,
+    book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    action book_version_action NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    snapshot JSONB NOT NULL,
+    changed_fields JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (book_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS book_versions_user_id_idx ON book_versions(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS book_versions;
+DROP TYPE IF EXISTS book_version_action;
+-- +goose StatementEnd
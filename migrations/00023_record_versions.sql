@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+-- version is bumped on every change so clients can send it back as an
+-- If-Match precondition and detect concurrent edits.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE user_books ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    IF NEW.version = OLD.version AND NEW IS DISTINCT FROM OLD THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER books_bump_version BEFORE UPDATE ON books
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER comments_bump_version BEFORE UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER user_books_bump_version BEFORE UPDATE ON user_books
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+
+-- A book's authors, images, chapters, genres, tags and series are part of
+-- the book as clients see it, so changing them bumps the book's version.
+CREATE OR REPLACE FUNCTION bump_book_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE books SET version = version + 1 WHERE id = OLD.book_id;
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.book_id <> OLD.book_id) THEN
+        UPDATE books SET version = version + 1 WHERE id = NEW.book_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER book_authors_bump_book_version AFTER INSERT OR UPDATE OR DELETE ON book_authors
+    FOR EACH ROW EXECUTE FUNCTION bump_book_version();
+CREATE TRIGGER book_images_bump_book_version AFTER INSERT OR UPDATE OR DELETE ON book_images
+    FOR EACH ROW EXECUTE FUNCTION bump_book_version();
+CREATE TRIGGER chapters_bump_book_version AFTER INSERT OR UPDATE OR DELETE ON chapters
+    FOR EACH ROW EXECUTE FUNCTION bump_book_version();
+CREATE TRIGGER book_genres_bump_book_version AFTER INSERT OR UPDATE OR DELETE ON book_genres
+    FOR EACH ROW EXECUTE FUNCTION bump_book_version();
+CREATE TRIGGER book_tags_bump_book_version AFTER INSERT OR UPDATE OR DELETE ON book_tags
+    FOR EACH ROW EXECUTE FUNCTION bump_book_version();
+CREATE TRIGGER series_books_bump_book_version AFTER INSERT OR UPDATE OR DELETE ON series_books
+    FOR EACH ROW EXECUTE FUNCTION bump_book_version();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS series_books_bump_book_version ON series_books;
+DROP TRIGGER IF EXISTS book_tags_bump_book_version ON book_tags;
+DROP TRIGGER IF EXISTS book_genres_bump_book_version ON book_genres;
+DROP TRIGGER IF EXISTS chapters_bump_book_version ON chapters;
+DROP TRIGGER IF EXISTS book_images_bump_book_version ON book_images;
+DROP TRIGGER IF EXISTS book_authors_bump_book_version ON book_authors;
+DROP FUNCTION IF EXISTS bump_book_version();
+
+DROP TRIGGER IF EXISTS user_books_bump_version ON user_books;
+DROP TRIGGER IF EXISTS comments_bump_version ON comments;
+DROP TRIGGER IF EXISTS books_bump_version ON books;
+DROP FUNCTION IF EXISTS bump_version();
+
+ALTER TABLE user_books DROP COLUMN IF EXISTS version;
+ALTER TABLE comments DROP COLUMN IF EXISTS version;
+ALTER TABLE books DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bulk imports run in the background. Each row of the uploaded file is kept
+-- with its result, so a job can be resumed after a restart and its report
+-- read once it is done.
+CREATE TYPE import_job_status AS ENUM ('queued', 'running', 'completed', 'failed');
+CREATE TYPE import_row_status AS ENUM ('pending', 'imported', 'valid', 'skipped', 'invalid', 'failed');
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    format TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    status import_job_status NOT NULL DEFAULT 'queued',
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS import_jobs_kind_idx ON import_jobs(kind, created_at DESC);
+CREATE INDEX IF NOT EXISTS import_jobs_unfinished_idx ON import_jobs(status) WHERE status IN ('queued', 'running');
+
+CREATE TABLE IF NOT EXISTS import_rows (
+    job_id BIGINT NOT NULL REFERENCES import_jobs(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    status import_row_status NOT NULL DEFAULT 'pending',
+    -- The row as read from the file.
+    data JSONB NOT NULL,
+    book_id BIGINT REFERENCES books(id) ON DELETE SET NULL,
+    -- Problems with the row, keyed by field name.
+    errors JSONB,
+    PRIMARY KEY (job_id, row_number)
+);
+
+CREATE INDEX IF NOT EXISTS import_rows_status_idx ON import_rows(job_id, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS import_rows;
+DROP TABLE IF EXISTS import_jobs;
+DROP TYPE IF EXISTS import_row_status;
+DROP TYPE IF EXISTS import_job_status;
+-- +goose StatementEnd
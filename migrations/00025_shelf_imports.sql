@@ -0,0 +1,27 @@
+-- +goose NO TRANSACTION
+
+-- +goose Up
+ALTER TABLE user_books ADD COLUMN IF NOT EXISTS rating NUMERIC(3,2)
+    CONSTRAINT rating_valid CHECK (rating > 0 AND rating <= 5);
+
+-- Results of the rows of a shelf import: the book was found in the catalog,
+-- added to it from the metadata providers, or could not be found at all.
+ALTER TYPE import_row_status ADD VALUE IF NOT EXISTS 'matched';
+ALTER TYPE import_row_status ADD VALUE IF NOT EXISTS 'created';
+ALTER TYPE import_row_status ADD VALUE IF NOT EXISTS 'unmatched';
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM import_rows WHERE status IN ('matched', 'created', 'unmatched');
+
+ALTER TYPE import_row_status RENAME TO import_row_status_old;
+CREATE TYPE import_row_status AS ENUM ('pending', 'imported', 'valid', 'skipped', 'invalid', 'failed');
+
+ALTER TABLE import_rows ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE import_rows ALTER COLUMN status TYPE import_row_status USING status::text::import_row_status;
+ALTER TABLE import_rows ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE import_row_status_old;
+
+ALTER TABLE user_books DROP COLUMN IF EXISTS rating;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Personal data exports are built in the background. The archive is kept
+-- until expires_at, when it is deleted and the export marked expired.
+CREATE TYPE user_export_status AS ENUM ('queued', 'running', 'completed', 'failed', 'expired');
+
+CREATE TABLE IF NOT EXISTS user_exports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status user_export_status NOT NULL DEFAULT 'queued',
+    error TEXT,
+    size_bytes BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS user_exports_user_idx ON user_exports(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS user_exports_unfinished_idx ON user_exports(status) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS user_exports_expires_idx ON user_exports(expires_at) WHERE status = 'completed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_exports;
+DROP TYPE IF EXISTS user_export_status;
+-- +goose StatementEnd
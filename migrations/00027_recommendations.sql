@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Both tables are rebuilt from user_books by the recommendations job, so
+-- their contents lag behind the shelves until its next run.
+
+-- "Readers who completed this book also completed these": the cosine
+-- similarity between the sets of readers who completed each pair of books.
+CREATE TABLE IF NOT EXISTS book_similarities (
+    book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    similar_book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    -- How many readers completed both books.
+    co_readers INT NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (book_id, similar_book_id)
+);
+
+CREATE INDEX IF NOT EXISTS book_similarities_score_idx ON book_similarities(book_id, score DESC);
+
+-- Books similar to the ones a user completed, leaving out works already on
+-- their shelves.
+CREATE TABLE IF NOT EXISTS user_recommendations (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS user_recommendations_score_idx ON user_recommendations(user_id, score DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_recommendations;
+DROP TABLE IF EXISTS book_similarities;
+-- +goose StatementEnd
@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A book's history entries are numbered separately from books.version, the
+-- counter clients send back in If-Match, so they are called revisions.
+ALTER TABLE book_versions RENAME TO book_revisions;
+ALTER TABLE book_revisions RENAME COLUMN version TO revision;
+ALTER TABLE book_revisions RENAME CONSTRAINT book_versions_pkey TO book_revisions_pkey;
+ALTER TABLE book_revisions RENAME CONSTRAINT book_versions_book_id_version_key TO book_revisions_book_id_revision_key;
+ALTER SEQUENCE book_versions_id_seq RENAME TO book_revisions_id_seq;
+ALTER INDEX book_versions_user_id_idx RENAME TO book_revisions_user_id_idx;
+ALTER TYPE book_version_action RENAME TO book_revision_action;
+
+-- Tags are added by each reader for themselves, so tagging a book must not
+-- make an editor's If-Match fail.
+DROP TRIGGER IF EXISTS book_tags_bump_book_version ON book_tags;
+
+-- A book's version is bumped once per transaction, however many of its rows
+-- the transaction changes, so replacing its chapters is a single change. The
+-- setting is local to the transaction and marks the book as already bumped.
+CREATE OR REPLACE FUNCTION bump_book_version_once(book BIGINT) RETURNS BOOLEAN AS $$
+BEGIN
+    IF current_setting('bookclub.book_version_bumped_' || book, true) IS NOT DISTINCT FROM '1' THEN
+        RETURN FALSE;
+    END IF;
+    PERFORM set_config('bookclub.book_version_bumped_' || book, '1', true);
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION bump_books_version() RETURNS trigger AS $$
+BEGIN
+    IF NEW.version = OLD.version AND NEW IS DISTINCT FROM OLD AND bump_book_version_once(OLD.id) THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS books_bump_version ON books;
+CREATE TRIGGER books_bump_version BEFORE UPDATE ON books
+    FOR EACH ROW EXECUTE FUNCTION bump_books_version();
+
+CREATE OR REPLACE FUNCTION bump_book_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND bump_book_version_once(OLD.book_id) THEN
+        UPDATE books SET version = version + 1 WHERE id = OLD.book_id;
+    END IF;
+    IF (TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.book_id <> OLD.book_id))
+        AND bump_book_version_once(NEW.book_id) THEN
+        UPDATE books SET version = version + 1 WHERE id = NEW.book_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION bump_book_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE books SET version = version + 1 WHERE id = OLD.book_id;
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.book_id <> OLD.book_id) THEN
+        UPDATE books SET version = version + 1 WHERE id = NEW.book_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS books_bump_version ON books;
+CREATE TRIGGER books_bump_version BEFORE UPDATE ON books
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+DROP FUNCTION IF EXISTS bump_books_version();
+DROP FUNCTION IF EXISTS bump_book_version_once(BIGINT);
+
+CREATE TRIGGER book_tags_bump_book_version AFTER INSERT OR UPDATE OR DELETE ON book_tags
+    FOR EACH ROW EXECUTE FUNCTION bump_book_version();
+
+ALTER TYPE book_revision_action RENAME TO book_version_action;
+ALTER INDEX book_revisions_user_id_idx RENAME TO book_versions_user_id_idx;
+ALTER SEQUENCE book_revisions_id_seq RENAME TO book_versions_id_seq;
+ALTER TABLE book_revisions RENAME CONSTRAINT book_revisions_book_id_revision_key TO book_versions_book_id_version_key;
+ALTER TABLE book_revisions RENAME CONSTRAINT book_revisions_pkey TO book_versions_pkey;
+ALTER TABLE book_revisions RENAME COLUMN revision TO version;
+ALTER TABLE book_revisions RENAME TO book_versions;
+-- +goose StatementEnd